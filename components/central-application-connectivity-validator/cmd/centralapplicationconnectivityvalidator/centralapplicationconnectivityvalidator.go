@@ -15,11 +15,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	"github.com/kyma-project/kyma/common/logging/logger"
-	"github.com/kyma-project/kyma/common/logging/tracing"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apis/applicationconnector/v1alpha1"
 
 	"github.com/kyma-project/kyma/components/central-application-connectivity-validator/internal/controller"
 	"github.com/kyma-project/kyma/components/central-application-connectivity-validator/internal/externalapi"
+	"github.com/kyma-project/kyma/components/central-application-connectivity-validator/internal/tracing"
 	"github.com/kyma-project/kyma/components/central-application-connectivity-validator/internal/validationproxy"
 	"github.com/oklog/run"
 	"github.com/patrickmn/go-cache"
@@ -86,6 +86,19 @@ func main() {
 
 	log.WithContext().With("options", options).Info("Starting Validation Proxy.")
 
+	shutdownTracing, err := tracing.Init(context.Background(), "central-application-connectivity-validator", options.tracingCollectorURL)
+	if err != nil {
+		log.WithContext().Error("Unable to initialize tracing: %s", err.Error())
+		os.Exit(1)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.WithContext().Warnf("Failed to flush spans: %s", err.Error())
+		}
+	}()
+
 	idCache := cache.New(
 		cache.NoExpiration,
 		cache.NoExpiration,
@@ -103,7 +116,7 @@ func main() {
 		idCache,
 		log)
 
-	tracingMiddleware := tracing.NewTracingMiddleware(proxyHandler.ProxyAppConnectorRequests)
+	tracingMiddleware := tracing.NewHandler(http.HandlerFunc(proxyHandler.ProxyAppConnectorRequests), "validation-proxy")
 
 	proxyServer := http.Server{
 		Handler: validationproxy.NewHandler(tracingMiddleware),
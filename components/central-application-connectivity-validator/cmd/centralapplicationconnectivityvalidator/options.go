@@ -20,6 +20,7 @@ type args struct {
 	eventingDestinationPath  string
 	appNamePlaceholder       string
 	syncPeriod               time.Duration
+	tracingCollectorURL      string
 }
 
 type config struct {
@@ -42,6 +43,7 @@ func parseOptions() (*options, error) {
 	eventingPathPrefixEvents := flag.String("eventingPathPrefixEvents", "/events", "Prefix of paths that is directed to the Cloud Events based Eventing")
 	appNamePlaceholder := flag.String("appNamePlaceholder", "%%APP_NAME%%", "Path URL placeholder used for an application name")
 	syncPeriod := flag.Duration("syncPeriod", 45*time.Second, "Sync period in seconds how often controller should periodically reconcile Application resource.")
+	tracingCollectorURL := flag.String("tracingCollectorURL", "", "URL of the OpenTelemetry collector receiving spans over OTLP/HTTP, for example http://localhost:4318/v1/traces. Spans aren't exported if empty")
 
 	flag.Parse()
 
@@ -61,6 +63,7 @@ func parseOptions() (*options, error) {
 			eventingDestinationPath:  *eventingDestinationPath,
 			appNamePlaceholder:       *appNamePlaceholder,
 			syncPeriod:               *syncPeriod,
+			tracingCollectorURL:      *tracingCollectorURL,
 		},
 		config: c,
 	}, nil
@@ -72,12 +75,12 @@ func (o *options) String() string {
 		"--eventingPathPrefixEvents=%s --eventingPublisherHost=%s "+
 		"--eventingDestinationPath=%s "+
 		"--appNamePlaceholder=%s "+
-		"--syncPeriod=%d APP_LOG_FORMAT=%s APP_LOG_LEVEL=%s KUBECONFIG=%s",
+		"--syncPeriod=%d --tracingCollectorURL=%s APP_LOG_FORMAT=%s APP_LOG_LEVEL=%s KUBECONFIG=%s",
 		o.proxyPort, o.externalAPIPort,
 		o.eventingPathPrefixV1, o.eventingPathPrefixV2, o.eventingPathPrefixEvents,
 		o.eventingPublisherHost, o.eventingDestinationPath,
 		o.appNamePlaceholder,
-		o.syncPeriod, o.LogFormat, o.LogLevel, os.Getenv(clientcmd.RecommendedConfigPathEnvVar))
+		o.syncPeriod, o.tracingCollectorURL, o.LogFormat, o.LogLevel, os.Getenv(clientcmd.RecommendedConfigPathEnvVar))
 }
 
 func (o *options) validate() error {
@@ -15,8 +15,8 @@ import (
 	"strings"
 
 	"github.com/kyma-project/kyma/common/logging/logger"
-	"github.com/kyma-project/kyma/common/logging/tracing"
 	"github.com/kyma-project/kyma/components/central-application-connectivity-validator/internal/controller"
+	"github.com/kyma-project/kyma/components/central-application-connectivity-validator/internal/tracing"
 	"github.com/kyma-project/kyma/components/central-application-connectivity-validator/internal/validationproxy"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apis/applicationconnector/v1alpha1"
 	"github.com/patrickmn/go-cache"
@@ -123,7 +123,7 @@ var _ = BeforeSuite(func() {
 		log,
 		validationproxy.WithCEProxyTransport(ceProxyTransport))
 
-	tracingMiddleware := tracing.NewTracingMiddleware(proxyHandler.ProxyAppConnectorRequests)
+	tracingMiddleware := tracing.NewHandler(http.HandlerFunc(proxyHandler.ProxyAppConnectorRequests), "validation-proxy")
 
 	go func() {
 		defer GinkgoRecover()
@@ -0,0 +1,88 @@
+// Package tracing configures OpenTelemetry tracing with the W3C Trace Context propagation
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	instrumentationName = "github.com/kyma-project/kyma/components/central-application-connectivity-validator"
+
+	attributeApplication = attribute.Key("kyma.application")
+)
+
+// ShutdownFunc flushes the spans which weren't exported yet
+type ShutdownFunc func(ctx context.Context) error
+
+// Init sets the W3C Trace Context propagator and the tracer provider exporting spans over OTLP/HTTP to the collector at collectorURL.
+// If collectorURL is empty, spans aren't recorded, but the trace context of incoming calls is still passed to Eventing.
+func Init(ctx context.Context, serviceName, collectorURL string) (ShutdownFunc, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if collectorURL == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(collectorURL))
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.AlwaysSample())),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// NewHandler starts a server span for every incoming call, continuing the trace passed in the traceparent header
+func NewHandler(handler http.Handler, operation string) http.Handler {
+	return otelhttp.NewHandler(handler, operation)
+}
+
+// NewTransport starts a client span for every outgoing call and passes the trace context in the traceparent header
+func NewTransport(transport http.RoundTripper) http.RoundTripper {
+	return otelhttp.NewTransport(transport)
+}
+
+// StartSpan starts an internal span of the validator
+func StartSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name)
+}
+
+// SetApplication adds the name of the Application to the span of the incoming call
+func SetApplication(ctx context.Context, applicationName string) {
+	trace.SpanFromContext(ctx).SetAttributes(attributeApplication.String(applicationName))
+}
+
+// End records the error, if any, and ends the span
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
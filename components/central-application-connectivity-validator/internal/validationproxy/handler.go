@@ -5,6 +5,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/kyma-project/kyma/components/central-application-connectivity-validator/internal/controller"
 	"github.com/kyma-project/kyma/components/central-application-connectivity-validator/internal/httptools"
+	"github.com/kyma-project/kyma/components/central-application-connectivity-validator/internal/tracing"
 	"net"
 	"net/http"
 	"net/http/httputil"
@@ -90,18 +91,11 @@ func (ph *proxyHandler) ProxyAppConnectorRequests(w http.ResponseWriter, r *http
 		return
 	}
 
+	tracing.SetApplication(r.Context(), applicationName)
 	ph.log.WithTracing(r.Context()).With("handler", handlerName).With("application", applicationName).With("proxyPath", r.URL.Path).Infof("Proxying request for application...")
 
-	applicationClientIDs, err := ph.getCompassMetadataClientIDs(applicationName)
-	if err != nil {
-		httptools.RespondWithError(ph.log.WithTracing(r.Context()).With("handler", handlerName).With("applicationName", applicationName), w, apperrors.NotFound("while getting application ClientIds: %s", err))
-		return
-	}
-
-	subjects := ph.extractSubjects(certInfoData)
-
-	if !hasValidSubject(subjects, applicationClientIDs, applicationName) {
-		httptools.RespondWithError(ph.log.WithTracing(r.Context()).With("handler", handlerName).With("applicationName", applicationName), w, apperrors.Forbidden("no valid subject found"))
+	if err := ph.validateSubject(r, certInfoData, applicationName); err != nil {
+		httptools.RespondWithError(ph.log.WithTracing(r.Context()).With("handler", handlerName).With("applicationName", applicationName), w, err)
 		return
 	}
 
@@ -114,6 +108,26 @@ func (ph *proxyHandler) ProxyAppConnectorRequests(w http.ResponseWriter, r *http
 	reverseProxy.ServeHTTP(w, r)
 }
 
+func (ph *proxyHandler) validateSubject(r *http.Request, certInfoData, applicationName string) (err apperrors.AppError) {
+	_, span := tracing.StartSpan(r.Context(), "subject validation")
+	defer func() {
+		tracing.End(span, err)
+	}()
+
+	applicationClientIDs, err := ph.getCompassMetadataClientIDs(applicationName)
+	if err != nil {
+		return apperrors.NotFound("while getting application ClientIds: %s", err)
+	}
+
+	subjects := ph.extractSubjects(certInfoData)
+
+	if !hasValidSubject(subjects, applicationClientIDs, applicationName) {
+		return apperrors.Forbidden("no valid subject found")
+	}
+
+	return nil
+}
+
 func (ph *proxyHandler) getCompassMetadataClientIDs(applicationName string) ([]string, apperrors.AppError) {
 	applicationClientIDs, found := ph.getClientIDsFromCache(applicationName)
 	if !found {
@@ -264,7 +278,7 @@ func createReverseProxy(log *logger.Logger, destinationHost string, reqOpts ...r
 			}
 			return nil
 		},
-		Transport: &http.Transport{
+		Transport: tracing.NewTransport(&http.Transport{
 			DialContext: (&net.Dialer{
 				Timeout:   30 * time.Second,
 				KeepAlive: 30 * time.Second,
@@ -277,7 +291,7 @@ func createReverseProxy(log *logger.Logger, destinationHost string, reqOpts ...r
 			IdleConnTimeout:       10 * time.Second,
 			TLSHandshakeTimeout:   10 * time.Second,
 			ExpectContinueTimeout: 1 * time.Second,
-		},
+		}),
 	}
 }
 
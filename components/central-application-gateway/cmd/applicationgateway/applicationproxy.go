@@ -7,31 +7,45 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/accesscontrol"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/accesslog"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/circuitbreaker"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf"
 	csrfClient "github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf/client"
 	csrfStrategy "github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf/strategy"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/drain"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/externalapi"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/invalidation"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/applications"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/configmaps"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/secrets"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/serviceapi"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/proxy"
-	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/ratelimit"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/responsecache"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/tracing"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/client/clientset/versioned"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/client/informers/externalversions"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httptools"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/proxyconfig"
 	"github.com/oklog/run"
 	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
-	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
 const (
-	shutdownTimeout = 2 * time.Second
+	tracingShutdownTimeout     = 2 * time.Second
+	externalAPIShutdownTimeout = 2 * time.Second
 )
 
 func main() {
@@ -63,6 +77,23 @@ func main() {
 		setupLogger.Fatal("Couldn't initiate logger", zap.Error(err))
 	}
 
+	shutdownTracing, err := tracing.Init(context.Background(), "central-application-gateway", options.tracingCollectorURL)
+	if err != nil {
+		log.Fatal("Unable to initialize tracing", zap.Error(err))
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Warn("Failed to export remaining spans", zap.Error(err))
+		}
+	}()
+
+	accessLog, err := newAccessLog(options)
+	if err != nil {
+		log.Fatal("Unable to create access log", zap.Error(err))
+	}
+
 	k8sConfig, err := clientcmd.BuildConfigFromFlags(options.apiServerURL, options.kubeConfig)
 	if err != nil {
 		log.Fatal("Error reading in cluster config", zap.Error(err))
@@ -73,22 +104,83 @@ func main() {
 		log.Fatal("Error creating core clientset", zap.Error(err))
 	}
 
-	serviceDefinitionService, err := newServiceDefinitionService(
-		k8sConfig,
-		coreClientset,
-		options.applicationSecretsNamespace,
-	)
+	applicationClientset, err := versioned.NewForConfig(k8sConfig)
 	if err != nil {
-		log.Fatal("Unable to create ServiceDefinitionService:'", zap.Error(err))
+		log.Fatal("Error creating application clientset", zap.Error(err))
+	}
+
+	applicationInformerFactory := externalversions.NewSharedInformerFactory(applicationClientset, 0)
+	applicationInformer := applicationInformerFactory.Applicationconnector().V1alpha1().Applications()
+	coreInformerFactory := informers.NewSharedInformerFactoryWithOptions(coreClientset, 0, informers.WithNamespace(options.applicationSecretsNamespace))
+	secretInformer := coreInformerFactory.Core().V1().Secrets()
+	configMapInformer := coreInformerFactory.Core().V1().ConfigMaps()
+
+	secretsRepository := newSecretsRepository(secretInformer.Lister().Secrets(options.applicationSecretsNamespace), options)
+	destinationSelector, err := labels.Parse(options.destinationSecretSelector)
+	if err != nil || destinationSelector.Empty() {
+		log.Fatal("Invalid selector of the destination Secrets, it must select some labels", zap.String("selector", options.destinationSecretSelector), zap.Error(err))
 	}
+	destinationSecretsRepository := secrets.NewSelectorRepository(secretInformer.Lister().Secrets(options.applicationSecretsNamespace), destinationSelector)
+	configMapsRepository := configmaps.NewRepository(configMapInformer.Lister().ConfigMaps(options.applicationSecretsNamespace))
+	applicationServiceRepository := applications.NewServiceRepository(applicationInformer.Lister())
+	serviceDefinitionService := newServiceDefinitionService(applicationServiceRepository, secretsRepository, configMapsRepository)
+
+	proxyCache := proxy.NewCache(options.proxyCacheTTL)
+	proxyCacheForCompass := proxy.NewCache(options.proxyCacheTTL)
+	proxyCacheForDestinations := proxy.NewCache(options.proxyCacheTTL)
+	responseCache := newResponseCache(options)
+	authorizer := accesscontrol.NewAuthorizer(coreClientset.AuthenticationV1().TokenReviews(), accesscontrol.Config{
+		TokenAudience:            options.callerTokenAudience,
+		TrustForwardedClientCert: options.trustForwardedClientCert,
+	})
+	proxyConfig := getProxyConfig(options, newCircuitBreakers(options), ratelimit.New(), responseCache, authorizer, secretsRepository)
+
+	internalHandler := newInternalHandler(serviceDefinitionService, proxyConfig.WithCache(proxyCache), options)
+	internalHandlerForCompass := newInternalHandlerForCompass(serviceDefinitionService, proxyConfig.WithCache(proxyCacheForCompass), options)
+	internalHandlerForDestinations := newInternalHandlerForDestinations(destinationSecretsRepository, proxyConfig.WithCache(proxyCacheForDestinations), options)
+	explainers := map[string]proxy.Explainer{
+		externalapi.ExplainModeStandalone: proxy.NewExplainer(serviceDefinitionService, applicationServiceRepository),
+		externalapi.ExplainModeCompass:    proxy.NewExplainerForCompass(serviceDefinitionService, applicationServiceRepository),
+	}
+	drainTracker := drain.NewTracker()
+	externalHandler := externalapi.NewHandler(logCfg.Level, proxyConfig.CircuitBreakers, explainers, options.explainTokenFile, drainTracker.Ready)
 
-	internalHandler := newInternalHandler(serviceDefinitionService, options)
-	internalHandlerForCompass := newInternalHandlerForCompass(serviceDefinitionService, options)
-	externalHandler := externalapi.NewHandler(logCfg.Level)
+	applicationCaches := []invalidation.Cache{proxyCache, proxyCacheForCompass}
+	if responseCache != nil {
+		applicationCaches = append(applicationCaches, responseCache)
+	}
+	destinationCaches := []invalidation.Cache{proxyCacheForDestinations}
+	// the path rewrites of the Application are compiled again when it changes, the Secrets and ConfigMaps don't change them
+	applicationEventCaches := append([]invalidation.Cache{applicationServiceRepository}, applicationCaches...)
+	if _, err := applicationInformer.Informer().AddEventHandler(invalidation.NewApplicationEventHandler(applicationEventCaches...)); err != nil {
+		log.Fatal("Unable to watch Applications", zap.Error(err))
+	}
+	if _, err := secretInformer.Informer().AddEventHandler(invalidation.NewSecretEventHandler(applicationInformer.Lister(), applicationCaches, destinationCaches)); err != nil {
+		log.Fatal("Unable to watch secrets", zap.Error(err))
+	}
+	if _, err := configMapInformer.Informer().AddEventHandler(invalidation.NewConfigMapEventHandler(applicationInformer.Lister(), applicationCaches)); err != nil {
+		log.Fatal("Unable to watch ConfigMaps", zap.Error(err))
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	applicationInformerFactory.Start(stopCh)
+	coreInformerFactory.Start(stopCh)
+	for informerType, synced := range applicationInformerFactory.WaitForCacheSync(stopCh) {
+		if !synced {
+			log.Fatal("Failed to sync informer cache", zap.Stringer("type", informerType))
+		}
+	}
+	for informerType, synced := range coreInformerFactory.WaitForCacheSync(stopCh) {
+		if !synced {
+			log.Fatal("Failed to sync informer cache", zap.Stringer("type", informerType))
+		}
+	}
 
-	internalHandler = httptools.RequestLogger("Internal handler: ", internalHandler)
-	internalHandlerForCompass = httptools.RequestLogger("Internal handler: ", internalHandlerForCompass)
-	externalHandler = httptools.RequestLogger("External handler: ", externalHandler)
+	internalHandler = drainTracker.Handler(tracing.NewHandler(accessLog.NewHandler(internalHandler), "proxy-kyma-os"))
+	internalHandlerForCompass = drainTracker.Handler(tracing.NewHandler(accessLog.NewHandler(internalHandlerForCompass), "proxy-kyma-mps"))
+	internalHandlerForDestinations = drainTracker.Handler(tracing.NewHandler(accessLog.NewHandler(internalHandlerForDestinations), "proxy-destination"))
+	externalHandler = drainTracker.Handler(httptools.RequestLogger("External handler: ", externalHandler))
 
 	externalSrv := &http.Server{
 		Addr:         ":" + strconv.Itoa(options.externalAPIPort),
@@ -101,20 +193,31 @@ func main() {
 		Addr:        ":" + strconv.Itoa(options.proxyPort),
 		Handler:     internalHandler,
 		ReadTimeout: time.Duration(options.requestTimeout) * time.Second,
+		Protocols:   proxyProtocols(),
 	}
 
 	internalSrvCompass := &http.Server{
 		Addr:        ":" + strconv.Itoa(options.proxyPortCompass),
 		Handler:     internalHandlerForCompass,
 		ReadTimeout: time.Duration(options.requestTimeout) * time.Second,
+		Protocols:   proxyProtocols(),
+	}
+
+	internalSrvDestinations := &http.Server{
+		Addr:        ":" + strconv.Itoa(options.proxyPortDestination),
+		Handler:     internalHandlerForDestinations,
+		ReadTimeout: time.Duration(options.requestTimeout) * time.Second,
+		Protocols:   proxyProtocols(),
 	}
 
 	var g run.Group
 
-	addHttpServerToRunGroup("external-api", &g, externalSrv)
-	addHttpServerToRunGroup("proxy-kyma-os", &g, internalSrv)
-	addHttpServerToRunGroup("proxy-kyma-mps", &g, internalSrvCompass)
-	addInterruptSignalToRunGroup(&g)
+	shutdown := newGracefulShutdown(drainTracker, externalSrv, options)
+	addHttpServerToRunGroup("external-api", &g, externalSrv, shutdown)
+	addHttpServerToRunGroup("proxy-kyma-os", &g, internalSrv, shutdown)
+	addHttpServerToRunGroup("proxy-kyma-mps", &g, internalSrvCompass, shutdown)
+	addHttpServerToRunGroup("proxy-destination", &g, internalSrvDestinations, shutdown)
+	addInterruptSignalToRunGroup(&g, shutdown)
 
 	err = g.Run()
 	if err != nil && err != http.ErrServerClosed {
@@ -122,8 +225,105 @@ func main() {
 	}
 }
 
-func addHttpServerToRunGroup(name string, g *run.Group, srv *http.Server) {
+// gracefulShutdown stops the servers without cutting calls. On SIGTERM, the gateway stops being ready and keeps serving calls
+// for the drain period, so that it's removed from the endpoints of its service. Then the proxy servers close their listeners and
+// wait for the calls in flight, all of them together at most for the shutdown timeout. The external API serving the probes and
+// the metrics is shut down last, so that the calls in flight are observed until they end.
+type gracefulShutdown struct {
+	tracker     *drain.Tracker
+	drainPeriod time.Duration
+	timeout     time.Duration
+	externalAPI *http.Server
+	servers     []namedServer
+
+	once sync.Once
+}
+
+type namedServer struct {
+	name string
+	srv  *http.Server
+}
+
+func newGracefulShutdown(tracker *drain.Tracker, externalAPI *http.Server, options options) *gracefulShutdown {
+	return &gracefulShutdown{
+		tracker:     tracker,
+		drainPeriod: time.Duration(options.shutdownDrainPeriod) * time.Second,
+		timeout:     time.Duration(options.shutdownTimeout) * time.Second,
+		externalAPI: externalAPI,
+	}
+}
+
+// startDraining fails the readiness probe and closes idle connections after their next call, so that clients move to other instances
+func (s *gracefulShutdown) startDraining() {
+	s.tracker.StartDraining()
+	for _, server := range s.servers {
+		server.srv.SetKeepAlivesEnabled(false)
+	}
+}
+
+// shutdownServers shuts the proxy servers down in parallel, as run.Group interrupts its actors one after another,
+// and the external API once the calls in flight end. Only the first interrupted actor shuts the servers down.
+func (s *gracefulShutdown) shutdownServers() {
+	s.once.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		defer cancel()
+
+		var wg sync.WaitGroup
+		var externalAPI namedServer
+		for _, server := range s.servers {
+			if server.srv == s.externalAPI {
+				externalAPI = server
+				continue
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				shutdownServer(ctx, server)
+			}()
+		}
+		wg.Wait()
+		s.waitForCallsInFlight(ctx)
+
+		if externalAPI.srv != nil {
+			externalAPICtx, cancelExternalAPI := context.WithTimeout(context.Background(), externalAPIShutdownTimeout)
+			defer cancelExternalAPI()
+			shutdownServer(externalAPICtx, externalAPI)
+		}
+	})
+}
+
+// waitForCallsInFlight waits for the streamed and upgraded calls, which the servers don't wait for
+func (s *gracefulShutdown) waitForCallsInFlight(ctx context.Context) {
+	if err := s.tracker.Wait(ctx); err != nil {
+		zap.L().Warn("Shutdown timeout exceeded, cutting calls in flight",
+			zap.Int("callsInFlight", s.tracker.InFlight()),
+			zap.Duration("shutdownTimeout", s.timeout))
+	}
+}
+
+func shutdownServer(ctx context.Context, server namedServer) {
 	log := zap.L().Sugar()
+	log.Infof("Shutting down %s HTTP server on %s", server.name, server.srv.Addr)
+
+	err := server.srv.Shutdown(ctx)
+	if err != nil && err != http.ErrServerClosed {
+		log.Warnf("HTTP server shutdown %s failed: %s", server.name, err)
+	}
+}
+
+// proxyProtocols accepts HTTP/2 calls without TLS (h2c) besides HTTP/1, so that gRPC clients can call the APIs proxied in the HTTP2 mode
+func proxyProtocols() *http.Protocols {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+
+	return protocols
+}
+
+func addHttpServerToRunGroup(name string, g *run.Group, srv *http.Server, shutdown *gracefulShutdown) {
+	log := zap.L().Sugar()
+	shutdown.servers = append(shutdown.servers, namedServer{name: name, srv: srv})
 
 	log.Infof("Starting %s HTTP server on %s", name, srv.Addr)
 	ln, err := net.Listen("tcp", srv.Addr)
@@ -134,26 +334,26 @@ func addHttpServerToRunGroup(name string, g *run.Group, srv *http.Server) {
 		defer log.Infof("Server %s finished", name)
 		return srv.Serve(ln)
 	}, func(error) {
-		log.Infof("Shutting down %s HTTP server on %s", name, srv.Addr)
-
-		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
-		defer cancel()
-		err = srv.Shutdown(ctx)
-		if err != nil && err != http.ErrServerClosed {
-			log.Warnf("HTTP server shutdown %s failed: %s", name, err)
-		}
+		shutdown.shutdownServers()
 	})
 }
 
-func addInterruptSignalToRunGroup(g *run.Group) {
+func addInterruptSignalToRunGroup(g *run.Group, shutdown *gracefulShutdown) {
 	cancelInterrupt := make(chan struct{})
 	g.Add(func() error {
 		c := make(chan os.Signal, 1)
 		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
 		select {
 		case <-cancelInterrupt:
+			return nil
 		case sig := <-c:
-			zap.L().Sugar().Infof("received signal %s", sig)
+			zap.L().Sugar().Infof("received signal %s, draining calls for %s", sig, shutdown.drainPeriod)
+		}
+
+		shutdown.startDraining()
+		select {
+		case <-cancelInterrupt:
+		case <-time.After(shutdown.drainPeriod):
 		}
 		return nil
 	}, func(error) {
@@ -161,63 +361,104 @@ func addInterruptSignalToRunGroup(g *run.Group) {
 	})
 }
 
-func newInternalHandler(serviceDefinitionService metadata.ServiceDefinitionService, options options) http.Handler {
-	authStrategyFactory := newAuthenticationStrategyFactory(options.proxyTimeout)
+func newInternalHandler(serviceDefinitionService metadata.ServiceDefinitionService, proxyConfig proxy.Config, options options) http.Handler {
+	authStrategyFactory := newAuthenticationStrategyFactory(options.proxyTimeout, options.tokenRefreshFraction)
 	csrfCl := newCSRFClient(options.proxyTimeout)
 	csrfTokenStrategyFactory := csrfStrategy.NewTokenStrategyFactory(csrfCl)
 
-	return proxy.New(serviceDefinitionService, authStrategyFactory, csrfTokenStrategyFactory, getProxyConfig(options))
+	return proxy.New(serviceDefinitionService, authStrategyFactory, csrfTokenStrategyFactory, proxyConfig)
 }
 
-func newInternalHandlerForCompass(serviceDefinitionService metadata.ServiceDefinitionService, options options) http.Handler {
-	authStrategyFactory := newAuthenticationStrategyFactory(options.proxyTimeout)
+func newInternalHandlerForCompass(serviceDefinitionService metadata.ServiceDefinitionService, proxyConfig proxy.Config, options options) http.Handler {
+	authStrategyFactory := newAuthenticationStrategyFactory(options.proxyTimeout, options.tokenRefreshFraction)
 	csrfCl := newCSRFClient(options.proxyTimeout)
 	csrfTokenStrategyFactory := csrfStrategy.NewTokenStrategyFactory(csrfCl)
 
-	return proxy.NewForCompass(serviceDefinitionService, authStrategyFactory, csrfTokenStrategyFactory, getProxyConfig(options))
+	return proxy.NewForCompass(serviceDefinitionService, authStrategyFactory, csrfTokenStrategyFactory, proxyConfig)
 }
 
-func getProxyConfig(options options) proxy.Config {
-	return proxy.Config{
-		ProxyTimeout:  options.proxyTimeout,
-		ProxyCacheTTL: options.proxyCacheTTL,
-	}
+func newInternalHandlerForDestinations(secretsRepository secrets.Repository, proxyConfig proxy.Config, options options) http.Handler {
+	authStrategyFactory := newAuthenticationStrategyFactory(options.proxyTimeout, options.tokenRefreshFraction)
+	csrfCl := newCSRFClient(options.proxyTimeout)
+	csrfTokenStrategyFactory := csrfStrategy.NewTokenStrategyFactory(csrfCl)
+	targetConfigProvider := proxyconfig.NewTargetConfigProvider(secretsRepository)
+
+	return proxy.NewForDestinations(targetConfigProvider, authStrategyFactory, csrfTokenStrategyFactory, proxyConfig)
 }
 
-func newAuthenticationStrategyFactory(oauthClientTimeout int) authorization.StrategyFactory {
-	return authorization.NewStrategyFactory(authorization.FactoryConfiguration{
-		OAuthClientTimeout: oauthClientTimeout,
-	})
+func getProxyConfig(options options, circuitBreakers circuitbreaker.CircuitBreakers, rateLimiters ratelimit.Limiters, responseCache responsecache.Cache, authorizer accesscontrol.Authorizer, secretsCache proxy.SecretsCache) proxy.Config {
+	return proxy.Config{
+		ProxyTimeout:              options.proxyTimeout,
+		ProxyCacheTTL:             options.proxyCacheTTL,
+		CircuitBreakers:           circuitBreakers,
+		RateLimiters:              rateLimiters,
+		RequestBodyMemoryLimit:    options.requestBodyMemoryLimit,
+		ResponseCache:             responseCache,
+		ResponseCacheMaxEntrySize: options.responseCacheMaxEntrySize,
+		Authorizer:                authorizer,
+		SecretsCache:              secretsCache,
+	}
 }
 
-func newServiceDefinitionService(k8sConfig *restclient.Config, coreClientset kubernetes.Interface, namespace string) (metadata.ServiceDefinitionService, error) {
-	applicationServiceRepository, apperror := newApplicationRepository(k8sConfig)
-	if apperror != nil {
-		return nil, apperror
+func newResponseCache(options options) responsecache.Cache {
+	if options.responseCacheMaxSize <= 0 {
+		return nil
 	}
 
-	secretsRepository := newSecretsRepository(coreClientset, namespace)
+	return responsecache.New(options.responseCacheMaxSize)
+}
 
-	serviceAPIService := serviceapi.NewService(secretsRepository)
+func newCircuitBreakers(options options) circuitbreaker.CircuitBreakers {
+	return circuitbreaker.New(circuitbreaker.Config{
+		FailureThreshold: options.circuitBreakerFailures,
+		OpenDuration:     time.Duration(options.circuitBreakerOpenDuration) * time.Second,
+		HalfOpenRequests: options.circuitBreakerHalfOpenCalls,
+	})
+}
 
-	return metadata.NewServiceDefinitionService(serviceAPIService, applicationServiceRepository), nil
+func newAccessLog(options options) (*accesslog.Logger, error) {
+	return accesslog.New(accesslog.Config{
+		Format:        accesslog.Format(options.accessLogFormat),
+		SampleRate:    options.accessLogSampleRate,
+		Headers:       splitNames(options.accessLogHeaders),
+		RedactedNames: splitNames(options.accessLogRedactedNames),
+	}, os.Stdout)
 }
 
-func newApplicationRepository(config *restclient.Config) (applications.ServiceRepository, apperrors.AppError) {
-	applicationClientset, err := versioned.NewForConfig(config)
-	if err != nil {
-		return nil, apperrors.Internalf("failed to create k8s application client, %s", err)
+func splitNames(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
 	}
 
-	rei := applicationClientset.ApplicationconnectorV1alpha1().Applications()
+	return names
+}
+
+func newAuthenticationStrategyFactory(oauthClientTimeout int, tokenRefreshFraction float64) authorization.StrategyFactory {
+	return authorization.NewStrategyFactory(authorization.FactoryConfiguration{
+		OAuthClientTimeout:   oauthClientTimeout,
+		TokenRefreshFraction: tokenRefreshFraction,
+	})
+}
+
+func newServiceDefinitionService(applicationServiceRepository applications.ServiceRepository, secretsRepository secrets.Repository, configMapsRepository configmaps.Repository) metadata.ServiceDefinitionService {
+	serviceAPIService := serviceapi.NewService(secretsRepository, configMapsRepository)
 
-	return applications.NewServiceRepository(rei), nil
+	return metadata.NewServiceDefinitionService(serviceAPIService, applicationServiceRepository)
 }
 
-func newSecretsRepository(coreClientset kubernetes.Interface, namespace string) secrets.Repository {
-	sei := coreClientset.CoreV1().Secrets(namespace)
+func newSecretsRepository(secretsLister secrets.Lister, options options) secrets.InvalidatingRepository {
+	sources := map[string]secrets.Repository{}
+	if options.credentialsDir != "" {
+		sources[secrets.SourceFile] = secrets.NewFileRepository(options.credentialsDir)
+	}
+	if options.credentialsBrokerURL != "" {
+		sources[secrets.SourceBroker] = secrets.NewBrokerRepository(options.credentialsBrokerURL, time.Duration(options.proxyTimeout)*time.Second)
+	}
 
-	return secrets.NewRepository(sei)
+	return secrets.NewSourceRepository(secrets.NewRepository(secretsLister), sources)
 }
 
 func newCSRFClient(timeout int) csrf.Client {
@@ -7,30 +7,74 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
+const defaultAccessLogRedactedNames = "Authorization,Proxy-Authorization,Access-Token,Cookie,X-Csrf-Token,access_token,client_secret,api_key,apikey,password,token"
+
 type options struct {
+	accessLogFormat             string
+	accessLogHeaders            string
+	accessLogRedactedNames      string
+	accessLogSampleRate         float64
 	apiServerURL                string
 	applicationSecretsNamespace string
+	callerTokenAudience         string
+	circuitBreakerFailures      int
+	circuitBreakerHalfOpenCalls int
+	circuitBreakerOpenDuration  int
+	credentialsBrokerURL        string
+	credentialsDir              string
+	destinationSecretSelector   string
+	explainTokenFile            string
 	externalAPIPort             int
 	kubeConfig                  string
 	logLevel                    *zapcore.Level
 	proxyCacheTTL               int
 	proxyPort                   int
 	proxyPortCompass            int
+	proxyPortDestination        int
 	proxyTimeout                int
+	requestBodyMemoryLimit      int64
 	requestTimeout              int
+	responseCacheMaxEntrySize   int64
+	responseCacheMaxSize        int64
+	shutdownDrainPeriod         int
+	shutdownTimeout             int
+	tokenRefreshFraction        float64
+	tracingCollectorURL         string
+	trustForwardedClientCert    bool
 }
 
 func parseArgs(log *zap.Logger) (opts options) {
+	flag.StringVar(&opts.accessLogFormat, "accessLogFormat", "json", "Format of the access log of proxied calls: json | clf | none")
+	flag.StringVar(&opts.accessLogHeaders, "accessLogHeaders", "", "Comma-separated names of the headers of proxied calls which are written to the access log in the json format")
+	flag.StringVar(&opts.accessLogRedactedNames, "accessLogRedactedNames", defaultAccessLogRedactedNames, "Comma-separated names of headers and query parameters whose values are never written to the access log, in addition to the request parameters of the API")
+	flag.Float64Var(&opts.accessLogSampleRate, "accessLogSampleRate", 1, "Fraction of successful proxied calls written to the access log. Failed calls are always written")
 	flag.StringVar(&opts.apiServerURL, "apiServerURL", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
 	flag.StringVar(&opts.applicationSecretsNamespace, "applicationSecretsNamespace", "kyma-system", "Namespace where Application secrets used by the Application Gateway exist")
+	flag.StringVar(&opts.callerTokenAudience, "callerTokenAudience", "central-application-gateway", "Audience of the ServiceAccount tokens which callers send in the X-Caller-Token header to be identified by the access control of Applications")
+	flag.IntVar(&opts.circuitBreakerFailures, "circuitBreakerFailures", 0, "Number of consecutive failed calls to a target system which opens its circuit breaker. Set to 0 to disable circuit breakers")
+	flag.IntVar(&opts.circuitBreakerHalfOpenCalls, "circuitBreakerHalfOpenCalls", 1, "Number of successful trial calls which close the half-open circuit breaker")
+	flag.IntVar(&opts.circuitBreakerOpenDuration, "circuitBreakerOpenDuration", 30, "Time, in seconds, for which the open circuit breaker rejects calls before letting trial calls through")
+	flag.StringVar(&opts.credentialsBrokerURL, "credentialsBrokerURL", "", "URL of the credential broker used for credentials referenced with the broker: prefix. Disabled if empty")
+	flag.StringVar(&opts.credentialsDir, "credentialsDir", "", "Directory with mounted credentials used for credentials referenced with the file: prefix. Disabled if empty")
+	flag.StringVar(&opts.destinationSecretSelector, "destinationSecretSelector", "applicationconnector.kyma-project.io/destination=true", "Label selector of the Secrets in applicationSecretsNamespace which define destinations, the other Secrets can't be used in the destination mode")
+	flag.StringVar(&opts.explainTokenFile, "explainTokenFile", "", "File with the bearer token required by the explain endpoint of the external API. The endpoint is disabled if empty")
 	flag.IntVar(&opts.externalAPIPort, "externalAPIPort", 8081, "Port that exposes the API which allows checking the component status and exposes log configuration")
 	flag.StringVar(&opts.kubeConfig, "kubeConfig", "", "Path to a kubeconfig. Only required if out-of-cluster")
 	opts.logLevel = zap.LevelFlag("logLevel", zap.InfoLevel, "Log level: panic | fatal | error | warn | info | debug. Can't be lower than info")
 	flag.IntVar(&opts.proxyCacheTTL, "proxyCacheTTL", 120, "TTL, in seconds, for proxy cache of Remote API information")
 	flag.IntVar(&opts.proxyPort, "proxyPort", 8080, "Port that acts as a proxy for the calls from services and Functions to an external solution in the default standalone mode or Compass bundles with a single API definition")
 	flag.IntVar(&opts.proxyPortCompass, "proxyPortCompass", 8082, "Port that acts as a proxy for the calls from services and Functions to an external solution in the Compass mode")
+	flag.IntVar(&opts.proxyPortDestination, "proxyPortDestination", 8083, "Port that acts as a proxy for the calls from services and Functions to destinations defined in secrets, without the Application CR")
 	flag.IntVar(&opts.proxyTimeout, "proxyTimeout", 10, "Timeout for requests sent through the proxy, expressed in seconds")
+	flag.Int64Var(&opts.requestBodyMemoryLimit, "requestBodyMemoryLimit", 1048576, "Number of bytes of the request body kept in memory in the File and Stream buffering modes, unless the Application sets its own limit")
 	flag.IntVar(&opts.requestTimeout, "requestTimeout", 10, "Timeout for requests sent through Central Application Gateway, expressed in seconds")
+	flag.Int64Var(&opts.responseCacheMaxEntrySize, "responseCacheMaxEntrySize", 1048576, "Maximum size, in bytes, of a cached response body, unless the API sets its own limit")
+	flag.Int64Var(&opts.responseCacheMaxSize, "responseCacheMaxSize", 67108864, "Maximum size, in bytes, of all responses kept in the response cache of APIs which enable it. Set to 0 to disable the response cache")
+	flag.IntVar(&opts.shutdownDrainPeriod, "shutdownDrainPeriod", 5, "Time, in seconds, for which the gateway keeps serving calls after SIGTERM while its readiness probe fails, so that it's removed from the endpoints of its service")
+	flag.IntVar(&opts.shutdownTimeout, "shutdownTimeout", 20, "Maximum time, in seconds, for which the gateway waits for calls in flight after the drain period before it exits")
+	flag.Float64Var(&opts.tokenRefreshFraction, "tokenRefreshFraction", 0.8, "Fraction of the OAuth token lifetime after which the token is refreshed in the background. Set to 1 to disable early refresh")
+	flag.StringVar(&opts.tracingCollectorURL, "tracingCollectorURL", "", "URL of the OpenTelemetry collector receiving spans over OTLP/HTTP, for example http://localhost:4318/v1/traces. Spans aren't exported if empty")
+	flag.BoolVar(&opts.trustForwardedClientCert, "trustForwardedClientCert", false, "Identify callers by the SPIFFE ID in the X-Forwarded-Client-Cert header. Enable only if the sidecar replaces the header sent by callers")
 
 	flag.Parse()
 
@@ -41,15 +85,36 @@ func parseArgs(log *zap.Logger) (opts options) {
 
 func (o options) Log(log *zap.Logger) {
 	log.Info("Parsed flags",
+		zap.String("-accessLogFormat", o.accessLogFormat),
+		zap.String("-accessLogHeaders", o.accessLogHeaders),
+		zap.String("-accessLogRedactedNames", o.accessLogRedactedNames),
+		zap.Float64("-accessLogSampleRate", o.accessLogSampleRate),
 		zap.String("-apiServerURL", o.apiServerURL),
 		zap.String("-applicationSecretsNamespace", o.applicationSecretsNamespace),
+		zap.String("-callerTokenAudience", o.callerTokenAudience),
+		zap.Int("-circuitBreakerFailures", o.circuitBreakerFailures),
+		zap.Int("-circuitBreakerHalfOpenCalls", o.circuitBreakerHalfOpenCalls),
+		zap.Int("-circuitBreakerOpenDuration", o.circuitBreakerOpenDuration),
+		zap.String("-credentialsBrokerURL", o.credentialsBrokerURL),
+		zap.String("-credentialsDir", o.credentialsDir),
+		zap.String("-destinationSecretSelector", o.destinationSecretSelector),
+		zap.String("-explainTokenFile", o.explainTokenFile),
 		zap.Int("-externalAPIPort", o.externalAPIPort),
 		zap.String("-kubeConfig", o.kubeConfig),
 		zap.String("-logLevel", o.logLevel.String()),
 		zap.Int("-proxyCacheTTL", o.proxyCacheTTL),
 		zap.Int("-proxyPort", o.proxyPort),
 		zap.Int("-proxyPortCompass", o.proxyPortCompass),
+		zap.Int("-proxyPortDestination", o.proxyPortDestination),
 		zap.Int("-proxyTimeout", o.proxyTimeout),
+		zap.Int64("-requestBodyMemoryLimit", o.requestBodyMemoryLimit),
 		zap.Int("-requestTimeout", o.requestTimeout),
+		zap.Int64("-responseCacheMaxEntrySize", o.responseCacheMaxEntrySize),
+		zap.Int64("-responseCacheMaxSize", o.responseCacheMaxSize),
+		zap.Int("-shutdownDrainPeriod", o.shutdownDrainPeriod),
+		zap.Int("-shutdownTimeout", o.shutdownTimeout),
+		zap.Float64("-tokenRefreshFraction", o.tokenRefreshFraction),
+		zap.String("-tracingCollectorURL", o.tracingCollectorURL),
+		zap.Bool("-trustForwardedClientCert", o.trustForwardedClientCert),
 	)
 }
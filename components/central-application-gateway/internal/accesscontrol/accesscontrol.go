@@ -0,0 +1,303 @@
+// Package accesscontrol checks if the callers of the proxy are allowed to call the APIs of Applications
+package accesscontrol
+
+import (
+	"context"
+	"crypto/sha256"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httptools"
+)
+
+const (
+	serviceAccountUsernamePrefix = "system:serviceaccount:"
+	defaultReviewCacheTTL        = 30 * time.Second
+	maxCachedReviews             = 10000
+)
+
+// Authorizer checks the identity of the caller against the access control of the Application
+type Authorizer interface {
+	// Authorize returns the Forbidden error when the caller isn't allowed, nil access control allows all callers
+	Authorize(r *http.Request, application string, accessControl *model.AccessControl) apperrors.AppError
+}
+
+// TokenReviewer reviews the ServiceAccount tokens of callers, it's implemented by the TokenReviews client of Kubernetes
+//
+//go:generate mockery --name=TokenReviewer
+type TokenReviewer interface {
+	Create(ctx context.Context, tokenReview *authenticationv1.TokenReview, opts metav1.CreateOptions) (*authenticationv1.TokenReview, error)
+}
+
+// Config defines how callers are identified
+type Config struct {
+	// TokenAudience is the audience the ServiceAccount tokens of callers must be issued for, empty accepts the audiences of the API server
+	TokenAudience string
+	// TrustForwardedClientCert identifies callers by the SPIFFE ID of their client certificate forwarded by the sidecar,
+	// it must be enabled only if the sidecar replaces the header sent by the caller
+	TrustForwardedClientCert bool
+	// ReviewCacheTTL is the time for which successful token reviews are cached, zero uses 30 seconds
+	ReviewCacheTTL time.Duration
+}
+
+type identity struct {
+	// serviceAccount is in the namespace/name form
+	serviceAccount string
+	spiffeID       string
+}
+
+type cachedReview struct {
+	serviceAccount string
+	expires        time.Time
+}
+
+type authorizer struct {
+	tokenReviewer TokenReviewer
+	config        Config
+	now           func() time.Time
+
+	mutex   sync.Mutex
+	reviews map[[sha256.Size]byte]cachedReview
+}
+
+// NewAuthorizer creates authorizer identifying callers by ServiceAccount tokens sent in the X-Caller-Token header
+// and by the forwarded client certificates. Nil token reviewer accepts no tokens.
+func NewAuthorizer(tokenReviewer TokenReviewer, config Config) Authorizer {
+	if config.ReviewCacheTTL <= 0 {
+		config.ReviewCacheTTL = defaultReviewCacheTTL
+	}
+
+	return &authorizer{
+		tokenReviewer: tokenReviewer,
+		config:        config,
+		now:           time.Now,
+		reviews:       make(map[[sha256.Size]byte]cachedReview),
+	}
+}
+
+func (a *authorizer) Authorize(r *http.Request, application string, accessControl *model.AccessControl) apperrors.AppError {
+	if accessControl == nil {
+		return nil
+	}
+
+	id, err := a.identify(r)
+	if err != nil {
+		return err
+	}
+
+	if allowed(accessControl, id) {
+		return nil
+	}
+
+	zap.L().Named("audit").Warn("Call denied by access control of the Application",
+		zap.String("application", application),
+		zap.String("serviceAccount", id.serviceAccount),
+		zap.String("spiffeId", id.spiffeID),
+		zap.String("method", r.Method),
+		zap.String("path", r.URL.Path),
+		zap.String("requestId", r.Header.Get(httpconsts.HeaderRequestID)))
+
+	return apperrors.Forbiddenf("caller %s isn't allowed to call the APIs of the Application '%s'", id, application)
+}
+
+func (a *authorizer) identify(r *http.Request) (identity, apperrors.AppError) {
+	var id identity
+
+	if token := callerToken(r); token != "" {
+		serviceAccount, err := a.review(r.Context(), token)
+		if err != nil {
+			return identity{}, err
+		}
+		id.serviceAccount = serviceAccount
+	}
+
+	if a.config.TrustForwardedClientCert {
+		id.spiffeID = forwardedSPIFFEID(r.Header.Get(httpconsts.HeaderXForwardedClientCert))
+	}
+
+	return id, nil
+}
+
+// review returns the ServiceAccount of the token, empty if the token isn't valid. The API server errors fail the call, so that no caller is allowed by mistake.
+func (a *authorizer) review(ctx context.Context, token string) (string, apperrors.AppError) {
+	if a.tokenReviewer == nil {
+		return "", nil
+	}
+
+	key := sha256.Sum256([]byte(token))
+	if serviceAccount, found := a.cachedReview(key); found {
+		return serviceAccount, nil
+	}
+
+	review := &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}
+	if a.config.TokenAudience != "" {
+		review.Spec.Audiences = []string{a.config.TokenAudience}
+	}
+
+	result, err := a.tokenReviewer.Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		zap.L().Error("Failed to review the token of the caller", zap.Error(err))
+		return "", apperrors.Internalf("failed to review the token of the caller")
+	}
+
+	if !result.Status.Authenticated {
+		return "", nil
+	}
+	serviceAccount := serviceAccountFromUsername(result.Status.User.Username)
+	if serviceAccount == "" {
+		return "", nil
+	}
+
+	a.cacheReview(key, serviceAccount)
+
+	return serviceAccount, nil
+}
+
+func (a *authorizer) cachedReview(key [sha256.Size]byte) (string, bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	review, found := a.reviews[key]
+	if !found {
+		return "", false
+	}
+	if a.now().After(review.expires) {
+		delete(a.reviews, key)
+		return "", false
+	}
+
+	return review.serviceAccount, true
+}
+
+func (a *authorizer) cacheReview(key [sha256.Size]byte, serviceAccount string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	// tokens of many callers can't grow the cache without bounds
+	if len(a.reviews) >= maxCachedReviews {
+		a.reviews = make(map[[sha256.Size]byte]cachedReview)
+	}
+	a.reviews[key] = cachedReview{serviceAccount: serviceAccount, expires: a.now().Add(a.config.ReviewCacheTTL)}
+}
+
+func allowed(accessControl *model.AccessControl, id identity) bool {
+	if id.serviceAccount != "" && contains(accessControl.ServiceAccounts, id.serviceAccount) {
+		return true
+	}
+	if id.spiffeID != "" && contains(accessControl.SPIFFEIDs, id.spiffeID) {
+		return true
+	}
+	for _, namespace := range id.namespaces() {
+		if contains(accessControl.Namespaces, namespace) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (id identity) namespaces() []string {
+	var namespaces []string
+	if namespace, _, found := strings.Cut(id.serviceAccount, "/"); found {
+		namespaces = append(namespaces, namespace)
+	}
+	if namespace := spiffeIDNamespace(id.spiffeID); namespace != "" {
+		namespaces = append(namespaces, namespace)
+	}
+
+	return namespaces
+}
+
+func (id identity) String() string {
+	var parts []string
+	if id.serviceAccount != "" {
+		parts = append(parts, "ServiceAccount "+id.serviceAccount)
+	}
+	if id.spiffeID != "" {
+		parts = append(parts, "SPIFFE ID "+id.spiffeID)
+	}
+	if len(parts) == 0 {
+		return "without identity"
+	}
+
+	return "with " + strings.Join(parts, " and ")
+}
+
+func callerToken(r *http.Request) string {
+	token := strings.TrimSpace(r.Header.Get(httpconsts.HeaderCallerToken))
+	if withoutPrefix, found := strings.CutPrefix(token, "Bearer "); found {
+		return strings.TrimSpace(withoutPrefix)
+	}
+
+	return token
+}
+
+// serviceAccountFromUsername converts the system:serviceaccount:namespace:name username to the namespace/name form
+func serviceAccountFromUsername(username string) string {
+	serviceAccount, found := strings.CutPrefix(username, serviceAccountUsernamePrefix)
+	if !found {
+		return ""
+	}
+	namespace, name, found := strings.Cut(serviceAccount, ":")
+	if !found || namespace == "" || name == "" {
+		return ""
+	}
+
+	return namespace + "/" + name
+}
+
+// spiffeIDNamespace returns the namespace of the spiffe://trust-domain/ns/namespace/sa/name ID
+func spiffeIDNamespace(spiffeID string) string {
+	path, found := strings.CutPrefix(spiffeID, "spiffe://")
+	if !found {
+		return ""
+	}
+	segments := strings.Split(path, "/")
+	if len(segments) != 5 || segments[1] != "ns" || segments[3] != "sa" {
+		return ""
+	}
+
+	return segments[2]
+}
+
+// forwardedSPIFFEID returns the SPIFFE ID of the caller from the last element of the X-Forwarded-Client-Cert header, which the sidecar adds for the direct caller
+func forwardedSPIFFEID(header string) string {
+	elements := httptools.SplitQuoted(header, ',')
+	if len(elements) == 0 {
+		return ""
+	}
+
+	for _, pair := range httptools.SplitQuoted(elements[len(elements)-1], ';') {
+		key, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found || !strings.EqualFold(key, "URI") {
+			continue
+		}
+		value = strings.Trim(value, `"`)
+		if strings.HasPrefix(value, "spiffe://") {
+			return value
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,179 @@
+package accesscontrol
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/accesscontrol/mocks"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+)
+
+const forwardedClientCert = `By=spiffe://cluster.local/ns/kyma-system/sa/central-application-gateway;Hash=1a2b;Subject="CN=a,O=b";URI=spiffe://cluster.local/ns/billing/sa/invoices`
+
+func TestAuthorizer(t *testing.T) {
+	accessControl := &model.AccessControl{
+		ServiceAccounts: []string{"orders/orders-client"},
+		SPIFFEIDs:       []string{"spiffe://cluster.local/ns/billing/sa/invoices"},
+		Namespaces:      []string{"shipping"},
+	}
+
+	newRequest := func(headers map[string]string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/app/orders-api/orders", nil)
+		for name, value := range headers {
+			r.Header.Set(name, value)
+		}
+		return r
+	}
+
+	reviewed := func(username string) *authenticationv1.TokenReview {
+		return &authenticationv1.TokenReview{Status: authenticationv1.TokenReviewStatus{
+			Authenticated: true,
+			User:          authenticationv1.UserInfo{Username: username},
+		}}
+	}
+
+	t.Run("should allow all callers when the Application has no access control", func(t *testing.T) {
+		// given
+		authorizer := NewAuthorizer(nil, Config{})
+
+		// when
+		err := authorizer.Authorize(newRequest(nil), "app", nil)
+
+		// then
+		assert.NoError(t, err)
+	})
+
+	t.Run("should allow ServiceAccounts and namespaces of the reviewed tokens", func(t *testing.T) {
+		for token, username := range map[string]string{
+			"orders-token":   "system:serviceaccount:orders:orders-client",
+			"shipping-token": "system:serviceaccount:shipping:default",
+		} {
+			// given
+			tokenReviewer := &mocks.TokenReviewer{}
+			tokenReviewer.On("Create", mock.Anything, mock.MatchedBy(func(review *authenticationv1.TokenReview) bool {
+				return review.Spec.Token == token && assert.ObjectsAreEqual([]string{"central-application-gateway"}, review.Spec.Audiences)
+			}), mock.Anything).Return(reviewed(username), nil)
+
+			authorizer := NewAuthorizer(tokenReviewer, Config{TokenAudience: "central-application-gateway"})
+
+			// when
+			err := authorizer.Authorize(newRequest(map[string]string{"X-Caller-Token": "Bearer " + token}), "app", accessControl)
+
+			// then
+			assert.NoError(t, err, token)
+			tokenReviewer.AssertExpectations(t)
+		}
+	})
+
+	t.Run("should allow SPIFFE IDs and namespaces of the forwarded client certificates", func(t *testing.T) {
+		// given
+		authorizer := NewAuthorizer(nil, Config{TrustForwardedClientCert: true})
+		byNamespace := &model.AccessControl{Namespaces: []string{"billing"}}
+
+		// when
+		err := authorizer.Authorize(newRequest(map[string]string{"X-Forwarded-Client-Cert": forwardedClientCert}), "app", accessControl)
+		namespaceErr := authorizer.Authorize(newRequest(map[string]string{"X-Forwarded-Client-Cert": forwardedClientCert}), "app", byNamespace)
+
+		// then
+		assert.NoError(t, err)
+		assert.NoError(t, namespaceErr)
+	})
+
+	t.Run("should deny callers which don't match the access control", func(t *testing.T) {
+		// given
+		tokenReviewer := &mocks.TokenReviewer{}
+		tokenReviewer.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(reviewed("system:serviceaccount:default:default"), nil)
+
+		authorizer := NewAuthorizer(tokenReviewer, Config{})
+
+		for _, r := range []*http.Request{
+			newRequest(nil),
+			newRequest(map[string]string{"X-Caller-Token": "default-token"}),
+			newRequest(map[string]string{"X-Forwarded-Client-Cert": forwardedClientCert}),
+		} {
+			// when
+			err := authorizer.Authorize(r, "app", accessControl)
+
+			// then
+			require.Error(t, err)
+			assert.Equal(t, apperrors.CodeForbidden, err.Code())
+		}
+	})
+
+	t.Run("should deny callers with tokens which aren't authenticated or don't belong to ServiceAccounts", func(t *testing.T) {
+		for _, review := range []*authenticationv1.TokenReview{
+			{Status: authenticationv1.TokenReviewStatus{Authenticated: false, User: authenticationv1.UserInfo{Username: "system:serviceaccount:orders:orders-client"}}},
+			reviewed("orders-client"),
+		} {
+			// given
+			tokenReviewer := &mocks.TokenReviewer{}
+			tokenReviewer.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(review, nil)
+
+			authorizer := NewAuthorizer(tokenReviewer, Config{})
+
+			// when
+			err := authorizer.Authorize(newRequest(map[string]string{"X-Caller-Token": "token"}), "app", accessControl)
+
+			// then
+			require.Error(t, err)
+			assert.Equal(t, apperrors.CodeForbidden, err.Code())
+		}
+	})
+
+	t.Run("should return error when the token can't be reviewed", func(t *testing.T) {
+		// given
+		tokenReviewer := &mocks.TokenReviewer{}
+		tokenReviewer.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("unavailable"))
+
+		authorizer := NewAuthorizer(tokenReviewer, Config{})
+
+		// when
+		err := authorizer.Authorize(newRequest(map[string]string{"X-Caller-Token": "token"}), "app", accessControl)
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, apperrors.CodeInternal, err.Code())
+	})
+
+	t.Run("should cache successful reviews until they expire", func(t *testing.T) {
+		// given
+		tokenReviewer := &mocks.TokenReviewer{}
+		tokenReviewer.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(reviewed("system:serviceaccount:orders:orders-client"), nil)
+
+		now := time.Now()
+		authorizer := NewAuthorizer(tokenReviewer, Config{ReviewCacheTTL: time.Minute}).(*authorizer)
+		authorizer.now = func() time.Time { return now }
+		r := newRequest(map[string]string{"X-Caller-Token": "token"})
+
+		// when
+		require.NoError(t, authorizer.Authorize(r, "app", accessControl))
+		require.NoError(t, authorizer.Authorize(r, "app", accessControl))
+		now = now.Add(2 * time.Minute)
+		require.NoError(t, authorizer.Authorize(r, "app", accessControl))
+
+		// then
+		tokenReviewer.AssertNumberOfCalls(t, "Create", 2)
+	})
+}
+
+func TestForwardedSPIFFEID(t *testing.T) {
+	t.Run("should read the SPIFFE ID of the last element", func(t *testing.T) {
+		header := `URI=spiffe://cluster.local/ns/other/sa/other,` + forwardedClientCert
+
+		assert.Equal(t, "spiffe://cluster.local/ns/billing/sa/invoices", forwardedSPIFFEID(header))
+	})
+
+	t.Run("should return empty ID when the header has no SPIFFE ID", func(t *testing.T) {
+		assert.Empty(t, forwardedSPIFFEID(""))
+		assert.Empty(t, forwardedSPIFFEID(`Hash=1a2b;Subject="CN=a,URI=spiffe://cluster.local/ns/billing/sa/invoices"`))
+	})
+}
@@ -0,0 +1,399 @@
+// Package accesslog writes a line for every call proxied by Central Application Gateway
+package accesslog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/tracing"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+)
+
+// Format of the access log lines
+type Format string
+
+const (
+	// FormatJSON writes a JSON object with all details of the call per line
+	FormatJSON Format = "json"
+	// FormatCLF writes lines in the Common Log Format
+	FormatCLF Format = "clf"
+	// FormatNone disables the access log
+	FormatNone Format = "none"
+
+	// Redacted replaces the values of headers and query parameters which may contain credentials
+	Redacted = "REDACTED"
+
+	clfTimeFormat = "02/Jan/2006:15:04:05 -0700"
+)
+
+// Config of the access log
+type Config struct {
+	Format Format
+	// SampleRate is the fraction of successful calls which are logged, calls which failed with a 4xx or 5xx status code are always logged
+	SampleRate float64
+	// Headers of the incoming call which are logged in the JSON format
+	Headers []string
+	// RedactedNames are the names of headers and query parameters whose values are never logged
+	RedactedNames []string
+}
+
+// Logger writes the access log lines to the output
+type Logger struct {
+	format     Format
+	sampleRate float64
+	headers    []string
+	redacted   map[string]bool
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// New creates the access log writing to out
+func New(config Config, out io.Writer) (*Logger, error) {
+	switch config.Format {
+	case FormatJSON, FormatCLF, FormatNone:
+	default:
+		return nil, fmt.Errorf("unsupported access log format %q, must be one of: %s, %s, %s", config.Format, FormatJSON, FormatCLF, FormatNone)
+	}
+	if config.SampleRate < 0 || config.SampleRate > 1 {
+		return nil, fmt.Errorf("access log sample rate %v must be between 0 and 1", config.SampleRate)
+	}
+
+	redacted := map[string]bool{}
+	for _, name := range config.RedactedNames {
+		redacted[strings.ToLower(name)] = true
+	}
+
+	return &Logger{
+		format:     config.Format,
+		sampleRate: config.SampleRate,
+		headers:    config.Headers,
+		redacted:   redacted,
+		out:        out,
+	}, nil
+}
+
+// Record collects the details of a proxied call while it's handled
+type Record struct {
+	start      time.Time
+	requestID  string
+	traceID    string
+	remoteAddr string
+	method     string
+	url        url.URL
+	proto      string
+	headers    http.Header
+
+	apiIdentifier  model.APIIdentifier
+	authStrategy   string
+	upstreamURL    *url.URL
+	upstreamStatus int
+	retries        int
+	redacted       []string
+
+	status   int
+	bytesIn  int64
+	bytesOut int64
+	duration time.Duration
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the record
+func NewContext(ctx context.Context, record *Record) context.Context {
+	return context.WithValue(ctx, contextKey{}, record)
+}
+
+// FromContext returns the record of the call, nil if the access log is disabled
+func FromContext(ctx context.Context) *Record {
+	record, _ := ctx.Value(contextKey{}).(*Record)
+	return record
+}
+
+// SetAPI sets the API the call is proxied to
+func (r *Record) SetAPI(apiIdentifier model.APIIdentifier) {
+	if r != nil {
+		r.apiIdentifier = apiIdentifier
+	}
+}
+
+// SetAuthStrategy sets the kind of credentials used for the call to the target system
+func (r *Record) SetAuthStrategy(authStrategy string) {
+	if r != nil {
+		r.authStrategy = authStrategy
+	}
+}
+
+// SetUpstream sets the URL of the target system and its response status, 0 if no response was received
+func (r *Record) SetUpstream(upstreamURL *url.URL, status int) {
+	if r != nil {
+		u := *upstreamURL
+		r.upstreamURL = &u
+		r.upstreamStatus = status
+	}
+}
+
+// AddRetry counts a repeated call to the target system
+func (r *Record) AddRetry() {
+	if r != nil {
+		r.retries++
+	}
+}
+
+// Redact hides the values of headers and query parameters with the given names, for example the ones set from the request parameters of the API
+func (r *Record) Redact(names ...string) {
+	if r != nil {
+		r.redacted = append(r.redacted, names...)
+	}
+}
+
+// NewHandler records the calls handled by handler and writes them to the access log
+func (l *Logger) NewHandler(handler http.Handler) http.Handler {
+	if l.format == FormatNone {
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		record := &Record{
+			start:      time.Now(),
+			requestID:  requestID(r),
+			traceID:    tracing.TraceID(r.Context()),
+			remoteAddr: r.RemoteAddr,
+			method:     r.Method,
+			url:        *r.URL,
+			proto:      r.Proto,
+			headers:    r.Header.Clone(),
+		}
+
+		body := &countingReader{ReadCloser: r.Body}
+		if r.Body != nil && r.Body != http.NoBody {
+			r.Body = body
+		}
+		rw := &responseWriter{ResponseWriter: w}
+
+		handler.ServeHTTP(rw, r.WithContext(NewContext(r.Context(), record)))
+
+		record.status = rw.statusCode()
+		record.bytesIn = body.n
+		record.bytesOut = rw.n
+		record.duration = time.Since(record.start)
+
+		if record.status < http.StatusBadRequest && rand.Float64() >= l.sampleRate {
+			return
+		}
+		l.write(record)
+	})
+}
+
+func requestID(r *http.Request) string {
+	if id := r.Header.Get(httpconsts.HeaderRequestID); id != "" {
+		return id
+	}
+
+	return uuid.New().String()
+}
+
+type entry struct {
+	Time           string            `json:"time"`
+	RequestID      string            `json:"requestID"`
+	TraceID        string            `json:"traceID,omitempty"`
+	RemoteAddr     string            `json:"remoteAddr"`
+	Method         string            `json:"method"`
+	URL            string            `json:"url"`
+	Proto          string            `json:"proto"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	Application    string            `json:"application,omitempty"`
+	Service        string            `json:"service,omitempty"`
+	Entry          string            `json:"entry,omitempty"`
+	AuthStrategy   string            `json:"authStrategy,omitempty"`
+	UpstreamURL    string            `json:"upstreamURL,omitempty"`
+	UpstreamStatus int               `json:"upstreamStatus,omitempty"`
+	Status         int               `json:"status"`
+	BytesIn        int64             `json:"bytesIn"`
+	BytesOut       int64             `json:"bytesOut"`
+	Retries        int               `json:"retries"`
+	DurationMs     int64             `json:"durationMs"`
+}
+
+func (l *Logger) write(record *Record) {
+	redacted := l.redactedNames(record)
+
+	var line []byte
+	switch l.format {
+	case FormatCLF:
+		line = []byte(l.formatCLF(record, redacted))
+	default:
+		var err error
+		line, err = json.Marshal(l.newEntry(record, redacted))
+		if err != nil {
+			return
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = l.out.Write(append(line, '\n'))
+}
+
+func (l *Logger) redactedNames(record *Record) map[string]bool {
+	if len(record.redacted) == 0 {
+		return l.redacted
+	}
+
+	redacted := make(map[string]bool, len(l.redacted)+len(record.redacted))
+	for name := range l.redacted {
+		redacted[name] = true
+	}
+	for _, name := range record.redacted {
+		redacted[strings.ToLower(name)] = true
+	}
+
+	return redacted
+}
+
+func (l *Logger) newEntry(record *Record, redacted map[string]bool) entry {
+	e := entry{
+		Time:           record.start.UTC().Format(time.RFC3339Nano),
+		RequestID:      record.requestID,
+		TraceID:        record.traceID,
+		RemoteAddr:     record.remoteAddr,
+		Method:         record.method,
+		URL:            redactURL(&record.url, redacted),
+		Proto:          record.proto,
+		Application:    record.apiIdentifier.Application,
+		Service:        record.apiIdentifier.Service,
+		Entry:          record.apiIdentifier.Entry,
+		AuthStrategy:   record.authStrategy,
+		UpstreamStatus: record.upstreamStatus,
+		Status:         record.status,
+		BytesIn:        record.bytesIn,
+		BytesOut:       record.bytesOut,
+		Retries:        record.retries,
+		DurationMs:     record.duration.Milliseconds(),
+	}
+	if record.upstreamURL != nil {
+		e.UpstreamURL = redactURL(record.upstreamURL, redacted)
+	}
+
+	for _, name := range l.headers {
+		value := record.headers.Get(name)
+		if value == "" {
+			continue
+		}
+		if e.Headers == nil {
+			e.Headers = map[string]string{}
+		}
+		if redacted[strings.ToLower(name)] {
+			value = Redacted
+		}
+		e.Headers[http.CanonicalHeaderKey(name)] = value
+	}
+
+	return e
+}
+
+// formatCLF formats the record as host ident authuser [date] "request line" status bytes
+func (l *Logger) formatCLF(record *Record, redacted map[string]bool) string {
+	host, _, err := net.SplitHostPort(record.remoteAddr)
+	if err != nil {
+		host = record.remoteAddr
+	}
+	if host == "" {
+		host = "-"
+	}
+
+	size := "-"
+	if record.bytesOut > 0 {
+		size = fmt.Sprint(record.bytesOut)
+	}
+
+	return fmt.Sprintf(`%s - - [%s] "%s %s %s" %d %s`,
+		host,
+		record.start.Format(clfTimeFormat),
+		record.method, redactURL(&record.url, redacted), record.proto,
+		record.status,
+		size)
+}
+
+// redactURL returns the request URI, or the full URL if it has a host, with the values of redacted query parameters hidden
+func redactURL(u *url.URL, redacted map[string]bool) string {
+	result := *u
+	result.User = nil
+
+	query := result.Query()
+	changed := false
+	for name := range query {
+		if redacted[strings.ToLower(name)] {
+			query[name] = []string{Redacted}
+			changed = true
+		}
+	}
+	if changed {
+		result.RawQuery = query.Encode()
+	}
+
+	if result.Host == "" {
+		return result.RequestURI()
+	}
+
+	return result.String()
+}
+
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// responseWriter remembers the status code before it's written, so that it's known even if writing fails
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	n      int64
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	// informational responses are followed by the final status code, except for switching protocols, e.g. to WebSocket
+	if w.status == 0 && (statusCode >= http.StatusOK || statusCode == http.StatusSwitchingProtocols) {
+		w.status = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.n += int64(n)
+	return n, err
+}
+
+// Unwrap allows http.ResponseController to reach the original writer, e.g. for flushing streamed responses
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *responseWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
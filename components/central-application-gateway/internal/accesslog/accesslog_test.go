@@ -0,0 +1,146 @@
+package accesslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+)
+
+func TestLogger(t *testing.T) {
+	apiIdentifier := model.APIIdentifier{Application: "app", Service: "service", Entry: "entry"}
+
+	t.Run("should write the call in the JSON format with redacted headers and query parameters", func(t *testing.T) {
+		// given
+		var out bytes.Buffer
+		logger, err := New(Config{
+			Format:        FormatJSON,
+			SampleRate:    1,
+			Headers:       []string{"X-Tenant", "Authorization"},
+			RedactedNames: []string{"authorization", "token"},
+		}, &out)
+		require.NoError(t, err)
+
+		handler := logger.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.ReadAll(r.Body)
+
+			record := FromContext(r.Context())
+			record.SetAPI(apiIdentifier)
+			record.SetAuthStrategy("OAuth")
+			record.Redact("api_key")
+			record.SetUpstream(&url.URL{Scheme: "https", Host: "target", Path: "/orders", RawQuery: "api_key=secret"}, http.StatusServiceUnavailable)
+			record.AddRetry()
+
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte("failed"))
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, "/app/service/orders?token=secret&id=1", strings.NewReader("body"))
+		req.Header.Set("X-Tenant", "tenant")
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set("X-Request-Id", "request-1")
+
+		// when
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		// then
+		assert.NotContains(t, out.String(), "secret")
+
+		var e entry
+		require.NoError(t, json.Unmarshal(out.Bytes(), &e))
+		assert.Equal(t, "request-1", e.RequestID)
+		assert.Equal(t, http.MethodPost, e.Method)
+		assert.Equal(t, "/app/service/orders?id=1&token="+Redacted, e.URL)
+		assert.Equal(t, map[string]string{"X-Tenant": "tenant", "Authorization": Redacted}, e.Headers)
+		assert.Equal(t, "app", e.Application)
+		assert.Equal(t, "service", e.Service)
+		assert.Equal(t, "entry", e.Entry)
+		assert.Equal(t, "OAuth", e.AuthStrategy)
+		assert.Equal(t, "https://target/orders?api_key="+Redacted, e.UpstreamURL)
+		assert.Equal(t, http.StatusServiceUnavailable, e.UpstreamStatus)
+		assert.Equal(t, http.StatusBadGateway, e.Status)
+		assert.Equal(t, int64(4), e.BytesIn)
+		assert.Equal(t, int64(6), e.BytesOut)
+		assert.Equal(t, 1, e.Retries)
+	})
+
+	t.Run("should write the call in the Common Log Format", func(t *testing.T) {
+		// given
+		var out bytes.Buffer
+		logger, err := New(Config{Format: FormatCLF, SampleRate: 1, RedactedNames: []string{"token"}}, &out)
+		require.NoError(t, err)
+
+		handler := logger.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("ok"))
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/app/service/orders?token=secret", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+
+		// when
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		// then
+		assert.Regexp(t, `^10\.0\.0\.1 - - \[.+\] "GET /app/service/orders\?token=REDACTED HTTP/1\.1" 200 2\n$`, out.String())
+	})
+
+	t.Run("should sample successful calls but write all failed ones", func(t *testing.T) {
+		// given
+		var out bytes.Buffer
+		logger, err := New(Config{Format: FormatCLF, SampleRate: 0}, &out)
+		require.NoError(t, err)
+
+		status := http.StatusOK
+		handler := logger.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+
+		// when
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ok", nil))
+		status = http.StatusNotFound
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+		// then
+		assert.NotContains(t, out.String(), "/ok")
+		assert.Contains(t, out.String(), `"GET /missing HTTP/1.1" 404 -`)
+	})
+
+	t.Run("should not record calls if the access log is disabled", func(t *testing.T) {
+		// given
+		var out bytes.Buffer
+		logger, err := New(Config{Format: FormatNone, SampleRate: 1}, &out)
+		require.NoError(t, err)
+
+		var record *Record
+		handler := logger.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			record = FromContext(r.Context())
+			record.SetAPI(apiIdentifier)
+		}))
+
+		// when
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/app/service", nil))
+
+		// then
+		assert.Nil(t, record)
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("should reject unsupported format and sample rate", func(t *testing.T) {
+		// when
+		_, formatErr := New(Config{Format: "xml", SampleRate: 1}, io.Discard)
+		_, sampleRateErr := New(Config{Format: FormatJSON, SampleRate: 2}, io.Discard)
+
+		// then
+		assert.Error(t, formatErr)
+		assert.Error(t, sampleRateErr)
+	})
+}
@@ -0,0 +1,219 @@
+// Package circuitbreaker contains circuit breakers which stop proxying calls to failing target systems
+package circuitbreaker
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+)
+
+// State of the circuit
+type State string
+
+const (
+	// StateClosed lets all calls through
+	StateClosed State = "closed"
+	// StateOpen rejects all calls until the open duration elapses
+	StateOpen State = "open"
+	// StateHalfOpen lets a limited number of trial calls through to check if the target system recovered
+	StateHalfOpen State = "half-open"
+)
+
+// Config stores thresholds of the circuit breakers
+type Config struct {
+	// FailureThreshold is the number of consecutive failures which opens the circuit, values lower than 1 disable the circuit breakers
+	FailureThreshold int
+	// OpenDuration is the time after which the open circuit lets trial calls through
+	OpenDuration time.Duration
+	// HalfOpenRequests is the number of successful trial calls which close the circuit
+	HalfOpenRequests int
+}
+
+// Status describes the state of the circuit breaker of a single API
+type Status struct {
+	Application         string     `json:"application"`
+	Service             string     `json:"service"`
+	Entry               string     `json:"entry,omitempty"`
+	State               State      `json:"state"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	OpenedAt            *time.Time `json:"openedAt,omitempty"`
+}
+
+// CircuitBreakers keeps a circuit breaker for every API identified the same way as in the proxy cache
+type CircuitBreakers interface {
+	// Allow returns false if calls to the API should be rejected, otherwise the outcome of the call must be reported with Done
+	Allow(apiIdentifier model.APIIdentifier) bool
+	// Done reports the outcome of the call allowed before
+	Done(apiIdentifier model.APIIdentifier, success bool)
+	// Statuses returns the states of the circuit breakers which aren't closed or recorded failures
+	Statuses() []Status
+}
+
+type breaker struct {
+	state               State
+	consecutiveFailures int
+	openedAt            time.Time
+	trialCalls          int
+	trialSuccesses      int
+}
+
+type circuitBreakers struct {
+	config   Config
+	mutex    sync.Mutex
+	breakers map[model.APIIdentifier]*breaker
+	now      func() time.Time
+}
+
+// New creates circuit breakers with the given thresholds
+func New(config Config) CircuitBreakers {
+	if config.HalfOpenRequests < 1 {
+		config.HalfOpenRequests = 1
+	}
+
+	return &circuitBreakers{
+		config:   config,
+		breakers: map[model.APIIdentifier]*breaker{},
+		now:      time.Now,
+	}
+}
+
+func (cb *circuitBreakers) enabled() bool {
+	return cb.config.FailureThreshold > 0
+}
+
+func (cb *circuitBreakers) Allow(apiIdentifier model.APIIdentifier) bool {
+	if !cb.enabled() {
+		return true
+	}
+
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	b, found := cb.breakers[apiIdentifier]
+	if !found {
+		return true
+	}
+
+	switch b.state {
+	case StateOpen:
+		if cb.now().Sub(b.openedAt) < cb.config.OpenDuration {
+			return false
+		}
+		b.state = StateHalfOpen
+		b.trialCalls = 0
+		b.trialSuccesses = 0
+		zap.L().Info("Circuit half-opened, letting trial calls through",
+			zap.String("application", apiIdentifier.Application),
+			zap.String("service", apiIdentifier.Service),
+			zap.String("entry", apiIdentifier.Entry))
+		fallthrough
+	case StateHalfOpen:
+		if b.trialCalls >= cb.config.HalfOpenRequests {
+			return false
+		}
+		b.trialCalls++
+		return true
+	default:
+		return true
+	}
+}
+
+func (cb *circuitBreakers) Done(apiIdentifier model.APIIdentifier, success bool) {
+	if !cb.enabled() {
+		return
+	}
+
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	b, found := cb.breakers[apiIdentifier]
+	if !found {
+		if success {
+			return
+		}
+		b = &breaker{state: StateClosed}
+		cb.breakers[apiIdentifier] = b
+	}
+
+	switch b.state {
+	case StateClosed:
+		if success {
+			delete(cb.breakers, apiIdentifier)
+			return
+		}
+		b.consecutiveFailures++
+		if b.consecutiveFailures >= cb.config.FailureThreshold {
+			cb.open(apiIdentifier, b)
+		}
+	case StateHalfOpen:
+		if b.trialCalls > 0 {
+			b.trialCalls--
+		}
+		if !success {
+			b.consecutiveFailures++
+			cb.open(apiIdentifier, b)
+			return
+		}
+		b.trialSuccesses++
+		if b.trialSuccesses >= cb.config.HalfOpenRequests {
+			delete(cb.breakers, apiIdentifier)
+			zap.L().Info("Circuit closed",
+				zap.String("application", apiIdentifier.Application),
+				zap.String("service", apiIdentifier.Service),
+				zap.String("entry", apiIdentifier.Entry))
+		}
+	case StateOpen:
+		// outcome of a call allowed before the circuit opened
+		if !success {
+			b.consecutiveFailures++
+		}
+	}
+}
+
+func (cb *circuitBreakers) open(apiIdentifier model.APIIdentifier, b *breaker) {
+	b.state = StateOpen
+	b.openedAt = cb.now()
+	zap.L().Warn("Circuit opened, calls to the target system will be rejected",
+		zap.String("application", apiIdentifier.Application),
+		zap.String("service", apiIdentifier.Service),
+		zap.String("entry", apiIdentifier.Entry),
+		zap.Int("consecutiveFailures", b.consecutiveFailures),
+		zap.Duration("openDuration", cb.config.OpenDuration))
+}
+
+func (cb *circuitBreakers) Statuses() []Status {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	statuses := make([]Status, 0, len(cb.breakers))
+	for apiIdentifier, b := range cb.breakers {
+		status := Status{
+			Application:         apiIdentifier.Application,
+			Service:             apiIdentifier.Service,
+			Entry:               apiIdentifier.Entry,
+			State:               b.state,
+			ConsecutiveFailures: b.consecutiveFailures,
+		}
+		if !b.openedAt.IsZero() {
+			openedAt := b.openedAt
+			status.OpenedAt = &openedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Application != statuses[j].Application {
+			return statuses[i].Application < statuses[j].Application
+		}
+		if statuses[i].Service != statuses[j].Service {
+			return statuses[i].Service < statuses[j].Service
+		}
+		return statuses[i].Entry < statuses[j].Entry
+	})
+
+	return statuses
+}
@@ -0,0 +1,118 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+)
+
+func TestCircuitBreakers(t *testing.T) {
+	apiIdentifier := model.APIIdentifier{Application: "app", Service: "service", Entry: "entry"}
+	otherAPIIdentifier := model.APIIdentifier{Application: "app", Service: "other"}
+
+	newCircuitBreakers := func(now *time.Time) *circuitBreakers {
+		cb := New(Config{FailureThreshold: 2, OpenDuration: 30 * time.Second, HalfOpenRequests: 2}).(*circuitBreakers)
+		cb.now = func() time.Time { return *now }
+		return cb
+	}
+
+	failCalls := func(cb CircuitBreakers, count int) {
+		for i := 0; i < count; i++ {
+			require.True(t, cb.Allow(apiIdentifier))
+			cb.Done(apiIdentifier, false)
+		}
+	}
+
+	t.Run("should open circuit after consecutive failures", func(t *testing.T) {
+		// given
+		now := time.Now()
+		cb := newCircuitBreakers(&now)
+
+		// when
+		failCalls(cb, 2)
+
+		// then
+		assert.False(t, cb.Allow(apiIdentifier))
+		assert.True(t, cb.Allow(otherAPIIdentifier))
+
+		statuses := cb.Statuses()
+		require.Len(t, statuses, 1)
+		assert.Equal(t, StateOpen, statuses[0].State)
+		assert.Equal(t, 2, statuses[0].ConsecutiveFailures)
+		assert.Equal(t, "entry", statuses[0].Entry)
+	})
+
+	t.Run("should reset failures after success", func(t *testing.T) {
+		// given
+		now := time.Now()
+		cb := newCircuitBreakers(&now)
+		failCalls(cb, 1)
+
+		// when
+		require.True(t, cb.Allow(apiIdentifier))
+		cb.Done(apiIdentifier, true)
+		failCalls(cb, 1)
+
+		// then
+		assert.True(t, cb.Allow(apiIdentifier))
+	})
+
+	t.Run("should let limited trial calls through after open duration and close after successes", func(t *testing.T) {
+		// given
+		now := time.Now()
+		cb := newCircuitBreakers(&now)
+		failCalls(cb, 2)
+
+		// when
+		now = now.Add(30 * time.Second)
+
+		// then
+		assert.True(t, cb.Allow(apiIdentifier))
+		assert.True(t, cb.Allow(apiIdentifier))
+		assert.False(t, cb.Allow(apiIdentifier))
+		assert.Equal(t, StateHalfOpen, cb.Statuses()[0].State)
+
+		cb.Done(apiIdentifier, true)
+		cb.Done(apiIdentifier, true)
+		assert.True(t, cb.Allow(apiIdentifier))
+		assert.Empty(t, cb.Statuses())
+	})
+
+	t.Run("should reopen circuit when trial call fails", func(t *testing.T) {
+		// given
+		now := time.Now()
+		cb := newCircuitBreakers(&now)
+		failCalls(cb, 2)
+		now = now.Add(30 * time.Second)
+
+		// when
+		require.True(t, cb.Allow(apiIdentifier))
+		cb.Done(apiIdentifier, false)
+
+		// then
+		assert.False(t, cb.Allow(apiIdentifier))
+		statuses := cb.Statuses()
+		require.Len(t, statuses, 1)
+		assert.Equal(t, StateOpen, statuses[0].State)
+		assert.Equal(t, now, *statuses[0].OpenedAt)
+	})
+
+	t.Run("should allow all calls when disabled", func(t *testing.T) {
+		// given
+		cb := New(Config{})
+
+		// when
+		for i := 0; i < 10; i++ {
+			require.True(t, cb.Allow(apiIdentifier))
+			cb.Done(apiIdentifier, false)
+		}
+
+		// then
+		assert.True(t, cb.Allow(apiIdentifier))
+		assert.Empty(t, cb.Statuses())
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metrics"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/clientcert"
@@ -33,7 +34,7 @@ type client struct {
 	clientCertificate clientcert.ClientCertificate
 }
 
-func (c *client) GetTokenEndpointResponse(tokenEndpointURL string, strategy authorization.Strategy, skipTLSVerify bool) (*csrf.Response, apperrors.AppError) {
+func (c *client) GetTokenEndpointResponse(tokenEndpointURL string, strategy authorization.Strategy, connection *httptools.ConnectionSettings, skipTLSVerify bool) (*csrf.Response, apperrors.AppError) {
 
 	resp, found := c.tokenCache.Get(tokenEndpointURL)
 	if found {
@@ -43,10 +44,12 @@ func (c *client) GetTokenEndpointResponse(tokenEndpointURL string, strategy auth
 	zap.L().Info("CSRF Token not found in cache, fetching",
 		zap.String("tokenEndpoint", tokenEndpointURL))
 
-	tokenResponse, err := c.requestToken(tokenEndpointURL, strategy, c.timeoutDuration, skipTLSVerify)
+	tokenResponse, err := c.requestToken(tokenEndpointURL, strategy, connection, c.timeoutDuration, skipTLSVerify)
 	if err != nil {
+		metrics.ObserveCSRFTokenFetch(metrics.ResultFailure)
 		return nil, err
 	}
+	metrics.ObserveCSRFTokenFetch(metrics.ResultSuccess)
 
 	c.tokenCache.Add(tokenEndpointURL, tokenResponse)
 
@@ -60,7 +63,7 @@ func (c *client) InvalidateTokenCache(tokenEndpointURL string) {
 	c.tokenCache.Remove(tokenEndpointURL)
 }
 
-func (c *client) requestToken(csrfEndpointURL string, strategy authorization.Strategy, timeoutDuration int, skipTLSVerify bool) (*csrf.Response, apperrors.AppError) {
+func (c *client) requestToken(csrfEndpointURL string, strategy authorization.Strategy, connection *httptools.ConnectionSettings, timeoutDuration int, skipTLSVerify bool) (*csrf.Response, apperrors.AppError) {
 
 	tokenRequest, err := http.NewRequest(http.MethodGet, csrfEndpointURL, strings.NewReader(""))
 	if err != nil {
@@ -79,7 +82,10 @@ func (c *client) requestToken(csrfEndpointURL string, strategy authorization.Str
 	requestWithContext := tokenRequest.WithContext(ctx)
 
 	httpClient := &http.Client{
-		Transport: httptools.NewRoundTripper(httptools.WithGetClientCertificate(c.clientCertificate.GetClientCertificate), httptools.WithTLSSkipVerify(skipTLSVerify)),
+		Transport: httptools.NewRoundTripper(
+			httptools.WithGetClientCertificate(c.clientCertificate.GetClientCertificate),
+			httptools.WithTLSSkipVerify(skipTLSVerify),
+			httptools.WithConnectionSettings(connection)),
 	}
 	resp, err := httpClient.Do(requestWithContext)
 	if err != nil {
@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 
@@ -16,6 +17,7 @@ import (
 	authorizationMocks "github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/mocks"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/testconsts"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httptools"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -62,7 +64,7 @@ func TestClient_GetTokenEndpointResponse(t *testing.T) {
 		c := New(timeoutDuration, fakeCache)
 
 		// when
-		response, appError := c.GetTokenEndpointResponse(testURL, nil, false)
+		response, appError := c.GetTokenEndpointResponse(testURL, nil, nil, false)
 
 		// then
 		require.Nil(t, appError)
@@ -83,7 +85,7 @@ func TestClient_GetTokenEndpointResponse(t *testing.T) {
 		mockURL := srv.URL
 
 		// when
-		response, appError := c.GetTokenEndpointResponse(mockURL, strategy, true)
+		response, appError := c.GetTokenEndpointResponse(mockURL, strategy, nil, true)
 		item, found := fakeCache.Get(mockURL)
 
 		// then
@@ -110,7 +112,7 @@ func TestClient_GetTokenEndpointResponse(t *testing.T) {
 		mockURL := srv.URL
 
 		// when
-		response, appError := c.GetTokenEndpointResponse(mockURL, strategy, false)
+		response, appError := c.GetTokenEndpointResponse(mockURL, strategy, nil, false)
 		item, found := fakeCache.Get(mockURL)
 
 		// then
@@ -132,7 +134,7 @@ func TestClient_GetTokenEndpointResponse(t *testing.T) {
 		mockURL := srv.URL
 
 		// when
-		response, appError := c.GetTokenEndpointResponse(mockURL, strategy, false)
+		response, appError := c.GetTokenEndpointResponse(mockURL, strategy, nil, false)
 		item, found := fakeCache.Get(mockURL)
 
 		// then
@@ -161,7 +163,7 @@ func TestClient_GetTokenEndpointResponse(t *testing.T) {
 			mockStrategy := &authorizationMocks.Strategy{}
 			mockStrategy.On("AddAuthorization", mock.Anything, mock.Anything, true).Return(nil)
 
-			response, appError := c.GetTokenEndpointResponse(mockURL, mockStrategy, true)
+			response, appError := c.GetTokenEndpointResponse(mockURL, mockStrategy, nil, true)
 
 			// then
 			require.Nil(t, appError)
@@ -178,13 +180,39 @@ func TestClient_GetTokenEndpointResponse(t *testing.T) {
 			mockStrategy := &authorizationMocks.Strategy{}
 			mockStrategy.On("AddAuthorization", mock.Anything, mock.Anything, false).Return(nil)
 
-			response, appError := c.GetTokenEndpointResponse(mockURL, mockStrategy, false)
+			response, appError := c.GetTokenEndpointResponse(mockURL, mockStrategy, nil, false)
 
 			// then
 			require.Nil(t, appError)
 			require.NotNil(t, response)
 		}
 	})
+	t.Run("Should fetch the token through the proxy of the connection", func(t *testing.T) {
+
+		// given
+		var proxiedURL string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			checkRequest(t, r)
+			proxiedURL = r.URL.String()
+			w.Header().Add("x-csrf-token", endpointTestToken)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer proxy.Close()
+
+		proxyURL, err := url.Parse(proxy.URL)
+		require.NoError(t, err)
+
+		c := New(timeoutDuration, NewTokenCache())
+
+		// when
+		response, appError := c.GetTokenEndpointResponse("http://csrf.example.com/token", strategy, &httptools.ConnectionSettings{Proxy: proxyURL}, false)
+
+		// then
+		require.Nil(t, appError)
+		require.NotNil(t, response)
+		assert.Equal(t, endpointTestToken, response.CSRFToken)
+		assert.Equal(t, "http://csrf.example.com/token", proxiedURL)
+	})
 }
 
 func TestAddAuthorization(t *testing.T) {
@@ -4,10 +4,13 @@ package mocks
 
 import (
 	apperrors "github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+
 	authorization "github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
 
 	csrf "github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf"
 
+	httptools "github.com/kyma-project/kyma/components/central-application-gateway/pkg/httptools"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -16,13 +19,13 @@ type Client struct {
 	mock.Mock
 }
 
-// GetTokenEndpointResponse provides a mock function with given fields: csrfEndpointURL, strategy, skipTLSVerify
-func (_m *Client) GetTokenEndpointResponse(csrfEndpointURL string, strategy authorization.Strategy, skipTLSVerify bool) (*csrf.Response, apperrors.AppError) {
-	ret := _m.Called(csrfEndpointURL, strategy, skipTLSVerify)
+// GetTokenEndpointResponse provides a mock function with given fields: csrfEndpointURL, strategy, connection, skipTLSVerify
+func (_m *Client) GetTokenEndpointResponse(csrfEndpointURL string, strategy authorization.Strategy, connection *httptools.ConnectionSettings, skipTLSVerify bool) (*csrf.Response, apperrors.AppError) {
+	ret := _m.Called(csrfEndpointURL, strategy, connection, skipTLSVerify)
 
 	var r0 *csrf.Response
-	if rf, ok := ret.Get(0).(func(string, authorization.Strategy, bool) *csrf.Response); ok {
-		r0 = rf(csrfEndpointURL, strategy, skipTLSVerify)
+	if rf, ok := ret.Get(0).(func(string, authorization.Strategy, *httptools.ConnectionSettings, bool) *csrf.Response); ok {
+		r0 = rf(csrfEndpointURL, strategy, connection, skipTLSVerify)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*csrf.Response)
@@ -30,8 +33,8 @@ func (_m *Client) GetTokenEndpointResponse(csrfEndpointURL string, strategy auth
 	}
 
 	var r1 apperrors.AppError
-	if rf, ok := ret.Get(1).(func(string, authorization.Strategy, bool) apperrors.AppError); ok {
-		r1 = rf(csrfEndpointURL, strategy, skipTLSVerify)
+	if rf, ok := ret.Get(1).(func(string, authorization.Strategy, *httptools.ConnectionSettings, bool) apperrors.AppError); ok {
+		r1 = rf(csrfEndpointURL, strategy, connection, skipTLSVerify)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(apperrors.AppError)
@@ -3,9 +3,12 @@
 package mocks
 
 import (
-	csrf "github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf"
 	authorization "github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
 
+	csrf "github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf"
+
+	httptools "github.com/kyma-project/kyma/components/central-application-gateway/pkg/httptools"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -14,13 +17,13 @@ type TokenStrategyFactory struct {
 	mock.Mock
 }
 
-// Create provides a mock function with given fields: authorizationStrategy, csrfTokenEndpointURL
-func (_m *TokenStrategyFactory) Create(authorizationStrategy authorization.Strategy, csrfTokenEndpointURL string) csrf.TokenStrategy {
-	ret := _m.Called(authorizationStrategy, csrfTokenEndpointURL)
+// Create provides a mock function with given fields: authorizationStrategy, csrfTokenEndpointURL, connection
+func (_m *TokenStrategyFactory) Create(authorizationStrategy authorization.Strategy, csrfTokenEndpointURL string, connection *httptools.ConnectionSettings) csrf.TokenStrategy {
+	ret := _m.Called(authorizationStrategy, csrfTokenEndpointURL, connection)
 
 	var r0 csrf.TokenStrategy
-	if rf, ok := ret.Get(0).(func(authorization.Strategy, string) csrf.TokenStrategy); ok {
-		r0 = rf(authorizationStrategy, csrfTokenEndpointURL)
+	if rf, ok := ret.Get(0).(func(authorization.Strategy, string, *httptools.ConnectionSettings) csrf.TokenStrategy); ok {
+		r0 = rf(authorizationStrategy, csrfTokenEndpointURL, connection)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(csrf.TokenStrategy)
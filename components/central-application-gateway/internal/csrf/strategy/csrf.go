@@ -10,6 +10,7 @@ import (
 
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httptools"
 )
 
 func NewTokenStrategyFactory(csrfClient csrf.Client) csrf.TokenStrategyFactory {
@@ -20,22 +21,23 @@ type strategyFactory struct {
 	csrfClient csrf.Client
 }
 
-func (tsf *strategyFactory) Create(authorizationStrategy authorization.Strategy, csrfTokenEndpointURL string) csrf.TokenStrategy {
+func (tsf *strategyFactory) Create(authorizationStrategy authorization.Strategy, csrfTokenEndpointURL string, connection *httptools.ConnectionSettings) csrf.TokenStrategy {
 	if csrfTokenEndpointURL == "" {
 		return &noTokenStrategy{}
 	}
-	return &strategy{authorizationStrategy, csrfTokenEndpointURL, tsf.csrfClient}
+	return &strategy{authorizationStrategy, csrfTokenEndpointURL, connection, tsf.csrfClient}
 }
 
 type strategy struct {
 	authorizationStrategy authorization.Strategy
 	csrfTokenURL          string
+	connection            *httptools.ConnectionSettings
 	csrfClient            csrf.Client
 }
 
 func (s *strategy) AddCSRFToken(apiRequest *http.Request, skipTLSVerify bool) apperrors.AppError {
 
-	tokenResponse, err := s.csrfClient.GetTokenEndpointResponse(s.csrfTokenURL, s.authorizationStrategy, skipTLSVerify)
+	tokenResponse, err := s.csrfClient.GetTokenEndpointResponse(s.csrfTokenURL, s.authorizationStrategy, s.connection, skipTLSVerify)
 	if err != nil {
 		zap.L().Error("failed to get CSRF token",
 			zap.Error(err))
@@ -10,6 +10,7 @@ import (
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 	authmocks "github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/mocks"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httptools"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -28,7 +29,7 @@ func TestStrategyFactory_Create(t *testing.T) {
 	t.Run("Should create strategy if the CSRF token endpoint URL has been provided", func(t *testing.T) {
 
 		// when
-		tokenStrategy := factory.Create(authStrategy, TestTokenEndpointURL)
+		tokenStrategy := factory.Create(authStrategy, TestTokenEndpointURL, nil)
 
 		// then
 		require.NotNil(t, tokenStrategy)
@@ -38,7 +39,7 @@ func TestStrategyFactory_Create(t *testing.T) {
 	t.Run("Should create noTokenStrategy if the CSRF token has not been provided", func(t *testing.T) {
 
 		// when
-		tokenStrategy := factory.Create(authStrategy, noURL)
+		tokenStrategy := factory.Create(authStrategy, noURL, nil)
 
 		// then
 		require.NotNil(t, tokenStrategy)
@@ -65,7 +66,7 @@ func TestStrategy_AddCSRFToken(t *testing.T) {
 			c := &mocks.Client{}
 			sf := NewTokenStrategyFactory(c)
 
-			s := sf.Create(authStrategy, testCSRFTokenEndpointURL)
+			s := sf.Create(authStrategy, testCSRFTokenEndpointURL, nil)
 
 			cachedItem := &csrf.Response{
 				CSRFToken: cachedToken,
@@ -74,7 +75,7 @@ func TestStrategy_AddCSRFToken(t *testing.T) {
 				},
 			}
 
-			c.On("GetTokenEndpointResponse", testCSRFTokenEndpointURL, authStrategy, false).Return(cachedItem, nil)
+			c.On("GetTokenEndpointResponse", testCSRFTokenEndpointURL, authStrategy, (*httptools.ConnectionSettings)(nil), false).Return(cachedItem, nil)
 
 			// when
 			err := s.AddCSRFToken(req, false)
@@ -96,7 +97,7 @@ func TestStrategy_AddCSRFToken(t *testing.T) {
 			c := &mocks.Client{}
 			sf := NewTokenStrategyFactory(c)
 
-			s := sf.Create(authStrategy, testCSRFTokenEndpointURL)
+			s := sf.Create(authStrategy, testCSRFTokenEndpointURL, nil)
 
 			cachedItem := &csrf.Response{
 				CSRFToken: cachedToken,
@@ -105,7 +106,7 @@ func TestStrategy_AddCSRFToken(t *testing.T) {
 				},
 			}
 
-			c.On("GetTokenEndpointResponse", testCSRFTokenEndpointURL, authStrategy, false).Return(cachedItem, nil)
+			c.On("GetTokenEndpointResponse", testCSRFTokenEndpointURL, authStrategy, (*httptools.ConnectionSettings)(nil), false).Return(cachedItem, nil)
 
 			// when
 			err := s.AddCSRFToken(req, false)
@@ -132,9 +133,9 @@ func TestStrategy_AddCSRFToken(t *testing.T) {
 			c := &mocks.Client{}
 			sf := NewTokenStrategyFactory(c)
 
-			s := sf.Create(authStrategy, testCSRFTokenEndpointURL)
+			s := sf.Create(authStrategy, testCSRFTokenEndpointURL, nil)
 
-			c.On("GetTokenEndpointResponse", testCSRFTokenEndpointURL, authStrategy, false).Return(nil, apperrors.NotFoundf("error"))
+			c.On("GetTokenEndpointResponse", testCSRFTokenEndpointURL, authStrategy, (*httptools.ConnectionSettings)(nil), false).Return(nil, apperrors.NotFoundf("error"))
 
 			//when
 			err := s.AddCSRFToken(req, false)
@@ -154,7 +155,7 @@ func TestStrategy_AddCSRFToken(t *testing.T) {
 			c := &mocks.Client{}
 			sf := NewTokenStrategyFactory(c)
 
-			s := sf.Create(nil, "")
+			s := sf.Create(nil, "", nil)
 
 			// when
 			err := s.AddCSRFToken(req, false)
@@ -5,14 +5,15 @@ import (
 
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httptools"
 )
 
 // CSRF Client is an HTTP client responsible for fetching and caching CSRF Tokens.
 //
 //go:generate mockery --name=Client
 type Client interface {
-	//Fetches data from CSRF Token Endpoint
-	GetTokenEndpointResponse(csrfEndpointURL string, strategy authorization.Strategy, skipTLSVerify bool) (*Response, apperrors.AppError)
+	//Fetches data from CSRF Token Endpoint, connection (optional) defines how the connection to the endpoint is opened
+	GetTokenEndpointResponse(csrfEndpointURL string, strategy authorization.Strategy, connection *httptools.ConnectionSettings, skipTLSVerify bool) (*Response, apperrors.AppError)
 
 	//Invalidates cached data
 	InvalidateTokenCache(csrfEndpointURL string)
@@ -28,7 +29,7 @@ type Response struct {
 //
 //go:generate mockery --name=TokenStrategyFactory
 type TokenStrategyFactory interface {
-	Create(authorizationStrategy authorization.Strategy, csrfTokenEndpointURL string) TokenStrategy
+	Create(authorizationStrategy authorization.Strategy, csrfTokenEndpointURL string, connection *httptools.ConnectionSettings) TokenStrategy
 }
 
 // Augments upstream API requests with CSRF data.
@@ -0,0 +1,90 @@
+// Package drain tracks the calls in flight and the readiness of the gateway, so that it can shut down without cutting calls
+package drain
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// Tracker counts the calls in flight, including the streamed and upgraded ones which the server doesn't wait for on shutdown.
+// The gateway stops being ready when draining starts.
+type Tracker struct {
+	draining atomic.Bool
+
+	mutex    sync.Mutex
+	inFlight int
+	// idle is closed when no calls are in flight
+	idle chan struct{}
+}
+
+// NewTracker creates tracker of a gateway which is ready
+func NewTracker() *Tracker {
+	idle := make(chan struct{})
+	close(idle)
+
+	return &Tracker{idle: idle}
+}
+
+// Handler tracks the calls to the handler until it returns
+func (t *Tracker) Handler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.begin()
+		defer t.end()
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func (t *Tracker) begin() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.inFlight == 0 {
+		t.idle = make(chan struct{})
+	}
+	t.inFlight++
+}
+
+func (t *Tracker) end() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.inFlight--
+	if t.inFlight == 0 {
+		close(t.idle)
+	}
+}
+
+// StartDraining marks the gateway as not ready, the calls are still served
+func (t *Tracker) StartDraining() {
+	t.draining.Store(true)
+}
+
+// Ready reports whether the gateway accepts new calls
+func (t *Tracker) Ready() bool {
+	return !t.draining.Load()
+}
+
+// InFlight returns the number of calls in flight
+func (t *Tracker) InFlight() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.inFlight
+}
+
+// Wait waits until no calls are in flight, or returns the error of the context if it's done first
+func (t *Tracker) Wait(ctx context.Context) error {
+	t.mutex.Lock()
+	idle := t.idle
+	t.mutex.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
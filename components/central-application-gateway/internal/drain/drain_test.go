@@ -0,0 +1,73 @@
+package drain
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker(t *testing.T) {
+	t.Run("should wait for the calls in flight", func(t *testing.T) {
+		// given
+		tracker := NewTracker()
+		started := make(chan struct{})
+		release := make(chan struct{})
+		handler := tracker.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/app/service", nil))
+		}()
+		<-started
+
+		// when
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := tracker.Wait(ctx)
+
+		// then
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, 1, tracker.InFlight())
+
+		// when
+		close(release)
+		<-done
+
+		// then
+		require.NoError(t, tracker.Wait(context.Background()))
+		assert.Zero(t, tracker.InFlight())
+	})
+
+	t.Run("should not wait when no calls are in flight", func(t *testing.T) {
+		// given
+		tracker := NewTracker()
+
+		// when
+		err := tracker.Wait(context.Background())
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("should stop being ready when draining starts", func(t *testing.T) {
+		// given
+		tracker := NewTracker()
+		require.True(t, tracker.Ready())
+
+		// when
+		tracker.StartDraining()
+
+		// then
+		assert.False(t, tracker.Ready())
+	})
+}
@@ -0,0 +1,23 @@
+package externalapi
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/circuitbreaker"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+)
+
+// NewCircuitBreakerHandler creates handler returning the states of circuit breakers which aren't closed or recorded failures
+func NewCircuitBreakerHandler(circuitBreakers circuitbreaker.CircuitBreakers) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		statuses := circuitBreakers.Statuses()
+
+		w.Header().Set(httpconsts.HeaderContentType, httpconsts.ContentTypeApplicationJson)
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(statuses); err != nil {
+			slog.Warn("encode failed", "body", statuses, "err", err.Error())
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package externalapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/circuitbreaker"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+)
+
+func TestCircuitBreakerHandler(t *testing.T) {
+	t.Run("should respond with states of circuit breakers", func(t *testing.T) {
+		// given
+		apiIdentifier := model.APIIdentifier{Application: "app", Service: "service"}
+		circuitBreakers := circuitbreaker.New(circuitbreaker.Config{FailureThreshold: 1})
+		circuitBreakers.Done(apiIdentifier, false)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/circuitbreakers", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		handler := NewCircuitBreakerHandler(circuitBreakers)
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var statuses []circuitbreaker.Status
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &statuses))
+		require.Len(t, statuses, 1)
+		assert.Equal(t, "app", statuses[0].Application)
+		assert.Equal(t, "service", statuses[0].Service)
+		assert.Equal(t, circuitbreaker.StateOpen, statuses[0].State)
+	})
+}
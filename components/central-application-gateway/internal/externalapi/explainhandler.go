@@ -0,0 +1,76 @@
+package externalapi
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/httperrors"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/proxy"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+)
+
+//go:generate mockery --srcpkg=github.com/kyma-project/kyma/components/central-application-gateway/internal/proxy --name=Explainer --output=mocks
+
+const (
+	// ExplainModeStandalone explains calls to the proxy of the standalone mode
+	ExplainModeStandalone = "standalone"
+	// ExplainModeCompass explains calls to the proxy of the Compass mode
+	ExplainModeCompass = "compass"
+)
+
+// NewExplainHandler creates handler explaining how the proxy of the mode query parameter routes the call to the path query parameter.
+// Callers must send the token read from tokenFile as the bearer token, the file is read on every call, so that the token can be rotated.
+func NewExplainHandler(explainers map[string]proxy.Explainer, tokenFile string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !authenticated(r, tokenFile) {
+			respondWithError(w, http.StatusUnauthorized, "valid bearer token required")
+			return
+		}
+
+		mode := r.URL.Query().Get("mode")
+		if mode == "" {
+			mode = ExplainModeStandalone
+		}
+		explainer, found := explainers[mode]
+		if !found {
+			respondWithError(w, http.StatusBadRequest, "unsupported mode '"+mode+"'")
+			return
+		}
+
+		explanation, err := explainer.Explain(r.URL.Query().Get("path"))
+		if err != nil {
+			status, body := httperrors.AppErrorToResponse(err)
+			respondWithError(w, status, body.Error)
+			return
+		}
+
+		w.Header().Set(httpconsts.HeaderContentType, httpconsts.ContentTypeApplicationJson)
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(explanation); err != nil {
+			slog.Warn("encode failed", "body", explanation, "err", err.Error())
+		}
+	})
+}
+
+func authenticated(r *http.Request, tokenFile string) bool {
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		zap.L().Error("Failed to read the token of the explain endpoint", zap.Error(err))
+		return false
+	}
+
+	expected := strings.TrimSpace(string(token))
+	actual, found := strings.CutPrefix(r.Header.Get(httpconsts.HeaderAuthorization), "Bearer ")
+
+	return found && expected != "" && subtle.ConstantTimeCompare([]byte(actual), []byte(expected)) == 1
+}
+
+func respondWithError(w http.ResponseWriter, code int, message string) {
+	NewErrorHandler(code, message).ServeHTTP(w, nil)
+}
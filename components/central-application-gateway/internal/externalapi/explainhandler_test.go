@@ -0,0 +1,105 @@
+package externalapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/externalapi/mocks"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/httperrors"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/proxy"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+)
+
+func TestExplainHandler(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("debug-token\n"), 0600))
+
+	call := func(handler http.Handler, url, token string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("should respond with explanation of the call in the mode", func(t *testing.T) {
+		// given
+		explainer := &mocks.Explainer{}
+		explainer.On("Explain", "/app/service/entry/orders").Return(proxy.Explanation{
+			Application: "app",
+			Service:     "service",
+			Entry:       "entry",
+			API:         &proxy.ExplainedAPI{TargetURLs: []string{"https://orders.example.com/orders"}, CredentialsType: "OAuth"},
+		}, nil)
+
+		handler := NewExplainHandler(map[string]proxy.Explainer{ExplainModeCompass: explainer}, tokenFile)
+
+		// when
+		rr := call(handler, "/v1/explain?mode=compass&path=/app/service/entry/orders", "debug-token")
+
+		// then
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var explanation proxy.Explanation
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &explanation))
+		assert.Equal(t, "entry", explanation.Entry)
+		assert.Equal(t, []string{"https://orders.example.com/orders"}, explanation.API.TargetURLs)
+		explainer.AssertExpectations(t)
+	})
+
+	t.Run("should reject calls without the token", func(t *testing.T) {
+		// given
+		explainer := &mocks.Explainer{}
+		handler := NewExplainHandler(map[string]proxy.Explainer{ExplainModeStandalone: explainer}, tokenFile)
+
+		for _, token := range []string{"", "other-token"} {
+			// when
+			rr := call(handler, "/v1/explain?path=/app/service", token)
+
+			// then
+			assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		}
+		explainer.AssertNotCalled(t, "Explain")
+	})
+
+	t.Run("should reject calls when the token can't be read", func(t *testing.T) {
+		// given
+		handler := NewExplainHandler(map[string]proxy.Explainer{}, filepath.Join(t.TempDir(), "missing"))
+
+		// when
+		rr := call(handler, "/v1/explain?path=/app/service", "debug-token")
+
+		// then
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("should respond with bad request when the mode or the path is invalid", func(t *testing.T) {
+		// given
+		explainer := &mocks.Explainer{}
+		explainer.On("Explain", "/app").Return(proxy.Explanation{}, apperrors.WrongInputf("path must contain Application and Service name"))
+
+		handler := NewExplainHandler(map[string]proxy.Explainer{ExplainModeStandalone: explainer}, tokenFile)
+
+		for _, url := range []string{"/v1/explain?mode=destination&path=/app", "/v1/explain?path=/app"} {
+			// when
+			rr := call(handler, url, "debug-token")
+
+			// then
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+			var errResponse httperrors.ErrorResponse
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &errResponse))
+			assert.Equal(t, http.StatusBadRequest, errResponse.Code)
+		}
+	})
+}
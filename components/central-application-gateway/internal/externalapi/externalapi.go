@@ -5,13 +5,24 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/circuitbreaker"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metrics"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/proxy"
 )
 
-func NewHandler(lvl zap.AtomicLevel) http.Handler {
+// NewHandler creates handler of the external API, the explain endpoint is exposed only if explainTokenFile is set
+func NewHandler(lvl zap.AtomicLevel, circuitBreakers circuitbreaker.CircuitBreakers, explainers map[string]proxy.Explainer, explainTokenFile string, ready func() bool) http.Handler {
 	router := mux.NewRouter()
 
 	router.Path("/v1/health").Handler(NewHealthCheckHandler()).Methods(http.MethodGet)
+	router.Path("/v1/ready").Handler(NewReadinessHandler(ready)).Methods(http.MethodGet)
 	router.Path("/v1/loglevel").Handler(lvl).Methods(http.MethodGet, http.MethodPut)
+	router.Path("/v1/circuitbreakers").Handler(NewCircuitBreakerHandler(circuitBreakers)).Methods(http.MethodGet)
+	if explainTokenFile != "" {
+		router.Path("/v1/explain").Handler(NewExplainHandler(explainers, explainTokenFile)).Methods(http.MethodGet)
+	}
+	router.Path("/metrics").Handler(metrics.NewHandler()).Methods(http.MethodGet)
 
 	router.NotFoundHandler = NewErrorHandler(404, "Requested resource could not be found.")
 	router.MethodNotAllowedHandler = NewErrorHandler(405, "Method not allowed.")
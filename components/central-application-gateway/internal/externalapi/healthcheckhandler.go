@@ -10,3 +10,14 @@ func NewHealthCheckHandler() http.Handler {
 		w.WriteHeader(http.StatusOK)
 	})
 }
+
+// NewReadinessHandler creates handler for performing readiness check, which fails while the gateway drains calls before shutdown
+func NewReadinessHandler(ready func() bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if !ready() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
@@ -25,3 +25,35 @@ func TestHealthCheckHandler_HandleRequest(t *testing.T) {
 		assert.Equal(t, http.StatusOK, rr.Code)
 	})
 }
+
+func TestReadinessHandler_HandleRequest(t *testing.T) {
+	t.Run("should respond with 200 status code when ready", func(t *testing.T) {
+		// given
+		req, err := http.NewRequest(http.MethodGet, "/v1/ready", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		handler := NewReadinessHandler(func() bool { return true })
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("should respond with 503 status code when draining", func(t *testing.T) {
+		// given
+		req, err := http.NewRequest(http.MethodGet, "/v1/ready", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		handler := NewReadinessHandler(func() bool { return false })
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	})
+}
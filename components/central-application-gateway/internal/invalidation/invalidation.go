@@ -0,0 +1,200 @@
+// Package invalidation contains handlers of Application, Secret and ConfigMap events which remove the stale proxies and tokens from caches
+package invalidation
+
+import (
+	"reflect"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apis/applicationconnector/v1alpha1"
+)
+
+// Cache keeps data created for an Application or for a destination secret
+//
+//go:generate mockery --name=Cache
+type Cache interface {
+	// Invalidate removes the data cached for the Application or the destination secret with the given name
+	Invalidate(name string)
+}
+
+// ApplicationLister lists Applications from the cache kept up to date by the informer
+type ApplicationLister interface {
+	List(selector labels.Selector) ([]*v1alpha1.Application, error)
+}
+
+// NewApplicationEventHandler creates a handler which invalidates the caches of the Application when its spec changes or it is deleted
+func NewApplicationEventHandler(caches ...Cache) cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldApp, ok := oldObj.(*v1alpha1.Application)
+			if !ok {
+				return
+			}
+			newApp, ok := newObj.(*v1alpha1.Application)
+			if !ok || reflect.DeepEqual(oldApp.Spec, newApp.Spec) {
+				return
+			}
+
+			zap.L().Info("Application changed, invalidating cached proxies", zap.String("application", newApp.Name))
+			invalidate(caches, newApp.Name)
+		},
+		DeleteFunc: func(obj interface{}) {
+			app, ok := deletedObject(obj).(*v1alpha1.Application)
+			if !ok {
+				return
+			}
+
+			zap.L().Info("Application deleted, invalidating cached proxies", zap.String("application", app.Name))
+			invalidate(caches, app.Name)
+		},
+	}
+}
+
+// NewSecretEventHandler creates a handler which invalidates the caches of the Applications referencing the secret and of the destination defined in the secret
+// when the secret data changes or the secret is deleted
+func NewSecretEventHandler(applicationLister ApplicationLister, applicationCaches []Cache, destinationCaches []Cache) cache.ResourceEventHandler {
+	onChange := func(secret *v1.Secret) {
+		invalidate(destinationCaches, secret.Name)
+
+		apps, err := applicationLister.List(labels.Everything())
+		if err != nil {
+			zap.L().Error("Failed to list Applications referencing the changed secret",
+				zap.String("secretName", secret.Name),
+				zap.Error(err))
+			return
+		}
+
+		for _, app := range apps {
+			if references(app, secret.Name) {
+				zap.L().Info("Secret referenced by the Application changed, invalidating cached proxies",
+					zap.String("application", app.Name),
+					zap.String("secretName", secret.Name))
+				invalidate(applicationCaches, app.Name)
+			}
+		}
+	}
+
+	return cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldSecret, ok := oldObj.(*v1.Secret)
+			if !ok {
+				return
+			}
+			newSecret, ok := newObj.(*v1.Secret)
+			if !ok || reflect.DeepEqual(oldSecret.Data, newSecret.Data) {
+				return
+			}
+
+			onChange(newSecret)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if secret, ok := deletedObject(obj).(*v1.Secret); ok {
+				onChange(secret)
+			}
+		},
+	}
+}
+
+// NewConfigMapEventHandler creates a handler which invalidates the caches of the Applications referencing the ConfigMap
+// when its data changes or it is deleted
+func NewConfigMapEventHandler(applicationLister ApplicationLister, applicationCaches []Cache) cache.ResourceEventHandler {
+	onChange := func(configMap *v1.ConfigMap) {
+		apps, err := applicationLister.List(labels.Everything())
+		if err != nil {
+			zap.L().Error("Failed to list Applications referencing the changed ConfigMap",
+				zap.String("configMapName", configMap.Name),
+				zap.Error(err))
+			return
+		}
+
+		for _, app := range apps {
+			if referencesConfigMap(app, configMap.Name) {
+				zap.L().Info("ConfigMap referenced by the Application changed, invalidating cached proxies",
+					zap.String("application", app.Name),
+					zap.String("configMapName", configMap.Name))
+				invalidate(applicationCaches, app.Name)
+			}
+		}
+	}
+
+	return cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldConfigMap, ok := oldObj.(*v1.ConfigMap)
+			if !ok {
+				return
+			}
+			newConfigMap, ok := newObj.(*v1.ConfigMap)
+			if !ok || reflect.DeepEqual(oldConfigMap.Data, newConfigMap.Data) {
+				return
+			}
+
+			onChange(newConfigMap)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if configMap, ok := deletedObject(obj).(*v1.ConfigMap); ok {
+				onChange(configMap)
+			}
+		},
+	}
+}
+
+// deletedObject unwraps the last known state of an object whose deletion was missed by the watch
+func deletedObject(obj interface{}) interface{} {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		return tombstone.Obj
+	}
+
+	return obj
+}
+
+func references(app *v1alpha1.Application, secretName string) bool {
+	if app.Spec.UpstreamProxy != nil && app.Spec.UpstreamProxy.CredentialsSecretName == secretName {
+		return true
+	}
+	if referencesCABundle(app.Spec.TLS, func(caBundle *v1alpha1.CABundle) bool { return caBundle.SecretName == secretName }) {
+		return true
+	}
+
+	for _, service := range app.Spec.Services {
+		for _, entry := range service.Entries {
+			if entry.Credentials.SecretName == secretName || entry.RequestParametersSecretName == secretName {
+				return true
+			}
+			if referencesCABundle(entry.TLS, func(caBundle *v1alpha1.CABundle) bool { return caBundle.SecretName == secretName }) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func referencesConfigMap(app *v1alpha1.Application, configMapName string) bool {
+	matches := func(caBundle *v1alpha1.CABundle) bool { return caBundle.ConfigMapName == configMapName }
+	if referencesCABundle(app.Spec.TLS, matches) {
+		return true
+	}
+
+	for _, service := range app.Spec.Services {
+		for _, entry := range service.Entries {
+			if referencesCABundle(entry.TLS, matches) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func referencesCABundle(tls *v1alpha1.TLS, matches func(caBundle *v1alpha1.CABundle) bool) bool {
+	return tls != nil && tls.CABundle != nil && matches(tls.CABundle)
+}
+
+func invalidate(caches []Cache, name string) {
+	for _, c := range caches {
+		c.Invalidate(name)
+	}
+}
@@ -0,0 +1,230 @@
+package invalidation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/invalidation/mocks"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apis/applicationconnector/v1alpha1"
+	listers "github.com/kyma-project/kyma/components/central-application-gateway/pkg/client/listers/applicationconnector/v1alpha1"
+)
+
+func TestApplicationEventHandler(t *testing.T) {
+	t.Run("should invalidate caches when the Application spec changes", func(t *testing.T) {
+		// given
+		cacheMock := &mocks.Cache{}
+		cacheMock.On("Invalidate", "app").Return().Once()
+		handler := NewApplicationEventHandler(cacheMock)
+
+		oldApp := createApplication("app", "secret")
+		newApp := createApplication("app", "secret")
+		newApp.Spec.SkipVerify = true
+
+		// when
+		handler.OnUpdate(oldApp, newApp)
+
+		// then
+		cacheMock.AssertExpectations(t)
+	})
+
+	t.Run("should not invalidate caches when only the Application status changes", func(t *testing.T) {
+		// given
+		cacheMock := &mocks.Cache{}
+		handler := NewApplicationEventHandler(cacheMock)
+
+		oldApp := createApplication("app", "secret")
+		newApp := createApplication("app", "secret")
+		newApp.ResourceVersion = "2"
+
+		// when
+		handler.OnUpdate(oldApp, newApp)
+
+		// then
+		cacheMock.AssertNotCalled(t, "Invalidate", "app")
+	})
+
+	t.Run("should invalidate caches when the Application is deleted", func(t *testing.T) {
+		// given
+		cacheMock := &mocks.Cache{}
+		cacheMock.On("Invalidate", "app").Return().Twice()
+		handler := NewApplicationEventHandler(cacheMock)
+
+		// when
+		handler.OnDelete(createApplication("app", "secret"))
+		handler.OnDelete(cache.DeletedFinalStateUnknown{Key: "app", Obj: createApplication("app", "secret")})
+
+		// then
+		cacheMock.AssertExpectations(t)
+	})
+}
+
+func TestSecretEventHandler(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	require.NoError(t, indexer.Add(createApplication("app-1", "shared-secret")))
+	require.NoError(t, indexer.Add(createApplication("app-2", "other-secret")))
+	appWithProxy := createApplication("app-3", "other-secret")
+	appWithProxy.Spec.UpstreamProxy = &v1alpha1.UpstreamProxy{URL: "http://proxy:3128", CredentialsSecretName: "proxy-secret"}
+	appWithProxy.Spec.Services[0].Entries[0].TLS = &v1alpha1.TLS{CABundle: &v1alpha1.CABundle{SecretName: "ca-secret"}}
+	require.NoError(t, indexer.Add(appWithProxy))
+	applicationLister := listers.NewApplicationLister(indexer)
+
+	t.Run("should invalidate caches of the destination and the Applications referencing the changed secret", func(t *testing.T) {
+		// given
+		applicationCacheMock := &mocks.Cache{}
+		applicationCacheMock.On("Invalidate", "app-1").Return().Once()
+		destinationCacheMock := &mocks.Cache{}
+		destinationCacheMock.On("Invalidate", "shared-secret").Return().Once()
+		handler := NewSecretEventHandler(applicationLister, []Cache{applicationCacheMock}, []Cache{destinationCacheMock})
+
+		// when
+		handler.OnUpdate(createSecret("shared-secret", "old"), createSecret("shared-secret", "new"))
+
+		// then
+		applicationCacheMock.AssertExpectations(t)
+		destinationCacheMock.AssertExpectations(t)
+		applicationCacheMock.AssertNotCalled(t, "Invalidate", "app-2")
+	})
+
+	t.Run("should invalidate caches of the Applications using the changed proxy credentials or CA bundle", func(t *testing.T) {
+		for _, secretName := range []string{"proxy-secret", "ca-secret"} {
+			// given
+			applicationCacheMock := &mocks.Cache{}
+			applicationCacheMock.On("Invalidate", "app-3").Return().Once()
+			destinationCacheMock := &mocks.Cache{}
+			destinationCacheMock.On("Invalidate", secretName).Return().Once()
+			handler := NewSecretEventHandler(applicationLister, []Cache{applicationCacheMock}, []Cache{destinationCacheMock})
+
+			// when
+			handler.OnUpdate(createSecret(secretName, "old"), createSecret(secretName, "new"))
+
+			// then
+			applicationCacheMock.AssertExpectations(t)
+			destinationCacheMock.AssertExpectations(t)
+			applicationCacheMock.AssertNotCalled(t, "Invalidate", "app-1")
+		}
+	})
+
+	t.Run("should not invalidate caches when the secret data doesn't change", func(t *testing.T) {
+		// given
+		applicationCacheMock := &mocks.Cache{}
+		destinationCacheMock := &mocks.Cache{}
+		handler := NewSecretEventHandler(applicationLister, []Cache{applicationCacheMock}, []Cache{destinationCacheMock})
+
+		// when
+		handler.OnUpdate(createSecret("shared-secret", "same"), createSecret("shared-secret", "same"))
+
+		// then
+		applicationCacheMock.AssertNotCalled(t, "Invalidate", "app-1")
+		destinationCacheMock.AssertNotCalled(t, "Invalidate", "shared-secret")
+	})
+
+	t.Run("should invalidate caches when the secret is deleted", func(t *testing.T) {
+		// given
+		applicationCacheMock := &mocks.Cache{}
+		applicationCacheMock.On("Invalidate", "app-2").Return().Once()
+		applicationCacheMock.On("Invalidate", "app-3").Return().Once()
+		destinationCacheMock := &mocks.Cache{}
+		destinationCacheMock.On("Invalidate", "other-secret").Return().Once()
+		handler := NewSecretEventHandler(applicationLister, []Cache{applicationCacheMock}, []Cache{destinationCacheMock})
+
+		// when
+		handler.OnDelete(cache.DeletedFinalStateUnknown{Key: "kyma-system/other-secret", Obj: createSecret("other-secret", "old")})
+
+		// then
+		applicationCacheMock.AssertExpectations(t)
+		destinationCacheMock.AssertExpectations(t)
+	})
+}
+
+func TestConfigMapEventHandler(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	appWithCABundle := createApplication("app-1", "secret")
+	appWithCABundle.Spec.TLS = &v1alpha1.TLS{CABundle: &v1alpha1.CABundle{ConfigMapName: "ca-bundle"}}
+	require.NoError(t, indexer.Add(appWithCABundle))
+	require.NoError(t, indexer.Add(createApplication("app-2", "secret")))
+	applicationLister := listers.NewApplicationLister(indexer)
+
+	t.Run("should invalidate caches of the Applications referencing the changed ConfigMap", func(t *testing.T) {
+		// given
+		applicationCacheMock := &mocks.Cache{}
+		applicationCacheMock.On("Invalidate", "app-1").Return().Once()
+		handler := NewConfigMapEventHandler(applicationLister, []Cache{applicationCacheMock})
+
+		// when
+		handler.OnUpdate(createConfigMap("ca-bundle", "old"), createConfigMap("ca-bundle", "new"))
+
+		// then
+		applicationCacheMock.AssertExpectations(t)
+		applicationCacheMock.AssertNotCalled(t, "Invalidate", "app-2")
+	})
+
+	t.Run("should not invalidate caches when the ConfigMap data doesn't change", func(t *testing.T) {
+		// given
+		applicationCacheMock := &mocks.Cache{}
+		handler := NewConfigMapEventHandler(applicationLister, []Cache{applicationCacheMock})
+
+		// when
+		handler.OnUpdate(createConfigMap("ca-bundle", "same"), createConfigMap("ca-bundle", "same"))
+
+		// then
+		applicationCacheMock.AssertNotCalled(t, "Invalidate", "app-1")
+	})
+
+	t.Run("should invalidate caches when the ConfigMap is deleted", func(t *testing.T) {
+		// given
+		applicationCacheMock := &mocks.Cache{}
+		applicationCacheMock.On("Invalidate", "app-1").Return().Once()
+		handler := NewConfigMapEventHandler(applicationLister, []Cache{applicationCacheMock})
+
+		// when
+		handler.OnDelete(cache.DeletedFinalStateUnknown{Key: "kyma-system/ca-bundle", Obj: createConfigMap("ca-bundle", "old")})
+
+		// then
+		applicationCacheMock.AssertExpectations(t)
+	})
+}
+
+func createApplication(name, secretName string) *v1alpha1.Application {
+	return &v1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: name, ResourceVersion: "1"},
+		Spec: v1alpha1.ApplicationSpec{
+			Services: []v1alpha1.Service{
+				{
+					DisplayName: "service",
+					Entries: []v1alpha1.Entry{
+						{
+							Type:      "API",
+							TargetUrl: "https://example.com",
+							Credentials: v1alpha1.Credentials{
+								Type:       "Basic",
+								SecretName: secretName,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func createSecret(name, password string) *v1.Secret {
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kyma-system"},
+		Data: map[string][]byte{
+			"password": []byte(password),
+		},
+	}
+}
+
+func createConfigMap(name, caBundle string) *v1.ConfigMap {
+	return &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kyma-system"},
+		Data: map[string]string{
+			"ca.crt": caBundle,
+		},
+	}
+}
@@ -14,6 +14,81 @@ type ServiceRepository struct {
 	mock.Mock
 }
 
+// CandidatesByEntryName provides a mock function with given fields: appName, serviceName, entryName
+func (_m *ServiceRepository) CandidatesByEntryName(appName string, serviceName string, entryName string) ([]applications.Candidate, apperrors.AppError) {
+	ret := _m.Called(appName, serviceName, entryName)
+
+	var r0 []applications.Candidate
+	if rf, ok := ret.Get(0).(func(string, string, string) []applications.Candidate); ok {
+		r0 = rf(appName, serviceName, entryName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]applications.Candidate)
+		}
+	}
+
+	var r1 apperrors.AppError
+	if rf, ok := ret.Get(1).(func(string, string, string) apperrors.AppError); ok {
+		r1 = rf(appName, serviceName, entryName)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(apperrors.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// CandidatesByServiceName provides a mock function with given fields: appName, serviceName
+func (_m *ServiceRepository) CandidatesByServiceName(appName string, serviceName string) ([]applications.Candidate, apperrors.AppError) {
+	ret := _m.Called(appName, serviceName)
+
+	var r0 []applications.Candidate
+	if rf, ok := ret.Get(0).(func(string, string) []applications.Candidate); ok {
+		r0 = rf(appName, serviceName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]applications.Candidate)
+		}
+	}
+
+	var r1 apperrors.AppError
+	if rf, ok := ret.Get(1).(func(string, string) apperrors.AppError); ok {
+		r1 = rf(appName, serviceName)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(apperrors.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetAccessControl provides a mock function with given fields: appName
+func (_m *ServiceRepository) GetAccessControl(appName string) (*applications.AccessControl, apperrors.AppError) {
+	ret := _m.Called(appName)
+
+	var r0 *applications.AccessControl
+	if rf, ok := ret.Get(0).(func(string) *applications.AccessControl); ok {
+		r0 = rf(appName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*applications.AccessControl)
+		}
+	}
+
+	var r1 apperrors.AppError
+	if rf, ok := ret.Get(1).(func(string) apperrors.AppError); ok {
+		r1 = rf(appName)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(apperrors.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
 // GetByEntryName provides a mock function with given fields: appName, serviceName, entryName
 func (_m *ServiceRepository) GetByEntryName(appName string, serviceName string, entryName string) (applications.Service, apperrors.AppError) {
 	ret := _m.Called(appName, serviceName, entryName)
@@ -60,6 +135,11 @@ func (_m *ServiceRepository) GetByServiceName(appName string, serviceName string
 	return r0, r1
 }
 
+// Invalidate provides a mock function with given fields: appName
+func (_m *ServiceRepository) Invalidate(appName string) {
+	_m.Called(appName)
+}
+
 type mockConstructorTestingTNewServiceRepository interface {
 	mock.TestingT
 	Cleanup(func())
@@ -2,19 +2,18 @@
 package applications
 
 import (
-	"context"
 	"fmt"
-	"os"
+	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apis/applicationconnector/v1alpha1"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/normalization"
-	"github.com/patrickmn/go-cache"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 )
@@ -24,17 +23,26 @@ const (
 	specEventsType = "Events"
 )
 
-// Manager contains operations for managing Application CRD
+// Lister reads Applications from the cache kept up to date by the informer
 //
-//go:generate mockery --name=Manager
-type Manager interface {
-	Get(ctx context.Context, name string, options v1.GetOptions) (*v1alpha1.Application, error)
+//go:generate mockery --name=Lister
+type Lister interface {
+	Get(name string) (*v1alpha1.Application, error)
 }
 
 type repository struct {
-	appManager     Manager
-	cache          *cache.Cache
-	cacheRetention time.Duration
+	appLister Lister
+
+	mutex   sync.Mutex
+	regexes map[string]*applicationRegexes
+}
+
+// applicationRegexes are the path rewrite regular expressions of an Application compiled once for its generation
+type applicationRegexes struct {
+	uid        types.UID
+	generation int64
+	compiled   map[string]*regexp.Regexp
+	invalid    map[string]error
 }
 
 // Credentials stores information about credentials needed to call an API
@@ -45,6 +53,12 @@ type Credentials struct {
 	CSRFTokenEndpointURL string
 }
 
+// RequestBodyBuffering stores information about buffering of request bodies sent to an API
+type RequestBodyBuffering struct {
+	Mode        string
+	MemoryLimit int64
+}
+
 // ServiceAPI stores information needed to call an API
 type ServiceAPI struct {
 	TargetURL                   string
@@ -52,10 +66,112 @@ type ServiceAPI struct {
 	RequestParametersSecretName string
 	SkipVerify                  bool
 	EncodeURL                   bool
+	RetryPolicy                 *RetryPolicy
+	RequestBodyBuffering        *RequestBodyBuffering
+	ApplicationRateLimit        *RateLimit
+	ServiceRateLimit            *RateLimit
+	Transformations             *Transformations
+	ProxyMode                   string
+	ResponseCache               *ResponseCache
+	Targets                     []Target
+	LoadBalancing               *LoadBalancing
+	UpstreamProxy               *UpstreamProxy
+	TLS                         *TLS
+}
+
+// AccessControl stores the identities of the callers allowed to call the APIs of an Application, ServiceAccounts are in the namespace/name form
+type AccessControl struct {
+	ServiceAccounts []string
+	SPIFFEIDs       []string
+	Namespaces      []string
+}
+
+// TLS stores information about the TLS connections to the target system of an API
+type TLS struct {
+	CABundleSecretName    string
+	CABundleConfigMapName string
+	CABundleKey           string
+	MinVersion            string
+	CipherSuites          []string
+	ServerName            string
+	PinnedPublicKeys      []string
+}
+
+// UpstreamProxy stores information about the proxy through which an API and its token endpoints are called
+type UpstreamProxy struct {
+	URL                   string
+	CredentialsSecretName string
+}
+
+// Target stores information about an instance of the target system serving an API
+type Target struct {
+	URL      string
+	Priority int
+}
+
+// LoadBalancing stores information about distribution of calls among the targets of an API
+type LoadBalancing struct {
+	Strategy           string
+	UnhealthyThreshold int
+	UnhealthyDuration  time.Duration
+}
+
+// ResponseCache stores information about caching of responses to GET calls to an API
+type ResponseCache struct {
+	MaxEntrySize int64
+}
+
+// Transformations stores information about modifications of calls to an API and its responses
+type Transformations struct {
+	StripRequestHeaders []string
+	PathRewrites        []PathRewrite
+	RequestHeaders      *HeaderTransformations
+	ResponseHeaders     *HeaderTransformations
+}
+
+// PathRewrite stores the regular expression replacing parts of the path of calls to an API
+type PathRewrite struct {
+	Regex       *regexp.Regexp
+	Replacement string
+}
+
+// HeaderTransformations stores information about modifications of headers
+type HeaderTransformations struct {
+	Remove []string
+	Rename map[string]string
+	Add    map[string]string
+}
+
+// RateLimit stores information about the rate limit of calls to an Application or its service
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// RetryPolicy stores information about retries of idempotent calls to an API
+type RetryPolicy struct {
+	MaxAttempts          int
+	InitialBackoff       time.Duration
+	MaxBackoff           time.Duration
+	RetryableStatusCodes []int
 }
 
 type predicateFunc func(service v1alpha1.Service, entry v1alpha1.Entry) bool
 
+// Candidate is an entry of an Application compared with the names in the path of a call
+type Candidate struct {
+	ServiceID          string `json:"serviceId"`
+	ServiceDisplayName string `json:"serviceDisplayName"`
+	// ServiceName is the normalized display name compared with the service name in the path
+	ServiceName string `json:"serviceName"`
+	EntryName   string `json:"entryName"`
+	// NormalizedEntryName is compared with the entry name in the path in the Compass mode
+	NormalizedEntryName string `json:"normalizedEntryName"`
+	EntryType           string `json:"entryType"`
+	Matches             bool   `json:"matches"`
+}
+
 // Service represents a service stored in Application
 type Service struct {
 	// Mapped to id in Application CRD
@@ -80,19 +196,18 @@ type Service struct {
 type ServiceRepository interface {
 	GetByServiceName(appName, serviceName string) (Service, apperrors.AppError)
 	GetByEntryName(appName, serviceName, entryName string) (Service, apperrors.AppError)
+	CandidatesByServiceName(appName, serviceName string) ([]Candidate, apperrors.AppError)
+	CandidatesByEntryName(appName, serviceName, entryName string) ([]Candidate, apperrors.AppError)
+	GetAccessControl(appName string) (*AccessControl, apperrors.AppError)
+	// Invalidate compiles the path rewrites of the changed Application, so that the invalid ones are logged when it changes, and drops them once it's deleted
+	Invalidate(appName string)
 }
 
 // NewServiceRepository creates a new ApplicationServiceRepository
-func NewServiceRepository(appManager Manager) ServiceRepository {
-	cacheRetention, err := time.ParseDuration(os.Getenv("ACM_GATEWAY_APPCACHE_RETENTION"))
-	if err != nil || cacheRetention <= 0 {
-		cacheRetention = 5 * time.Minute
-	}
-	zap.L().Info("Configuring application cache to store application data for %.2fm", zap.Float64("cacheRetention", cacheRetention.Minutes()))
+func NewServiceRepository(appLister Lister) ServiceRepository {
 	return &repository{
-		appManager:     appManager,
-		cache:          cache.New(cacheRetention, 3*time.Minute),
-		cacheRetention: cacheRetention,
+		appLister: appLister,
+		regexes:   map[string]*applicationRegexes{},
 	}
 }
 
@@ -102,12 +217,95 @@ func (r *repository) GetByServiceName(appName, serviceName string) (Service, app
 }
 
 func (r *repository) GetByEntryName(appName, serviceName, entryName string) (Service, apperrors.AppError) {
+	return r.get(appName, getEntryMatchFunction(serviceName, entryName))
+}
 
-	matchServiceAndEntry := func(service v1alpha1.Service, entry v1alpha1.Entry) bool {
-		serviceMatchFunc := getMatchFunction(serviceName)
-		return serviceMatchFunc(service, entry) && entryName == normalization.NormalizeName(entry.Name)
+// CandidatesByServiceName lists all entries of the Application and marks the ones GetByServiceName selects from
+func (r *repository) CandidatesByServiceName(appName, serviceName string) ([]Candidate, apperrors.AppError) {
+	return r.candidates(appName, getMatchFunction(serviceName))
+}
+
+// CandidatesByEntryName lists all entries of the Application and marks the ones GetByEntryName selects from
+func (r *repository) CandidatesByEntryName(appName, serviceName, entryName string) ([]Candidate, apperrors.AppError) {
+	return r.candidates(appName, getEntryMatchFunction(serviceName, entryName))
+}
+
+// GetAccessControl reads the identities of the callers allowed to call the APIs of the Application, nil allows all callers
+func (r *repository) GetAccessControl(appName string) (*AccessControl, apperrors.AppError) {
+	app, err := r.getApplication(appName)
+	if err != nil {
+		return nil, err
 	}
-	return r.get(appName, matchServiceAndEntry)
+
+	return convertAccessControlFromK8sType(app.Spec.AccessControl), nil
+}
+
+func (r *repository) Invalidate(appName string) {
+	app, err := r.appLister.Get(appName)
+	if err != nil {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		delete(r.regexes, appName)
+		return
+	}
+
+	r.pathRewriteRegexes(app)
+}
+
+// pathRewriteRegexes returns the path rewrites of the Application, compiled again only if the Application changed
+func (r *repository) pathRewriteRegexes(app *v1alpha1.Application) *applicationRegexes {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	regexes, found := r.regexes[app.Name]
+	if found && regexes.uid == app.UID && regexes.generation == app.Generation {
+		return regexes
+	}
+
+	regexes = compilePathRewriteRegexes(app)
+	r.regexes[app.Name] = regexes
+
+	return regexes
+}
+
+func compilePathRewriteRegexes(app *v1alpha1.Application) *applicationRegexes {
+	regexes := &applicationRegexes{
+		uid:        app.UID,
+		generation: app.Generation,
+		compiled:   map[string]*regexp.Regexp{},
+		invalid:    map[string]error{},
+	}
+
+	for _, service := range app.Spec.Services {
+		for _, entry := range service.Entries {
+			if entry.Transformations == nil || entry.Transformations.Request == nil {
+				continue
+			}
+			for _, pathRewrite := range entry.Transformations.Request.PathRewrites {
+				if _, found := regexes.compiled[pathRewrite.Regex]; found {
+					continue
+				}
+				if _, found := regexes.invalid[pathRewrite.Regex]; found {
+					continue
+				}
+
+				regex, err := regexp.Compile(pathRewrite.Regex)
+				if err != nil {
+					zap.L().Error("Invalid path rewrite regex, calls to the entry are rejected",
+						zap.String("application", app.Name),
+						zap.String("serviceName", service.DisplayName),
+						zap.String("entryName", entry.Name),
+						zap.String("regex", pathRewrite.Regex),
+						zap.Error(err))
+					regexes.invalid[pathRewrite.Regex] = err
+					continue
+				}
+				regexes.compiled[pathRewrite.Regex] = regex
+			}
+		}
+	}
+
+	return regexes
 }
 
 func getMatchFunction(serviceName string) predicateFunc {
@@ -116,17 +314,53 @@ func getMatchFunction(serviceName string) predicateFunc {
 	}
 }
 
+func getEntryMatchFunction(serviceName, entryName string) predicateFunc {
+	return func(service v1alpha1.Service, entry v1alpha1.Entry) bool {
+		serviceMatchFunc := getMatchFunction(serviceName)
+		return serviceMatchFunc(service, entry) && entryName == normalization.NormalizeName(entry.Name)
+	}
+}
+
+func (r *repository) candidates(appName string, predicate predicateFunc) ([]Candidate, apperrors.AppError) {
+	app, err := r.getApplication(appName)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]Candidate, 0)
+	for _, service := range app.Spec.Services {
+		for _, entry := range service.Entries {
+			candidates = append(candidates, Candidate{
+				ServiceID:           service.ID,
+				ServiceDisplayName:  service.DisplayName,
+				ServiceName:         normalization.NormalizeName(service.DisplayName),
+				EntryName:           entry.Name,
+				NormalizedEntryName: normalization.NormalizeName(entry.Name),
+				EntryType:           entry.Type,
+				Matches:             predicate(service, entry),
+			})
+		}
+	}
+
+	return candidates, nil
+}
+
 func (r *repository) get(appName string, predicate func(service v1alpha1.Service, entry v1alpha1.Entry) bool) (Service, apperrors.AppError) {
 	app, err := r.getApplication(appName)
 	if err != nil {
 		return Service{}, err
 	}
+	regexes := r.pathRewriteRegexes(app)
 	services := make([]Service, 0)
 	infos := make([]string, 0)
 	for _, service := range app.Spec.Services {
 		for _, entry := range service.Entries {
 			if predicate(service, entry) {
-				services = append(services, convert(service, entry, app.Spec.SkipVerify, app.Spec.EncodeURL))
+				converted, err := convert(service, entry, app.Spec, regexes)
+				if err != nil {
+					return Service{}, err
+				}
+				services = append(services, converted)
 				infos = append(infos, fmt.Sprintf("service.ID: '%s', service.DisplayName: '%s', entry.Name: '%s'", service.ID, service.DisplayName, entry.Name))
 			}
 		}
@@ -142,20 +376,7 @@ func (r *repository) get(appName string, predicate func(service v1alpha1.Service
 }
 
 func (r *repository) getApplication(appName string) (*v1alpha1.Application, apperrors.AppError) {
-	var app *v1alpha1.Application
-	cacheKey := fmt.Sprintf("app-%s", appName)
-	if cachedItem, found := r.cache.Get(cacheKey); found {
-		if cachedItem == nil {
-			zap.L().Warn("found empty application entity '%s' in cache - this is not expected, deleting it from cache now",
-				zap.String("appName", appName))
-			r.cache.Delete(cacheKey)
-		} else {
-			app := cachedItem.(*v1alpha1.Application)
-			return app, nil
-		}
-	}
-
-	app, err := r.appManager.Get(context.Background(), appName, v1.GetOptions{})
+	app, err := r.appLister.Get(appName)
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
 			message := fmt.Sprintf("Application: %s not found.", appName)
@@ -171,19 +392,35 @@ func (r *repository) getApplication(appName string) (*v1alpha1.Application, appe
 		return nil, apperrors.Internal(message)
 	}
 
-	if err := r.cache.Add(cacheKey, app, r.cacheRetention); err != nil {
-		zap.L().Warn("Failed to update application cache entity '%s': %v", zap.String("appName", cacheKey), zap.Error(err))
-	}
 	return app, nil
 }
 
-func convert(service v1alpha1.Service, entry v1alpha1.Entry, skipVerify, encodeURL bool) Service {
+func convert(service v1alpha1.Service, entry v1alpha1.Entry, spec v1alpha1.ApplicationSpec, regexes *applicationRegexes) (Service, apperrors.AppError) {
+	transformations, err := convertTransformationsFromK8sType(entry.Transformations, regexes)
+	if err != nil {
+		return Service{}, err
+	}
+
 	api := &ServiceAPI{
 		TargetURL:                   entry.TargetUrl,
 		Credentials:                 convertCredentialsFromK8sType(entry.Credentials),
 		RequestParametersSecretName: entry.RequestParametersSecretName,
-		SkipVerify:                  skipVerify,
-		EncodeURL:                   encodeURL,
+		SkipVerify:                  spec.SkipVerify,
+		EncodeURL:                   spec.EncodeURL,
+		RetryPolicy:                 convertRetryPolicyFromK8sType(spec.RetryPolicy),
+		RequestBodyBuffering:        convertRequestBodyBufferingFromK8sType(spec.RequestBodyBuffering),
+		ApplicationRateLimit:        convertRateLimitFromK8sType(spec.RateLimit),
+		ServiceRateLimit:            convertRateLimitFromK8sType(service.RateLimit),
+		Transformations:             transformations,
+		ProxyMode:                   entry.ProxyMode,
+		ResponseCache:               convertResponseCacheFromK8sType(entry.ResponseCache),
+		Targets:                     convertTargetsFromK8sType(entry.Targets),
+		LoadBalancing:               convertLoadBalancingFromK8sType(entry.LoadBalancing),
+		UpstreamProxy:               convertUpstreamProxyFromK8sType(spec.UpstreamProxy),
+		TLS:                         convertTLSFromK8sType(spec.TLS),
+	}
+	if entry.TLS != nil {
+		api.TLS = convertTLSFromK8sType(entry.TLS)
 	}
 
 	return Service{
@@ -194,7 +431,7 @@ func convert(service v1alpha1.Service, entry v1alpha1.Entry, skipVerify, encodeU
 		ProviderDisplayName: service.ProviderDisplayName,
 		Tags:                service.Tags,
 		API:                 api,
-	}
+	}, nil
 }
 
 func convertCredentialsFromK8sType(credentials v1alpha1.Credentials) *Credentials {
@@ -215,3 +452,171 @@ func convertCredentialsFromK8sType(credentials v1alpha1.Credentials) *Credential
 		CSRFTokenEndpointURL: csrfTokenEndpointURL,
 	}
 }
+
+func convertRetryPolicyFromK8sType(retryPolicy *v1alpha1.RetryPolicy) *RetryPolicy {
+	if retryPolicy == nil {
+		return nil
+	}
+
+	result := &RetryPolicy{
+		MaxAttempts:          retryPolicy.MaxAttempts,
+		RetryableStatusCodes: retryPolicy.RetryableStatusCodes,
+	}
+	if retryPolicy.InitialBackoff != nil {
+		result.InitialBackoff = retryPolicy.InitialBackoff.Duration
+	}
+	if retryPolicy.MaxBackoff != nil {
+		result.MaxBackoff = retryPolicy.MaxBackoff.Duration
+	}
+
+	return result
+}
+
+func convertRequestBodyBufferingFromK8sType(requestBodyBuffering *v1alpha1.RequestBodyBuffering) *RequestBodyBuffering {
+	if requestBodyBuffering == nil {
+		return nil
+	}
+
+	return &RequestBodyBuffering{
+		Mode:        requestBodyBuffering.Mode,
+		MemoryLimit: requestBodyBuffering.MemoryLimit,
+	}
+}
+
+func convertRateLimitFromK8sType(rateLimit *v1alpha1.RateLimit) *RateLimit {
+	if rateLimit == nil {
+		return nil
+	}
+
+	result := &RateLimit{
+		Requests: rateLimit.Requests,
+		Burst:    rateLimit.Burst,
+	}
+	if rateLimit.Period != nil {
+		result.Period = rateLimit.Period.Duration
+	}
+
+	return result
+}
+
+func convertUpstreamProxyFromK8sType(upstreamProxy *v1alpha1.UpstreamProxy) *UpstreamProxy {
+	if upstreamProxy == nil {
+		return nil
+	}
+
+	return &UpstreamProxy{
+		URL:                   upstreamProxy.URL,
+		CredentialsSecretName: upstreamProxy.CredentialsSecretName,
+	}
+}
+
+func convertAccessControlFromK8sType(accessControl *v1alpha1.AccessControl) *AccessControl {
+	if accessControl == nil {
+		return nil
+	}
+
+	result := &AccessControl{
+		SPIFFEIDs:  accessControl.SPIFFEIDs,
+		Namespaces: accessControl.Namespaces,
+	}
+	for _, serviceAccount := range accessControl.ServiceAccounts {
+		result.ServiceAccounts = append(result.ServiceAccounts, serviceAccount.Namespace+"/"+serviceAccount.Name)
+	}
+
+	return result
+}
+
+func convertTLSFromK8sType(tls *v1alpha1.TLS) *TLS {
+	if tls == nil {
+		return nil
+	}
+
+	result := &TLS{
+		MinVersion:       tls.MinVersion,
+		CipherSuites:     tls.CipherSuites,
+		ServerName:       tls.ServerName,
+		PinnedPublicKeys: tls.PinnedPublicKeys,
+	}
+	if tls.CABundle != nil {
+		result.CABundleSecretName = tls.CABundle.SecretName
+		result.CABundleConfigMapName = tls.CABundle.ConfigMapName
+		result.CABundleKey = tls.CABundle.Key
+	}
+
+	return result
+}
+
+func convertResponseCacheFromK8sType(responseCache *v1alpha1.ResponseCache) *ResponseCache {
+	if responseCache == nil {
+		return nil
+	}
+
+	return &ResponseCache{
+		MaxEntrySize: responseCache.MaxEntrySize,
+	}
+}
+
+func convertTargetsFromK8sType(targets []v1alpha1.Target) []Target {
+	var result []Target
+	for _, target := range targets {
+		result = append(result, Target{
+			URL:      target.URL,
+			Priority: target.Priority,
+		})
+	}
+
+	return result
+}
+
+func convertLoadBalancingFromK8sType(loadBalancing *v1alpha1.LoadBalancing) *LoadBalancing {
+	if loadBalancing == nil {
+		return nil
+	}
+
+	result := &LoadBalancing{
+		Strategy:           loadBalancing.Strategy,
+		UnhealthyThreshold: loadBalancing.UnhealthyThreshold,
+	}
+	if loadBalancing.UnhealthyDuration != nil {
+		result.UnhealthyDuration = loadBalancing.UnhealthyDuration.Duration
+	}
+
+	return result
+}
+
+func convertTransformationsFromK8sType(transformations *v1alpha1.Transformations, regexes *applicationRegexes) (*Transformations, apperrors.AppError) {
+	if transformations == nil {
+		return nil, nil
+	}
+
+	result := &Transformations{
+		ResponseHeaders: convertHeaderTransformationsFromK8sType(transformations.Response),
+	}
+	if transformations.Request != nil {
+		result.StripRequestHeaders = transformations.Request.StripHeaders
+		result.RequestHeaders = convertHeaderTransformationsFromK8sType(transformations.Request.Headers)
+		for _, pathRewrite := range transformations.Request.PathRewrites {
+			if err, found := regexes.invalid[pathRewrite.Regex]; found {
+				return nil, apperrors.Internalf("invalid path rewrite regex '%s': %s", pathRewrite.Regex, err.Error())
+			}
+			result.PathRewrites = append(result.PathRewrites, PathRewrite{
+				Regex:       regexes.compiled[pathRewrite.Regex],
+				Replacement: pathRewrite.Replacement,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+func convertHeaderTransformationsFromK8sType(headers *v1alpha1.HeaderTransformations) *HeaderTransformations {
+	if headers == nil {
+		return nil
+	}
+
+	return &HeaderTransformations{
+		Remove: headers.Remove,
+		Rename: headers.Rename,
+		Add:    headers.Add,
+	}
+}
@@ -1,8 +1,9 @@
 package applications_test
 
 import (
-	"context"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/applications"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/applications/mocks"
@@ -10,6 +11,7 @@ import (
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -44,6 +46,34 @@ func TestGetServices(t *testing.T) {
 		},
 	}
 
+	expectedServiceAPIWithPolicies := applications.ServiceAPI{
+		TargetURL: "https://192.168.1.2",
+		Credentials: &applications.Credentials{
+			Type:       "OAuth",
+			SecretName: "SecretName",
+			URL:        "www.example.com/token",
+		},
+		RetryPolicy: &applications.RetryPolicy{
+			MaxAttempts:          3,
+			InitialBackoff:       200 * time.Millisecond,
+			RetryableStatusCodes: []int{503},
+		},
+		ApplicationRateLimit: &applications.RateLimit{Requests: 100, Period: time.Minute},
+		ServiceRateLimit:     &applications.RateLimit{Requests: 10, Burst: 5},
+		Transformations: &applications.Transformations{
+			StripRequestHeaders: []string{"Cookie"},
+			PathRewrites:        []applications.PathRewrite{{Regex: regexp.MustCompile("^/v1/"), Replacement: "/v2/"}},
+			RequestHeaders:      &applications.HeaderTransformations{Add: map[string]string{"X-Source": "kyma"}},
+			ResponseHeaders:     &applications.HeaderTransformations{Remove: []string{"Server"}},
+		},
+		ProxyMode:     "PassThrough",
+		ResponseCache: &applications.ResponseCache{MaxEntrySize: 4096},
+		Targets:       []applications.Target{{URL: "https://192.168.1.2"}, {URL: "https://192.168.1.3", Priority: 1}},
+		LoadBalancing: &applications.LoadBalancing{Strategy: "Failover", UnhealthyDuration: time.Minute},
+		UpstreamProxy: &applications.UpstreamProxy{URL: "http://proxy.example.com:3128", CredentialsSecretName: "proxy-secret"},
+		TLS:           &applications.TLS{CABundleConfigMapName: "entry-ca", MinVersion: "1.3", ServerName: "orders.internal"},
+	}
+
 	for _, testCase := range []testcase{
 		{
 			description: "should get service by service name",
@@ -77,14 +107,21 @@ func TestGetServices(t *testing.T) {
 			},
 			expectedServiceAPI: expectedServiceAPISkipVerify,
 		},
+		{
+			description: "should get service with retry policy, rate limits and transformations",
+			application: createApplicationWithPolicies("production"),
+			testFunc: func(repository applications.ServiceRepository) (applications.Service, apperrors.AppError) {
+				return repository.GetByServiceName("production", "service-1")
+			},
+			expectedServiceAPI: expectedServiceAPIWithPolicies,
+		},
 	} {
 		t.Run(testCase.description, func(t *testing.T) {
 			// given
-			managerMock := &mocks.Manager{}
-			managerMock.On("Get", context.Background(), "production", metav1.GetOptions{}).
-				Return(testCase.application, nil)
+			listerMock := &mocks.Lister{}
+			listerMock.On("Get", "production").Return(testCase.application, nil)
 
-			repository := applications.NewServiceRepository(managerMock)
+			repository := applications.NewServiceRepository(listerMock)
 			require.NotNil(t, repository)
 
 			// when
@@ -128,11 +165,10 @@ func TestGetServices(t *testing.T) {
 	} {
 		t.Run("should return not found error if service doesn't exist", func(t *testing.T) {
 			// given
-			managerMock := &mocks.Manager{}
-			managerMock.On("Get", context.Background(), "production", metav1.GetOptions{}).
-				Return(testCase.application, nil)
+			listerMock := &mocks.Lister{}
+			listerMock.On("Get", "production").Return(testCase.application, nil)
 
-			repository := applications.NewServiceRepository(managerMock)
+			repository := applications.NewServiceRepository(listerMock)
 			require.NotNil(t, repository)
 
 			// when
@@ -163,11 +199,10 @@ func TestGetServices(t *testing.T) {
 	} {
 		t.Run("should return bad request error if multiple services were found", func(t *testing.T) {
 			// given
-			managerMock := &mocks.Manager{}
-			managerMock.On("Get", context.Background(), "production", metav1.GetOptions{}).
-				Return(testCase.application, nil)
+			listerMock := &mocks.Lister{}
+			listerMock.On("Get", "production").Return(testCase.application, nil)
 
-			repository := applications.NewServiceRepository(managerMock)
+			repository := applications.NewServiceRepository(listerMock)
 			require.NotNil(t, repository)
 
 			// when
@@ -179,25 +214,218 @@ func TestGetServices(t *testing.T) {
 		})
 	}
 
-	t.Run("Verify caching of application entities", func(t *testing.T) {
+	t.Run("should return not found error if Application doesn't exist", func(t *testing.T) {
 		// given
-		managerMock := &mocks.Manager{}
-		managerMock.On("Get", context.Background(), "cachedApp", metav1.GetOptions{}).
-			Once(). //Once() is mandatory to make this test meaningful!
-			Return(createApplication("cachedApp", false), nil)
+		listerMock := &mocks.Lister{}
+		listerMock.On("Get", "deletedApp").
+			Return(nil, k8serrors.NewNotFound(v1alpha1.Resource("application"), "deletedApp"))
 
-		repository := applications.NewServiceRepository(managerMock)
+		repository := applications.NewServiceRepository(listerMock)
 		require.NotNil(t, repository)
 
 		// when
-		for i := 0; i < 100; i++ {
-			// only first object will be retrieved from manager and afterwards from cache otherwise manager mock will fail
-			svc, err := repository.GetByServiceName("cachedApp", "service-1")
-			assert.NoError(t, err)
-			assert.NotNil(t, svc)
+		service, err := repository.GetByServiceName("deletedApp", "service-1")
+
+		// then
+		assert.Equal(t, applications.Service{}, service)
+		require.Error(t, err)
+		assert.Equal(t, apperrors.CodeNotFound, err.Code())
+	})
+
+}
+
+func TestGetAccessControl(t *testing.T) {
+	t.Run("should get access control of the Application", func(t *testing.T) {
+		// given
+		listerMock := &mocks.Lister{}
+		listerMock.On("Get", "production").Return(createApplicationWithPolicies("production"), nil)
+
+		repository := applications.NewServiceRepository(listerMock)
+
+		// when
+		accessControl, err := repository.GetAccessControl("production")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, &applications.AccessControl{ServiceAccounts: []string{"orders/orders-client"}, Namespaces: []string{"billing"}}, accessControl)
+	})
+
+	t.Run("should return no access control if the Application doesn't restrict the callers", func(t *testing.T) {
+		// given
+		listerMock := &mocks.Lister{}
+		listerMock.On("Get", "production").Return(createApplication("production", false), nil)
+
+		repository := applications.NewServiceRepository(listerMock)
+
+		// when
+		accessControl, err := repository.GetAccessControl("production")
+
+		// then
+		require.NoError(t, err)
+		assert.Nil(t, accessControl)
+	})
+
+	t.Run("should return not found error if Application doesn't exist", func(t *testing.T) {
+		// given
+		listerMock := &mocks.Lister{}
+		listerMock.On("Get", "deletedApp").
+			Return(nil, k8serrors.NewNotFound(v1alpha1.Resource("application"), "deletedApp"))
+
+		repository := applications.NewServiceRepository(listerMock)
+
+		// when
+		_, err := repository.GetAccessControl("deletedApp")
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, apperrors.CodeNotFound, err.Code())
+	})
+}
+
+func TestPathRewrites(t *testing.T) {
+	t.Run("should compile the path rewrites once for the generation of the Application", func(t *testing.T) {
+		// given
+		application := createApplicationWithPolicies("production")
+		application.Generation = 1
+		changedApplication := createApplicationWithPolicies("production")
+		changedApplication.Generation = 2
+
+		listerMock := &mocks.Lister{}
+		listerMock.On("Get", "production").Return(application, nil).Twice()
+		listerMock.On("Get", "production").Return(changedApplication, nil).Once()
+
+		repository := applications.NewServiceRepository(listerMock)
+
+		// when
+		service, err := repository.GetByServiceName("production", "service-1")
+		require.NoError(t, err)
+		sameGenerationService, err := repository.GetByServiceName("production", "service-1")
+		require.NoError(t, err)
+		changedService, err := repository.GetByServiceName("production", "service-1")
+		require.NoError(t, err)
+
+		// then
+		regex := service.API.Transformations.PathRewrites[0].Regex
+		assert.Same(t, regex, sameGenerationService.API.Transformations.PathRewrites[0].Regex)
+		assert.NotSame(t, regex, changedService.API.Transformations.PathRewrites[0].Regex)
+	})
+
+	t.Run("should return internal error if the path rewrite regex is invalid", func(t *testing.T) {
+		// given
+		application := createApplicationWithPolicies("production")
+		application.Spec.Services[0].Entries[0].Transformations.Request.PathRewrites = []v1alpha1.PathRewrite{{Regex: "^/v1/(.*"}}
+
+		listerMock := &mocks.Lister{}
+		listerMock.On("Get", "production").Return(application, nil)
+
+		repository := applications.NewServiceRepository(listerMock)
+
+		// when
+		service, err := repository.GetByServiceName("production", "service-1")
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, apperrors.CodeInternal, err.Code())
+		assert.Contains(t, err.Error(), "invalid path rewrite regex")
+		assert.Equal(t, applications.Service{}, service)
+	})
+
+	t.Run("should compile the path rewrites when the Application changes", func(t *testing.T) {
+		// given
+		listerMock := &mocks.Lister{}
+		listerMock.On("Get", "production").Return(createApplicationWithPolicies("production"), nil)
+
+		repository := applications.NewServiceRepository(listerMock)
+		repository.Invalidate("production")
+
+		// when
+		service, err := repository.GetByServiceName("production", "service-1")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, regexp.MustCompile("^/v1/"), service.API.Transformations.PathRewrites[0].Regex)
+		listerMock.AssertNumberOfCalls(t, "Get", 2)
+	})
+
+	t.Run("should drop the path rewrites of the deleted Application", func(t *testing.T) {
+		// given
+		listerMock := &mocks.Lister{}
+		listerMock.On("Get", "deletedApp").
+			Return(nil, k8serrors.NewNotFound(v1alpha1.Resource("application"), "deletedApp"))
+
+		repository := applications.NewServiceRepository(listerMock)
+
+		// when
+		repository.Invalidate("deletedApp")
+
+		// then
+		listerMock.AssertExpectations(t)
+	})
+}
+
+func TestGetCandidates(t *testing.T) {
+	t.Run("should list all entries and mark the ones matching the entry name", func(t *testing.T) {
+		// given
+		listerMock := &mocks.Lister{}
+		listerMock.On("Get", "production").Return(createApplication("production", false), nil)
+
+		repository := applications.NewServiceRepository(listerMock)
+
+		// when
+		candidates, err := repository.CandidatesByEntryName("production", "service-3", "service-entry-duplicate")
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, candidates, 7)
+		assert.Equal(t, applications.Candidate{
+			ServiceDisplayName:  "Service 3",
+			ServiceName:         "service-3",
+			EntryName:           "Service entry duplicate",
+			NormalizedEntryName: "service-entry-duplicate",
+			EntryType:           "API",
+			Matches:             true,
+		}, candidates[2])
+		assert.True(t, candidates[3].Matches)
+		assert.False(t, candidates[4].Matches)
+	})
+
+	t.Run("should list all entries and mark the ones matching the service name", func(t *testing.T) {
+		// given
+		listerMock := &mocks.Lister{}
+		listerMock.On("Get", "production").Return(createApplication("production", false), nil)
+
+		repository := applications.NewServiceRepository(listerMock)
+
+		// when
+		candidates, err := repository.CandidatesByServiceName("production", "products-api")
+
+		// then
+		require.NoError(t, err)
+		var matching []applications.Candidate
+		for _, candidate := range candidates {
+			if candidate.Matches {
+				matching = append(matching, candidate)
+			}
 		}
+		require.Len(t, matching, 1)
+		assert.Equal(t, "id2", matching[0].ServiceID)
 	})
 
+	t.Run("should return not found error if Application doesn't exist", func(t *testing.T) {
+		// given
+		listerMock := &mocks.Lister{}
+		listerMock.On("Get", "deletedApp").
+			Return(nil, k8serrors.NewNotFound(v1alpha1.Resource("application"), "deletedApp"))
+
+		repository := applications.NewServiceRepository(listerMock)
+
+		// when
+		_, err := repository.CandidatesByServiceName("deletedApp", "service-1")
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, apperrors.CodeNotFound, err.Code())
+	})
 }
 
 func createApplication(name string, skipVerify bool) *v1alpha1.Application {
@@ -313,3 +541,39 @@ func createApplication(name string, skipVerify bool) *v1alpha1.Application {
 		Spec:       spec1,
 	}
 }
+
+func createApplicationWithPolicies(name string) *v1alpha1.Application {
+	application := createApplication(name, false)
+	application.Spec.RetryPolicy = &v1alpha1.RetryPolicy{
+		MaxAttempts:          3,
+		InitialBackoff:       &metav1.Duration{Duration: 200 * time.Millisecond},
+		RetryableStatusCodes: []int{503},
+	}
+	application.Spec.RateLimit = &v1alpha1.RateLimit{Requests: 100, Period: &metav1.Duration{Duration: time.Minute}}
+	application.Spec.UpstreamProxy = &v1alpha1.UpstreamProxy{URL: "http://proxy.example.com:3128", CredentialsSecretName: "proxy-secret"}
+	application.Spec.TLS = &v1alpha1.TLS{CABundle: &v1alpha1.CABundle{SecretName: "application-ca"}, MinVersion: "1.2"}
+	application.Spec.AccessControl = &v1alpha1.AccessControl{
+		ServiceAccounts: []v1alpha1.ServiceAccountReference{{Namespace: "orders", Name: "orders-client"}},
+		Namespaces:      []string{"billing"},
+	}
+	for i := range application.Spec.Services {
+		application.Spec.Services[i].RateLimit = &v1alpha1.RateLimit{Requests: 10, Burst: 5}
+		for j := range application.Spec.Services[i].Entries {
+			application.Spec.Services[i].Entries[j].Transformations = &v1alpha1.Transformations{
+				Request: &v1alpha1.RequestTransformations{
+					StripHeaders: []string{"Cookie"},
+					PathRewrites: []v1alpha1.PathRewrite{{Regex: "^/v1/", Replacement: "/v2/"}},
+					Headers:      &v1alpha1.HeaderTransformations{Add: map[string]string{"X-Source": "kyma"}},
+				},
+				Response: &v1alpha1.HeaderTransformations{Remove: []string{"Server"}},
+			}
+			application.Spec.Services[i].Entries[j].ProxyMode = "PassThrough"
+			application.Spec.Services[i].Entries[j].ResponseCache = &v1alpha1.ResponseCache{MaxEntrySize: 4096}
+			application.Spec.Services[i].Entries[j].Targets = []v1alpha1.Target{{URL: "https://192.168.1.2"}, {URL: "https://192.168.1.3", Priority: 1}}
+			application.Spec.Services[i].Entries[j].LoadBalancing = &v1alpha1.LoadBalancing{Strategy: "Failover", UnhealthyDuration: &metav1.Duration{Duration: time.Minute}}
+			application.Spec.Services[i].Entries[j].TLS = &v1alpha1.TLS{CABundle: &v1alpha1.CABundle{ConfigMapName: "entry-ca"}, MinVersion: "1.3", ServerName: "orders.internal"}
+		}
+	}
+
+	return application
+}
@@ -0,0 +1,50 @@
+// Package configmaps contains components for reading the ConfigMaps referenced by Applications, e.g. CA bundles
+package configmaps
+
+import (
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+)
+
+// Repository contains operations for reading ConfigMaps
+//
+//go:generate mockery --name=Repository
+type Repository interface {
+	Get(name string) (map[string]string, apperrors.AppError)
+}
+
+type repository struct {
+	configMapsLister Lister
+}
+
+// Lister reads k8s ConfigMaps from the cache kept up to date by the informer
+//
+//go:generate mockery --name=Lister
+type Lister interface {
+	Get(name string) (*v1.ConfigMap, error)
+}
+
+// NewRepository creates a new ConfigMaps repository
+func NewRepository(configMapsLister Lister) Repository {
+	return &repository{
+		configMapsLister: configMapsLister,
+	}
+}
+
+func (r *repository) Get(name string) (map[string]string, apperrors.AppError) {
+	configMap, err := r.configMapsLister.Get(name)
+	if err != nil {
+		zap.L().Error("failed to read ConfigMap",
+			zap.String("configMapName", name),
+			zap.Error(err))
+		if k8serrors.IsNotFound(err) {
+			return nil, apperrors.NotFoundf("ConfigMap '%s' not found", name)
+		}
+		return nil, apperrors.Internalf("failed to get '%s' ConfigMap, %s", name, err)
+	}
+
+	return configMap.Data, nil
+}
@@ -0,0 +1,69 @@
+package configmaps
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/configmaps/mocks"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+)
+
+func TestRepository_Get(t *testing.T) {
+	t.Run("should get given ConfigMap", func(t *testing.T) {
+		// given
+		configMapsListerMock := &mocks.Lister{}
+		repository := NewRepository(configMapsListerMock)
+
+		configMapsListerMock.On("Get", "ca-bundle").Return(&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "ca-bundle"},
+			Data:       map[string]string{"ca.crt": "certificates"},
+		}, nil)
+
+		// when
+		data, err := repository.Get("ca-bundle")
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, "certificates", data["ca.crt"])
+
+		configMapsListerMock.AssertExpectations(t)
+	})
+
+	t.Run("should return an error in case fetching fails", func(t *testing.T) {
+		// given
+		configMapsListerMock := &mocks.Lister{}
+		repository := NewRepository(configMapsListerMock)
+
+		configMapsListerMock.On("Get", "ca-bundle").Return(nil, errors.New("some error"))
+
+		// when
+		data, err := repository.Get("ca-bundle")
+
+		// then
+		assert.Error(t, err)
+		assert.Equal(t, apperrors.CodeInternal, err.Code())
+		assert.Nil(t, data)
+	})
+
+	t.Run("should return not found if ConfigMap does not exist", func(t *testing.T) {
+		// given
+		configMapsListerMock := &mocks.Lister{}
+		repository := NewRepository(configMapsListerMock)
+
+		configMapsListerMock.On("Get", "ca-bundle").Return(nil, k8serrors.NewNotFound(schema.GroupResource{}, "ca-bundle"))
+
+		// when
+		data, err := repository.Get("ca-bundle")
+
+		// then
+		assert.Error(t, err)
+		assert.Equal(t, apperrors.CodeNotFound, err.Code())
+		assert.Nil(t, data)
+	})
+}
@@ -61,3 +61,28 @@ func (_m *ServiceDefinitionService) GetAPIByServiceName(appName string, serviceN
 
 	return r0, r1
 }
+
+// GetAccessControl provides a mock function with given fields: appName
+func (_m *ServiceDefinitionService) GetAccessControl(appName string) (*model.AccessControl, apperrors.AppError) {
+	ret := _m.Called(appName)
+
+	var r0 *model.AccessControl
+	if rf, ok := ret.Get(0).(func(string) *model.AccessControl); ok {
+		r0 = rf(appName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AccessControl)
+		}
+	}
+
+	var r1 apperrors.AppError
+	if rf, ok := ret.Get(1).(func(string) apperrors.AppError); ok {
+		r1 = rf(appName)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(apperrors.AppError)
+		}
+	}
+
+	return r0, r1
+}
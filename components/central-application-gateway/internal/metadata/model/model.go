@@ -1,6 +1,13 @@
 package model
 
-import "github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
+import (
+	"regexp"
+	"time"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httptools"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/proxyconfig"
+)
 
 // ServiceDefinition is an internal representation of a service.
 type ServiceDefinition struct {
@@ -26,6 +33,8 @@ type API struct {
 	TargetUrl string
 	// Credentials is a credentials of API.
 	Credentials *authorization.Credentials
+	// SourcedSecretNames are the names of the credentials and request parameters read from the sources which the informers don't watch, e.g. broker:orders
+	SourcedSecretNames []string
 	// Spec contains specification of an API.
 	Spec []byte
 	// RequestParameters will be used with request send by the Application Gateway
@@ -34,6 +43,154 @@ type API struct {
 	SkipVerify bool
 	// encodeUrl is flag set on Application CRD
 	EncodeUrl bool
+	// RetryPolicy is set on Application CRD, nil disables retries of idempotent calls
+	RetryPolicy *RetryPolicy
+	// RequestBodyBuffering is set on Application CRD, nil keeps request bodies in memory
+	RequestBodyBuffering *RequestBodyBuffering
+	// ApplicationRateLimit is set on Application CRD and limits calls to all APIs of the Application
+	ApplicationRateLimit *RateLimit
+	// ServiceRateLimit is set on the service in Application CRD and limits calls to all APIs of the service
+	ServiceRateLimit *RateLimit
+	// Transformations are set on the entry in Application CRD, nil proxies calls and responses unchanged
+	Transformations *Transformations
+	// ProxyMode is set on the entry in Application CRD, one of ProxyModeBuffered, ProxyModePassThrough, ProxyModeHTTP2
+	ProxyMode string
+	// ResponseCache is set on the entry in Application CRD, nil disables caching of responses
+	ResponseCache *ResponseCache
+	// Targets are set on the entry in Application CRD, empty means the single target TargetUrl
+	Targets []Target
+	// LoadBalancing is set on the entry in Application CRD, nil distributes calls among Targets in turn
+	LoadBalancing *LoadBalancing
+	// Connection is set from the upstream proxy and the TLS settings in Application CRD, nil uses the proxy from the environment
+	Connection *httptools.ConnectionSettings
+	// CSRFConfig is set in the destination secret, nil uses the CSRF token endpoint of the credentials
+	CSRFConfig *proxyconfig.CSRFConfig
+}
+
+// AccessControl lists the identities of the callers allowed to call the APIs of the Application, a caller matching any of them is allowed
+type AccessControl struct {
+	// ServiceAccounts are in the namespace/name form
+	ServiceAccounts []string
+	SPIFFEIDs       []string
+	Namespaces      []string
+}
+
+// Target is an instance of the target system serving the API
+type Target struct {
+	URL string
+	// Priority orders the targets in the LoadBalancingFailover strategy, lower values first
+	Priority int
+}
+
+// LoadBalancing defines how calls are distributed among the targets of an API
+type LoadBalancing struct {
+	// Strategy is one of LoadBalancingRoundRobin, LoadBalancingFailover
+	Strategy string
+	// UnhealthyThreshold is the number of consecutive failed calls after which a target is skipped, 0 means the default
+	UnhealthyThreshold int
+	// UnhealthyDuration is the time for which an unhealthy target is skipped, 0 means the default
+	UnhealthyDuration time.Duration
+}
+
+const (
+	// LoadBalancingRoundRobin sends calls to all healthy targets in turn
+	LoadBalancingRoundRobin = "RoundRobin"
+	// LoadBalancingFailover sends calls to the healthy targets with the lowest priority in turn
+	LoadBalancingFailover = "Failover"
+)
+
+// AllTargets returns the targets of the API, or TargetUrl if it has no other targets
+func (api API) AllTargets() []Target {
+	if len(api.Targets) == 0 {
+		return []Target{{URL: api.TargetUrl}}
+	}
+
+	return api.Targets
+}
+
+// ResponseCache defines how responses to GET calls to an API are cached
+type ResponseCache struct {
+	// MaxEntrySize is the maximum size of a cached response body, 0 means the default of the gateway
+	MaxEntrySize int64
+}
+
+const (
+	// ProxyModeBuffered buffers request bodies, so that calls can be retried
+	ProxyModeBuffered = "Buffered"
+	// ProxyModePassThrough streams calls and responses without retries and supports protocol upgrades, e.g. WebSocket
+	ProxyModePassThrough = "PassThrough"
+	// ProxyModeHTTP2 streams calls and responses like ProxyModePassThrough over HTTP/2, or h2c for plain HTTP targets, e.g. for gRPC
+	ProxyModeHTTP2 = "HTTP2"
+)
+
+// IsPassThrough reports whether calls to the API are streamed without buffering and retries
+func (api API) IsPassThrough() bool {
+	return api.ProxyMode == ProxyModePassThrough || api.ProxyMode == ProxyModeHTTP2
+}
+
+// Transformations modify calls to an API and its responses
+type Transformations struct {
+	// StripRequestHeaders are removed from the call before the credentials of the API are added
+	StripRequestHeaders []string
+	// PathRewrites are applied in order to the path following the API name
+	PathRewrites []PathRewrite
+	// RequestHeaders modify the headers of the call after the request parameters are added
+	RequestHeaders *HeaderTransformations
+	// ResponseHeaders modify the headers of the response returned to the caller
+	ResponseHeaders *HeaderTransformations
+}
+
+// PathRewrite replaces the matches of Regex with Replacement, which can refer to capture groups
+type PathRewrite struct {
+	Regex       *regexp.Regexp
+	Replacement string
+}
+
+// HeaderTransformations are applied in the order: remove, rename, add. Header names are canonical.
+type HeaderTransformations struct {
+	Remove []string
+	// Rename maps the old header names to the new ones
+	Rename map[string]string
+	// Add appends the values to the headers
+	Add map[string]string
+}
+
+// RateLimit defines the token bucket which limits the rate of calls
+type RateLimit struct {
+	// Requests is the number of calls allowed per Period
+	Requests int
+	Period   time.Duration
+	// Burst is the number of calls allowed at once
+	Burst int
+}
+
+// RetryPolicy defines how idempotent calls to an API are retried
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of calls, including the first one
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry, doubled with every next retry
+	InitialBackoff time.Duration
+	// MaxBackoff limits the delay between retries
+	MaxBackoff time.Duration
+	// RetryableStatusCodes lists response status codes which cause a retry
+	RetryableStatusCodes []int
+}
+
+const (
+	// BodyBufferingMemory keeps the whole request body in memory
+	BodyBufferingMemory = "Memory"
+	// BodyBufferingFile keeps the request body in memory up to the limit and writes the rest to a temporary file
+	BodyBufferingFile = "File"
+	// BodyBufferingStream keeps the request body in memory up to the limit and streams larger bodies without retries
+	BodyBufferingStream = "Stream"
+)
+
+// RequestBodyBuffering defines how request bodies are kept to be sent again on retries
+type RequestBodyBuffering struct {
+	// Mode is one of BodyBufferingMemory, BodyBufferingFile, BodyBufferingStream
+	Mode string
+	// MemoryLimit is the number of bytes kept in memory in the File and Stream modes
+	MemoryLimit int64
 }
 
 // Events contains specification for events.
@@ -0,0 +1,123 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"go.uber.org/zap"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+)
+
+// maxCredentialsSize is the maximum size of the response of the broker with the credentials
+const maxCredentialsSize = 1 << 20
+
+type brokerRepository struct {
+	brokerURL      string
+	httpClient     *http.Client
+	timeout        time.Duration
+	cache          *cache.Cache
+	cacheRetention time.Duration
+}
+
+// NewBrokerRepository creates a repository fetching credentials from an HTTP credential broker.
+// Credentials with the given name are fetched with a GET request to brokerURL/credentials/name which returns a JSON object with string values.
+func NewBrokerRepository(brokerURL string, timeout time.Duration) Repository {
+	cacheRetention := secretCacheRetention()
+	return &brokerRepository{
+		brokerURL:      strings.TrimSuffix(brokerURL, "/"),
+		httpClient:     &http.Client{},
+		timeout:        timeout,
+		cache:          cache.New(cacheRetention, 3*time.Minute),
+		cacheRetention: cacheRetention,
+	}
+}
+
+func (r *brokerRepository) Get(name string) (map[string][]byte, apperrors.AppError) {
+	cacheKey := brokerCacheKey(name)
+	if cachedItem, found := r.cache.Get(cacheKey); found {
+		return cachedItem.(map[string][]byte), nil
+	}
+
+	credentials, err := r.fetch(name)
+	if err != nil {
+		zap.L().Error("failed to fetch credentials from broker",
+			zap.String("credentialsName", name),
+			zap.Error(err))
+		return nil, err
+	}
+
+	r.cache.Set(cacheKey, credentials, r.cacheRetention)
+
+	return credentials, nil
+}
+
+// Invalidate drops the cached credentials, for example when the target system rejects them after they were rotated in the broker
+func (r *brokerRepository) Invalidate(name string) {
+	r.cache.Delete(brokerCacheKey(name))
+}
+
+func brokerCacheKey(name string) string {
+	return fmt.Sprintf("broker-%s", name)
+}
+
+func (r *brokerRepository) fetch(name string) (map[string][]byte, apperrors.AppError) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.brokerURL+"/credentials/"+url.PathEscape(name), nil)
+	if err != nil {
+		return nil, apperrors.Internalf("failed to create credentials request: %s", err.Error())
+	}
+
+	response, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, apperrors.Internalf("failed to fetch '%s' credentials: %s", name, err.Error())
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, apperrors.NotFoundf("credentials '%s' not found", name)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, apperrors.Internalf("incorrect response code '%d' while fetching '%s' credentials", response.StatusCode, name)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxCredentialsSize+1))
+	if err != nil {
+		return nil, apperrors.Internalf("failed to read '%s' credentials: %s", name, err.Error())
+	}
+	if len(body) > maxCredentialsSize {
+		return nil, apperrors.Internalf("'%s' credentials exceed %d bytes", name, maxCredentialsSize)
+	}
+
+	values := map[string]string{}
+	if err := json.Unmarshal(body, &values); err != nil {
+		return nil, apperrors.Internalf("failed to unmarshal '%s' credentials: %s", name, err.Error())
+	}
+
+	credentials := make(map[string][]byte, len(values))
+	for key, value := range values {
+		credentials[key] = []byte(value)
+	}
+
+	return credentials, nil
+}
+
+// secretCacheRetention returns the time the broker credentials are cached for, the broker can't notify about changes
+func secretCacheRetention() time.Duration {
+	cacheRetention, err := time.ParseDuration(os.Getenv("ACM_GATEWAY_SECRETCACHE_RETENTION"))
+	if err != nil || cacheRetention <= 0 {
+		cacheRetention = 5 * time.Minute
+	}
+	return cacheRetention
+}
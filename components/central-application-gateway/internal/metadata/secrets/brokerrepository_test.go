@@ -0,0 +1,129 @@
+package secrets
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrokerRepository_Get(t *testing.T) {
+	t.Run("should fetch credentials from broker and cache them", func(t *testing.T) {
+		// given
+		requestCount := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestCount++
+			assert.Equal(t, http.MethodGet, r.Method)
+			assert.Equal(t, "/credentials/my-credentials", r.URL.Path)
+
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"clientId":"CLIENT_ID","clientSecret":"CLIENT_SECRET"}`))
+		}))
+		defer ts.Close()
+
+		repository := NewBrokerRepository(ts.URL+"/", time.Second)
+
+		// when
+		credentials, err := repository.Get("my-credentials")
+		cachedCredentials, cachedErr := repository.Get("my-credentials")
+
+		// then
+		require.NoError(t, err)
+		require.NoError(t, cachedErr)
+		assert.Equal(t, map[string][]byte{
+			"clientId":     []byte("CLIENT_ID"),
+			"clientSecret": []byte("CLIENT_SECRET"),
+		}, credentials)
+		assert.Equal(t, credentials, cachedCredentials)
+		assert.Equal(t, 1, requestCount)
+	})
+
+	t.Run("should return not found if broker does not know credentials", func(t *testing.T) {
+		// given
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer ts.Close()
+
+		repository := NewBrokerRepository(ts.URL, time.Second)
+
+		// when
+		credentials, err := repository.Get("my-credentials")
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, apperrors.CodeNotFound, err.Code())
+		assert.Nil(t, credentials)
+	})
+
+	t.Run("should return an error if broker responds with invalid payload", func(t *testing.T) {
+		// given
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`not json`))
+		}))
+		defer ts.Close()
+
+		repository := NewBrokerRepository(ts.URL, time.Second)
+
+		// when
+		credentials, err := repository.Get("my-credentials")
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, apperrors.CodeInternal, err.Code())
+		assert.Nil(t, credentials)
+	})
+
+	t.Run("should return an error if broker responds with too large payload", func(t *testing.T) {
+		// given
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"clientId":"` + strings.Repeat("a", maxCredentialsSize) + `"}`))
+		}))
+		defer ts.Close()
+
+		repository := NewBrokerRepository(ts.URL, time.Second)
+
+		// when
+		credentials, err := repository.Get("my-credentials")
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, apperrors.CodeInternal, err.Code())
+		assert.Contains(t, err.Error(), "exceed")
+		assert.Nil(t, credentials)
+	})
+}
+
+func TestBrokerRepository_Invalidate(t *testing.T) {
+	t.Run("should fetch credentials from broker again after they are invalidated", func(t *testing.T) {
+		// given
+		clientSecrets := []string{"OLD_SECRET", "NEW_SECRET"}
+		requestCount := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"clientSecret":"` + clientSecrets[requestCount] + `"}`))
+			requestCount++
+		}))
+		defer ts.Close()
+
+		repository := NewBrokerRepository(ts.URL, time.Second)
+		_, err := repository.Get("my-credentials")
+		require.NoError(t, err)
+
+		// when
+		repository.(*brokerRepository).Invalidate("my-credentials")
+		credentials, err := repository.Get("my-credentials")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]byte{"clientSecret": []byte("NEW_SECRET")}, credentials)
+		assert.Equal(t, 2, requestCount)
+	})
+}
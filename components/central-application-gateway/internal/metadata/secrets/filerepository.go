@@ -0,0 +1,68 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+)
+
+type fileRepository struct {
+	dir string
+}
+
+// NewFileRepository creates a repository reading credentials from a directory, for example mounted by the Secrets Store CSI Driver.
+// Credentials with the given name are read from the dir/name directory where every file holds a single key.
+func NewFileRepository(dir string) Repository {
+	return &fileRepository{
+		dir: dir,
+	}
+}
+
+func (r *fileRepository) Get(name string) (map[string][]byte, apperrors.AppError) {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return nil, apperrors.WrongInputf("invalid credentials name '%s'", name)
+	}
+
+	credentialsDir := filepath.Join(r.dir, name)
+	entries, err := os.ReadDir(credentialsDir)
+	if err != nil {
+		zap.L().Error("failed to read credentials directory",
+			zap.String("credentialsName", name),
+			zap.Error(err))
+		if os.IsNotExist(err) {
+			return nil, apperrors.NotFoundf("credentials '%s' not found", name)
+		}
+		return nil, apperrors.Internalf("failed to read '%s' credentials, %s", name, err)
+	}
+
+	credentials := make(map[string][]byte, len(entries))
+	for _, entry := range entries {
+		// skip the hidden files and directories created by atomic writers of Kubernetes volumes
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		// os.Stat follows symlinks which point to the current version of the mounted files
+		path := filepath.Join(credentialsDir, entry.Name())
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		value, err := os.ReadFile(path)
+		if err != nil {
+			return nil, apperrors.Internalf("failed to read '%s' key of '%s' credentials, %s", entry.Name(), name, err)
+		}
+		credentials[entry.Name()] = value
+	}
+
+	if len(credentials) == 0 {
+		return nil, apperrors.NotFoundf("credentials '%s' are empty", name)
+	}
+
+	return credentials, nil
+}
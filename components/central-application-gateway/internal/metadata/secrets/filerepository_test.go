@@ -0,0 +1,62 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileRepository_Get(t *testing.T) {
+	t.Run("should read credentials from files", func(t *testing.T) {
+		// given
+		dir := t.TempDir()
+		credentialsDir := filepath.Join(dir, "my-credentials")
+		require.NoError(t, os.MkdirAll(filepath.Join(credentialsDir, "..data"), 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(credentialsDir, "clientId"), []byte("CLIENT_ID"), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(credentialsDir, "clientSecret"), []byte("CLIENT_SECRET"), 0600))
+
+		repository := NewFileRepository(dir)
+
+		// when
+		credentials, err := repository.Get("my-credentials")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]byte{
+			"clientId":     []byte("CLIENT_ID"),
+			"clientSecret": []byte("CLIENT_SECRET"),
+		}, credentials)
+	})
+
+	t.Run("should return not found if credentials directory does not exist", func(t *testing.T) {
+		// given
+		repository := NewFileRepository(t.TempDir())
+
+		// when
+		credentials, err := repository.Get("my-credentials")
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, apperrors.CodeNotFound, err.Code())
+		assert.Nil(t, credentials)
+	})
+
+	t.Run("should reject names pointing outside of credentials directory", func(t *testing.T) {
+		// given
+		repository := NewFileRepository(t.TempDir())
+
+		for _, name := range []string{"", "..", "../etc", "a/b"} {
+			// when
+			credentials, err := repository.Get(name)
+
+			// then
+			require.Error(t, err)
+			assert.Equal(t, apperrors.CodeWrongInput, err.Code())
+			assert.Nil(t, credentials)
+		}
+	})
+}
@@ -2,18 +2,12 @@
 package secrets
 
 import (
-	"context"
-	"fmt"
-	"os"
-	"time"
-
 	"go.uber.org/zap"
 
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
-	"github.com/patrickmn/go-cache"
 	v1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 // Repository contains operations for managing client credentials
@@ -24,46 +18,36 @@ type Repository interface {
 }
 
 type repository struct {
-	secretsManager Manager
-	cache          *cache.Cache
-	cacheRetention time.Duration
+	secretsLister Lister
+	selector      labels.Selector
 }
 
-// Manager contains operations for managing k8s secrets
+// Lister reads k8s secrets from the cache kept up to date by the informer
 //
-//go:generate mockery --name=Manager
-type Manager interface {
-	Get(ctx context.Context, name string, options metav1.GetOptions) (*v1.Secret, error)
+//go:generate mockery --name=Lister
+type Lister interface {
+	Get(name string) (*v1.Secret, error)
 }
 
 // NewRepository creates a new secrets repository
-func NewRepository(secretsManager Manager) Repository {
-	cacheRetention, err := time.ParseDuration(os.Getenv("ACM_GATEWAY_SECRETCACHE_RETENTION"))
-	if err != nil || cacheRetention <= 0 {
-		cacheRetention = 5 * time.Minute
-	}
-	zap.L().Info("Configuring application cache to store application data for %.2fm", zap.Float64("cacheRetention", cacheRetention.Minutes()))
+func NewRepository(secretsLister Lister) Repository {
 	return &repository{
-		secretsManager: secretsManager,
-		cache:          cache.New(cacheRetention, 3*time.Minute),
-		cacheRetention: cacheRetention,
+		secretsLister: secretsLister,
+		selector:      labels.Everything(),
 	}
 }
 
-func (r *repository) Get(name string) (map[string][]byte, apperrors.AppError) {
-	cacheKey := fmt.Sprintf("secret-%s", name)
-	if cachedItem, found := r.cache.Get(cacheKey); found {
-		secret := cachedItem.(map[string][]byte)
-		if len(secret) == 0 {
-			zap.L().Warn("found empty secret '%s' in cache - this is not expected, deleting it from cache now",
-				zap.String("secretName", name))
-			r.cache.Delete(cacheKey)
-		} else {
-			return secret, nil
-		}
+// NewSelectorRepository creates a secrets repository reading only the secrets with labels matching the selector,
+// so that callers naming the secret can't read other secrets of the namespace. The secrets which don't match aren't found.
+func NewSelectorRepository(secretsLister Lister, selector labels.Selector) Repository {
+	return &repository{
+		secretsLister: secretsLister,
+		selector:      selector,
 	}
+}
 
-	secret, err := r.secretsManager.Get(context.Background(), name, metav1.GetOptions{})
+func (r *repository) Get(name string) (map[string][]byte, apperrors.AppError) {
+	secret, err := r.secretsLister.Get(name)
 	if err != nil {
 		zap.L().Error("failed to read secret",
 			zap.String("secretName", name),
@@ -73,9 +57,11 @@ func (r *repository) Get(name string) (map[string][]byte, apperrors.AppError) {
 		}
 		return nil, apperrors.Internalf("failed to get '%s' secret, %s", name, err)
 	}
-
-	if err := r.cache.Add(cacheKey, secret.Data, r.cacheRetention); err != nil {
-		zap.L().Warn("Failed to update secret cache entity '%s': %v", zap.String("secretName", cacheKey), zap.Error(err))
+	if !r.selector.Matches(labels.Set(secret.Labels)) {
+		zap.L().Warn("secret doesn't match the selector",
+			zap.String("secretName", name),
+			zap.String("selector", r.selector.String()))
+		return nil, apperrors.NotFoundf("secret '%s' not found", name)
 	}
 
 	return secret.Data, nil
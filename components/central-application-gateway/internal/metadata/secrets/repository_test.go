@@ -1,7 +1,6 @@
 package secrets
 
 import (
-	"context"
 	"errors"
 	"testing"
 
@@ -9,21 +8,21 @@ import (
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/secrets/mocks"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func TestRepository_Get(t *testing.T) {
 	t.Run("should get given secret", func(t *testing.T) {
 		// given
-		secretsManagerMock := &mocks.Manager{}
-		repository := NewRepository(secretsManagerMock)
+		secretsListerMock := &mocks.Lister{}
+		repository := NewRepository(secretsListerMock)
 
 		secret := makeSecret("new-secret", "CLIENT_ID", "CLIENT_SECRET", "secretId", "default-ec")
-		secretsManagerMock.On("Get", context.Background(), "new-secret", metav1.GetOptions{}).Return(secret, nil)
+		secretsListerMock.On("Get", "new-secret").Return(secret, nil)
 
 		// when
 		secrets, err := repository.Get("new-secret")
@@ -33,15 +32,15 @@ func TestRepository_Get(t *testing.T) {
 		assert.NotNil(t, secrets["clientId"])
 		assert.NotNil(t, secrets["clientSecret"])
 
-		secretsManagerMock.AssertExpectations(t)
+		secretsListerMock.AssertExpectations(t)
 	})
 
 	t.Run("should return an error in case fetching fails", func(t *testing.T) {
 		// given
-		secretsManagerMock := &mocks.Manager{}
-		repository := NewRepository(secretsManagerMock)
+		secretsListerMock := &mocks.Lister{}
+		repository := NewRepository(secretsListerMock)
 
-		secretsManagerMock.On("Get", context.Background(), "secret-name", metav1.GetOptions{}).Return(
+		secretsListerMock.On("Get", "secret-name").Return(
 			nil,
 			errors.New("some error"))
 
@@ -54,15 +53,15 @@ func TestRepository_Get(t *testing.T) {
 		assert.NotEmpty(t, err.Error())
 		assert.Nil(t, cacheData)
 
-		secretsManagerMock.AssertExpectations(t)
+		secretsListerMock.AssertExpectations(t)
 	})
 
 	t.Run("should return not found if secret does not exist", func(t *testing.T) {
 		// given
-		secretsManagerMock := &mocks.Manager{}
-		repository := NewRepository(secretsManagerMock)
+		secretsListerMock := &mocks.Lister{}
+		repository := NewRepository(secretsListerMock)
 
-		secretsManagerMock.On("Get", context.Background(), "secret-name", metav1.GetOptions{}).Return(
+		secretsListerMock.On("Get", "secret-name").Return(
 			nil,
 			k8serrors.NewNotFound(schema.GroupResource{},
 				""))
@@ -76,27 +75,40 @@ func TestRepository_Get(t *testing.T) {
 		assert.NotEmpty(t, err.Error())
 
 		assert.Nil(t, secrets)
-		secretsManagerMock.AssertExpectations(t)
+		secretsListerMock.AssertExpectations(t)
 	})
 
-	t.Run("Verify caching of secret entities", func(t *testing.T) {
+	t.Run("should get secret matching the selector", func(t *testing.T) {
 		// given
+		secretsListerMock := &mocks.Lister{}
+		repository := NewSelectorRepository(secretsListerMock, labels.SelectorFromSet(labels.Set{k8sconsts.LabelApplication: "default-ec"}))
+
 		secret := makeSecret("new-secret", "CLIENT_ID", "CLIENT_SECRET", "secretId", "default-ec")
-		secretsManagerMock := &mocks.Manager{}
-		secretsManagerMock.On("Get", context.Background(), "new-secret", metav1.GetOptions{}).
-			Once(). //Once() is mandatory to make this test meaningful!
-			Return(secret, nil)
+		secretsListerMock.On("Get", "new-secret").Return(secret, nil)
+
+		// when
+		secrets, err := repository.Get("new-secret")
+
+		// then
+		assert.NoError(t, err)
+		assert.NotNil(t, secrets["clientId"])
+	})
 
-		repository := NewRepository(secretsManagerMock)
-		require.NotNil(t, repository)
+	t.Run("should return not found if secret does not match the selector", func(t *testing.T) {
+		// given
+		secretsListerMock := &mocks.Lister{}
+		repository := NewSelectorRepository(secretsListerMock, labels.SelectorFromSet(labels.Set{"destination": "true"}))
+
+		secret := makeSecret("new-secret", "CLIENT_ID", "CLIENT_SECRET", "secretId", "default-ec")
+		secretsListerMock.On("Get", "new-secret").Return(secret, nil)
 
 		// when
-		for i := 0; i < 100; i++ {
-			// only first secret will be retrieved from manager and afterwards from cache otherwise manager mock will fail
-			secret, err := repository.Get("new-secret")
-			assert.NoError(t, err)
-			assert.Equal(t, []byte("CLIENT_ID"), secret["clientId"])
-		}
+		secrets, err := repository.Get("new-secret")
+
+		// then
+		assert.Error(t, err)
+		assert.Equal(t, apperrors.CodeNotFound, err.Code())
+		assert.Nil(t, secrets)
 	})
 }
 
@@ -0,0 +1,72 @@
+package secrets
+
+import (
+	"strings"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+)
+
+const (
+	// SourceFile selects credentials read from files, for example `file:my-credentials`
+	SourceFile = "file"
+	// SourceBroker selects credentials fetched from the credential broker, for example `broker:my-credentials`
+	SourceBroker = "broker"
+
+	sourceSeparator = ":"
+)
+
+// InvalidatingRepository drops the cached credentials of the sources which the informers don't watch, so that the rotated credentials are read again
+type InvalidatingRepository interface {
+	Repository
+	// Invalidate drops the credentials with the given name if they were read from a source, the Secrets are kept up to date by the informers
+	Invalidate(name string)
+}
+
+// invalidator is implemented by the sources caching the credentials
+type invalidator interface {
+	Invalidate(name string)
+}
+
+type sourceRepository struct {
+	defaultRepository Repository
+	sources           map[string]Repository
+}
+
+// NewSourceRepository creates a repository which resolves credentials from the source selected by the prefix of the secret name.
+// Names without a prefix are resolved by the default repository. Kubernetes Secret names can't contain a colon, so the prefix is unambiguous.
+func NewSourceRepository(defaultRepository Repository, sources map[string]Repository) InvalidatingRepository {
+	return &sourceRepository{
+		defaultRepository: defaultRepository,
+		sources:           sources,
+	}
+}
+
+func (r *sourceRepository) Get(name string) (map[string][]byte, apperrors.AppError) {
+	source, credentialsName, found := strings.Cut(name, sourceSeparator)
+	if !found {
+		return r.defaultRepository.Get(name)
+	}
+
+	repository, found := r.sources[source]
+	if !found {
+		return nil, apperrors.WrongInputf("credentials source '%s' of '%s' is not configured", source, name)
+	}
+
+	return repository.Get(credentialsName)
+}
+
+func (r *sourceRepository) Invalidate(name string) {
+	source, credentialsName, found := strings.Cut(name, sourceSeparator)
+	if !found {
+		return
+	}
+
+	if repository, ok := r.sources[source].(invalidator); ok {
+		repository.Invalidate(credentialsName)
+	}
+}
+
+// HasSource returns true if the credentials with the given name are read from a source which the informers don't watch
+func HasSource(name string) bool {
+	return strings.Contains(name, sourceSeparator)
+}
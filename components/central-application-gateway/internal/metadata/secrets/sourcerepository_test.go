@@ -0,0 +1,96 @@
+package secrets
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/secrets/mocks"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceRepository_Get(t *testing.T) {
+	credentials := map[string][]byte{"clientId": []byte("CLIENT_ID")}
+
+	t.Run("should use default repository for names without source", func(t *testing.T) {
+		// given
+		defaultRepository := &mocks.Repository{}
+		defaultRepository.On("Get", "my-secret").Return(credentials, nil)
+		fileRepository := &mocks.Repository{}
+
+		repository := NewSourceRepository(defaultRepository, map[string]Repository{SourceFile: fileRepository})
+
+		// when
+		result, err := repository.Get("my-secret")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, credentials, result)
+		defaultRepository.AssertExpectations(t)
+		fileRepository.AssertNotCalled(t, "Get")
+	})
+
+	t.Run("should use repository selected by source", func(t *testing.T) {
+		// given
+		defaultRepository := &mocks.Repository{}
+		fileRepository := &mocks.Repository{}
+		fileRepository.On("Get", "my-credentials").Return(credentials, nil)
+
+		repository := NewSourceRepository(defaultRepository, map[string]Repository{SourceFile: fileRepository})
+
+		// when
+		result, err := repository.Get("file:my-credentials")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, credentials, result)
+		fileRepository.AssertExpectations(t)
+		defaultRepository.AssertNotCalled(t, "Get")
+	})
+
+	t.Run("should return an error if source is not configured", func(t *testing.T) {
+		// given
+		repository := NewSourceRepository(&mocks.Repository{}, map[string]Repository{})
+
+		// when
+		result, err := repository.Get("broker:my-credentials")
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, apperrors.CodeWrongInput, err.Code())
+		assert.Nil(t, result)
+	})
+}
+
+func TestSourceRepository_Invalidate(t *testing.T) {
+	t.Run("should drop the credentials cached by the source", func(t *testing.T) {
+		// given
+		requestCount := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestCount++
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"clientId":"CLIENT_ID"}`))
+		}))
+		defer ts.Close()
+
+		repository := NewSourceRepository(&mocks.Repository{}, map[string]Repository{
+			SourceBroker: NewBrokerRepository(ts.URL, time.Second),
+			SourceFile:   NewFileRepository(t.TempDir()),
+		})
+		_, err := repository.Get("broker:my-credentials")
+		require.NoError(t, err)
+
+		// when
+		repository.Invalidate("broker:my-credentials")
+		repository.Invalidate("file:my-credentials")
+		repository.Invalidate("my-secret")
+		_, err = repository.Get("broker:my-credentials")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 2, requestCount)
+	})
+}
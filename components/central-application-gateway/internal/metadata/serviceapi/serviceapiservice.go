@@ -2,28 +2,66 @@ package serviceapi
 
 import (
 	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/net/http/httpguts"
 
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/applications"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/configmaps"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/secrets"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httptools"
 )
 
 const (
-	ClientIDKey        = "clientId"
-	ClientSecretKey    = "clientSecret"
-	UsernameKey        = "username"
-	PasswordKey        = "password"
-	TypeOAuth          = "OAuth"
-	TypeOAuthWithCert  = "OAuthWithCert"
-	TypeBasic          = "Basic"
-	TypeCertificateGen = "CertificateGen"
-	PrivateKeyKey      = "key"
-	CertificateKey     = "crt"
+	ClientIDKey            = "clientId"
+	ClientSecretKey        = "clientSecret"
+	UsernameKey            = "username"
+	PasswordKey            = "password"
+	TypeOAuth              = "OAuth"
+	TypeOAuthWithCert      = "OAuthWithCert"
+	TypeOAuthJWTAssertion  = "OAuthJWTAssertion"
+	TypeOAuthTokenExchange = "OAuthTokenExchange"
+	TypeBasic              = "Basic"
+	TypeCertificateGen     = "CertificateGen"
+	TypeAPIKey             = "APIKey"
+	TypeDigest             = "Digest"
+	TypeNTLM               = "NTLM"
+	TypeAWSSigV4           = "AWSSigV4"
+	PrivateKeyKey          = "key"
+	CertificateKey         = "crt"
+	KeyIDKey               = "keyId"
+	DomainKey              = "domain"
+
+	GrantTypeKey          = "grantType"
+	SubjectTokenTypeKey   = "subjectTokenType"
+	RequestedTokenTypeKey = "requestedTokenType"
+
+	APIKeyKey          = "apiKey"
+	APIKeyNameKey      = "name"
+	APIKeyPlacementKey = "placement"
+	APIKeyPrefixKey    = "prefix"
+
+	DefaultAPIKeyHeader = "X-API-Key"
+
+	AccessKeyIDKey     = "accessKeyId"
+	SecretAccessKeyKey = "secretAccessKey"
+	SessionTokenKey    = "sessionToken"
+	RegionKey          = "region"
+	ServiceKey         = "service"
+
+	ScopeKey    = "scope"
+	AudienceKey = "audience"
+	ResourceKey = "resource"
 
 	HeadersKey         = "headers"
 	QueryParametersKey = "queryParameters"
+
+	DefaultCABundleKey = "ca.crt"
 )
 
 // Service manages API definition of a service
@@ -33,13 +71,15 @@ type Service interface {
 }
 
 type defaultService struct {
-	secretsRepository secrets.Repository
+	secretsRepository    secrets.Repository
+	configMapsRepository configmaps.Repository
 }
 
-func NewService(secretsRepository secrets.Repository) Service {
+func NewService(secretsRepository secrets.Repository, configMapsRepository configmaps.Repository) Service {
 
 	return defaultService{
-		secretsRepository: secretsRepository,
+		secretsRepository:    secretsRepository,
+		configMapsRepository: configMapsRepository,
 	}
 }
 
@@ -50,6 +90,72 @@ func (sas defaultService) Read(applicationAPI *applications.ServiceAPI) (*model.
 		EncodeUrl:  applicationAPI.EncodeURL,
 	}
 
+	if applicationAPI.RetryPolicy != nil {
+		api.RetryPolicy = &model.RetryPolicy{
+			MaxAttempts:          applicationAPI.RetryPolicy.MaxAttempts,
+			InitialBackoff:       applicationAPI.RetryPolicy.InitialBackoff,
+			MaxBackoff:           applicationAPI.RetryPolicy.MaxBackoff,
+			RetryableStatusCodes: applicationAPI.RetryPolicy.RetryableStatusCodes,
+		}
+	}
+
+	if applicationAPI.RequestBodyBuffering != nil {
+		api.RequestBodyBuffering = &model.RequestBodyBuffering{
+			Mode:        applicationAPI.RequestBodyBuffering.Mode,
+			MemoryLimit: applicationAPI.RequestBodyBuffering.MemoryLimit,
+		}
+	}
+
+	api.ApplicationRateLimit = convertRateLimit(applicationAPI.ApplicationRateLimit)
+	api.ServiceRateLimit = convertRateLimit(applicationAPI.ServiceRateLimit)
+
+	transformations, err := convertTransformations(applicationAPI.Transformations)
+	if err != nil {
+		return nil, err
+	}
+	api.Transformations = transformations
+
+	switch applicationAPI.ProxyMode {
+	case "", model.ProxyModeBuffered, model.ProxyModePassThrough, model.ProxyModeHTTP2:
+		api.ProxyMode = applicationAPI.ProxyMode
+	default:
+		return nil, apperrors.Internalf("invalid proxy mode '%s'", applicationAPI.ProxyMode)
+	}
+
+	if applicationAPI.ResponseCache != nil {
+		api.ResponseCache = &model.ResponseCache{
+			MaxEntrySize: applicationAPI.ResponseCache.MaxEntrySize,
+		}
+	}
+
+	for _, target := range applicationAPI.Targets {
+		api.Targets = append(api.Targets, model.Target{
+			URL:      target.URL,
+			Priority: target.Priority,
+		})
+	}
+
+	if applicationAPI.LoadBalancing != nil {
+		switch applicationAPI.LoadBalancing.Strategy {
+		case "", model.LoadBalancingRoundRobin, model.LoadBalancingFailover:
+		default:
+			return nil, apperrors.Internalf("invalid load balancing strategy '%s'", applicationAPI.LoadBalancing.Strategy)
+		}
+		api.LoadBalancing = &model.LoadBalancing{
+			Strategy:           applicationAPI.LoadBalancing.Strategy,
+			UnhealthyThreshold: applicationAPI.LoadBalancing.UnhealthyThreshold,
+			UnhealthyDuration:  applicationAPI.LoadBalancing.UnhealthyDuration,
+		}
+	}
+
+	if applicationAPI.UpstreamProxy != nil || applicationAPI.TLS != nil {
+		connection, err := sas.readConnectionSettings(applicationAPI.UpstreamProxy, applicationAPI.TLS)
+		if err != nil {
+			return nil, err
+		}
+		api.Connection = connection
+	}
+
 	if applicationAPI.Credentials != nil {
 		credentialsSecretName := applicationAPI.Credentials.SecretName
 
@@ -57,11 +163,20 @@ func (sas defaultService) Read(applicationAPI *applications.ServiceAPI) (*model.
 		if err != nil {
 			return nil, err
 		}
+		if secrets.HasSource(credentialsSecretName) {
+			api.SourcedSecretNames = append(api.SourcedSecretNames, credentialsSecretName)
+		}
 
 		api.Credentials, err = sas.readCredentials(secret, applicationAPI)
 		if err != nil {
 			return nil, err
 		}
+		if api.Credentials != nil {
+			api.Credentials.Connection = api.Connection
+		}
+		if err := validateStreamedCredentials(api); err != nil {
+			return nil, err
+		}
 	}
 
 	if applicationAPI.RequestParametersSecretName != "" {
@@ -69,6 +184,9 @@ func (sas defaultService) Read(applicationAPI *applications.ServiceAPI) (*model.
 		if err != nil {
 			return nil, err
 		}
+		if secrets.HasSource(applicationAPI.RequestParametersSecretName) {
+			api.SourcedSecretNames = append(api.SourcedSecretNames, applicationAPI.RequestParametersSecretName)
+		}
 
 		requestParameters, err := getRequestParameters(secret)
 		if err != nil {
@@ -81,6 +199,102 @@ func (sas defaultService) Read(applicationAPI *applications.ServiceAPI) (*model.
 	return api, nil
 }
 
+func (sas defaultService) readConnectionSettings(upstreamProxy *applications.UpstreamProxy, tlsSettings *applications.TLS) (*httptools.ConnectionSettings, apperrors.AppError) {
+	connection := &httptools.ConnectionSettings{}
+
+	if upstreamProxy != nil {
+		proxyURL, err := httptools.ParseProxyURL(upstreamProxy.URL)
+		if err != nil {
+			return nil, apperrors.Internalf("invalid upstream proxy: %s", err.Error())
+		}
+
+		if upstreamProxy.CredentialsSecretName != "" {
+			secret, err := sas.secretsRepository.Get(upstreamProxy.CredentialsSecretName)
+			if err != nil {
+				return nil, err
+			}
+			proxyURL.User = url.UserPassword(string(secret[UsernameKey]), string(secret[PasswordKey]))
+		}
+
+		connection.Proxy = proxyURL
+	}
+
+	if tlsSettings != nil {
+		if err := sas.readTLSSettings(tlsSettings, connection); err != nil {
+			return nil, err
+		}
+	}
+
+	return connection, nil
+}
+
+func (sas defaultService) readTLSSettings(tlsSettings *applications.TLS, connection *httptools.ConnectionSettings) apperrors.AppError {
+	if tlsSettings.CABundleSecretName != "" || tlsSettings.CABundleConfigMapName != "" {
+		caBundle, err := sas.readCABundle(tlsSettings)
+		if err != nil {
+			return err
+		}
+
+		rootCAs, parseErr := httptools.ParseCABundle(caBundle)
+		if parseErr != nil {
+			return apperrors.Internalf("invalid TLS settings: %s", parseErr.Error())
+		}
+		connection.RootCAs = rootCAs
+	}
+
+	if tlsSettings.MinVersion != "" {
+		minVersion, err := httptools.ParseTLSVersion(tlsSettings.MinVersion)
+		if err != nil {
+			return apperrors.Internalf("invalid TLS settings: %s", err.Error())
+		}
+		connection.MinVersion = minVersion
+	}
+
+	cipherSuites, err := httptools.ParseCipherSuites(tlsSettings.CipherSuites)
+	if err != nil {
+		return apperrors.Internalf("invalid TLS settings: %s", err.Error())
+	}
+	connection.CipherSuites = cipherSuites
+
+	pins, err := httptools.ParsePublicKeyPins(tlsSettings.PinnedPublicKeys)
+	if err != nil {
+		return apperrors.Internalf("invalid TLS settings: %s", err.Error())
+	}
+	connection.PinnedPublicKeys = pins
+
+	connection.ServerName = tlsSettings.ServerName
+
+	return nil
+}
+
+func (sas defaultService) readCABundle(tlsSettings *applications.TLS) ([]byte, apperrors.AppError) {
+	key := tlsSettings.CABundleKey
+	if key == "" {
+		key = DefaultCABundleKey
+	}
+
+	var caBundle []byte
+	if tlsSettings.CABundleSecretName != "" {
+		secret, err := sas.secretsRepository.Get(tlsSettings.CABundleSecretName)
+		if err != nil {
+			return nil, err
+		}
+		caBundle = secret[key]
+	} else {
+		configMap, err := sas.configMapsRepository.Get(tlsSettings.CABundleConfigMapName)
+		if err != nil {
+			return nil, err
+		}
+		caBundle = []byte(configMap[key])
+	}
+
+	if len(caBundle) == 0 {
+		return nil, apperrors.Internalf("invalid TLS settings: CA bundle has no '%s' key", key)
+	}
+
+	return caBundle, nil
+}
+
 func (sas defaultService) readCredentials(secret map[string][]byte, applicationAPI *applications.ServiceAPI) (*authorization.Credentials, apperrors.AppError) {
 	var credentials *authorization.Credentials
 
@@ -102,6 +316,22 @@ func (sas defaultService) readCredentials(secret map[string][]byte, applicationA
 		credentials = &authorization.Credentials{
 			OAuthWithCert: oAuthWithCredentials,
 		}
+	} else if credentialsType == TypeOAuthJWTAssertion {
+		oAuthJWTAssertionCredentials, err := getOAuthJWTAssertionCredentials(secret, applicationAPI.Credentials.URL)
+		if err != nil {
+			return nil, err
+		}
+		credentials = &authorization.Credentials{
+			OAuthJWTAssertion: oAuthJWTAssertionCredentials,
+		}
+	} else if credentialsType == TypeOAuthTokenExchange {
+		oAuthTokenExchangeCredentials, err := getOAuthTokenExchangeCredentials(secret, applicationAPI.Credentials.URL)
+		if err != nil {
+			return nil, err
+		}
+		credentials = &authorization.Credentials{
+			OAuthTokenExchange: oAuthTokenExchangeCredentials,
+		}
 	} else if credentialsType == TypeBasic {
 		credentials = &authorization.Credentials{
 			BasicAuth: getBasicAuthCredentials(secret),
@@ -110,6 +340,30 @@ func (sas defaultService) readCredentials(secret map[string][]byte, applicationA
 		credentials = &authorization.Credentials{
 			CertificateGen: getCertificateGenCredentials(secret),
 		}
+	} else if credentialsType == TypeAPIKey {
+		apiKeyCredentials, err := getAPIKeyCredentials(secret)
+		if err != nil {
+			return nil, err
+		}
+		credentials = &authorization.Credentials{
+			APIKey: apiKeyCredentials,
+		}
+	} else if credentialsType == TypeDigest {
+		credentials = &authorization.Credentials{
+			Digest: getDigestCredentials(secret),
+		}
+	} else if credentialsType == TypeNTLM {
+		credentials = &authorization.Credentials{
+			NTLM: getNTLMCredentials(secret),
+		}
+	} else if credentialsType == TypeAWSSigV4 {
+		awsSigV4Credentials, err := getAWSSigV4Credentials(secret)
+		if err != nil {
+			return nil, err
+		}
+		credentials = &authorization.Credentials{
+			AWSSigV4: awsSigV4Credentials,
+		}
 	} else {
 		credentials = nil
 	}
@@ -163,6 +417,7 @@ func getOAuthCredentials(secret map[string][]byte, url string) (*authorization.O
 		ClientID:          string(secret[ClientIDKey]),
 		ClientSecret:      string(secret[ClientSecretKey]),
 		URL:               url,
+		TokenParameters:   getTokenParameters(secret),
 		RequestParameters: requestParameters,
 	}, nil
 }
@@ -179,10 +434,60 @@ func getOAuthWithCertCredentials(secret map[string][]byte, url string) (*authori
 		Certificate:       secret[CertificateKey],
 		PrivateKey:        secret[PrivateKeyKey],
 		URL:               url,
+		TokenParameters:   getTokenParameters(secret),
 		RequestParameters: requestParameters,
 	}, nil
 }
 
+func getOAuthJWTAssertionCredentials(secret map[string][]byte, url string) (*authorization.OAuthJWTAssertion, apperrors.AppError) {
+	requestParameters, err := getRequestParameters(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &authorization.OAuthJWTAssertion{
+		ClientID:          string(secret[ClientIDKey]),
+		PrivateKey:        secret[PrivateKeyKey],
+		KeyID:             string(secret[KeyIDKey]),
+		URL:               url,
+		TokenParameters:   getTokenParameters(secret),
+		RequestParameters: requestParameters,
+	}, nil
+}
+
+func getOAuthTokenExchangeCredentials(secret map[string][]byte, url string) (*authorization.OAuthTokenExchange, apperrors.AppError) {
+	requestParameters, err := getRequestParameters(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	grantType := string(secret[GrantTypeKey])
+	if grantType != "" && grantType != oauth.GrantTypeTokenExchange && grantType != oauth.GrantTypeSAMLBearer {
+		return nil, apperrors.Internalf("Unsupported grant type '%s' of the token exchange", grantType)
+	}
+
+	return &authorization.OAuthTokenExchange{
+		ClientID:     string(secret[ClientIDKey]),
+		ClientSecret: string(secret[ClientSecretKey]),
+		URL:          url,
+		Exchange: oauth.TokenExchange{
+			GrantType:          grantType,
+			SubjectTokenType:   string(secret[SubjectTokenTypeKey]),
+			RequestedTokenType: string(secret[RequestedTokenTypeKey]),
+		},
+		TokenParameters:   getTokenParameters(secret),
+		RequestParameters: requestParameters,
+	}, nil
+}
+
+func getTokenParameters(secret map[string][]byte) oauth.TokenParameters {
+	return oauth.TokenParameters{
+		Scope:    string(secret[ScopeKey]),
+		Audience: string(secret[AudienceKey]),
+		Resource: string(secret[ResourceKey]),
+	}
+}
+
 func getBasicAuthCredentials(secret map[string][]byte) *authorization.BasicAuth {
 	return &authorization.BasicAuth{
 		Username: string(secret[UsernameKey]),
@@ -190,9 +495,199 @@ func getBasicAuthCredentials(secret map[string][]byte) *authorization.BasicAuth
 	}
 }
 
+func getDigestCredentials(secret map[string][]byte) *authorization.Digest {
+	return &authorization.Digest{
+		Username: string(secret[UsernameKey]),
+		Password: string(secret[PasswordKey]),
+	}
+}
+
+func getNTLMCredentials(secret map[string][]byte) *authorization.NTLM {
+	return &authorization.NTLM{
+		Username: string(secret[UsernameKey]),
+		Password: string(secret[PasswordKey]),
+		Domain:   string(secret[DomainKey]),
+	}
+}
+
+func getAWSSigV4Credentials(secret map[string][]byte) (*authorization.AWSSigV4, apperrors.AppError) {
+	for _, key := range []string{AccessKeyIDKey, SecretAccessKeyKey, RegionKey, ServiceKey} {
+		if len(secret[key]) == 0 {
+			return nil, apperrors.Internalf("invalid AWS SigV4 credentials: the Secret has no '%s' key", key)
+		}
+	}
+
+	return &authorization.AWSSigV4{
+		AccessKeyID:     string(secret[AccessKeyIDKey]),
+		SecretAccessKey: string(secret[SecretAccessKeyKey]),
+		SessionToken:    string(secret[SessionTokenKey]),
+		Region:          string(secret[RegionKey]),
+		Service:         string(secret[ServiceKey]),
+	}, nil
+}
+
 func getCertificateGenCredentials(secret map[string][]byte) *authorization.CertificateGen {
 	return &authorization.CertificateGen{
 		Certificate: secret[CertificateKey],
 		PrivateKey:  secret[PrivateKeyKey],
 	}
 }
+
+func getAPIKeyCredentials(secret map[string][]byte) (*authorization.APIKey, apperrors.AppError) {
+	apiKey := &authorization.APIKey{
+		Key:       string(secret[APIKeyKey]),
+		Name:      string(secret[APIKeyNameKey]),
+		Placement: string(secret[APIKeyPlacementKey]),
+		Prefix:    string(secret[APIKeyPrefixKey]),
+	}
+
+	if apiKey.Key == "" {
+		return nil, apperrors.Internalf("invalid API key credentials: the Secret has no '%s' key", APIKeyKey)
+	}
+	if apiKey.Placement == "" {
+		apiKey.Placement = authorization.APIKeyPlacementHeader
+	}
+
+	switch apiKey.Placement {
+	case authorization.APIKeyPlacementHeader:
+		if apiKey.Name == "" {
+			apiKey.Name = DefaultAPIKeyHeader
+		}
+		if !httpguts.ValidHeaderFieldName(apiKey.Name) {
+			return nil, apperrors.Internalf("invalid API key credentials: invalid header name '%s'", apiKey.Name)
+		}
+		if !httpguts.ValidHeaderFieldValue(apiKey.Prefix + apiKey.Key) {
+			return nil, apperrors.Internalf("invalid API key credentials: the key isn't a valid header value")
+		}
+	case authorization.APIKeyPlacementQuery:
+		if apiKey.Name == "" {
+			return nil, apperrors.Internalf("invalid API key credentials: the Secret has no '%s' key of the query parameter", APIKeyNameKey)
+		}
+	default:
+		return nil, apperrors.Internalf("invalid API key credentials: unsupported placement '%s', use '%s' or '%s'", apiKey.Placement, authorization.APIKeyPlacementHeader, authorization.APIKeyPlacementQuery)
+	}
+
+	return apiKey, nil
+}
+
+func convertRateLimit(rateLimit *applications.RateLimit) *model.RateLimit {
+	if rateLimit == nil {
+		return nil
+	}
+
+	return &model.RateLimit{
+		Requests: rateLimit.Requests,
+		Period:   rateLimit.Period,
+		Burst:    rateLimit.Burst,
+	}
+}
+
+// convertTransformations compiles the path rewrites and validates the header names, so that invalid transformations fail all calls to the API
+func convertTransformations(transformations *applications.Transformations) (*model.Transformations, apperrors.AppError) {
+	if transformations == nil {
+		return nil, nil
+	}
+
+	stripRequestHeaders, err := canonicalHeaderNames(transformations.StripRequestHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.Transformations{
+		StripRequestHeaders: stripRequestHeaders,
+	}
+
+	// the regular expressions are compiled by the repository once for the generation of the Application
+	for _, pathRewrite := range transformations.PathRewrites {
+		result.PathRewrites = append(result.PathRewrites, model.PathRewrite{
+			Regex:       pathRewrite.Regex,
+			Replacement: pathRewrite.Replacement,
+		})
+	}
+
+	if result.RequestHeaders, err = convertHeaderTransformations(transformations.RequestHeaders); err != nil {
+		return nil, err
+	}
+	if result.ResponseHeaders, err = convertHeaderTransformations(transformations.ResponseHeaders); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func convertHeaderTransformations(headers *applications.HeaderTransformations) (*model.HeaderTransformations, apperrors.AppError) {
+	if headers == nil {
+		return nil, nil
+	}
+
+	remove, err := canonicalHeaderNames(headers.Remove)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.HeaderTransformations{
+		Remove: remove,
+	}
+
+	for oldName, newName := range headers.Rename {
+		names, err := canonicalHeaderNames([]string{oldName, newName})
+		if err != nil {
+			return nil, err
+		}
+		if result.Rename == nil {
+			result.Rename = map[string]string{}
+		}
+		result.Rename[names[0]] = names[1]
+	}
+
+	for name, value := range headers.Add {
+		names, err := canonicalHeaderNames([]string{name})
+		if err != nil {
+			return nil, err
+		}
+		if !httpguts.ValidHeaderFieldValue(value) {
+			return nil, apperrors.Internalf("invalid value of the header '%s' added by transformations", name)
+		}
+		if result.Add == nil {
+			result.Add = map[string]string{}
+		}
+		result.Add[names[0]] = value
+	}
+
+	return result, nil
+}
+
+func canonicalHeaderNames(names []string) ([]string, apperrors.AppError) {
+	var result []string
+	for _, name := range names {
+		if !httpguts.ValidHeaderFieldName(name) {
+			return nil, apperrors.Internalf("invalid header name '%s' in transformations", name)
+		}
+		result = append(result, http.CanonicalHeaderKey(name))
+	}
+
+	return result, nil
+}
+
+// validateStreamedCredentials rejects the credentials which can't authorize calls in the proxy mode of the API,
+// as calls in the PassThrough and HTTP2 modes, and large bodies in the Stream buffering mode, are streamed without retries
+func validateStreamedCredentials(api *model.API) apperrors.AppError {
+	if api.Credentials == nil {
+		return nil
+	}
+
+	if api.Credentials.Digest != nil && api.IsPassThrough() {
+		return apperrors.Internalf("Digest credentials can't be used in the '%s' proxy mode, which doesn't answer the challenge of the target system", api.ProxyMode)
+	}
+
+	if awsSigV4 := api.Credentials.AWSSigV4; awsSigV4 != nil && !awsSigV4.AcceptsUnsignedPayload() {
+		if api.IsPassThrough() {
+			return apperrors.Internalf("AWSSigV4 credentials for the '%s' service, which accepts only signed payloads, can't be used in the '%s' proxy mode", awsSigV4.Service, api.ProxyMode)
+		}
+		if api.RequestBodyBuffering != nil && api.RequestBodyBuffering.Mode == model.BodyBufferingStream {
+			return apperrors.Internalf("AWSSigV4 credentials for the '%s' service, which accepts only signed payloads, can't be used in the '%s' body buffering mode", awsSigV4.Service, model.BodyBufferingStream)
+		}
+	}
+
+	return nil
+}
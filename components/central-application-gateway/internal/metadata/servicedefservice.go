@@ -19,6 +19,8 @@ type ServiceDefinitionService interface {
 	// GetAPI gets API of a service with given ID
 	GetAPIByServiceName(appName, serviceName string) (*model.API, apperrors.AppError)
 	GetAPIByEntryName(appName, serviceName, entryName string) (*model.API, apperrors.AppError)
+	// GetAccessControl gets the identities of the callers allowed to call the APIs of the Application
+	GetAccessControl(appName string) (*model.AccessControl, apperrors.AppError)
 }
 
 type serviceDefinitionService struct {
@@ -61,6 +63,27 @@ func (sds *serviceDefinitionService) GetAPIByEntryName(appName, serviceName, ent
 	return sds.getAPI(service)
 }
 
+func (sds *serviceDefinitionService) GetAccessControl(appName string) (*model.AccessControl, apperrors.AppError) {
+	accessControl, err := sds.applicationRepository.GetAccessControl(appName)
+
+	if err != nil {
+		notFoundMessage := fmt.Sprintf("application with name %s not found", appName)
+		internalErrMessage := fmt.Sprintf("failed to get application with name '%s': %s", appName, err.Error())
+
+		return nil, handleError(err, notFoundMessage, internalErrMessage)
+	}
+
+	if accessControl == nil {
+		return nil, nil
+	}
+
+	return &model.AccessControl{
+		ServiceAccounts: accessControl.ServiceAccounts,
+		SPIFFEIDs:       accessControl.SPIFFEIDs,
+		Namespaces:      accessControl.Namespaces,
+	}, nil
+}
+
 func (sds *serviceDefinitionService) getAPI(service applications.Service) (*model.API, apperrors.AppError) {
 
 	if service.API == nil {
@@ -221,3 +221,59 @@ func TestServiceDefinitionService_GetAPI(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceDefinitionService_GetAccessControl(t *testing.T) {
+	t.Run("should get access control of the Application", func(t *testing.T) {
+		// given
+		serviceRepository := &applicationmocks.ServiceRepository{}
+		serviceRepository.On("GetAccessControl", "app").Return(&applications.AccessControl{
+			ServiceAccounts: []string{"orders/orders-client"},
+			SPIFFEIDs:       []string{"spiffe://cluster.local/ns/billing/sa/billing"},
+			Namespaces:      []string{"shipping"},
+		}, nil)
+
+		service := NewServiceDefinitionService(new(serviceapimocks.Service), serviceRepository)
+
+		// when
+		accessControl, err := service.GetAccessControl("app")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, &model.AccessControl{
+			ServiceAccounts: []string{"orders/orders-client"},
+			SPIFFEIDs:       []string{"spiffe://cluster.local/ns/billing/sa/billing"},
+			Namespaces:      []string{"shipping"},
+		}, accessControl)
+	})
+
+	t.Run("should allow all callers if the Application doesn't restrict them", func(t *testing.T) {
+		// given
+		serviceRepository := &applicationmocks.ServiceRepository{}
+		serviceRepository.On("GetAccessControl", "app").Return(nil, nil)
+
+		service := NewServiceDefinitionService(new(serviceapimocks.Service), serviceRepository)
+
+		// when
+		accessControl, err := service.GetAccessControl("app")
+
+		// then
+		require.NoError(t, err)
+		assert.Nil(t, accessControl)
+	})
+
+	t.Run("should return not found error if Application doesn't exist", func(t *testing.T) {
+		// given
+		serviceRepository := &applicationmocks.ServiceRepository{}
+		serviceRepository.On("GetAccessControl", "app").Return(nil, apperrors.NotFound("Application: app not found."))
+
+		service := NewServiceDefinitionService(new(serviceapimocks.Service), serviceRepository)
+
+		// when
+		accessControl, err := service.GetAccessControl("app")
+
+		// then
+		require.Error(t, err)
+		assert.Nil(t, accessControl)
+		assert.Equal(t, apperrors.CodeNotFound, err.Code())
+	})
+}
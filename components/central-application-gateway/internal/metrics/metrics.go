@@ -0,0 +1,209 @@
+// Package metrics contains Prometheus collectors describing calls proxied by Central Application Gateway
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+)
+
+const (
+	namespace = "central_application_gateway"
+
+	labelApplication = "application"
+	labelService     = "service"
+	labelEntry       = "entry"
+	labelMethod      = "method"
+	labelCode        = "code"
+	labelToken       = "token"
+	labelResult      = "result"
+	labelScope       = "scope"
+
+	// TokenAuthorization marks failures of fetching credentials by the authorization strategy
+	TokenAuthorization = "authorization"
+	// TokenCSRF marks failures of fetching CSRF tokens
+	TokenCSRF = "csrf"
+
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+
+	// CacheHit marks calls answered with a fresh response from the response cache
+	CacheHit = "hit"
+	// CacheMiss marks calls forwarded because no response was cached
+	CacheMiss = "miss"
+	// CacheStale marks calls forwarded to validate or replace a stale cached response
+	CacheStale = "stale"
+	// CacheBypass marks calls which can't use the response cache, e.g. because they carry their own credentials
+	CacheBypass = "bypass"
+)
+
+var apiLabels = []string{labelApplication, labelService, labelEntry}
+
+// UnknownAPI labels the calls to APIs which don't exist, so that the paths of such calls don't create new series
+var UnknownAPI = model.APIIdentifier{Application: "unknown", Service: "unknown", Entry: "unknown"}
+
+var (
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "requests_total",
+		Help:      "Number of proxied requests by Application, service, entry, method and status code returned to the caller",
+	}, append(apiLabels, labelMethod, labelCode))
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "request_duration_seconds",
+		Help:      "Duration of proxied requests by Application, service and entry",
+		Buckets:   prometheus.DefBuckets,
+	}, apiLabels)
+
+	upstreamResponsesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_responses_total",
+		Help:      "Number of responses received from target systems by status code, before 5xx codes are rewritten to 502 and reported in the Target-System-Status header",
+	}, append(apiLabels, labelCode))
+
+	tokenFetchFailuresTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "token_fetch_failures_total",
+		Help:      "Number of failures of fetching authorization or CSRF tokens by Application, service and entry",
+	}, append(apiLabels, labelToken))
+
+	upstreamRetriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_retries_total",
+		Help:      "Number of idempotent calls to target systems retried according to the retry policy of the Application",
+	}, apiLabels)
+
+	rateLimitedRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_requests_total",
+		Help:      "Number of requests rejected with 429 by Application, service, entry and scope of the exceeded limit",
+	}, append(apiLabels, labelScope))
+
+	rateLimit = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_requests_per_second",
+		Help:      "Rate limits configured for Applications and services, enforced by every replica",
+	}, []string{labelApplication, labelService, labelScope})
+
+	csrfTokenFetchesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "csrf_token_fetches_total",
+		Help:      "Number of requests sent to CSRF token endpoints by result",
+	}, []string{labelResult})
+
+	responseCacheLookupsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "response_cache_lookups_total",
+		Help:      "Number of GET calls to APIs with the response cache enabled by Application, service, entry and result: hit, miss, stale or bypass",
+	}, append(apiLabels, labelResult))
+
+	responseCacheSize = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "response_cache_size_bytes",
+		Help:      "Size of the responses kept in the response cache",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(
+		requestsTotal,
+		requestDuration,
+		upstreamResponsesTotal,
+		tokenFetchFailuresTotal,
+		upstreamRetriesTotal,
+		rateLimitedRequestsTotal,
+		rateLimit,
+		csrfTokenFetchesTotal,
+		responseCacheLookupsTotal,
+		responseCacheSize,
+	)
+}
+
+// NewHandler creates handler exposing collected metrics in the Prometheus format
+func NewHandler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveRequest records the status code returned to the caller and the duration of a proxied request
+func ObserveRequest(id model.APIIdentifier, method string, code int, duration time.Duration) {
+	requestsTotal.WithLabelValues(id.Application, id.Service, id.Entry, method, strconv.Itoa(code)).Inc()
+	requestDuration.WithLabelValues(id.Application, id.Service, id.Entry).Observe(duration.Seconds())
+}
+
+// ObserveUpstreamResponse records the status code returned by the target system
+func ObserveUpstreamResponse(id model.APIIdentifier, code int) {
+	upstreamResponsesTotal.WithLabelValues(id.Application, id.Service, id.Entry, strconv.Itoa(code)).Inc()
+}
+
+// ObserveTokenFetchFailure records failure of fetching the token of the given kind
+func ObserveTokenFetchFailure(id model.APIIdentifier, token string) {
+	tokenFetchFailuresTotal.WithLabelValues(id.Application, id.Service, id.Entry, token).Inc()
+}
+
+// ObserveRetry records a retry of a call to the target system
+func ObserveRetry(id model.APIIdentifier) {
+	upstreamRetriesTotal.WithLabelValues(id.Application, id.Service, id.Entry).Inc()
+}
+
+// ObserveRateLimited records a request rejected because the limit of the given scope was exceeded
+func ObserveRateLimited(id model.APIIdentifier, scope string) {
+	rateLimitedRequestsTotal.WithLabelValues(id.Application, id.Service, id.Entry, scope).Inc()
+}
+
+// ObserveResponseCache records the result of looking up the response to a call in the response cache
+func ObserveResponseCache(id model.APIIdentifier, result string) {
+	responseCacheLookupsTotal.WithLabelValues(id.Application, id.Service, id.Entry, result).Inc()
+}
+
+// SetResponseCacheSize records the size of the responses kept in the response cache
+func SetResponseCacheSize(size int64) {
+	responseCacheSize.Set(float64(size))
+}
+
+// SetRateLimit records the rate limit of an Application or its service
+func SetRateLimit(application, service, scope string, requestsPerSecond float64) {
+	rateLimit.WithLabelValues(application, service, scope).Set(requestsPerSecond)
+}
+
+// ObserveCSRFTokenFetch records a request sent to a CSRF token endpoint
+func ObserveCSRFTokenFetch(result string) {
+	csrfTokenFetchesTotal.WithLabelValues(result).Inc()
+}
+
+// ResponseWriter remembers the status code written to the wrapped http.ResponseWriter
+type ResponseWriter struct {
+	http.ResponseWriter
+	status int
+	start  time.Time
+}
+
+// NewResponseWriter wraps w, starting the measurement of the request duration
+func NewResponseWriter(w http.ResponseWriter) *ResponseWriter {
+	return &ResponseWriter{ResponseWriter: w, status: http.StatusOK, start: time.Now()}
+}
+
+func (w *ResponseWriter) WriteHeader(statusCode int) {
+	w.status = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Status returns the status code written to the caller
+func (w *ResponseWriter) Status() int {
+	return w.status
+}
+
+// Unwrap allows http.ResponseController to reach the original writer, e.g. for flushing streamed responses
+func (w *ResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Observe records the request handled with w
+func (w *ResponseWriter) Observe(id model.APIIdentifier, method string) {
+	ObserveRequest(id, method, w.status, time.Since(w.start))
+}
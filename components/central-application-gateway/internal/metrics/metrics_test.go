@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+)
+
+func TestMetrics(t *testing.T) {
+	apiIdentifier := model.APIIdentifier{
+		Application: "app",
+		Service:     "service",
+		Entry:       "entry",
+	}
+
+	t.Run("should record status code written to the response", func(t *testing.T) {
+		// given
+		rr := httptest.NewRecorder()
+		mw := NewResponseWriter(rr)
+
+		// when
+		mw.WriteHeader(http.StatusBadGateway)
+		mw.Observe(apiIdentifier, http.MethodPost)
+
+		// then
+		assert.Equal(t, http.StatusBadGateway, rr.Code)
+		assert.Equal(t, float64(1), testutil.ToFloat64(requestsTotal.WithLabelValues("app", "service", "entry", http.MethodPost, "502")))
+	})
+
+	t.Run("should record status code 200 when header was not written explicitly", func(t *testing.T) {
+		// given
+		rr := httptest.NewRecorder()
+		mw := NewResponseWriter(rr)
+
+		// when
+		_, err := mw.Write([]byte("test"))
+		require.NoError(t, err)
+		mw.Observe(apiIdentifier, http.MethodPut)
+
+		// then
+		assert.Equal(t, float64(1), testutil.ToFloat64(requestsTotal.WithLabelValues("app", "service", "entry", http.MethodPut, "200")))
+	})
+
+	t.Run("should record upstream status codes", func(t *testing.T) {
+		// when
+		ObserveUpstreamResponse(apiIdentifier, http.StatusServiceUnavailable)
+		ObserveUpstreamResponse(apiIdentifier, http.StatusServiceUnavailable)
+
+		// then
+		assert.Equal(t, float64(2), testutil.ToFloat64(upstreamResponsesTotal.WithLabelValues("app", "service", "entry", "503")))
+	})
+
+	t.Run("should record token fetch failures", func(t *testing.T) {
+		// when
+		ObserveTokenFetchFailure(apiIdentifier, TokenCSRF)
+
+		// then
+		assert.Equal(t, float64(1), testutil.ToFloat64(tokenFetchFailuresTotal.WithLabelValues("app", "service", "entry", TokenCSRF)))
+		assert.Equal(t, float64(0), testutil.ToFloat64(tokenFetchFailuresTotal.WithLabelValues("app", "service", "entry", TokenAuthorization)))
+	})
+
+	t.Run("should expose metrics", func(t *testing.T) {
+		// given
+		ObserveRequest(apiIdentifier, http.MethodGet, http.StatusOK, time.Second)
+		ObserveCSRFTokenFetch(ResultSuccess)
+
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		rr := httptest.NewRecorder()
+
+		// when
+		NewHandler().ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusOK, rr.Code)
+		body := rr.Body.String()
+		assert.True(t, strings.Contains(body, `central_application_gateway_requests_total{application="app",code="200",entry="entry",method="GET",service="service"}`))
+		assert.True(t, strings.Contains(body, `central_application_gateway_request_duration_seconds_bucket{application="app",entry="entry",service="service"`))
+		assert.True(t, strings.Contains(body, `central_application_gateway_csrf_token_fetches_total{result="success"}`))
+	})
+}
@@ -3,6 +3,7 @@ package proxy
 import (
 	"net/http"
 	"net/http/httputil"
+	"strings"
 	"time"
 
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf"
@@ -41,6 +42,10 @@ type Cache interface {
 	Get(appName, serviceName, apiName string) (*CacheEntry, bool)
 	// Put adds entry to the cache
 	Put(appName, serviceName, apiName string, reverseProxy *httputil.ReverseProxy, authorizationStrategy authorization.Strategy, csrfTokenStrategy csrf.TokenStrategy, clientCertificate clientcert.ClientCertificate) *CacheEntry
+	// Invalidate removes all entries of the Application, or of the destination secret in the destination mode, and the tokens cached for them
+	Invalidate(appName string)
+	// Delete removes the entry and the tokens cached for it
+	Delete(appName, serviceName, apiName string)
 }
 
 type cache struct {
@@ -54,8 +59,13 @@ func NewCache(proxyCacheTTL int) Cache {
 	}
 }
 
+// cacheKey separates the names with a slash, which they can't contain as they are taken from the path segments
+func cacheKey(appName, serviceName, apiName string) string {
+	return appName + "/" + serviceName + "/" + apiName
+}
+
 func (p *cache) Get(appName, serviceName, apiName string) (*CacheEntry, bool) {
-	key := appName + serviceName + apiName
+	key := cacheKey(appName, serviceName, apiName)
 	proxy, found := p.proxyCache.Get(key)
 	if !found {
 		return nil, false
@@ -65,9 +75,36 @@ func (p *cache) Get(appName, serviceName, apiName string) (*CacheEntry, bool) {
 }
 
 func (p *cache) Put(appName, serviceName, apiName string, reverseProxy *httputil.ReverseProxy, authorizationStrategy authorization.Strategy, csrfTokenStrategy csrf.TokenStrategy, clientCertificate clientcert.ClientCertificate) *CacheEntry {
-	key := appName + serviceName + apiName
+	key := cacheKey(appName, serviceName, apiName)
 	proxy := &CacheEntry{Proxy: reverseProxy, AuthorizationStrategy: &authorizationStrategyWrapper{authorizationStrategy, reverseProxy, clientCertificate}, CSRFTokenStrategy: csrfTokenStrategy}
 	p.proxyCache.Set(key, proxy, gocache.DefaultExpiration)
 
 	return proxy
 }
+
+func (p *cache) Invalidate(appName string) {
+	prefix := appName + "/"
+	for key, item := range p.proxyCache.Items() {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		p.proxyCache.Delete(key)
+
+		entry := item.Object.(*CacheEntry)
+		entry.AuthorizationStrategy.Invalidate()
+		entry.CSRFTokenStrategy.Invalidate()
+	}
+}
+
+func (p *cache) Delete(appName, serviceName, apiName string) {
+	key := cacheKey(appName, serviceName, apiName)
+	item, found := p.proxyCache.Get(key)
+	if !found {
+		return
+	}
+	p.proxyCache.Delete(key)
+
+	entry := item.(*CacheEntry)
+	entry.AuthorizationStrategy.Invalidate()
+	entry.CSRFTokenStrategy.Invalidate()
+}
@@ -55,4 +55,70 @@ func TestCache(t *testing.T) {
 		assert.Equal(t, authorizationStrategyMock, cacheEntry.AuthorizationStrategy.actualStrategy)
 		assert.Equal(t, csrfTokenStrategy, cacheEntry.CSRFTokenStrategy)
 	})
+
+	t.Run("should invalidate entries of the Application and their tokens", func(t *testing.T) {
+		// given
+		cache := NewCache(60)
+		proxy := httputil.NewSingleHostReverseProxy(net.FormatURL("http", "www.example.com", 8080, ""))
+		clientCertificate := clientcert.NewClientCertificate(nil)
+
+		authorizationStrategyMock := &mocks.Strategy{}
+		authorizationStrategyMock.On("Invalidate").Return().Twice()
+		csrfTokenStrategyMock := &csrfmocks.TokenStrategy{}
+		csrfTokenStrategyMock.On("Invalidate").Return().Twice()
+		cache.Put("app1", "service1", "api1", proxy, authorizationStrategyMock, csrfTokenStrategyMock, clientCertificate)
+		cache.Put("app1", "service2", "", proxy, authorizationStrategyMock, csrfTokenStrategyMock, clientCertificate)
+
+		otherAuthorizationStrategyMock := &mocks.Strategy{}
+		otherCSRFTokenStrategyMock := &csrfmocks.TokenStrategy{}
+		cache.Put("app1-other", "service1", "api1", proxy, otherAuthorizationStrategyMock, otherCSRFTokenStrategyMock, clientCertificate)
+
+		// when
+		cache.Invalidate("app1")
+
+		// then
+		_, found := cache.Get("app1", "service1", "api1")
+		assert.False(t, found)
+		_, found = cache.Get("app1", "service2", "")
+		assert.False(t, found)
+		_, found = cache.Get("app1-other", "service1", "api1")
+		assert.True(t, found)
+
+		authorizationStrategyMock.AssertExpectations(t)
+		csrfTokenStrategyMock.AssertExpectations(t)
+		otherAuthorizationStrategyMock.AssertNotCalled(t, "Invalidate")
+		otherCSRFTokenStrategyMock.AssertNotCalled(t, "Invalidate")
+	})
+
+	t.Run("should delete the entry and its tokens", func(t *testing.T) {
+		// given
+		cache := NewCache(60)
+		proxy := httputil.NewSingleHostReverseProxy(net.FormatURL("http", "www.example.com", 8080, ""))
+		clientCertificate := clientcert.NewClientCertificate(nil)
+
+		authorizationStrategyMock := &mocks.Strategy{}
+		authorizationStrategyMock.On("Invalidate").Return().Once()
+		csrfTokenStrategyMock := &csrfmocks.TokenStrategy{}
+		csrfTokenStrategyMock.On("Invalidate").Return().Once()
+		cache.Put("app1", "service1", "api1", proxy, authorizationStrategyMock, csrfTokenStrategyMock, clientCertificate)
+
+		otherAuthorizationStrategyMock := &mocks.Strategy{}
+		otherCSRFTokenStrategyMock := &csrfmocks.TokenStrategy{}
+		cache.Put("app1", "service2", "", proxy, otherAuthorizationStrategyMock, otherCSRFTokenStrategyMock, clientCertificate)
+
+		// when
+		cache.Delete("app1", "service1", "api1")
+		cache.Delete("app1", "service3", "")
+
+		// then
+		_, found := cache.Get("app1", "service1", "api1")
+		assert.False(t, found)
+		_, found = cache.Get("app1", "service2", "")
+		assert.True(t, found)
+
+		authorizationStrategyMock.AssertExpectations(t)
+		csrfTokenStrategyMock.AssertExpectations(t)
+		otherAuthorizationStrategyMock.AssertNotCalled(t, "Invalidate")
+		otherCSRFTokenStrategyMock.AssertNotCalled(t, "Invalidate")
+	})
 }
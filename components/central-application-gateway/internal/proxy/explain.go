@@ -0,0 +1,152 @@
+package proxy
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/httperrors"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/applications"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+)
+
+// Explainer explains how the proxy resolves the API of a call and where it sends the call, without calling the target system
+type Explainer interface {
+	// Explain explains the call to the path, which must start with the API identifier as in calls to the proxy
+	Explain(path string) (Explanation, apperrors.AppError)
+}
+
+// Explanation describes the resolution of the API of a call. It holds no credentials, only their type.
+type Explanation struct {
+	Application string `json:"application"`
+	Service     string `json:"service"`
+	Entry       string `json:"entry,omitempty"`
+	// Candidates are all entries of the Application, the call is proxied only if exactly one of them matches
+	Candidates []applications.Candidate `json:"candidates,omitempty"`
+	// API is the resolved API, nil if the call would fail before reaching the target system
+	API *ExplainedAPI `json:"api,omitempty"`
+	// Error is the error response the proxy would return instead of calling the target system
+	Error *httperrors.ErrorResponse `json:"error,omitempty"`
+}
+
+// ExplainedAPI describes the resolved API and the URLs the call is sent to
+type ExplainedAPI struct {
+	// TargetURLs are the URLs of the call for every target of the API, after the path rewrites
+	TargetURLs           []string `json:"targetUrls"`
+	CredentialsType      string   `json:"credentialsType"`
+	CSRFTokenEndpointURL string   `json:"csrfTokenEndpointUrl,omitempty"`
+	ProxyMode            string   `json:"proxyMode,omitempty"`
+	SkipVerify           bool     `json:"skipVerify"`
+}
+
+type candidatesFunc func(identifier model.APIIdentifier) ([]applications.Candidate, apperrors.AppError)
+
+type explainer struct {
+	extractPathFunc pathExtractorFunc
+	candidatesFunc  candidatesFunc
+	apiExtractor    APIExtractor
+}
+
+// NewExplainer creates explainer of the calls to the proxy created by New
+func NewExplainer(serviceDefService metadata.ServiceDefinitionService, serviceRepository applications.ServiceRepository) Explainer {
+	return &explainer{
+		extractPathFunc: extractServicePath,
+		candidatesFunc: func(identifier model.APIIdentifier) ([]applications.Candidate, apperrors.AppError) {
+			return serviceRepository.CandidatesByServiceName(identifier.Application, identifier.Service)
+		},
+		apiExtractor: apiExtractor{
+			serviceDefService: serviceDefService,
+		},
+	}
+}
+
+// NewExplainerForCompass creates explainer of the calls to the proxy created by NewForCompass
+func NewExplainerForCompass(serviceDefService metadata.ServiceDefinitionService, serviceRepository applications.ServiceRepository) Explainer {
+	return &explainer{
+		extractPathFunc: extractEntryPath,
+		candidatesFunc: func(identifier model.APIIdentifier) ([]applications.Candidate, apperrors.AppError) {
+			return serviceRepository.CandidatesByEntryName(identifier.Application, identifier.Service, identifier.Entry)
+		},
+		apiExtractor: compassAPIExtractor{
+			serviceDefService: serviceDefService,
+		},
+	}
+}
+
+func (e *explainer) Explain(path string) (Explanation, apperrors.AppError) {
+	callURL, err := url.Parse(path)
+	if err != nil {
+		return Explanation{}, apperrors.WrongInputf("invalid path: %s", err.Error())
+	}
+
+	apiIdentifier, targetPath, _, appErr := e.extractPathFunc(callURL)
+	if appErr != nil {
+		return Explanation{}, appErr
+	}
+
+	explanation := Explanation{
+		Application: apiIdentifier.Application,
+		Service:     apiIdentifier.Service,
+		Entry:       apiIdentifier.Entry,
+	}
+
+	candidates, appErr := e.candidatesFunc(apiIdentifier)
+	if appErr != nil {
+		return explanation.withError(appErr), nil
+	}
+	explanation.Candidates = candidates
+
+	serviceAPI, appErr := e.apiExtractor.Get(apiIdentifier)
+	if appErr != nil {
+		return explanation.withError(appErr), nil
+	}
+
+	if appErr := rewritePath(targetPath, serviceAPI.Transformations); appErr != nil {
+		return explanation.withError(appErr), nil
+	}
+
+	targetURLs, appErr := explainTargetURLs(serviceAPI, targetPath, callURL.RawQuery)
+	if appErr != nil {
+		return explanation.withError(appErr), nil
+	}
+
+	explanation.API = &ExplainedAPI{
+		TargetURLs:      targetURLs,
+		CredentialsType: credentialsType(serviceAPI.Credentials),
+		ProxyMode:       serviceAPI.ProxyMode,
+		SkipVerify:      serviceAPI.SkipVerify,
+	}
+	if serviceAPI.Credentials != nil {
+		explanation.API.CSRFTokenEndpointURL = serviceAPI.Credentials.CSRFTokenEndpointURL
+	}
+
+	return explanation, nil
+}
+
+func (e Explanation) withError(appErr apperrors.AppError) Explanation {
+	_, body := httperrors.AppErrorToResponse(appErr)
+	e.Error = &body
+
+	return e
+}
+
+// explainTargetURLs builds the URLs of the call the same way as the proxy, without the request parameters read from the secret of the API
+func explainTargetURLs(serviceAPI *model.API, targetPath *url.URL, rawQuery string) ([]string, apperrors.AppError) {
+	pool, appErr := newTargetPool(serviceAPI.AllTargets(), nil)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	req := &http.Request{URL: &url.URL{Path: targetPath.Path, RawQuery: rawQuery}}
+	if !serviceAPI.EncodeUrl {
+		req.URL.RawPath = targetPath.RawPath
+	}
+
+	targetURLs := make([]string, 0, len(pool.targets))
+	for _, t := range pool.targets {
+		targetURLs = append(targetURLs, withTarget(req, t).URL.Redacted())
+	}
+
+	return targetURLs, nil
+}
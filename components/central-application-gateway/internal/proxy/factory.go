@@ -5,11 +5,15 @@ import (
 	"net/url"
 	"strings"
 
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/accesscontrol"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/circuitbreaker"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/ratelimit"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/proxyconfig"
 )
 
 type pathExtractorFunc func(*url.URL) (model.APIIdentifier, *url.URL, *url.URL, apperrors.AppError)
@@ -22,46 +26,24 @@ func New(
 	csrfTokenStrategyFactory csrf.TokenStrategyFactory,
 	config Config) http.Handler {
 
-	pathExtractor := func(u *url.URL) (model.APIIdentifier, *url.URL, *url.URL, apperrors.AppError) {
-		path := u.EscapedPath()
-
-		trimmed := strings.Trim(path, "/")
-		split := strings.Split(trimmed, "/")
-
-		if len(split) < 2 || split[0] == path {
-			return model.APIIdentifier{}, nil, nil, apperrors.WrongInputf("path must contain Application and Service name")
-		}
-
-		apiIdentifier := model.APIIdentifier{
-			Application: split[0],
-			Service:     split[1],
-		}
-
-		targetAPIPath := strings.Join(split[2:], "/")
-
-		gwURL := *u
-		gwURL.Path = "/" + strings.Join(split[:2], "/")
-
-		targetURL, err := url.Parse(targetAPIPath)
-
-		if err != nil {
-			return model.APIIdentifier{}, nil, nil, apperrors.WrongInputf("Path has to be a valid path")
-		}
-
-		return apiIdentifier, targetURL, &gwURL, nil
-	}
-
 	apiExtractor := apiExtractor{
 		serviceDefService: serviceDefService,
 	}
 
 	return &proxy{
-		cache:                        NewCache(config.ProxyCacheTTL),
+		cache:                        newCache(config),
 		proxyTimeout:                 config.ProxyTimeout,
 		authorizationStrategyFactory: authorizationStrategyFactory,
 		csrfTokenStrategyFactory:     csrfTokenStrategyFactory,
-		extractPathFunc:              pathExtractor,
+		extractPathFunc:              extractServicePath,
 		apiExtractor:                 apiExtractor,
+		circuitBreakers:              newCircuitBreakers(config),
+		requestBodyMemoryLimit:       config.RequestBodyMemoryLimit,
+		rateLimiters:                 newRateLimiters(config),
+		responseCache:                config.ResponseCache,
+		responseCacheMaxEntrySize:    config.ResponseCacheMaxEntrySize,
+		authorizer:                   newAuthorizer(config),
+		secretsCache:                 config.SecretsCache,
 	}
 }
 
@@ -71,46 +53,145 @@ func NewForCompass(
 	csrfTokenStrategyFactory csrf.TokenStrategyFactory,
 	config Config) http.Handler {
 
-	extractFunc := func(u *url.URL) (model.APIIdentifier, *url.URL, *url.URL, apperrors.AppError) {
-		path := u.EscapedPath()
-		trimmed := strings.Trim(path, "/")
-		split := strings.Split(trimmed, "/")
-
-		if len(split) < 3 || split[0] == path {
-			return model.APIIdentifier{}, nil, nil, apperrors.WrongInputf("path must contain Application, Service and Entry name")
-		}
-
-		apiIdentifier := model.APIIdentifier{
-			Application: split[0],
-			Service:     split[1],
-			Entry:       split[2],
-		}
-
-		targetAPIPath := strings.Join(split[3:], "/")
-
-		gwURL := *u
-		gwURL.Path = "/" + strings.Join(split[:3], "/")
-
-		targetURL, err := url.Parse(targetAPIPath)
-		if err != nil {
-			return model.APIIdentifier{}, nil, nil, apperrors.WrongInputf("invalid target path")
-		}
+	apiExtractor := compassAPIExtractor{
+		serviceDefService: serviceDefService,
+	}
 
-		return apiIdentifier, targetURL, &gwURL, nil
+	return &proxy{
+		cache:                        newCache(config),
+		proxyTimeout:                 config.ProxyTimeout,
+		authorizationStrategyFactory: authorizationStrategyFactory,
+		csrfTokenStrategyFactory:     csrfTokenStrategyFactory,
+		extractPathFunc:              extractEntryPath,
+		apiExtractor:                 apiExtractor,
+		circuitBreakers:              newCircuitBreakers(config),
+		requestBodyMemoryLimit:       config.RequestBodyMemoryLimit,
+		rateLimiters:                 newRateLimiters(config),
+		responseCache:                config.ResponseCache,
+		responseCacheMaxEntrySize:    config.ResponseCacheMaxEntrySize,
+		authorizer:                   newAuthorizer(config),
+		secretsCache:                 config.SecretsCache,
 	}
+}
 
-	apiExtractor := compassAPIExtractor{
-		serviceDefService: serviceDefService,
+// NewForDestinations creates proxy for handling calls to destinations defined in secrets, without the Application CR.
+// The path has the form /{secret}/{api}/{path}, where api is the key of the secret holding the destination configuration.
+func NewForDestinations(
+	targetConfigProvider proxyconfig.TargetConfigProvider,
+	authorizationStrategyFactory authorization.StrategyFactory,
+	csrfTokenStrategyFactory csrf.TokenStrategyFactory,
+	config Config) http.Handler {
+
+	apiExtractor := destinationAPIExtractor{
+		targetConfigProvider: targetConfigProvider,
 	}
 
 	return &proxy{
-		cache:                        NewCache(config.ProxyCacheTTL),
+		cache:                        newCache(config),
 		proxyTimeout:                 config.ProxyTimeout,
 		authorizationStrategyFactory: authorizationStrategyFactory,
 		csrfTokenStrategyFactory:     csrfTokenStrategyFactory,
-		extractPathFunc:              extractFunc,
+		extractPathFunc:              extractServicePath,
 		apiExtractor:                 apiExtractor,
+		circuitBreakers:              newCircuitBreakers(config),
+		requestBodyMemoryLimit:       config.RequestBodyMemoryLimit,
+		rateLimiters:                 newRateLimiters(config),
+		responseCache:                config.ResponseCache,
+		responseCacheMaxEntrySize:    config.ResponseCacheMaxEntrySize,
+		authorizer:                   newAuthorizer(config),
+		secretsCache:                 config.SecretsCache,
+	}
+}
+
+// extractServicePath extracts the API identifier from the path of the form /{application}/{service}/{path}
+func extractServicePath(u *url.URL) (model.APIIdentifier, *url.URL, *url.URL, apperrors.AppError) {
+	path := u.EscapedPath()
+
+	trimmed := strings.Trim(path, "/")
+	split := strings.Split(trimmed, "/")
+
+	if len(split) < 2 || split[0] == path {
+		return model.APIIdentifier{}, nil, nil, apperrors.WrongInputf("path must contain Application and Service name")
+	}
+
+	apiIdentifier := model.APIIdentifier{
+		Application: split[0],
+		Service:     split[1],
+	}
+
+	targetAPIPath := strings.Join(split[2:], "/")
+
+	gwURL := *u
+	gwURL.Path = "/" + strings.Join(split[:2], "/")
+
+	targetURL, err := url.Parse(targetAPIPath)
+
+	if err != nil {
+		return model.APIIdentifier{}, nil, nil, apperrors.WrongInputf("Path has to be a valid path")
 	}
+
+	return apiIdentifier, targetURL, &gwURL, nil
+}
+
+// extractEntryPath extracts the API identifier from the path of the form /{application}/{service}/{entry}/{path}
+func extractEntryPath(u *url.URL) (model.APIIdentifier, *url.URL, *url.URL, apperrors.AppError) {
+	path := u.EscapedPath()
+	trimmed := strings.Trim(path, "/")
+	split := strings.Split(trimmed, "/")
+
+	if len(split) < 3 || split[0] == path {
+		return model.APIIdentifier{}, nil, nil, apperrors.WrongInputf("path must contain Application, Service and Entry name")
+	}
+
+	apiIdentifier := model.APIIdentifier{
+		Application: split[0],
+		Service:     split[1],
+		Entry:       split[2],
+	}
+
+	targetAPIPath := strings.Join(split[3:], "/")
+
+	gwURL := *u
+	gwURL.Path = "/" + strings.Join(split[:3], "/")
+
+	targetURL, err := url.Parse(targetAPIPath)
+	if err != nil {
+		return model.APIIdentifier{}, nil, nil, apperrors.WrongInputf("invalid target path")
+	}
+
+	return apiIdentifier, targetURL, &gwURL, nil
+}
+
+func newCache(config Config) Cache {
+	if config.Cache != nil {
+		return config.Cache
+	}
+
+	return NewCache(config.ProxyCacheTTL)
+}
+
+func newCircuitBreakers(config Config) circuitbreaker.CircuitBreakers {
+	if config.CircuitBreakers != nil {
+		return config.CircuitBreakers
+	}
+
+	return circuitbreaker.New(circuitbreaker.Config{})
+}
+
+func newRateLimiters(config Config) ratelimit.Limiters {
+	if config.RateLimiters != nil {
+		return config.RateLimiters
+	}
+
+	return ratelimit.New()
+}
+
+func newAuthorizer(config Config) accesscontrol.Authorizer {
+	if config.Authorizer != nil {
+		return config.Authorizer
+	}
+
+	return accesscontrol.NewAuthorizer(nil, accesscontrol.Config{})
 }
 
 type apiExtractor struct {
@@ -121,6 +202,10 @@ func (ae apiExtractor) Get(identifier model.APIIdentifier) (*model.API, apperror
 	return ae.serviceDefService.GetAPIByServiceName(identifier.Application, identifier.Service)
 }
 
+func (ae apiExtractor) GetAccessControl(application string) (*model.AccessControl, apperrors.AppError) {
+	return ae.serviceDefService.GetAccessControl(application)
+}
+
 type compassAPIExtractor struct {
 	serviceDefService metadata.ServiceDefinitionService
 }
@@ -128,3 +213,35 @@ type compassAPIExtractor struct {
 func (ae compassAPIExtractor) Get(identifier model.APIIdentifier) (*model.API, apperrors.AppError) {
 	return ae.serviceDefService.GetAPIByEntryName(identifier.Application, identifier.Service, identifier.Entry)
 }
+
+func (ae compassAPIExtractor) GetAccessControl(application string) (*model.AccessControl, apperrors.AppError) {
+	return ae.serviceDefService.GetAccessControl(application)
+}
+
+type destinationAPIExtractor struct {
+	targetConfigProvider proxyconfig.TargetConfigProvider
+}
+
+func (ae destinationAPIExtractor) Get(identifier model.APIIdentifier) (*model.API, apperrors.AppError) {
+	destination, err := ae.targetConfigProvider.GetDestinationConfig(identifier.Application, identifier.Service)
+	if err != nil {
+		return nil, err
+	}
+
+	var credentials *authorization.Credentials
+	if destination.Configuration.Credentials != nil {
+		credentials = destination.Configuration.Credentials.ToCredentials()
+	}
+
+	return &model.API{
+		TargetUrl:         destination.TargetURL,
+		Credentials:       credentials,
+		RequestParameters: destination.Configuration.RequestParameters,
+		CSRFConfig:        destination.Configuration.CSRFConfig,
+	}, nil
+}
+
+// GetAccessControl allows all callers, the destinations aren't restricted by the Application CR
+func (ae destinationAPIExtractor) GetAccessControl(_ string) (*model.AccessControl, apperrors.AppError) {
+	return nil, nil
+}
@@ -10,12 +10,17 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf"
+	csrfMock "github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf/mocks"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata"
 	metadatamocks "github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/mocks"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
 	metadatamodel "github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
 	authMock "github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/mocks"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httptools"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/proxyconfig"
+	proxyconfigmocks "github.com/kyma-project/kyma/components/central-application-gateway/pkg/proxyconfig/mocks"
 )
 
 type createHandlerFunc func(serviceDefService metadata.ServiceDefinitionService, authorizationStrategyFactory authorization.StrategyFactory, csrfTokenStrategyFactory csrf.TokenStrategyFactory, config Config) http.Handler
@@ -49,6 +54,7 @@ func TestProxyFactory(t *testing.T) {
 	createMockServiceDeffService :=
 		func(apiIdentifier metadatamodel.APIIdentifier, targetURL string, credentials *authorization.Credentials) metadatamocks.ServiceDefinitionService {
 			serviceDefServiceMock := metadatamocks.ServiceDefinitionService{}
+			serviceDefServiceMock.On("GetAccessControl", apiIdentifier.Application).Return(nil, nil)
 			serviceDefServiceMock.On("GetAPIByServiceName", apiIdentifier.Application, apiIdentifier.Service).Return(&metadatamodel.API{
 				TargetUrl:   targetURL,
 				Credentials: credentials,
@@ -60,6 +66,7 @@ func TestProxyFactory(t *testing.T) {
 	createMockServiceDeffServiceWithoutEncoding :=
 		func(apiIdentifier metadatamodel.APIIdentifier, targetURL string, credentials *authorization.Credentials) metadatamocks.ServiceDefinitionService {
 			serviceDefServiceMock := metadatamocks.ServiceDefinitionService{}
+			serviceDefServiceMock.On("GetAccessControl", apiIdentifier.Application).Return(nil, nil)
 			serviceDefServiceMock.On("GetAPIByServiceName", apiIdentifier.Application, apiIdentifier.Service).Return(&metadatamodel.API{
 				TargetUrl:   targetURL,
 				Credentials: credentials,
@@ -71,6 +78,7 @@ func TestProxyFactory(t *testing.T) {
 
 	createMockServiceDeffServiceForCompass := func(apiIdentifier model.APIIdentifier, targetURL string, credentials *authorization.Credentials) metadatamocks.ServiceDefinitionService {
 		serviceDefServiceMock := metadatamocks.ServiceDefinitionService{}
+		serviceDefServiceMock.On("GetAccessControl", apiIdentifier.Application).Return(nil, nil)
 		serviceDefServiceMock.On("GetAPIByEntryName", apiIdentifier.Application, apiIdentifier.Service, apiIdentifier.Entry).Return(&metadatamodel.API{
 			TargetUrl:   targetURL,
 			Credentials: credentials,
@@ -237,3 +245,99 @@ func TestPathExtractionErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestProxyFactoryForDestinations(t *testing.T) {
+	proxyConfig := Config{
+		ProxyTimeout:  10,
+		ProxyCacheTTL: 10,
+	}
+
+	t.Run("should proxy using destination secret and API name", func(t *testing.T) {
+		// given
+		ts := NewTestServer(func(req *http.Request) {
+			assert.Equal(t, http.MethodGet, req.Method)
+			assert.Equal(t, "/orders/123", req.URL.String())
+		})
+		defer ts.Close()
+
+		req, err := http.NewRequest(http.MethodGet, "/destinations/orders-api/orders/123", nil)
+		require.NoError(t, err)
+
+		targetConfigProviderMock := &proxyconfigmocks.TargetConfigProvider{}
+		targetConfigProviderMock.On("GetDestinationConfig", "destinations", "orders-api").Return(proxyconfig.ProxyDestinationConfig{
+			TargetURL: ts.URL,
+			Configuration: proxyconfig.Configuration{
+				Credentials: proxyconfig.BasicAuthConfig{Username: "user", Password: "password"},
+				CSRFConfig:  &proxyconfig.CSRFConfig{TokenURL: "http://csrf.example.com"},
+			},
+		}, nil).Once()
+
+		authStrategyMock := &authMock.Strategy{}
+		authStrategyMock.
+			On("AddAuthorization", mock.AnythingOfType("*http.Request"), mock.AnythingOfType("SetClientCertificateFunc"), false).
+			Return(nil).
+			Once()
+
+		credentials := &authorization.Credentials{
+			BasicAuth: &authorization.BasicAuth{Username: "user", Password: "password"},
+		}
+		authStrategyFactoryMock := &authMock.StrategyFactory{}
+		authStrategyFactoryMock.On("Create", credentials).Return(authStrategyMock).Once()
+
+		csrfTokenStrategyMock := &csrfMock.TokenStrategy{}
+		csrfTokenStrategyMock.On("AddCSRFToken", mock.AnythingOfType("*http.Request"), false).Return(nil).Once()
+		csrfFactoryMock := &csrfMock.TokenStrategyFactory{}
+		csrfFactoryMock.On("Create", authStrategyMock, "http://csrf.example.com", (*httptools.ConnectionSettings)(nil)).Return(csrfTokenStrategyMock).Once()
+
+		handler := NewForDestinations(targetConfigProviderMock, authStrategyFactoryMock, csrfFactoryMock, proxyConfig)
+		rr := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "test", rr.Body.String())
+		targetConfigProviderMock.AssertExpectations(t)
+		authStrategyFactoryMock.AssertExpectations(t)
+		authStrategyMock.AssertExpectations(t)
+		csrfFactoryMock.AssertExpectations(t)
+		csrfTokenStrategyMock.AssertExpectations(t)
+	})
+
+	t.Run("should return Not Found when destination does not exist", func(t *testing.T) {
+		// given
+		req, err := http.NewRequest(http.MethodGet, "/destinations/orders-api/orders/123", nil)
+		require.NoError(t, err)
+
+		targetConfigProviderMock := &proxyconfigmocks.TargetConfigProvider{}
+		targetConfigProviderMock.On("GetDestinationConfig", "destinations", "orders-api").
+			Return(proxyconfig.ProxyDestinationConfig{}, apperrors.NotFoundf("not found")).
+			Once()
+
+		handler := NewForDestinations(targetConfigProviderMock, nil, nil, proxyConfig)
+		rr := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		targetConfigProviderMock.AssertExpectations(t)
+	})
+
+	t.Run("should return Bad Request when path does not contain API name", func(t *testing.T) {
+		// given
+		req, err := http.NewRequest(http.MethodGet, "/destinations", nil)
+		require.NoError(t, err)
+
+		handler := NewForDestinations(nil, nil, nil, proxyConfig)
+		rr := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -0,0 +1,122 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/tracing"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httptools"
+)
+
+// maxNegotiations is the number of negotiate messages of the handshake, the second one is sent in the scheme offered by the target system
+const maxNegotiations = 2
+
+// handshakeRoundTripper sends the calls of the strategies whose handshake authenticates the connection, e.g. NTLM, see authorization.ConnectionStrategy.
+// Every call has the transport of its own, so the messages of the handshake aren't sent over the connections of other calls,
+// and the connection is closed once the response is read, as the authenticated connection mustn't be reused by other calls.
+type handshakeRoundTripper struct {
+	options            []httptools.RoundTripperOption
+	connectionStrategy authorization.ConnectionStrategy
+}
+
+// newTransport returns the round tripper sending the calls to the targets, over the connections dedicated to the calls if the strategy authenticates them
+func newTransport(authorizationStrategy authorization.Strategy, options ...httptools.RoundTripperOption) http.RoundTripper {
+	connectionStrategy, ok := authorization.AsConnectionStrategy(authorizationStrategy)
+	if !ok {
+		return tracing.NewTransport(httptools.NewRoundTripper(options...))
+	}
+
+	return &handshakeRoundTripper{
+		options:            options,
+		connectionStrategy: connectionStrategy,
+	}
+}
+
+func (h *handshakeRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	roundTripper := httptools.NewRoundTripper(h.options...)
+	transport := tracing.NewTransport(roundTripper)
+
+	// the round tripper mustn't modify the call, which is sent again by the retries
+	outreq := req.Clone(req.Context())
+	var resp *http.Response
+	authenticated := false
+	for negotiation := 1; !authenticated && negotiation <= maxNegotiations; negotiation++ {
+		discardResponse(resp)
+
+		var err error
+		resp, err = transport.RoundTrip(h.negotiate(req))
+		if err != nil {
+			closeBody(req.Body)
+			roundTripper.CloseIdleConnections()
+			return nil, err
+		}
+
+		challenges := resp.Header.Values(httpconsts.HeaderWWWAuthenticate)
+		if resp.StatusCode != http.StatusUnauthorized || !h.connectionStrategy.Answers(challenges) {
+			break
+		}
+
+		var appErr apperrors.AppError
+		authenticated, appErr = h.connectionStrategy.Authenticate(outreq, challenges)
+		if appErr != nil {
+			discardResponse(resp)
+			closeBody(req.Body)
+			roundTripper.CloseIdleConnections()
+			return nil, appErr
+		}
+	}
+
+	if !authenticated && (req.Body == nil || req.Body == http.NoBody) {
+		// the response to the negotiate message is the response to the call without body
+		return closingTransport(resp, roundTripper), nil
+	}
+	discardResponse(resp)
+
+	resp, err := transport.RoundTrip(outreq)
+	if err != nil {
+		roundTripper.CloseIdleConnections()
+		return nil, err
+	}
+
+	return closingTransport(resp, roundTripper), nil
+}
+
+// negotiate returns the call starting the handshake, which is sent without the body
+func (h *handshakeRoundTripper) negotiate(req *http.Request) *http.Request {
+	negotiate := req.Clone(req.Context())
+	negotiate.Body = http.NoBody
+	negotiate.GetBody = nil
+	negotiate.ContentLength = 0
+	h.connectionStrategy.Negotiate(negotiate)
+
+	return negotiate
+}
+
+// closingTransport closes the connection of the call once its response is read, upgraded connections are closed by the proxy
+func closingTransport(resp *http.Response, roundTripper *httptools.RoundTripper) *http.Response {
+	if resp.StatusCode == http.StatusSwitchingProtocols {
+		return resp
+	}
+
+	resp.Body = &closingBody{
+		ReadCloser:   resp.Body,
+		roundTripper: roundTripper,
+	}
+
+	return resp
+}
+
+type closingBody struct {
+	io.ReadCloser
+	roundTripper *httptools.RoundTripper
+}
+
+func (b *closingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.roundTripper.CloseIdleConnections()
+
+	return err
+}
@@ -0,0 +1,202 @@
+package proxy
+
+import (
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
+	authMock "github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/mocks"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+)
+
+// ntlmServer authenticates the connections in the NTLM handshake, the authenticate message is accepted only over the connection which received the challenge
+type ntlmServer struct {
+	mutex      sync.Mutex
+	challenged map[string]bool
+	bodies     []string
+	opened     int
+	closed     int
+}
+
+func newNTLMServer() (*ntlmServer, *httptest.Server) {
+	server := &ntlmServer{challenged: map[string]bool{}}
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(server.handle))
+	ts.Config.ConnState = server.connState
+	ts.Start()
+
+	return server, ts
+}
+
+func (s *ntlmServer) handle(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.bodies = append(s.bodies, string(body))
+
+	token, found := strings.CutPrefix(r.Header.Get(httpconsts.HeaderAuthorization), "NTLM ")
+	message, err := base64.StdEncoding.DecodeString(token)
+	if !found || err != nil || len(message) < 12 {
+		w.Header().Set(httpconsts.HeaderWWWAuthenticate, "NTLM")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case message[8] == 1:
+		s.challenged[r.RemoteAddr] = true
+		w.Header().Set(httpconsts.HeaderWWWAuthenticate, "NTLM "+base64.StdEncoding.EncodeToString(ntlmChallengeMessage()))
+		w.WriteHeader(http.StatusUnauthorized)
+	case message[8] == 3 && s.challenged[r.RemoteAddr]:
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.Header().Set(httpconsts.HeaderWWWAuthenticate, "NTLM")
+		w.WriteHeader(http.StatusUnauthorized)
+	}
+}
+
+func (s *ntlmServer) connState(_ net.Conn, state http.ConnState) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	switch state {
+	case http.StateNew:
+		s.opened++
+	case http.StateClosed, http.StateHijacked:
+		s.closed++
+	}
+}
+
+func (s *ntlmServer) connections() (opened, closed int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.opened, s.closed
+}
+
+func TestHandshakeRoundTripper(t *testing.T) {
+	strategyFactory := authorization.NewStrategyFactory(authorization.FactoryConfiguration{})
+	newStrategy := func() authorization.Strategy {
+		return strategyFactory.Create(&authorization.Credentials{NTLM: &authorization.NTLM{Username: "user", Password: "password", Domain: "DOMAIN"}})
+	}
+
+	t.Run("should send the handshakes of concurrent calls over the connections dedicated to the calls", func(t *testing.T) {
+		// given
+		server, ts := newNTLMServer()
+		defer ts.Close()
+		transport := newTransport(newStrategy())
+
+		// when
+		statusCodes := make([]int, 10)
+		var wg sync.WaitGroup
+		for i := range statusCodes {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				req, _ := http.NewRequest(http.MethodPost, ts.URL+"/orders", strings.NewReader("order"))
+				res, err := transport.RoundTrip(req)
+				if !assert.NoError(t, err) {
+					return
+				}
+				_ = res.Body.Close()
+				statusCodes[i] = res.StatusCode
+			}(i)
+		}
+		wg.Wait()
+
+		// then
+		for _, statusCode := range statusCodes {
+			assert.Equal(t, http.StatusOK, statusCode)
+		}
+		assert.Eventually(t, func() bool {
+			opened, closed := server.connections()
+			return opened == len(statusCodes) && closed == opened
+		}, 5*time.Second, 10*time.Millisecond, "every call has the connection of its own, closed once the response is read")
+	})
+
+	t.Run("should send the body only with the authenticate message", func(t *testing.T) {
+		// given
+		server, ts := newNTLMServer()
+		defer ts.Close()
+		transport := newTransport(newStrategy())
+
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/orders", strings.NewReader("order"))
+		require.NoError(t, err)
+
+		// when
+		res, err := transport.RoundTrip(req)
+
+		// then
+		require.NoError(t, err)
+		_ = res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, []string{"", "order"}, server.bodies)
+	})
+
+	t.Run("should return the response to the negotiate message if the target system doesn't challenge the call without body", func(t *testing.T) {
+		// given
+		var requestCount int
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestCount++
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+		transport := newTransport(newStrategy())
+
+		req, err := http.NewRequest(http.MethodGet, ts.URL+"/orders", nil)
+		require.NoError(t, err)
+
+		// when
+		res, err := transport.RoundTrip(req)
+
+		// then
+		require.NoError(t, err)
+		_ = res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, 1, requestCount)
+	})
+
+	t.Run("should send the call with body if the target system doesn't challenge the negotiate message", func(t *testing.T) {
+		// given
+		var bodies []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+		transport := newTransport(newStrategy())
+
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/orders", strings.NewReader("order"))
+		require.NoError(t, err)
+
+		// when
+		res, err := transport.RoundTrip(req)
+
+		// then
+		require.NoError(t, err)
+		_ = res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, []string{"", "order"}, bodies)
+	})
+
+	t.Run("should not send the calls of strategies which don't authenticate the connection over dedicated connections", func(t *testing.T) {
+		// when
+		transport := newTransport(&authMock.Strategy{})
+
+		// then
+		_, ok := transport.(*handshakeRoundTripper)
+		assert.False(t, ok)
+	})
+}
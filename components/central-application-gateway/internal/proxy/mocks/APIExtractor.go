@@ -39,6 +39,31 @@ func (_m *APIExtractor) Get(identifier model.APIIdentifier) (*model.API, apperro
 	return r0, r1
 }
 
+// GetAccessControl provides a mock function with given fields: application
+func (_m *APIExtractor) GetAccessControl(application string) (*model.AccessControl, apperrors.AppError) {
+	ret := _m.Called(application)
+
+	var r0 *model.AccessControl
+	if rf, ok := ret.Get(0).(func(string) *model.AccessControl); ok {
+		r0 = rf(application)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AccessControl)
+		}
+	}
+
+	var r1 apperrors.AppError
+	if rf, ok := ret.Get(1).(func(string) apperrors.AppError); ok {
+		r1 = rf(application)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(apperrors.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewAPIExtractor interface {
 	mock.TestingT
 	Cleanup(func())
@@ -1,22 +1,36 @@
 package proxy
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
+	"go.uber.org/zap"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/accesscontrol"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/accesslog"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/circuitbreaker"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/httperrors"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metrics"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/ratelimit"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/responsecache"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/tracing"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/clientcert"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httptools"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/proxyconfig"
 )
 
@@ -28,11 +42,28 @@ type proxy struct {
 	extractPathFunc              pathExtractorFunc
 	extractGatewayFunc           gatewayURLExtractorFunc
 	apiExtractor                 APIExtractor
+	circuitBreakers              circuitbreaker.CircuitBreakers
+	requestBodyMemoryLimit       int64
+	rateLimiters                 ratelimit.Limiters
+	responseCache                responsecache.Cache
+	responseCacheMaxEntrySize    int64
+	authorizer                   accesscontrol.Authorizer
+	secretsCache                 SecretsCache
+}
+
+// SecretsCache keeps the credentials read from the sources which the informers don't watch, e.g. fetched from the credential broker
+//
+//go:generate mockery --name=SecretsCache
+type SecretsCache interface {
+	// Invalidate drops the credentials with the given name, so that they are read again
+	Invalidate(name string)
 }
 
 //go:generate mockery --name=APIExtractor
 type APIExtractor interface {
 	Get(identifier model.APIIdentifier) (*model.API, apperrors.AppError)
+	// GetAccessControl is read before the API, so the callers which aren't allowed don't learn which APIs exist
+	GetAccessControl(application string) (*model.AccessControl, apperrors.AppError)
 }
 
 // Config stores Proxy config
@@ -40,6 +71,28 @@ type Config struct {
 	ProxyTimeout  int
 	Application   string
 	ProxyCacheTTL int
+	// Cache of the proxy, nil creates a new one which can't be invalidated from outside
+	Cache Cache
+	// CircuitBreakers are shared by all proxies, nil disables them
+	CircuitBreakers circuitbreaker.CircuitBreakers
+	// RateLimiters are shared by all proxies, so that the limits of an Application apply to all its APIs
+	RateLimiters ratelimit.Limiters
+	// RequestBodyMemoryLimit is the default number of bytes of the request body kept in memory in the File and Stream buffering modes
+	RequestBodyMemoryLimit int64
+	// ResponseCache is shared by all proxies, nil disables caching of responses even if APIs enable it
+	ResponseCache responsecache.Cache
+	// ResponseCacheMaxEntrySize is the default maximum size of a cached response body
+	ResponseCacheMaxEntrySize int64
+	// Authorizer checks the callers of Applications with access control, nil identifies no callers, so that all their calls are denied
+	Authorizer accesscontrol.Authorizer
+	// SecretsCache is invalidated when the target system rejects the credentials read from a source, nil keeps them until they expire
+	SecretsCache SecretsCache
+}
+
+// WithCache returns a copy of the config with the given cache, which lets the caller invalidate the cached proxies
+func (c Config) WithCache(cache Cache) Config {
+	c.Cache = cache
+	return c
 }
 
 func (p *proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -49,12 +102,61 @@ func (p *proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	tracing.SetAPI(r.Context(), apiIdentifier)
+	record := accesslog.FromContext(r.Context())
+	record.SetAPI(apiIdentifier)
+
+	mw := metrics.NewResponseWriter(w)
+	observedAPI := metrics.UnknownAPI
+	defer func() {
+		mw.Observe(observedAPI, r.Method)
+	}()
+	w = mw
+
+	accessControl, err := p.apiExtractor.GetAccessControl(apiIdentifier.Application)
+	if err != nil {
+		handleErrors(w, err)
+		return
+	}
+	if err := p.authorizer.Authorize(r, apiIdentifier.Application, accessControl); err != nil {
+		handleErrors(w, err)
+		return
+	}
+
 	serviceAPI, err := p.apiExtractor.Get(apiIdentifier)
 	if err != nil {
 		handleErrors(w, err)
 		return
 	}
+	observedAPI = apiIdentifier
+	// the token of the caller is meant for the gateway only
+	r.Header.Del(httpconsts.HeaderCallerToken)
+	// the bearer token of the user is exchanged even if the Authorization header of the caller is stripped
+	subjectToken := callerSubjectToken(r, serviceAPI.Credentials)
+	stripRequestHeaders(r.Header, serviceAPI.Transformations)
+	record.SetAuthStrategy(authStrategyName(r, serviceAPI.Credentials))
+	record.Redact(requestParameterNames(serviceAPI.RequestParameters)...)
+	record.Redact(credentialNames(serviceAPI.Credentials)...)
+
+	cachedCall, served := p.serveFromResponseCache(w, r, apiIdentifier, serviceAPI)
+	if served {
+		return
+	}
+
+	if retryAfter, scope, allowed := p.rateLimiters.Allow(rateLimits(apiIdentifier, serviceAPI)...); !allowed {
+		metrics.ObserveRateLimited(apiIdentifier, scope)
+		w.Header().Set(httpconsts.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		respondWithBody(w, http.StatusTooManyRequests, httperrors.ErrorResponse{
+			Code:  http.StatusTooManyRequests,
+			Error: fmt.Sprintf("rate limit of the %s exceeded", scope),
+		})
+		return
+	}
 
+	if err := rewritePath(path, serviceAPI.Transformations); err != nil {
+		handleErrors(w, err)
+		return
+	}
 	r.URL.Path = path.Path
 	if !serviceAPI.EncodeUrl {
 		r.URL.RawPath = path.RawPath
@@ -66,8 +168,28 @@ func (p *proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	newRequest, cancel := p.setRequestTimeout(r)
+	var newRequest *http.Request
+	var cancel context.CancelFunc
+	if serviceAPI.IsPassThrough() {
+		newRequest, cancel = p.setPassThroughContext(w, r, apiIdentifier)
+	} else {
+		newRequest, cancel = p.setRequestTimeout(r, apiIdentifier)
+	}
 	defer cancel()
+	newRequest = withCachedCall(newRequest, cachedCall)
+	newRequest = withSubjectToken(newRequest, subjectToken)
+
+	if !p.circuitBreakers.Allow(apiIdentifier) {
+		respondWithBody(w, http.StatusServiceUnavailable, httperrors.ErrorResponse{
+			Code:  http.StatusServiceUnavailable,
+			Error: "circuit breaker is open, calls to the target system are temporarily rejected",
+		})
+		return
+	}
+	// responses and errors of the target system, and failures of fetching its tokens, are written as 5xx status codes
+	defer func() {
+		p.circuitBreakers.Done(apiIdentifier, mw.Status() < http.StatusInternalServerError)
+	}()
 
 	err = p.addAuthorization(newRequest, cacheEntry, serviceAPI.SkipVerify)
 	if err != nil {
@@ -75,8 +197,28 @@ func (p *proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cacheEntry.Proxy.ModifyResponse = responseModifier(gwURL, serviceAPI.TargetUrl, urlRewriter)
+	cacheEntry.Proxy.ModifyResponse = withResponseCache(withResponseHeaderTransformations(responseModifier(gwURL, urlRewriter), serviceAPI.Transformations))
 	cacheEntry.Proxy.ServeHTTP(w, newRequest)
+
+	if mw.Status() == http.StatusUnauthorized {
+		p.invalidateSourcedSecrets(apiIdentifier, serviceAPI)
+	}
+}
+
+// invalidateSourcedSecrets drops the proxy and the credentials read from the sources which the informers don't watch,
+// so that the next call reads the credentials again if they were rotated in the source
+func (p *proxy) invalidateSourcedSecrets(apiIdentifier model.APIIdentifier, serviceAPI *model.API) {
+	if len(serviceAPI.SourcedSecretNames) == 0 {
+		return
+	}
+
+	p.cache.Delete(apiIdentifier.Application, apiIdentifier.Service, apiIdentifier.Entry)
+	if p.secretsCache == nil {
+		return
+	}
+	for _, name := range serviceAPI.SourcedSecretNames {
+		p.secretsCache.Invalidate(name)
+	}
 }
 
 func (p *proxy) extractPath(u *url.URL) (model.APIIdentifier, *url.URL, *url.URL, apperrors.AppError) {
@@ -101,8 +243,13 @@ func (p *proxy) getOrCreateCacheEntry(apiIdentifier model.APIIdentifier, service
 func (p *proxy) createCacheEntry(apiIdentifier model.APIIdentifier, serviceAPI model.API) (*CacheEntry, apperrors.AppError) {
 	clientCertificate := clientcert.NewClientCertificate(nil)
 	authorizationStrategy := p.newAuthorizationStrategy(serviceAPI.Credentials)
-	csrfTokenStrategy := p.newCSRFTokenStrategy(authorizationStrategy, serviceAPI.Credentials)
-	proxy, err := makeProxy(serviceAPI.TargetUrl, serviceAPI.RequestParameters, apiIdentifier.Service, serviceAPI.SkipVerify, authorizationStrategy, csrfTokenStrategy, clientCertificate, p.proxyTimeout)
+	var csrfTokenStrategy csrf.TokenStrategy
+	if serviceAPI.CSRFConfig != nil {
+		csrfTokenStrategy = p.newCSRFTokenStrategyFromCSRFConfig(authorizationStrategy, serviceAPI.CSRFConfig)
+	} else {
+		csrfTokenStrategy = p.newCSRFTokenStrategy(authorizationStrategy, serviceAPI.Credentials)
+	}
+	proxy, err := makeProxy(serviceAPI.AllTargets(), serviceAPI.LoadBalancing, serviceAPI.RequestParameters, apiIdentifier.Service, serviceAPI.SkipVerify, authorizationStrategy, csrfTokenStrategy, clientCertificate, serviceAPI.Connection, p.proxyTimeout, serviceAPI.RetryPolicy, p.requestBodyBuffering(serviceAPI.RequestBodyBuffering), requestHeaderTransformations(serviceAPI.Transformations), serviceAPI.ProxyMode)
 	if err != nil {
 		return nil, err
 	}
@@ -110,16 +257,52 @@ func (p *proxy) createCacheEntry(apiIdentifier model.APIIdentifier, serviceAPI m
 	return p.cache.Put(apiIdentifier.Application, apiIdentifier.Service, apiIdentifier.Entry, proxy, authorizationStrategy, csrfTokenStrategy, clientCertificate), nil
 }
 
+func rateLimits(apiIdentifier model.APIIdentifier, serviceAPI *model.API) []ratelimit.Limit {
+	limits := make([]ratelimit.Limit, 0, 2)
+	if serviceAPI.ServiceRateLimit != nil {
+		limits = append(limits, ratelimit.Limit{
+			Scope:       ratelimit.ScopeService,
+			Application: apiIdentifier.Application,
+			Service:     apiIdentifier.Service,
+			RateLimit:   *serviceAPI.ServiceRateLimit,
+		})
+	}
+	if serviceAPI.ApplicationRateLimit != nil {
+		limits = append(limits, ratelimit.Limit{
+			Scope:       ratelimit.ScopeApplication,
+			Application: apiIdentifier.Application,
+			RateLimit:   *serviceAPI.ApplicationRateLimit,
+		})
+	}
+
+	return limits
+}
+
+func (p *proxy) requestBodyBuffering(buffering *model.RequestBodyBuffering) model.RequestBodyBuffering {
+	if buffering == nil {
+		return model.RequestBodyBuffering{Mode: model.BodyBufferingMemory}
+	}
+
+	result := *buffering
+	if result.MemoryLimit <= 0 {
+		result.MemoryLimit = p.requestBodyMemoryLimit
+	}
+
+	return result
+}
+
 func (p *proxy) newAuthorizationStrategy(credentials *authorization.Credentials) authorization.Strategy {
 	return p.authorizationStrategyFactory.Create(credentials)
 }
 
 func (p *proxy) newCSRFTokenStrategy(authorizationStrategy authorization.Strategy, credentials *authorization.Credentials) csrf.TokenStrategy {
 	csrfTokenEndpointURL := ""
+	var connection *httptools.ConnectionSettings
 	if credentials != nil {
 		csrfTokenEndpointURL = credentials.CSRFTokenEndpointURL
+		connection = credentials.Connection
 	}
-	return p.csrfTokenStrategyFactory.Create(authorizationStrategy, csrfTokenEndpointURL)
+	return p.csrfTokenStrategyFactory.Create(authorizationStrategy, csrfTokenEndpointURL, connection)
 }
 
 func (p *proxy) newCSRFTokenStrategyFromCSRFConfig(authorizationStrategy authorization.Strategy, csrfConfig *proxyconfig.CSRFConfig) csrf.TokenStrategy {
@@ -127,53 +310,196 @@ func (p *proxy) newCSRFTokenStrategyFromCSRFConfig(authorizationStrategy authori
 	if csrfConfig != nil {
 		csrfTokenEndpointURL = csrfConfig.TokenURL
 	}
-	return p.csrfTokenStrategyFactory.Create(authorizationStrategy, csrfTokenEndpointURL)
+	return p.csrfTokenStrategyFactory.Create(authorizationStrategy, csrfTokenEndpointURL, nil)
 }
 
-func (p *proxy) setRequestTimeout(r *http.Request) (*http.Request, context.CancelFunc) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(p.proxyTimeout)*time.Second)
+func (p *proxy) setRequestTimeout(r *http.Request, apiIdentifier model.APIIdentifier) (*http.Request, context.CancelFunc) {
+	// the call to the target system isn't canceled together with the incoming call, but belongs to its trace and access log record
+	ctx := accesslog.NewContext(tracing.Detach(r.Context()), accesslog.FromContext(r.Context()))
+	ctx, cancel := context.WithTimeout(withAPIIdentifier(ctx, apiIdentifier), time.Duration(p.proxyTimeout)*time.Second)
 	newRequest := r.WithContext(ctx)
 
 	return newRequest, cancel
 }
 
+// setPassThroughContext lets streamed calls and upgraded connections last as long as the caller keeps them open,
+// instead of being limited by the proxy timeout and the read and write timeouts of the server
+func (p *proxy) setPassThroughContext(w http.ResponseWriter, r *http.Request, apiIdentifier model.APIIdentifier) (*http.Request, context.CancelFunc) {
+	controller := http.NewResponseController(w)
+	if err := controller.SetReadDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		zap.L().Warn("Failed to clear read deadline of the streamed call", zap.Error(err))
+	}
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		zap.L().Warn("Failed to clear write deadline of the streamed call", zap.Error(err))
+	}
+
+	ctx, cancel := context.WithCancel(withAPIIdentifier(r.Context(), apiIdentifier))
+
+	return r.WithContext(ctx), cancel
+}
+
+// authStrategyName names the credentials used for the call, the token passed by the caller takes precedence over the credentials of the API
+func authStrategyName(r *http.Request, credentials *authorization.Credentials) string {
+	if r.Header.Get(httpconsts.HeaderAccessToken) != "" {
+		return "AccessToken"
+	}
+
+	return credentialsType(credentials)
+}
+
+// credentialsType names the credentials of the API
+func credentialsType(credentials *authorization.Credentials) string {
+	switch {
+	case credentials == nil:
+		return "NoAuth"
+	case credentials.OAuth != nil:
+		return "OAuth"
+	case credentials.OAuthWithCert != nil:
+		return "OAuthWithCert"
+	case credentials.OAuthJWTAssertion != nil:
+		return "OAuthJWTAssertion"
+	case credentials.OAuthTokenExchange != nil:
+		return "OAuthTokenExchange"
+	case credentials.BasicAuth != nil:
+		return "BasicAuth"
+	case credentials.CertificateGen != nil:
+		return "CertificateGen"
+	case credentials.APIKey != nil:
+		return "APIKey"
+	case credentials.Digest != nil:
+		return "Digest"
+	case credentials.NTLM != nil:
+		return "NTLM"
+	case credentials.AWSSigV4 != nil:
+		return "AWSSigV4"
+	}
+
+	return "NoAuth"
+}
+
+// requestParameterNames returns the names of the headers and query parameters added to the call from the secret of the API
+func requestParameterNames(requestParameters *authorization.RequestParameters) []string {
+	if requestParameters == nil {
+		return nil
+	}
+
+	var names []string
+	if requestParameters.Headers != nil {
+		for name := range *requestParameters.Headers {
+			names = append(names, name)
+		}
+	}
+	if requestParameters.QueryParameters != nil {
+		for name := range *requestParameters.QueryParameters {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// credentialNames returns the names of the headers and query parameters the credentials of the API add to the call
+func credentialNames(credentials *authorization.Credentials) []string {
+	if credentials == nil || credentials.APIKey == nil {
+		return nil
+	}
+
+	return []string{credentials.APIKey.Name}
+}
+
 func (p *proxy) addAuthorization(r *http.Request, cacheEntry *CacheEntry, skipTLSVerify bool) apperrors.AppError {
 
-	err := cacheEntry.AuthorizationStrategy.AddAuthorization(r, skipTLSVerify)
+	err := traced(r.Context(), spanAuthorization, func() apperrors.AppError {
+		return cacheEntry.AuthorizationStrategy.AddAuthorization(r, skipTLSVerify)
+	})
+	if err != nil {
+		metrics.ObserveTokenFetchFailure(apiIdentifierFromContext(r.Context()), metrics.TokenAuthorization)
+		return err
+	}
 
+	err = traced(r.Context(), spanCSRFToken, func() apperrors.AppError {
+		return cacheEntry.CSRFTokenStrategy.AddCSRFToken(r, skipTLSVerify)
+	})
 	if err != nil {
+		metrics.ObserveTokenFetchFailure(apiIdentifierFromContext(r.Context()), metrics.TokenCSRF)
 		return err
 	}
 
-	return cacheEntry.CSRFTokenStrategy.AddCSRFToken(r, skipTLSVerify)
+	return nil
 }
 
-func copyRequestBody(r *http.Request) (io.ReadCloser, apperrors.AppError) {
-	if r.Body == nil {
-		return nil, nil
+const (
+	spanAuthorization = "authorization"
+	spanCSRFToken     = "csrf token"
+	spanUpstreamCall  = "upstream call"
+)
+
+// traced runs the step, for example fetching a token, in a child span of the call
+func traced(ctx context.Context, name string, step func() apperrors.AppError) apperrors.AppError {
+	_, span := tracing.StartSpan(ctx, name)
+	err := step()
+	tracing.End(span, err)
+
+	return err
+}
+
+type contextKey string
+
+const apiIdentifierKey contextKey = "api-identifier"
+
+// withSubjectToken keeps the bearer token of the user calling the API with the token exchange credentials in the context of the call,
+// so that the token is exchanged for the token of the target system
+func withSubjectToken(r *http.Request, subjectToken string) *http.Request {
+	if subjectToken == "" {
+		return r
 	}
 
-	bodyCopy, secondRequestBody, err := drainBody(r.Body)
-	if err != nil {
-		return nil, apperrors.Internalf("failed to drain request body, %s", err)
+	return r.WithContext(authorization.WithSubjectToken(r.Context(), subjectToken))
+}
+
+// callerSubjectToken returns the bearer token of the user calling the API with token exchange credentials, empty for other credentials
+func callerSubjectToken(r *http.Request, credentials *authorization.Credentials) string {
+	if credentials == nil || credentials.OAuthTokenExchange == nil {
+		return ""
 	}
-	r.Body = bodyCopy
 
-	return secondRequestBody, nil
+	subjectToken, found := strings.CutPrefix(r.Header.Get(httpconsts.HeaderAuthorization), "Bearer ")
+	if !found {
+		return ""
+	}
+
+	return strings.TrimSpace(subjectToken)
+}
+
+func withAPIIdentifier(ctx context.Context, apiIdentifier model.APIIdentifier) context.Context {
+	return context.WithValue(ctx, apiIdentifierKey, apiIdentifier)
+}
+
+func apiIdentifierFromContext(ctx context.Context) model.APIIdentifier {
+	apiIdentifier, _ := ctx.Value(apiIdentifierKey).(model.APIIdentifier)
+	return apiIdentifier
 }
 
-func drainBody(b io.ReadCloser) (r1, r2 io.ReadCloser, err error) {
-	if b == http.NoBody {
-		return http.NoBody, http.NoBody, nil
+// copyRequestBody buffers the request body and returns the reader of the same content for the retry.
+// It returns false if the body isn't buffered because it exceeds the memory limit of the stream mode.
+func copyRequestBody(r *http.Request, buffering model.RequestBodyBuffering) (io.ReadCloser, bool, apperrors.AppError) {
+	if r.Body == nil {
+		return nil, true, nil
+	}
+	if r.Body == http.NoBody {
+		return http.NoBody, true, nil
 	}
-	var buf bytes.Buffer
-	if _, err = buf.ReadFrom(b); err != nil {
-		return nil, b, err
+	if body, ok := r.Body.(replayableBody); ok {
+		return body.replay(), true, nil
 	}
-	if err = b.Close(); err != nil {
-		return nil, b, err
+
+	bodyCopy, secondRequestBody, replayable, err := bufferBody(r.Body, buffering)
+	if err != nil {
+		return nil, false, apperrors.Internalf("failed to drain request body, %s", err)
 	}
-	return io.NopCloser(&buf), io.NopCloser(bytes.NewReader(buf.Bytes())), nil
+	r.Body = bodyCopy
+
+	return secondRequestBody, replayable, nil
 }
 
 func handleErrors(w http.ResponseWriter, apperr apperrors.AppError) {
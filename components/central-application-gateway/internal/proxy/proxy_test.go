@@ -1,26 +1,42 @@
 package proxy
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"encoding/json"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/accesscontrol"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/accesslog"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/circuitbreaker"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf"
 	csrfMock "github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf/mocks"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
 	metadatamodel "github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metrics"
 	proxyMocks "github.com/kyma-project/kyma/components/central-application-gateway/internal/proxy/mocks"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/responsecache"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/tracing"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
 	authMock "github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/mocks"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httptools"
 )
 
 func TestProxyRequest(t *testing.T) {
@@ -242,6 +258,7 @@ func TestProxyRequest(t *testing.T) {
 			csrfFactoryMock, csrfStrategyMock := mockCSRFStrategy(authStrategyMock, calledOnce, tc.skipTLSVerify)
 
 			apiExtractorMock := &proxyMocks.APIExtractor{}
+			apiExtractorMock.On("GetAccessControl", mock.Anything).Return(nil, nil)
 			apiExtractorMock.On("Get", metadatamodel.APIIdentifier{
 				Application: "app",
 				Service:     "service",
@@ -316,6 +333,34 @@ func TestProxy(t *testing.T) {
 		return url, nil
 	}
 
+	t.Run("should record calls to APIs which don't exist with the unknown API labels", func(t *testing.T) {
+		// given
+		scanPathExtractor := func(u *url.URL) (metadatamodel.APIIdentifier, *url.URL, *url.URL, apperrors.AppError) {
+			return metadatamodel.APIIdentifier{Application: "wp-admin", Service: "setup", Entry: "php"}, u, u, nil
+		}
+
+		apiExtractorMock := &proxyMocks.APIExtractor{}
+		apiExtractorMock.On("GetAccessControl", mock.Anything).Return(nil, nil)
+		apiExtractorMock.On("Get", mock.Anything).Return(nil, apperrors.NotFound("API not found"))
+
+		handler := newProxyForTest(apiExtractorMock, &authMock.StrategyFactory{}, &csrfMock.TokenStrategyFactory{}, scanPathExtractor, fakeGwExtractor, createProxyConfig(proxyTimeout))
+
+		req, err := http.NewRequest(http.MethodGet, "/wp-admin/setup/php", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+
+		metricsRecorder := httptest.NewRecorder()
+		metrics.NewHandler().ServeHTTP(metricsRecorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		assert.NotContains(t, metricsRecorder.Body.String(), "wp-admin")
+		assert.Contains(t, metricsRecorder.Body.String(), `central_application_gateway_requests_total{application="unknown",code="404",entry="unknown",method="GET",service="unknown"}`)
+	})
+
 	t.Run("should fail with Bad Gateway error when failed to get OAuth token", func(t *testing.T) {
 		// given
 		ts := NewTestServer(func(req *http.Request) {
@@ -339,6 +384,7 @@ func TestProxy(t *testing.T) {
 		csrfFactoryMock, csrfStrategyMock := neverCalledCSRFStrategy(authStrategyMock)
 
 		apiExtractorMock := &proxyMocks.APIExtractor{}
+		apiExtractorMock.On("GetAccessControl", mock.Anything).Return(nil, nil)
 		apiExtractorMock.On("Get", apiIdentifier).Return(&metadatamodel.API{
 			TargetUrl: ts.URL,
 			Credentials: &authorization.Credentials{
@@ -383,6 +429,7 @@ func TestProxy(t *testing.T) {
 		req.AddCookie(&http.Cookie{Name: "user-cookie", Value: "user-cookie-value"})
 
 		apiExtractorMock := &proxyMocks.APIExtractor{}
+		apiExtractorMock.On("GetAccessControl", mock.Anything).Return(nil, nil)
 		apiExtractorMock.On("Get", apiIdentifier).Return(&metadatamodel.API{
 			TargetUrl:   tsf.URL,
 			Credentials: &authorization.Credentials{},
@@ -402,7 +449,7 @@ func TestProxy(t *testing.T) {
 		authStrategyFactoryMock.On("Create", mock.Anything).Return(authStrategyMock)
 
 		csrfTokenStrategyFactoryMock := &csrfMock.TokenStrategyFactory{}
-		csrfTokenStrategyFactoryMock.On("Create", authStrategyMock, "").Return(csrfTokenStrategyMock)
+		csrfTokenStrategyFactoryMock.On("Create", authStrategyMock, "", (*httptools.ConnectionSettings)(nil)).Return(csrfTokenStrategyMock)
 
 		handler := newProxyForTest(apiExtractorMock, authStrategyFactoryMock, csrfTokenStrategyFactoryMock, fakePathExtractor, fakeGwExtractor, createProxyConfig(proxyTimeout))
 		rr := httptest.NewRecorder()
@@ -445,6 +492,983 @@ func TestProxy(t *testing.T) {
 
 		}, requestBody, http.StatusForbidden, t)
 	})
+
+	t.Run("should reject calls with Service Unavailable when circuit breaker is open", func(t *testing.T) {
+		// given
+		var callCount int
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			callCount++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+
+		apiExtractorMock := &proxyMocks.APIExtractor{}
+		apiExtractorMock.On("GetAccessControl", mock.Anything).Return(nil, nil)
+		apiExtractorMock.On("Get", apiIdentifier).Return(&metadatamodel.API{TargetUrl: ts.URL}, nil)
+
+		authStrategyMock := &authMock.Strategy{}
+		authStrategyMock.
+			On("AddAuthorization", mock.Anything, mock.AnythingOfType("SetClientCertificateFunc"), false).
+			Return(nil)
+
+		csrfTokenStrategyMock := &csrfMock.TokenStrategy{}
+		csrfTokenStrategyMock.On("AddCSRFToken", mock.AnythingOfType("*http.Request"), false).Return(nil)
+
+		authStrategyFactoryMock := &authMock.StrategyFactory{}
+		authStrategyFactoryMock.On("Create", mock.Anything).Return(authStrategyMock)
+
+		csrfTokenStrategyFactoryMock := &csrfMock.TokenStrategyFactory{}
+		csrfTokenStrategyFactoryMock.On("Create", authStrategyMock, "", (*httptools.ConnectionSettings)(nil)).Return(csrfTokenStrategyMock)
+
+		proxyConfig := createProxyConfig(proxyTimeout)
+		proxyConfig.CircuitBreakers = circuitbreaker.New(circuitbreaker.Config{FailureThreshold: 2, OpenDuration: time.Minute})
+		handler := newProxyForTest(apiExtractorMock, authStrategyFactoryMock, csrfTokenStrategyFactoryMock, fakePathExtractor, fakeGwExtractor, proxyConfig)
+
+		for i := 0; i < 2; i++ {
+			req, err := http.NewRequest(http.MethodGet, "/orders/123", nil)
+			require.NoError(t, err)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			require.Equal(t, http.StatusBadGateway, rr.Code)
+		}
+
+		req, err := http.NewRequest(http.MethodGet, "/orders/123", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		assert.Equal(t, httpconsts.ContentTypeApplicationJson, rr.Header().Get(httpconsts.HeaderContentType))
+		assert.Contains(t, rr.Body.String(), "circuit breaker is open")
+		assert.Equal(t, 2, callCount)
+		authStrategyMock.AssertNumberOfCalls(t, "AddAuthorization", 2)
+		csrfTokenStrategyMock.AssertNumberOfCalls(t, "AddCSRFToken", 2)
+	})
+
+	t.Run("should reject calls with Too Many Requests when rate limit is exceeded", func(t *testing.T) {
+		// given
+		var callCount int
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			callCount++
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+
+		apiExtractorMock := &proxyMocks.APIExtractor{}
+		apiExtractorMock.On("GetAccessControl", mock.Anything).Return(nil, nil)
+		apiExtractorMock.On("Get", apiIdentifier).Return(&metadatamodel.API{
+			TargetUrl:            ts.URL,
+			ApplicationRateLimit: &metadatamodel.RateLimit{Requests: 10, Period: time.Minute},
+			ServiceRateLimit:     &metadatamodel.RateLimit{Requests: 1, Period: time.Minute},
+		}, nil)
+
+		authStrategyMock := &authMock.Strategy{}
+		authStrategyMock.
+			On("AddAuthorization", mock.Anything, mock.AnythingOfType("SetClientCertificateFunc"), false).
+			Return(nil).Once()
+
+		csrfTokenStrategyMock := &csrfMock.TokenStrategy{}
+		csrfTokenStrategyMock.On("AddCSRFToken", mock.AnythingOfType("*http.Request"), false).Return(nil).Once()
+
+		authStrategyFactoryMock := &authMock.StrategyFactory{}
+		authStrategyFactoryMock.On("Create", mock.Anything).Return(authStrategyMock)
+
+		csrfTokenStrategyFactoryMock := &csrfMock.TokenStrategyFactory{}
+		csrfTokenStrategyFactoryMock.On("Create", authStrategyMock, "", (*httptools.ConnectionSettings)(nil)).Return(csrfTokenStrategyMock)
+
+		handler := newProxyForTest(apiExtractorMock, authStrategyFactoryMock, csrfTokenStrategyFactoryMock, fakePathExtractor, fakeGwExtractor, createProxyConfig(proxyTimeout))
+
+		req, err := http.NewRequest(http.MethodGet, "/orders/123", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		req, err = http.NewRequest(http.MethodGet, "/orders/123", nil)
+		require.NoError(t, err)
+		rr = httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+		assert.Equal(t, "60", rr.Header().Get(httpconsts.HeaderRetryAfter))
+		assert.Contains(t, rr.Body.String(), "rate limit of the service exceeded")
+		assert.Equal(t, 1, callCount)
+		authStrategyMock.AssertExpectations(t)
+	})
+
+	t.Run("should reject calls with Forbidden when the caller isn't allowed by access control", func(t *testing.T) {
+		// given
+		var callerTokens []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			callerTokens = append(callerTokens, r.Header.Get(httpconsts.HeaderCallerToken))
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+
+		apiExtractorMock := &proxyMocks.APIExtractor{}
+		apiExtractorMock.On("GetAccessControl", "app").Return(&metadatamodel.AccessControl{
+			SPIFFEIDs: []string{"spiffe://cluster.local/ns/orders/sa/orders-client"},
+		}, nil)
+		apiExtractorMock.On("Get", apiIdentifier).Return(&metadatamodel.API{TargetUrl: ts.URL}, nil)
+
+		authStrategyMock := &authMock.Strategy{}
+		authStrategyMock.
+			On("AddAuthorization", mock.Anything, mock.AnythingOfType("SetClientCertificateFunc"), false).
+			Return(nil).Once()
+
+		csrfTokenStrategyMock := &csrfMock.TokenStrategy{}
+		csrfTokenStrategyMock.On("AddCSRFToken", mock.AnythingOfType("*http.Request"), false).Return(nil).Once()
+
+		authStrategyFactoryMock := &authMock.StrategyFactory{}
+		authStrategyFactoryMock.On("Create", mock.Anything).Return(authStrategyMock)
+
+		csrfTokenStrategyFactoryMock := &csrfMock.TokenStrategyFactory{}
+		csrfTokenStrategyFactoryMock.On("Create", authStrategyMock, "", (*httptools.ConnectionSettings)(nil)).Return(csrfTokenStrategyMock)
+
+		config := createProxyConfig(proxyTimeout)
+		config.Authorizer = accesscontrol.NewAuthorizer(nil, accesscontrol.Config{TrustForwardedClientCert: true})
+		handler := newProxyForTest(apiExtractorMock, authStrategyFactoryMock, csrfTokenStrategyFactoryMock, fakePathExtractor, fakeGwExtractor, config)
+
+		req, err := http.NewRequest(http.MethodGet, "/orders/123", nil)
+		require.NoError(t, err)
+		req.Header.Set(httpconsts.HeaderXForwardedClientCert, "URI=spiffe://cluster.local/ns/orders/sa/orders-client")
+		req.Header.Set(httpconsts.HeaderCallerToken, "token")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		req, err = http.NewRequest(http.MethodGet, "/orders/123", nil)
+		require.NoError(t, err)
+		req.Header.Set(httpconsts.HeaderXForwardedClientCert, "URI=spiffe://cluster.local/ns/default/sa/default")
+		rr = httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		assert.Equal(t, httpconsts.ContentTypeApplicationJson, rr.Header().Get(httpconsts.HeaderContentType))
+		assert.JSONEq(t, `{"code":403,"error":"caller with SPIFFE ID spiffe://cluster.local/ns/default/sa/default isn't allowed to call the APIs of the Application 'app'"}`, rr.Body.String())
+		assert.Equal(t, []string{""}, callerTokens, "the caller token should be stripped")
+		apiExtractorMock.AssertNumberOfCalls(t, "Get", 1)
+		authStrategyMock.AssertExpectations(t)
+	})
+
+	t.Run("should continue the trace of the caller in the call to the target system", func(t *testing.T) {
+		// given
+		spanRecorder := tracetest.NewSpanRecorder()
+		previousProvider := otel.GetTracerProvider()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)))
+		defer otel.SetTracerProvider(previousProvider)
+		_, err := tracing.Init(context.Background(), "test", "")
+		require.NoError(t, err)
+
+		const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		var targetTraceParent string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			targetTraceParent = r.Header.Get("traceparent")
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+
+		apiExtractorMock := &proxyMocks.APIExtractor{}
+		apiExtractorMock.On("GetAccessControl", mock.Anything).Return(nil, nil)
+		apiExtractorMock.On("Get", apiIdentifier).Return(&metadatamodel.API{TargetUrl: ts.URL}, nil)
+
+		authStrategyMock := &authMock.Strategy{}
+		authStrategyMock.
+			On("AddAuthorization", mock.Anything, mock.AnythingOfType("SetClientCertificateFunc"), false).
+			Return(nil)
+
+		authStrategyFactoryMock := &authMock.StrategyFactory{}
+		authStrategyFactoryMock.On("Create", mock.Anything).Return(authStrategyMock)
+
+		csrfTokenStrategyFactoryMock, _ := mockCSRFStrategy(authStrategyMock, calledOnce, false)
+
+		handler := tracing.NewHandler(newProxyForTest(apiExtractorMock, authStrategyFactoryMock, csrfTokenStrategyFactoryMock, fakePathExtractor, fakeGwExtractor, createProxyConfig(proxyTimeout)), "test")
+
+		req, err := http.NewRequest(http.MethodGet, "/orders/123", nil)
+		require.NoError(t, err)
+		req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+		rr := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, targetTraceParent, traceID)
+
+		spanNames := map[string]bool{}
+		for _, span := range spanRecorder.Ended() {
+			assert.Equal(t, traceID, span.SpanContext().TraceID().String())
+			spanNames[span.Name()] = true
+		}
+		assert.True(t, spanNames[spanAuthorization])
+		assert.True(t, spanNames[spanCSRFToken])
+		assert.True(t, spanNames[spanUpstreamCall])
+	})
+
+	t.Run("should write the access log record of the call without the values of the request parameters", func(t *testing.T) {
+		// given
+		var callCount int
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			callCount++
+			if callCount == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte("ok"))
+		}))
+		defer ts.Close()
+
+		apiExtractorMock := &proxyMocks.APIExtractor{}
+		apiExtractorMock.On("GetAccessControl", mock.Anything).Return(nil, nil)
+		apiExtractorMock.On("Get", apiIdentifier).Return(&metadatamodel.API{
+			TargetUrl: ts.URL,
+			Credentials: &authorization.Credentials{
+				BasicAuth: &authorization.BasicAuth{Username: "user", Password: "password"},
+			},
+			RequestParameters: &authorization.RequestParameters{
+				QueryParameters: &map[string][]string{"api_secret": {"secret-value"}},
+			},
+			RetryPolicy: &metadatamodel.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+		}, nil)
+
+		authStrategyMock := &authMock.Strategy{}
+		authStrategyMock.
+			On("AddAuthorization", mock.Anything, mock.AnythingOfType("SetClientCertificateFunc"), false).
+			Return(nil)
+
+		authStrategyFactoryMock := &authMock.StrategyFactory{}
+		authStrategyFactoryMock.On("Create", mock.Anything).Return(authStrategyMock)
+
+		csrfTokenStrategyFactoryMock, _ := mockCSRFStrategy(authStrategyMock, calledOnce, false)
+
+		var out bytes.Buffer
+		accessLog, err := accesslog.New(accesslog.Config{Format: accesslog.FormatJSON, SampleRate: 1}, &out)
+		require.NoError(t, err)
+		handler := accessLog.NewHandler(newProxyForTest(apiExtractorMock, authStrategyFactoryMock, csrfTokenStrategyFactoryMock, fakePathExtractor, fakeGwExtractor, createProxyConfig(proxyTimeout)))
+
+		req, err := http.NewRequest(http.MethodGet, "/orders/123", nil)
+		require.NoError(t, err)
+		req.Header.Set(httpconsts.HeaderRequestID, "request-1")
+		rr := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.NotContains(t, out.String(), "secret-value")
+
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal(out.Bytes(), &record))
+		assert.Equal(t, "request-1", record["requestID"])
+		assert.Equal(t, "app", record["application"])
+		assert.Equal(t, "service", record["service"])
+		assert.Equal(t, "entry", record["entry"])
+		assert.Equal(t, "BasicAuth", record["authStrategy"])
+		assert.Equal(t, ts.URL+"/orders/123?api_secret="+accesslog.Redacted, record["upstreamURL"])
+		assert.Equal(t, float64(http.StatusOK), record["upstreamStatus"])
+		assert.Equal(t, float64(http.StatusOK), record["status"])
+		assert.Equal(t, float64(2), record["bytesOut"])
+		assert.Equal(t, float64(1), record["retries"])
+	})
+
+	t.Run("should write the access log record of the call without the API key", func(t *testing.T) {
+		// given
+		ts := NewTestServer(func(req *http.Request) {
+			assert.Equal(t, "key-value", req.URL.Query().Get("subscription"))
+		})
+		defer ts.Close()
+
+		apiExtractorMock := &proxyMocks.APIExtractor{}
+		apiExtractorMock.On("GetAccessControl", mock.Anything).Return(nil, nil)
+		apiExtractorMock.On("Get", apiIdentifier).Return(&metadatamodel.API{
+			TargetUrl: ts.URL,
+			Credentials: &authorization.Credentials{
+				APIKey: &authorization.APIKey{Key: "key-value", Name: "subscription", Placement: authorization.APIKeyPlacementQuery},
+			},
+		}, nil)
+
+		csrfTokenStrategyMock := &csrfMock.TokenStrategy{}
+		csrfTokenStrategyMock.On("AddCSRFToken", mock.AnythingOfType("*http.Request"), false).Return(nil)
+
+		csrfTokenStrategyFactoryMock := &csrfMock.TokenStrategyFactory{}
+		csrfTokenStrategyFactoryMock.On("Create", mock.Anything, "", mock.Anything).Return(csrfTokenStrategyMock)
+
+		var out bytes.Buffer
+		accessLog, err := accesslog.New(accesslog.Config{Format: accesslog.FormatJSON, SampleRate: 1}, &out)
+		require.NoError(t, err)
+		authStrategyFactory := authorization.NewStrategyFactory(authorization.FactoryConfiguration{OAuthClientTimeout: proxyTimeout})
+		handler := accessLog.NewHandler(newProxyForTest(apiExtractorMock, authStrategyFactory, csrfTokenStrategyFactoryMock, fakePathExtractor, fakeGwExtractor, createProxyConfig(proxyTimeout)))
+
+		req, err := http.NewRequest(http.MethodGet, "/orders/123", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.NotContains(t, out.String(), "key-value")
+
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal(out.Bytes(), &record))
+		assert.Equal(t, "APIKey", record["authStrategy"])
+		assert.Equal(t, ts.URL+"/orders/123?subscription="+accesslog.Redacted, record["upstreamURL"])
+	})
+
+	t.Run("should apply the transformations of the API to the call and the response", func(t *testing.T) {
+		// given
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v2/orders/123", r.URL.Path)
+			assert.Equal(t, "Basic dXNlcjpwYXNzd29yZA==", r.Header.Get(httpconsts.HeaderAuthorization))
+			assert.Empty(t, r.Header.Get("Cookie"))
+			assert.Empty(t, r.Header.Get("X-Tenant"))
+			assert.Equal(t, "tenant", r.Header.Get("X-Customer"))
+			assert.Equal(t, "kyma", r.Header.Get("X-Source"))
+
+			w.Header().Set("Server", "target")
+			_, _ = w.Write([]byte("ok"))
+		}))
+		defer ts.Close()
+
+		apiExtractorMock := &proxyMocks.APIExtractor{}
+		apiExtractorMock.On("GetAccessControl", mock.Anything).Return(nil, nil)
+		apiExtractorMock.On("Get", apiIdentifier).Return(&metadatamodel.API{
+			TargetUrl: ts.URL,
+			Transformations: &metadatamodel.Transformations{
+				StripRequestHeaders: []string{"Authorization", "Cookie"},
+				PathRewrites:        []metadatamodel.PathRewrite{{Regex: regexp.MustCompile("^/v1/"), Replacement: "/api/v2/"}},
+				RequestHeaders: &metadatamodel.HeaderTransformations{
+					Rename: map[string]string{"X-Tenant": "X-Customer"},
+					Add:    map[string]string{"X-Source": "kyma"},
+				},
+				ResponseHeaders: &metadatamodel.HeaderTransformations{
+					Remove: []string{"Server"},
+					Add:    map[string]string{"X-Proxied-By": "kyma"},
+				},
+			},
+		}, nil)
+
+		authStrategyMock := &authMock.Strategy{}
+		authStrategyMock.
+			On("AddAuthorization", mock.Anything, mock.AnythingOfType("SetClientCertificateFunc"), false).
+			Run(func(args mock.Arguments) {
+				req := args.Get(0).(*http.Request)
+				assert.Empty(t, req.Header.Get(httpconsts.HeaderAuthorization))
+				req.SetBasicAuth("user", "password")
+			}).
+			Return(nil)
+
+		authStrategyFactoryMock := &authMock.StrategyFactory{}
+		authStrategyFactoryMock.On("Create", mock.Anything).Return(authStrategyMock)
+
+		csrfTokenStrategyFactoryMock, _ := mockCSRFStrategy(authStrategyMock, calledOnce, false)
+
+		handler := newProxyForTest(apiExtractorMock, authStrategyFactoryMock, csrfTokenStrategyFactoryMock, fakePathExtractor, fakeGwExtractor, createProxyConfig(proxyTimeout))
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/orders/123", nil)
+		require.NoError(t, err)
+		req.Header.Set(httpconsts.HeaderAuthorization, "Bearer caller-token")
+		req.Header.Set("Cookie", "session=caller")
+		req.Header.Set("X-Tenant", "tenant")
+		rr := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Empty(t, rr.Header().Get("Server"))
+		assert.Equal(t, "kyma", rr.Header().Get("X-Proxied-By"))
+	})
+
+	t.Run("should pass through the WebSocket upgrade with credentials in the pass-through mode", func(t *testing.T) {
+		// given
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "websocket", r.Header.Get("Upgrade"))
+			assert.Equal(t, "Basic dXNlcjpwYXNzd29yZA==", r.Header.Get(httpconsts.HeaderAuthorization))
+
+			conn, rw, err := http.NewResponseController(w).Hijack()
+			require.NoError(t, err)
+			defer conn.Close()
+
+			_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n\r\n")
+			_ = rw.Flush()
+
+			message, err := rw.ReadString('\n')
+			require.NoError(t, err)
+			_, _ = rw.WriteString("echo: " + message)
+			_ = rw.Flush()
+		}))
+		defer ts.Close()
+
+		apiExtractorMock := &proxyMocks.APIExtractor{}
+		apiExtractorMock.On("GetAccessControl", mock.Anything).Return(nil, nil)
+		apiExtractorMock.On("Get", apiIdentifier).Return(&metadatamodel.API{
+			TargetUrl:   ts.URL,
+			RetryPolicy: &metadatamodel.RetryPolicy{MaxAttempts: 3},
+			ProxyMode:   metadatamodel.ProxyModePassThrough,
+		}, nil)
+
+		authStrategyMock := &authMock.Strategy{}
+		authStrategyMock.
+			On("AddAuthorization", mock.Anything, mock.AnythingOfType("SetClientCertificateFunc"), false).
+			Run(func(args mock.Arguments) {
+				args.Get(0).(*http.Request).SetBasicAuth("user", "password")
+			}).
+			Return(nil)
+
+		authStrategyFactoryMock := &authMock.StrategyFactory{}
+		authStrategyFactoryMock.On("Create", mock.Anything).Return(authStrategyMock)
+
+		csrfTokenStrategyFactoryMock, _ := mockCSRFStrategy(authStrategyMock, calledOnce, false)
+
+		gateway := httptest.NewServer(newProxyForTest(apiExtractorMock, authStrategyFactoryMock, csrfTokenStrategyFactoryMock, fakePathExtractor, fakeGwExtractor, createProxyConfig(proxyTimeout)))
+		defer gateway.Close()
+
+		conn, err := net.Dial("tcp", gateway.Listener.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+
+		// when
+		_, err = conn.Write([]byte("GET /notifications HTTP/1.1\r\nHost: gateway\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n\r\n"))
+		require.NoError(t, err)
+		resp, err := http.ReadResponse(reader, nil)
+		require.NoError(t, err)
+
+		_, err = conn.Write([]byte("hello\n"))
+		require.NoError(t, err)
+		message, err := reader.ReadString('\n')
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+		assert.Equal(t, "echo: hello\n", message)
+	})
+
+	t.Run("should not retry calls in the pass-through mode", func(t *testing.T) {
+		// given
+		var callCount int
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			callCount++
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Equal(t, "streamed body", string(body))
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer ts.Close()
+
+		apiExtractorMock := &proxyMocks.APIExtractor{}
+		apiExtractorMock.On("GetAccessControl", mock.Anything).Return(nil, nil)
+		apiExtractorMock.On("Get", apiIdentifier).Return(&metadatamodel.API{
+			TargetUrl:   ts.URL,
+			RetryPolicy: &metadatamodel.RetryPolicy{MaxAttempts: 3, RetryableStatusCodes: []int{http.StatusUnauthorized}},
+			ProxyMode:   metadatamodel.ProxyModePassThrough,
+		}, nil)
+
+		authStrategyMock := &authMock.Strategy{}
+		authStrategyMock.
+			On("AddAuthorization", mock.Anything, mock.AnythingOfType("SetClientCertificateFunc"), false).
+			Return(nil).
+			Once()
+
+		authStrategyFactoryMock := &authMock.StrategyFactory{}
+		authStrategyFactoryMock.On("Create", mock.Anything).Return(authStrategyMock)
+
+		csrfTokenStrategyFactoryMock, _ := mockCSRFStrategy(authStrategyMock, calledOnce, false)
+
+		handler := newProxyForTest(apiExtractorMock, authStrategyFactoryMock, csrfTokenStrategyFactoryMock, fakePathExtractor, fakeGwExtractor, createProxyConfig(proxyTimeout))
+
+		req, err := http.NewRequest(http.MethodPut, "/orders/123", bytes.NewBufferString("streamed body"))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Equal(t, 1, callCount)
+		authStrategyMock.AssertExpectations(t)
+	})
+
+	t.Run("should fail calls with streamed bodies to AWS services which accept only signed payloads", func(t *testing.T) {
+		// given
+		var callCount int
+		ts := NewTestServer(func(req *http.Request) {
+			callCount++
+		})
+		defer ts.Close()
+
+		apiExtractorMock := &proxyMocks.APIExtractor{}
+		apiExtractorMock.On("GetAccessControl", mock.Anything).Return(nil, nil)
+		apiExtractorMock.On("Get", apiIdentifier).Return(&metadatamodel.API{
+			TargetUrl: ts.URL,
+			Credentials: &authorization.Credentials{
+				AWSSigV4: &authorization.AWSSigV4{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret", Region: "eu-central-1", Service: "execute-api"},
+			},
+			ProxyMode: metadatamodel.ProxyModePassThrough,
+		}, nil)
+
+		csrfTokenStrategyMock := &csrfMock.TokenStrategy{}
+		csrfTokenStrategyMock.On("AddCSRFToken", mock.AnythingOfType("*http.Request"), false).Return(nil)
+
+		csrfTokenStrategyFactoryMock := &csrfMock.TokenStrategyFactory{}
+		csrfTokenStrategyFactoryMock.On("Create", mock.Anything, "", mock.Anything).Return(csrfTokenStrategyMock)
+
+		authStrategyFactory := authorization.NewStrategyFactory(authorization.FactoryConfiguration{OAuthClientTimeout: proxyTimeout})
+		handler := newProxyForTest(apiExtractorMock, authStrategyFactory, csrfTokenStrategyFactoryMock, fakePathExtractor, fakeGwExtractor, createProxyConfig(proxyTimeout))
+
+		req := httptest.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString("streamed body"))
+		rr := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Contains(t, rr.Body.String(), "execute-api")
+		assert.Zero(t, callCount)
+	})
+
+	t.Run("should forward calls with trailers over h2c in the HTTP2 mode", func(t *testing.T) {
+		// given
+		ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, 2, r.ProtoMajor)
+			assert.Equal(t, "Basic dXNlcjpwYXNzd29yZA==", r.Header.Get(httpconsts.HeaderAuthorization))
+
+			w.Header().Set("Trailer", "Grpc-Status")
+			w.Header().Set(httpconsts.HeaderContentType, "application/grpc")
+			_, _ = w.Write([]byte("message"))
+			w.Header().Set("Grpc-Status", "0")
+		}))
+		ts.Config.Protocols = new(http.Protocols)
+		ts.Config.Protocols.SetUnencryptedHTTP2(true)
+		ts.Start()
+		defer ts.Close()
+
+		apiExtractorMock := &proxyMocks.APIExtractor{}
+		apiExtractorMock.On("GetAccessControl", mock.Anything).Return(nil, nil)
+		apiExtractorMock.On("Get", apiIdentifier).Return(&metadatamodel.API{
+			TargetUrl: ts.URL,
+			ProxyMode: metadatamodel.ProxyModeHTTP2,
+		}, nil)
+
+		authStrategyMock := &authMock.Strategy{}
+		authStrategyMock.
+			On("AddAuthorization", mock.Anything, mock.AnythingOfType("SetClientCertificateFunc"), false).
+			Run(func(args mock.Arguments) {
+				args.Get(0).(*http.Request).SetBasicAuth("user", "password")
+			}).
+			Return(nil)
+
+		authStrategyFactoryMock := &authMock.StrategyFactory{}
+		authStrategyFactoryMock.On("Create", mock.Anything).Return(authStrategyMock)
+
+		csrfTokenStrategyFactoryMock, _ := mockCSRFStrategy(authStrategyMock, calledOnce, false)
+
+		handler := newProxyForTest(apiExtractorMock, authStrategyFactoryMock, csrfTokenStrategyFactoryMock, fakePathExtractor, fakeGwExtractor, createProxyConfig(proxyTimeout))
+
+		req, err := http.NewRequest(http.MethodPost, "/orders.Orders/Get", bytes.NewBufferString("request"))
+		require.NoError(t, err)
+		req.Header.Set(httpconsts.HeaderContentType, "application/grpc")
+		rr := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		result := rr.Result()
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		assert.Equal(t, "message", rr.Body.String())
+		assert.Equal(t, "0", result.Trailer.Get("Grpc-Status"))
+	})
+
+	t.Run("should serve cached responses of GET calls and revalidate them with the target system", func(t *testing.T) {
+		// given
+		var callCount int
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			callCount++
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			_, _ = w.Write([]byte("orders"))
+		}))
+		defer ts.Close()
+
+		apiExtractorMock := &proxyMocks.APIExtractor{}
+		apiExtractorMock.On("GetAccessControl", mock.Anything).Return(nil, nil)
+		apiExtractorMock.On("Get", apiIdentifier).Return(&metadatamodel.API{
+			TargetUrl:     ts.URL,
+			ResponseCache: &metadatamodel.ResponseCache{},
+		}, nil)
+
+		authStrategyMock := &authMock.Strategy{}
+		authStrategyMock.
+			On("AddAuthorization", mock.Anything, mock.AnythingOfType("SetClientCertificateFunc"), false).
+			Return(nil)
+
+		authStrategyFactoryMock := &authMock.StrategyFactory{}
+		authStrategyFactoryMock.On("Create", mock.Anything).Return(authStrategyMock)
+
+		csrfTokenStrategyMock := &csrfMock.TokenStrategy{}
+		csrfTokenStrategyMock.On("AddCSRFToken", mock.AnythingOfType("*http.Request"), false).Return(nil)
+
+		csrfTokenStrategyFactoryMock := &csrfMock.TokenStrategyFactory{}
+		csrfTokenStrategyFactoryMock.On("Create", authStrategyMock, "", (*httptools.ConnectionSettings)(nil)).Return(csrfTokenStrategyMock)
+
+		proxyConfig := createProxyConfig(proxyTimeout)
+		proxyConfig.ResponseCache = responsecache.New(1024)
+		proxyConfig.ResponseCacheMaxEntrySize = 1024
+		handler := newProxyForTest(apiExtractorMock, authStrategyFactoryMock, csrfTokenStrategyFactoryMock, fakePathExtractor, fakeGwExtractor, proxyConfig)
+
+		call := func(header http.Header) *httptest.ResponseRecorder {
+			req, err := http.NewRequest(http.MethodGet, "/orders", nil)
+			require.NoError(t, err)
+			for name, values := range header {
+				req.Header[name] = values
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			return rr
+		}
+
+		// when
+		miss := call(nil)
+		hit := call(nil)
+		revalidated := call(http.Header{"Cache-Control": {"no-cache"}})
+		bypassed := call(http.Header{httpconsts.HeaderAccessToken: {"Bearer token"}})
+
+		// then
+		assert.Equal(t, http.StatusOK, miss.Code)
+		assert.Equal(t, "orders", miss.Body.String())
+		assert.Equal(t, "ApplicationGateway; fwd=miss; fwd-status=200", miss.Header().Get(httpconsts.HeaderCacheStatus))
+
+		assert.Equal(t, http.StatusOK, hit.Code)
+		assert.Equal(t, "orders", hit.Body.String())
+		assert.Contains(t, hit.Header().Get(httpconsts.HeaderCacheStatus), "ApplicationGateway; hit; ttl=")
+		assert.Equal(t, `"v1"`, hit.Header().Get("ETag"))
+
+		assert.Equal(t, http.StatusOK, revalidated.Code)
+		assert.Equal(t, "orders", revalidated.Body.String())
+		assert.Equal(t, "ApplicationGateway; fwd=request; fwd-status=304", revalidated.Header().Get(httpconsts.HeaderCacheStatus))
+
+		assert.Equal(t, http.StatusOK, bypassed.Code)
+		assert.Equal(t, "ApplicationGateway; fwd=bypass", bypassed.Header().Get(httpconsts.HeaderCacheStatus))
+
+		assert.Equal(t, 3, callCount)
+	})
+
+	t.Run("should fail over to the next target and rewrite the Location of the target which served the call", func(t *testing.T) {
+		// given
+		var primaryCallCount int
+		primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			primaryCallCount++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer primary.Close()
+
+		secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/orders/1", r.URL.Path)
+			w.Header().Set("Location", "http://"+r.Host+"/api/orders/2")
+			w.WriteHeader(http.StatusFound)
+		}))
+		defer secondary.Close()
+
+		apiExtractorMock := &proxyMocks.APIExtractor{}
+		apiExtractorMock.On("GetAccessControl", mock.Anything).Return(nil, nil)
+		apiExtractorMock.On("Get", apiIdentifier).Return(&metadatamodel.API{
+			TargetUrl: primary.URL,
+			Targets: []metadatamodel.Target{
+				{URL: secondary.URL + "/api", Priority: 1},
+				{URL: primary.URL},
+			},
+			LoadBalancing: &metadatamodel.LoadBalancing{Strategy: metadatamodel.LoadBalancingFailover, UnhealthyThreshold: 1},
+		}, nil)
+
+		authStrategyMock := &authMock.Strategy{}
+		authStrategyMock.
+			On("AddAuthorization", mock.Anything, mock.AnythingOfType("SetClientCertificateFunc"), false).
+			Return(nil)
+
+		authStrategyFactoryMock := &authMock.StrategyFactory{}
+		authStrategyFactoryMock.On("Create", mock.Anything).Return(authStrategyMock)
+
+		csrfTokenStrategyMock := &csrfMock.TokenStrategy{}
+		csrfTokenStrategyMock.On("AddCSRFToken", mock.AnythingOfType("*http.Request"), false).Return(nil)
+
+		csrfTokenStrategyFactoryMock := &csrfMock.TokenStrategyFactory{}
+		csrfTokenStrategyFactoryMock.On("Create", authStrategyMock, "", (*httptools.ConnectionSettings)(nil)).Return(csrfTokenStrategyMock)
+
+		handler := newProxyForTest(apiExtractorMock, authStrategyFactoryMock, csrfTokenStrategyFactoryMock, fakePathExtractor, fakeGwExtractor, createProxyConfig(proxyTimeout))
+
+		call := func() *httptest.ResponseRecorder {
+			req, err := http.NewRequest(http.MethodGet, "/orders/1", nil)
+			require.NoError(t, err)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			return rr
+		}
+
+		// when
+		failedOver := call()
+		skipped := call()
+
+		// then
+		assert.Equal(t, http.StatusFound, failedOver.Code)
+		assert.Equal(t, "/orders/2", failedOver.Header().Get("Location"))
+		assert.Equal(t, http.StatusFound, skipped.Code)
+		assert.Equal(t, 1, primaryCallCount, "unhealthy primary target should be skipped")
+	})
+	t.Run("should send the call through the proxy of the Application and pass it to the CSRF token strategy", func(t *testing.T) {
+		// given
+		var proxiedURL string
+		upstreamProxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxiedURL = r.URL.String()
+			_, _ = w.Write([]byte("ok"))
+		}))
+		defer upstreamProxy.Close()
+
+		proxyURL, err := url.Parse(upstreamProxy.URL)
+		require.NoError(t, err)
+		connection := &httptools.ConnectionSettings{Proxy: proxyURL}
+
+		apiExtractorMock := &proxyMocks.APIExtractor{}
+		apiExtractorMock.On("GetAccessControl", mock.Anything).Return(nil, nil)
+		apiExtractorMock.On("Get", apiIdentifier).Return(&metadatamodel.API{
+			TargetUrl:   "http://orders.internal.example.com",
+			Credentials: &authorization.Credentials{CSRFTokenEndpointURL: "http://orders.internal.example.com/csrf", Connection: connection},
+			Connection:  connection,
+		}, nil)
+
+		authStrategyMock := &authMock.Strategy{}
+		authStrategyMock.
+			On("AddAuthorization", mock.Anything, mock.AnythingOfType("SetClientCertificateFunc"), false).
+			Return(nil)
+
+		authStrategyFactoryMock := &authMock.StrategyFactory{}
+		authStrategyFactoryMock.On("Create", mock.Anything).Return(authStrategyMock)
+
+		csrfTokenStrategyMock := &csrfMock.TokenStrategy{}
+		csrfTokenStrategyMock.On("AddCSRFToken", mock.AnythingOfType("*http.Request"), false).Return(nil)
+
+		csrfTokenStrategyFactoryMock := &csrfMock.TokenStrategyFactory{}
+		csrfTokenStrategyFactoryMock.On("Create", authStrategyMock, "http://orders.internal.example.com/csrf", connection).Return(csrfTokenStrategyMock)
+
+		handler := newProxyForTest(apiExtractorMock, authStrategyFactoryMock, csrfTokenStrategyFactoryMock, fakePathExtractor, fakeGwExtractor, createProxyConfig(proxyTimeout))
+
+		req, err := http.NewRequest(http.MethodGet, "/orders/1", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "http://orders.internal.example.com/orders/1", proxiedURL)
+		csrfTokenStrategyFactoryMock.AssertExpectations(t)
+	})
+
+	t.Run("should exchange the bearer token of the user for the token of the target system", func(t *testing.T) {
+		// given
+		tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "urn:ietf:params:oauth:grant-type:token-exchange", r.PostForm.Get("grant_type"))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token":"exchanged-` + r.PostForm.Get("subject_token") + `","token_type":"bearer","expires_in":3600}`))
+		}))
+		defer tokenServer.Close()
+
+		ts := NewTestServer(func(req *http.Request) {
+			assert.Equal(t, "Bearer exchanged-user-token", req.Header.Get(httpconsts.HeaderAuthorization))
+		})
+		defer ts.Close()
+
+		apiExtractorMock := &proxyMocks.APIExtractor{}
+		apiExtractorMock.On("GetAccessControl", mock.Anything).Return(nil, nil)
+		apiExtractorMock.On("Get", apiIdentifier).Return(&metadatamodel.API{
+			TargetUrl: ts.URL,
+			Credentials: &authorization.Credentials{
+				OAuthTokenExchange: &authorization.OAuthTokenExchange{
+					ClientID:     "clientId",
+					ClientSecret: "clientSecret",
+					URL:          tokenServer.URL,
+				},
+			},
+		}, nil)
+
+		csrfTokenStrategyMock := &csrfMock.TokenStrategy{}
+		csrfTokenStrategyMock.On("AddCSRFToken", mock.AnythingOfType("*http.Request"), false).Return(nil)
+
+		csrfTokenStrategyFactoryMock := &csrfMock.TokenStrategyFactory{}
+		csrfTokenStrategyFactoryMock.On("Create", mock.Anything, "", mock.Anything).Return(csrfTokenStrategyMock)
+
+		authStrategyFactory := authorization.NewStrategyFactory(authorization.FactoryConfiguration{OAuthClientTimeout: proxyTimeout})
+		handler := newProxyForTest(apiExtractorMock, authStrategyFactory, csrfTokenStrategyFactoryMock, fakePathExtractor, fakeGwExtractor, createProxyConfig(proxyTimeout))
+
+		req, err := http.NewRequest(http.MethodGet, "/orders/1", nil)
+		require.NoError(t, err)
+		req.Header.Set(httpconsts.HeaderAuthorization, "Bearer user-token")
+		rr := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("should exchange the bearer token of the user when the API strips the Authorization header of the caller", func(t *testing.T) {
+		// given
+		tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, r.ParseForm())
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token":"exchanged-` + r.PostForm.Get("subject_token") + `","token_type":"bearer","expires_in":3600}`))
+		}))
+		defer tokenServer.Close()
+
+		ts := NewTestServer(func(req *http.Request) {
+			assert.Equal(t, []string{"Bearer exchanged-user-token"}, req.Header.Values(httpconsts.HeaderAuthorization))
+		})
+		defer ts.Close()
+
+		apiExtractorMock := &proxyMocks.APIExtractor{}
+		apiExtractorMock.On("GetAccessControl", mock.Anything).Return(nil, nil)
+		apiExtractorMock.On("Get", apiIdentifier).Return(&metadatamodel.API{
+			TargetUrl: ts.URL,
+			Credentials: &authorization.Credentials{
+				OAuthTokenExchange: &authorization.OAuthTokenExchange{
+					ClientID:     "clientId",
+					ClientSecret: "clientSecret",
+					URL:          tokenServer.URL,
+				},
+			},
+			Transformations: &metadatamodel.Transformations{
+				StripRequestHeaders: []string{"Authorization"},
+			},
+		}, nil)
+
+		csrfTokenStrategyMock := &csrfMock.TokenStrategy{}
+		csrfTokenStrategyMock.On("AddCSRFToken", mock.AnythingOfType("*http.Request"), false).Return(nil)
+
+		csrfTokenStrategyFactoryMock := &csrfMock.TokenStrategyFactory{}
+		csrfTokenStrategyFactoryMock.On("Create", mock.Anything, "", mock.Anything).Return(csrfTokenStrategyMock)
+
+		authStrategyFactory := authorization.NewStrategyFactory(authorization.FactoryConfiguration{OAuthClientTimeout: proxyTimeout})
+		handler := newProxyForTest(apiExtractorMock, authStrategyFactory, csrfTokenStrategyFactoryMock, fakePathExtractor, fakeGwExtractor, createProxyConfig(proxyTimeout))
+
+		req, err := http.NewRequest(http.MethodGet, "/orders/1", nil)
+		require.NoError(t, err)
+		req.Header.Set(httpconsts.HeaderAuthorization, "Bearer user-token")
+		rr := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("should drop the proxy and the credentials read from a source when the target system rejects them", func(t *testing.T) {
+		// given
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer ts.Close()
+
+		apiExtractorMock := &proxyMocks.APIExtractor{}
+		apiExtractorMock.On("GetAccessControl", mock.Anything).Return(nil, nil)
+		apiExtractorMock.On("Get", apiIdentifier).Return(&metadatamodel.API{
+			TargetUrl:          ts.URL,
+			SourcedSecretNames: []string{"broker:orders-credentials"},
+			ProxyMode:          metadatamodel.ProxyModePassThrough,
+		}, nil)
+
+		authStrategyMock := &authMock.Strategy{}
+		authStrategyMock.
+			On("AddAuthorization", mock.Anything, mock.AnythingOfType("SetClientCertificateFunc"), false).
+			Return(nil).
+			Once()
+		authStrategyMock.On("Invalidate").Return().Once()
+
+		csrfTokenStrategyMock := &csrfMock.TokenStrategy{}
+		csrfTokenStrategyMock.On("AddCSRFToken", mock.AnythingOfType("*http.Request"), false).Return(nil).Once()
+		csrfTokenStrategyMock.On("Invalidate").Return().Once()
+
+		authStrategyFactoryMock := &authMock.StrategyFactory{}
+		authStrategyFactoryMock.On("Create", mock.Anything).Return(authStrategyMock)
+
+		csrfTokenStrategyFactoryMock := &csrfMock.TokenStrategyFactory{}
+		csrfTokenStrategyFactoryMock.On("Create", authStrategyMock, "", (*httptools.ConnectionSettings)(nil)).Return(csrfTokenStrategyMock)
+
+		secretsCacheMock := &proxyMocks.SecretsCache{}
+		secretsCacheMock.On("Invalidate", "broker:orders-credentials").Return().Once()
+
+		cache := NewCache(proxyTimeout)
+		config := createProxyConfig(proxyTimeout)
+		config.Cache = cache
+		config.SecretsCache = secretsCacheMock
+		handler := newProxyForTest(apiExtractorMock, authStrategyFactoryMock, csrfTokenStrategyFactoryMock, fakePathExtractor, fakeGwExtractor, config)
+
+		req, err := http.NewRequest(http.MethodGet, "/orders/123", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		_, found := cache.Get(apiIdentifier.Application, apiIdentifier.Service, apiIdentifier.Entry)
+		assert.False(t, found)
+		secretsCacheMock.AssertExpectations(t)
+		authStrategyMock.AssertExpectations(t)
+		csrfTokenStrategyMock.AssertExpectations(t)
+	})
+
+	t.Run("should fail with Bad Request when the user calls the API with token exchange credentials without bearer token", func(t *testing.T) {
+		// given
+		apiExtractorMock := &proxyMocks.APIExtractor{}
+		apiExtractorMock.On("GetAccessControl", mock.Anything).Return(nil, nil)
+		apiExtractorMock.On("Get", apiIdentifier).Return(&metadatamodel.API{
+			TargetUrl: "http://orders.internal.example.com",
+			Credentials: &authorization.Credentials{
+				OAuthTokenExchange: &authorization.OAuthTokenExchange{URL: "http://orders.internal.example.com/token"},
+			},
+		}, nil)
+
+		csrfTokenStrategyFactoryMock := &csrfMock.TokenStrategyFactory{}
+		csrfTokenStrategyFactoryMock.On("Create", mock.Anything, "", mock.Anything).Return(&csrfMock.TokenStrategy{})
+
+		authStrategyFactory := authorization.NewStrategyFactory(authorization.FactoryConfiguration{OAuthClientTimeout: proxyTimeout})
+		handler := newProxyForTest(apiExtractorMock, authStrategyFactory, csrfTokenStrategyFactoryMock, fakePathExtractor, fakeGwExtractor, createProxyConfig(proxyTimeout))
+
+		req, err := http.NewRequest(http.MethodGet, "/orders/1", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
 }
 
 func assertCookie(t *testing.T, r *http.Request, name, value string) {
@@ -475,13 +1499,20 @@ func newProxyForTest(
 	proxyConfig Config) http.Handler {
 
 	return &proxy{
-		cache:                        NewCache(proxyConfig.ProxyCacheTTL),
+		cache:                        newCache(proxyConfig),
 		proxyTimeout:                 proxyConfig.ProxyTimeout,
 		authorizationStrategyFactory: authorizationStrategyFactory,
 		csrfTokenStrategyFactory:     csrfTokenStrategyFactory,
 		extractPathFunc:              pathExtractorFunc,
 		extractGatewayFunc:           extractGatewayFunc,
 		apiExtractor:                 apiExtractor,
+		circuitBreakers:              newCircuitBreakers(proxyConfig),
+		requestBodyMemoryLimit:       proxyConfig.RequestBodyMemoryLimit,
+		rateLimiters:                 newRateLimiters(proxyConfig),
+		responseCache:                proxyConfig.ResponseCache,
+		responseCacheMaxEntrySize:    proxyConfig.ResponseCacheMaxEntrySize,
+		authorizer:                   newAuthorizer(proxyConfig),
+		secretsCache:                 proxyConfig.SecretsCache,
 	}
 }
 
@@ -553,7 +1584,7 @@ func mockCSRFStrategy(
 	ef(strategyCall)
 
 	csrfTokenStrategyFactoryMock := &csrfMock.TokenStrategyFactory{}
-	csrfTokenStrategyFactoryMock.On("Create", authorizationStrategy, "").Return(csrfTokenStrategyMock).Once()
+	csrfTokenStrategyFactoryMock.On("Create", authorizationStrategy, "", (*httptools.ConnectionSettings)(nil)).Return(csrfTokenStrategyMock).Once()
 
 	return csrfTokenStrategyFactoryMock, csrfTokenStrategyMock
 }
@@ -562,7 +1593,7 @@ func neverCalledCSRFStrategy(authorizationStrategy authorization.Strategy) (*csr
 	csrfTokenStrategyMock := &csrfMock.TokenStrategy{}
 
 	csrfTokenStrategyFactoryMock := &csrfMock.TokenStrategyFactory{}
-	csrfTokenStrategyFactoryMock.On("Create", authorizationStrategy, "").Return(csrfTokenStrategyMock).Once()
+	csrfTokenStrategyFactoryMock.On("Create", authorizationStrategy, "", (*httptools.ConnectionSettings)(nil)).Return(csrfTokenStrategyMock).Once()
 
 	return csrfTokenStrategyFactoryMock, csrfTokenStrategyMock
 }
@@ -0,0 +1,155 @@
+package proxy
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+)
+
+// replayableBody is a buffered request body which can be read again without buffering it one more time
+type replayableBody interface {
+	io.ReadCloser
+	replay() io.ReadCloser
+}
+
+// bufferBody reads the body according to the buffering settings of the Application.
+// It returns the reader which replaces the original body, the reader with the same content for the retry,
+// and false if the body exceeds the memory limit in the stream mode and can't be sent again.
+func bufferBody(body io.ReadCloser, buffering model.RequestBodyBuffering) (io.ReadCloser, io.ReadCloser, bool, error) {
+	if buffering.Mode == "" || buffering.Mode == model.BodyBufferingMemory || buffering.MemoryLimit <= 0 {
+		data, err := readAndClose(body)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		return newMemoryBody(data), newMemoryBody(data), true, nil
+	}
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(io.LimitReader(body, buffering.MemoryLimit+1)); err != nil {
+		_ = body.Close()
+		return nil, nil, false, err
+	}
+	head := buf.Bytes()
+	if int64(len(head)) <= buffering.MemoryLimit {
+		if err := body.Close(); err != nil {
+			return nil, nil, false, err
+		}
+		return newMemoryBody(head), newMemoryBody(head), true, nil
+	}
+
+	if buffering.Mode == model.BodyBufferingStream {
+		return &streamedBody{Reader: io.MultiReader(bytes.NewReader(head), body), body: body}, nil, false, nil
+	}
+
+	file, err := spill(head, body)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	return file.newReader(), file.newReader(), true, nil
+}
+
+func readAndClose(body io.ReadCloser) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(body); err != nil {
+		_ = body.Close()
+		return nil, err
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+type memoryBody struct {
+	*bytes.Reader
+	data []byte
+}
+
+func newMemoryBody(data []byte) *memoryBody {
+	return &memoryBody{Reader: bytes.NewReader(data), data: data}
+}
+
+func (b *memoryBody) Close() error {
+	return nil
+}
+
+func (b *memoryBody) replay() io.ReadCloser {
+	return newMemoryBody(b.data)
+}
+
+// streamedBody passes the part of the body read while checking the memory limit and the rest of the original body
+type streamedBody struct {
+	io.Reader
+	body io.ReadCloser
+}
+
+func (b *streamedBody) Close() error {
+	return b.body.Close()
+}
+
+// spilledFile is a temporary file with the request body, closed when all its readers are closed
+type spilledFile struct {
+	file    *os.File
+	size    int64
+	readers int32
+}
+
+func spill(head []byte, rest io.ReadCloser) (*spilledFile, error) {
+	defer rest.Close()
+
+	file, err := os.CreateTemp("", "request-body-")
+	if err != nil {
+		return nil, err
+	}
+	// the content stays available through the open descriptor and is freed by the OS once it's closed
+	if err := os.Remove(file.Name()); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	size, err := io.Copy(file, io.MultiReader(bytes.NewReader(head), rest))
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return &spilledFile{file: file, size: size}, nil
+}
+
+func (f *spilledFile) newReader() io.ReadCloser {
+	atomic.AddInt32(&f.readers, 1)
+	return &fileBody{SectionReader: io.NewSectionReader(f.file, 0, f.size), file: f}
+}
+
+func (f *spilledFile) release() {
+	if atomic.AddInt32(&f.readers, -1) == 0 {
+		_ = f.file.Close()
+	}
+}
+
+type fileBody struct {
+	*io.SectionReader
+	file      *spilledFile
+	closeOnce sync.Once
+}
+
+func (b *fileBody) Close() error {
+	b.closeOnce.Do(b.file.release)
+	return nil
+}
+
+func (b *fileBody) replay() io.ReadCloser {
+	return b.file.newReader()
+}
+
+func closeBody(body io.ReadCloser) {
+	if body != nil {
+		_ = body.Close()
+	}
+}
@@ -0,0 +1,129 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+)
+
+func TestCopyRequestBody(t *testing.T) {
+	newRequest := func(body string) *http.Request {
+		req, err := http.NewRequest(http.MethodPost, "http://target.com", strings.NewReader(body))
+		require.NoError(t, err)
+		return req
+	}
+
+	readBody := func(body io.ReadCloser) string {
+		data, err := io.ReadAll(body)
+		require.NoError(t, err)
+		require.NoError(t, body.Close())
+		return string(data)
+	}
+
+	for _, tc := range []struct {
+		name      string
+		buffering model.RequestBodyBuffering
+		body      string
+		bodyType  interface{}
+	}{
+		{
+			name:      "should keep whole body in memory by default",
+			buffering: model.RequestBodyBuffering{},
+			body:      "some body",
+			bodyType:  &memoryBody{},
+		},
+		{
+			name:      "should keep body within memory limit in memory in File mode",
+			buffering: model.RequestBodyBuffering{Mode: model.BodyBufferingFile, MemoryLimit: 9},
+			body:      "some body",
+			bodyType:  &memoryBody{},
+		},
+		{
+			name:      "should write body exceeding memory limit to file in File mode",
+			buffering: model.RequestBodyBuffering{Mode: model.BodyBufferingFile, MemoryLimit: 4},
+			body:      "some body",
+			bodyType:  &fileBody{},
+		},
+		{
+			name:      "should keep body within memory limit in memory in Stream mode",
+			buffering: model.RequestBodyBuffering{Mode: model.BodyBufferingStream, MemoryLimit: 9},
+			body:      "some body",
+			bodyType:  &memoryBody{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			req := newRequest(tc.body)
+
+			// when
+			secondRequestBody, replayable, err := copyRequestBody(req, tc.buffering)
+
+			// then
+			require.NoError(t, err)
+			assert.True(t, replayable)
+			assert.IsType(t, tc.bodyType, req.Body)
+
+			thirdRequestBody, replayable, err := copyRequestBody(req, tc.buffering)
+			require.NoError(t, err)
+			assert.True(t, replayable)
+
+			assert.Equal(t, tc.body, readBody(req.Body))
+			assert.Equal(t, tc.body, readBody(secondRequestBody))
+			assert.Equal(t, tc.body, readBody(thirdRequestBody))
+		})
+	}
+
+	t.Run("should stream body exceeding memory limit without copy in Stream mode", func(t *testing.T) {
+		// given
+		req := newRequest("some body")
+
+		// when
+		secondRequestBody, replayable, err := copyRequestBody(req, model.RequestBodyBuffering{Mode: model.BodyBufferingStream, MemoryLimit: 4})
+
+		// then
+		require.NoError(t, err)
+		assert.False(t, replayable)
+		assert.Nil(t, secondRequestBody)
+		assert.Equal(t, "some body", readBody(req.Body))
+	})
+
+	t.Run("should close file when all readers are closed", func(t *testing.T) {
+		// given
+		req := newRequest("some body")
+		secondRequestBody, _, appErr := copyRequestBody(req, model.RequestBodyBuffering{Mode: model.BodyBufferingFile, MemoryLimit: 4})
+		require.NoError(t, appErr)
+		file := req.Body.(*fileBody).file.file
+
+		// when
+		require.NoError(t, req.Body.Close())
+		require.NoError(t, req.Body.Close())
+
+		// then
+		_, err := file.Stat()
+		require.NoError(t, err)
+
+		require.NoError(t, secondRequestBody.Close())
+		_, err = file.Stat()
+		assert.Error(t, err)
+	})
+
+	t.Run("should not copy request without body", func(t *testing.T) {
+		// given
+		req, err := http.NewRequest(http.MethodGet, "http://target.com", nil)
+		require.NoError(t, err)
+
+		// when
+		secondRequestBody, replayable, appErr := copyRequestBody(req, model.RequestBodyBuffering{})
+
+		// then
+		require.NoError(t, appErr)
+		assert.True(t, replayable)
+		assert.Nil(t, secondRequestBody)
+	})
+}
@@ -0,0 +1,190 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metrics"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/responsecache"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+)
+
+// cacheName identifies Application Gateway in the Cache-Status header, see RFC 9211
+const cacheName = "ApplicationGateway"
+
+// cachedCall is a call forwarded to the target system whose response can be stored in the response cache
+type cachedCall struct {
+	cache        responsecache.Cache
+	key          responsecache.Key
+	header       http.Header
+	maxEntrySize int64
+	// stale is the stored response validated with the target system, nil if the call isn't conditional
+	stale *responsecache.Response
+	// forward describes why the call is forwarded in the Cache-Status header
+	forward string
+}
+
+// serveFromResponseCache writes the fresh cached response of the call and returns true,
+// otherwise it returns the call whose response is stored, nil if the response cache isn't used
+func (p *proxy) serveFromResponseCache(w http.ResponseWriter, r *http.Request, apiIdentifier model.APIIdentifier, serviceAPI *model.API) (*cachedCall, bool) {
+	if p.responseCache == nil || serviceAPI.ResponseCache == nil || serviceAPI.IsPassThrough() || r.Method != http.MethodGet {
+		return nil, false
+	}
+
+	if !responsecache.Cacheable(r) {
+		metrics.ObserveResponseCache(apiIdentifier, metrics.CacheBypass)
+		w.Header().Set(httpconsts.HeaderCacheStatus, cacheName+"; fwd=bypass")
+		return nil, false
+	}
+
+	call := &cachedCall{
+		cache:        p.responseCache,
+		key:          responsecache.Key{API: apiIdentifier, URL: r.URL.RequestURI()},
+		header:       r.Header.Clone(),
+		maxEntrySize: serviceAPI.ResponseCache.MaxEntrySize,
+		forward:      "miss",
+	}
+	if call.maxEntrySize <= 0 {
+		call.maxEntrySize = p.responseCacheMaxEntrySize
+	}
+
+	stored, found := p.responseCache.Get(call.key, r.Header)
+	if !found {
+		metrics.ObserveResponseCache(apiIdentifier, metrics.CacheMiss)
+		return call, false
+	}
+
+	now := time.Now()
+	if stored.Fresh(now) && !responsecache.RequiresValidation(r) {
+		metrics.ObserveResponseCache(apiIdentifier, metrics.CacheHit)
+		writeCachedResponse(w, r, stored, now)
+		return nil, true
+	}
+
+	metrics.ObserveResponseCache(apiIdentifier, metrics.CacheStale)
+	call.forward = "stale"
+	if responsecache.RequiresValidation(r) {
+		call.forward = "request"
+	}
+	// the conditional headers of the caller are passed unchanged, so the stored response can't replace the response to them
+	if !responsecache.Conditional(r.Header) {
+		call.stale = stored
+	}
+
+	return call, false
+}
+
+func writeCachedResponse(w http.ResponseWriter, r *http.Request, stored *responsecache.Response, now time.Time) {
+	header := w.Header()
+	for name, values := range stored.Header {
+		header[name] = append([]string(nil), values...)
+	}
+	header.Set("Age", strconv.Itoa(int(stored.Age(now).Seconds())))
+	header.Set(httpconsts.HeaderCacheStatus, cacheName+"; hit; ttl="+strconv.Itoa(int(stored.TTL(now).Seconds())))
+
+	if stored.NotModified(r.Header) {
+		header.Del("Content-Length")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	header.Set("Content-Length", strconv.Itoa(len(stored.Body)))
+	w.WriteHeader(stored.StatusCode)
+	_, _ = w.Write(stored.Body)
+}
+
+type cachedCallKey struct{}
+
+// withCachedCall adds the conditional headers validating the stale response to the call, and keeps the call in its context
+// for the response modifier, because the proxy is shared by concurrent calls
+func withCachedCall(r *http.Request, call *cachedCall) *http.Request {
+	if call == nil {
+		return r
+	}
+	if call.stale != nil && !call.stale.AddConditions(r.Header) {
+		call.stale = nil
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), cachedCallKey{}, call))
+}
+
+// withResponseCache stores the responses to cached calls, and replaces 304 Not Modified responses validating stale responses with the stored ones
+func withResponseCache(modifyResponse func(*http.Response) error) func(*http.Response) error {
+	return func(resp *http.Response) error {
+		if err := modifyResponse(resp); err != nil {
+			return err
+		}
+
+		if call, ok := resp.Request.Context().Value(cachedCallKey{}).(*cachedCall); ok {
+			call.modifyResponse(resp)
+		}
+
+		return nil
+	}
+}
+
+func (c *cachedCall) modifyResponse(resp *http.Response) {
+	now := time.Now()
+
+	if resp.StatusCode == http.StatusNotModified && c.stale != nil {
+		revalidated, storable := c.stale.Revalidated(resp.Header, now)
+		if storable {
+			c.cache.Put(c.key, c.header, revalidated)
+		}
+
+		discardResponse(resp)
+		resp.StatusCode = revalidated.StatusCode
+		resp.Header = revalidated.Header.Clone()
+		resp.Header.Set(httpconsts.HeaderCacheStatus, cacheName+"; fwd="+c.forward+"; fwd-status=304")
+		resp.Body = io.NopCloser(bytes.NewReader(revalidated.Body))
+		resp.ContentLength = int64(len(revalidated.Body))
+		resp.Header.Set("Content-Length", strconv.Itoa(len(revalidated.Body)))
+		return
+	}
+
+	storable := responsecache.Storable(resp.StatusCode, resp.Header) && resp.ContentLength <= c.maxEntrySize
+	if storable {
+		statusCode, header := resp.StatusCode, resp.Header.Clone()
+		resp.Body = &capturingBody{
+			ReadCloser: resp.Body,
+			limit:      c.maxEntrySize,
+			onComplete: func(body []byte) {
+				c.cache.Put(c.key, c.header, responsecache.NewResponse(statusCode, header, body, now))
+			},
+		}
+	}
+
+	resp.Header.Set(httpconsts.HeaderCacheStatus, cacheName+"; fwd="+c.forward+"; fwd-status="+strconv.Itoa(resp.StatusCode))
+}
+
+// capturingBody keeps the body read by the proxy up to the limit and passes it to onComplete once it's read entirely
+type capturingBody struct {
+	io.ReadCloser
+	limit      int64
+	buf        bytes.Buffer
+	overflow   bool
+	onComplete func(body []byte)
+}
+
+func (b *capturingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if !b.overflow {
+		if int64(b.buf.Len()+n) > b.limit {
+			b.overflow = true
+			b.buf = bytes.Buffer{}
+		} else {
+			b.buf.Write(p[:n])
+		}
+	}
+	if err == io.EOF && !b.overflow && b.onComplete != nil {
+		b.onComplete(b.buf.Bytes())
+		b.onComplete = nil
+	}
+
+	return n, err
+}
@@ -6,11 +6,23 @@ import (
 	"net/http"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/accesslog"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metrics"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/tracing"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/clientcert"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
 )
 
+// maxChallengeRounds is the number of calls answering the authentication challenges of the target system, e.g. Digest answers the challenge with the stale nonce again
+const maxChallengeRounds = 2
+
 type RetryableRoundTripper struct {
 	roundTripper          http.RoundTripper
 	authorizationStrategy authorization.Strategy
@@ -18,9 +30,11 @@ type RetryableRoundTripper struct {
 	clientCertificate     clientcert.ClientCertificate
 	timeout               int
 	skipTLSVerify         bool
+	retryPolicy           *retryPolicy
+	bodyBuffering         model.RequestBodyBuffering
 }
 
-func NewRetryableRoundTripper(roundTripper http.RoundTripper, authorizationStrategy authorization.Strategy, csrfTokenStrategy csrf.TokenStrategy, clientCertificate clientcert.ClientCertificate, timeout int, skipTLSVerify bool) *RetryableRoundTripper {
+func NewRetryableRoundTripper(roundTripper http.RoundTripper, authorizationStrategy authorization.Strategy, csrfTokenStrategy csrf.TokenStrategy, clientCertificate clientcert.ClientCertificate, timeout int, skipTLSVerify bool, retryPolicy *model.RetryPolicy, bodyBuffering model.RequestBodyBuffering) *RetryableRoundTripper {
 	return &RetryableRoundTripper{
 		roundTripper:          roundTripper,
 		authorizationStrategy: authorizationStrategy,
@@ -28,57 +42,186 @@ func NewRetryableRoundTripper(roundTripper http.RoundTripper, authorizationStrat
 		clientCertificate:     clientCertificate,
 		timeout:               timeout,
 		skipTLSVerify:         skipTLSVerify,
+		retryPolicy:           newRetryPolicy(retryPolicy),
+		bodyBuffering:         bodyBuffering,
 	}
 }
 
-func (p *RetryableRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+func (p *RetryableRoundTripper) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+	ctx, span := tracing.StartSpan(req.Context(), spanUpstreamCall)
+	defer func() {
+		tracing.End(span, err)
+	}()
+	req = req.WithContext(ctx)
+
 	// Handle the case when credentials has been changed or OAuth token has expired
-	secondRequestBody, copyErr := copyRequestBody(req)
+	secondRequestBody, replayable, copyErr := copyRequestBody(req, p.bodyBuffering)
 	if copyErr != nil {
 		return nil, copyErr
 	}
-	resp, err := p.roundTripper.RoundTrip(req)
+	if !replayable {
+		// the body exceeds the memory limit of the stream mode, so the call can't be sent again
+		return p.roundTripper.RoundTrip(req)
+	}
+	resp, err = p.roundTripWithRetryPolicy(req)
 	if err != nil {
+		closeBody(secondRequestBody)
 		return nil, err
 	}
 	if !p.shouldRetry(resp) {
+		closeBody(secondRequestBody)
 		return resp, err
 	}
 	if req.Context().Err() != nil {
+		closeBody(secondRequestBody)
 		return nil, req.Context().Err()
 	}
-	return p.retry(req, secondRequestBody)
+	return p.retry(req, secondRequestBody, resp)
+}
+
+// roundTripWithRetryPolicy retries idempotent calls failed with a transient error according to the retry policy of the Application
+func (p *RetryableRoundTripper) roundTripWithRetryPolicy(req *http.Request) (*http.Response, error) {
+	if p.retryPolicy == nil || !isIdempotent(req.Method) {
+		return p.roundTripper.RoundTrip(req)
+	}
+
+	for attempt := 1; ; attempt++ {
+		lastAttempt := attempt >= p.retryPolicy.maxAttempts
+
+		var nextRequestBody io.ReadCloser
+		if !lastAttempt {
+			body, _, err := copyRequestBody(req, p.bodyBuffering)
+			if err != nil {
+				return nil, err
+			}
+			nextRequestBody = body
+		}
+
+		resp, err := p.roundTripper.RoundTrip(req)
+		if lastAttempt || !p.retryPolicy.shouldRetry(req, resp, err) {
+			closeBody(nextRequestBody)
+			return resp, err
+		}
+
+		delay, ok := p.retryPolicy.delay(attempt, resp)
+		if !ok {
+			closeBody(nextRequestBody)
+			return resp, err
+		}
+		discardResponse(resp)
+
+		zap.L().Info("Retrying call to the target system",
+			zap.Int("attempt", attempt+1),
+			zap.Duration("delay", delay),
+			zap.Error(err))
+
+		if err := wait(req.Context(), delay); err != nil {
+			closeBody(nextRequestBody)
+			return nil, err
+		}
+		metrics.ObserveRetry(apiIdentifierFromContext(req.Context()))
+		accesslog.FromContext(req.Context()).AddRetry()
+		tracing.AddEvent(req.Context(), "retry", attribute.Int("attempt", attempt+1))
+
+		req = req.Clone(req.Context())
+		req.Body = nextRequestBody
+	}
 }
 
 func (p *RetryableRoundTripper) shouldRetry(resp *http.Response) bool {
 	return resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized
 }
 
-func (p *RetryableRoundTripper) retry(req *http.Request, retryBody io.ReadCloser) (*http.Response, error) {
+// retry sends the call again with new authorization. If the target system challenged the call with an authentication scheme
+// of the authorization strategy, e.g. Digest or NTLM, the retry answers the challenge in the handshake of up to maxChallengeRounds calls.
+func (p *RetryableRoundTripper) retry(req *http.Request, retryBody io.ReadCloser, resp *http.Response) (*http.Response, error) {
 	request, cancel := p.prepareRequest(req)
 	defer cancel()
-	request.Body = retryBody
-	if err := p.addAuthorization(request); err != nil {
-		return nil, err
+	challengeStrategy, answersChallenges := authorization.AsChallengeStrategy(p.authorizationStrategy)
+
+	for round := 1; ; round++ {
+		challenged := answersChallenges && p.challenged(resp, challengeStrategy)
+		if challenged {
+			request = withChallenges(request, resp)
+		}
+		discardResponse(resp)
+
+		request.Body = retryBody
+		if err := p.addAuthorization(request); err != nil {
+			closeBody(retryBody)
+			return nil, err
+		}
+		accesslog.FromContext(request.Context()).AddRetry()
+
+		nextRound := challenged && round < maxChallengeRounds
+		var nextRequestBody io.ReadCloser
+		if nextRound {
+			body, _, err := copyRequestBody(request, p.bodyBuffering)
+			if err != nil {
+				closeBody(request.Body)
+				return nil, err
+			}
+			nextRequestBody = body
+		}
+
+		var err error
+		resp, err = p.roundTripper.RoundTrip(request)
+		if err != nil || !nextRound || !p.challenged(resp, challengeStrategy) {
+			closeBody(nextRequestBody)
+			return resp, err
+		}
+
+		request = request.Clone(request.Context())
+		retryBody = nextRequestBody
 	}
+}
 
-	return p.roundTripper.RoundTrip(request)
+func (p *RetryableRoundTripper) challenged(resp *http.Response, challengeStrategy authorization.ChallengeStrategy) bool {
+	return resp.StatusCode == http.StatusUnauthorized && challengeStrategy.Answers(resp.Header.Values(httpconsts.HeaderWWWAuthenticate))
+}
+
+// withChallenges passes the challenges of the response to the authorization strategy. The answer is sent to the target which challenged the call,
+// as the challenge is valid only for that target. Handshakes authenticating the connection, e.g. NTLM, are completed by every call, see handshakeRoundTripper.
+func withChallenges(req *http.Request, resp *http.Response) *http.Request {
+	ctx := authorization.WithChallenges(req.Context(), resp.Header.Values(httpconsts.HeaderWWWAuthenticate))
+	if resp.Request != nil {
+		if targetURL := targetFromContext(resp.Request.Context()); targetURL != nil {
+			ctx = withPinnedTarget(ctx, targetURL)
+		}
+	}
+
+	return req.WithContext(ctx)
 }
 
 func (p *RetryableRoundTripper) prepareRequest(req *http.Request) (*http.Request, context.CancelFunc) {
 	req.RequestURI = ""
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(p.timeout)*time.Second)
+	// keep request scoped values, e.g. the API identifier, but not the cancellation of the original request
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(req.Context()), time.Duration(p.timeout)*time.Second)
 	return req.WithContext(ctx), cancel
 }
 
 func (p *RetryableRoundTripper) addAuthorization(r *http.Request) error {
 	authorizationStrategy := p.authorizationStrategy
-	authorizationStrategy.Invalidate()
-	err := authorizationStrategy.AddAuthorization(r, p.clientCertificate.SetCertificate, p.skipTLSVerify)
+	if callInvalidatingStrategy, ok := authorization.AsCallInvalidatingStrategy(authorizationStrategy); ok {
+		callInvalidatingStrategy.InvalidateCall(r)
+	} else {
+		authorizationStrategy.Invalidate()
+	}
+	err := traced(r.Context(), spanAuthorization, func() apperrors.AppError {
+		return authorizationStrategy.AddAuthorization(r, p.clientCertificate.SetCertificate, p.skipTLSVerify)
+	})
 	if err != nil {
+		metrics.ObserveTokenFetchFailure(apiIdentifierFromContext(r.Context()), metrics.TokenAuthorization)
 		return err
 	}
 	csrfTokenStrategy := p.csrfTokenStrategy
 	csrfTokenStrategy.Invalidate()
-	return csrfTokenStrategy.AddCSRFToken(r, p.skipTLSVerify)
+	err = traced(r.Context(), spanCSRFToken, func() apperrors.AppError {
+		return csrfTokenStrategy.AddCSRFToken(r, p.skipTLSVerify)
+	})
+	if err != nil {
+		metrics.ObserveTokenFetchFailure(apiIdentifierFromContext(r.Context()), metrics.TokenCSRF)
+		return err
+	}
+	return nil
 }
@@ -1,16 +1,23 @@
 package proxy
 
 import (
+	"context"
 	"crypto/tls"
+	"encoding/base64"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	csrfMock "github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf/mocks"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/clientcert"
 	authMock "github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/mocks"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
@@ -167,7 +174,7 @@ func TestRetryableRoundTripper(t *testing.T) {
 			csrfTokenStrategyMock := tc.csrfTokenStrategyFunc(tc.skipTLSVerify)
 			clientCertificate := clientcert.NewClientCertificate(nil)
 
-			transport := NewRetryableRoundTripper(http.DefaultTransport, authStrategyMock, csrfTokenStrategyMock, clientCertificate, 10, tc.skipTLSVerify)
+			transport := NewRetryableRoundTripper(http.DefaultTransport, authStrategyMock, csrfTokenStrategyMock, clientCertificate, 10, tc.skipTLSVerify, nil, model.RequestBodyBuffering{})
 			httpClient := &http.Client{
 				Transport: transport,
 			}
@@ -189,3 +196,370 @@ func TestRetryableRoundTripper(t *testing.T) {
 		})
 	}
 }
+
+func TestRetryableRoundTripper_RetryPolicy(t *testing.T) {
+	retryPolicy := &model.RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+	}
+
+	tests := []struct {
+		name                 string
+		method               string
+		retryPolicy          *model.RetryPolicy
+		serverStatusCodes    []int
+		retryAfter           string
+		expectedStatusCode   int
+		expectedRequestCount int
+	}{
+		{
+			name:                 "should retry idempotent call until it succeeds",
+			method:               http.MethodPut,
+			retryPolicy:          retryPolicy,
+			serverStatusCodes:    []int{http.StatusServiceUnavailable, http.StatusBadGateway},
+			expectedStatusCode:   http.StatusOK,
+			expectedRequestCount: 3,
+		},
+		{
+			name:                 "should return last response when attempts are exhausted",
+			method:               http.MethodGet,
+			retryPolicy:          retryPolicy,
+			serverStatusCodes:    []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+			expectedStatusCode:   http.StatusGatewayTimeout,
+			expectedRequestCount: 3,
+		},
+		{
+			name:                 "should not retry non-idempotent call",
+			method:               http.MethodPost,
+			retryPolicy:          retryPolicy,
+			serverStatusCodes:    []int{http.StatusServiceUnavailable},
+			expectedStatusCode:   http.StatusServiceUnavailable,
+			expectedRequestCount: 1,
+		},
+		{
+			name:                 "should not retry status code which is not retryable",
+			method:               http.MethodGet,
+			retryPolicy:          retryPolicy,
+			serverStatusCodes:    []int{http.StatusInternalServerError},
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedRequestCount: 1,
+		},
+		{
+			name:   "should retry configured status codes",
+			method: http.MethodDelete,
+			retryPolicy: &model.RetryPolicy{
+				MaxAttempts:          2,
+				InitialBackoff:       time.Millisecond,
+				RetryableStatusCodes: []int{http.StatusTooManyRequests},
+			},
+			serverStatusCodes:    []int{http.StatusTooManyRequests},
+			expectedStatusCode:   http.StatusOK,
+			expectedRequestCount: 2,
+		},
+		{
+			name:                 "should not retry without retry policy",
+			method:               http.MethodGet,
+			serverStatusCodes:    []int{http.StatusServiceUnavailable},
+			expectedStatusCode:   http.StatusServiceUnavailable,
+			expectedRequestCount: 1,
+		},
+		{
+			name:                 "should respect Retry-After header",
+			method:               http.MethodGet,
+			retryPolicy:          retryPolicy,
+			serverStatusCodes:    []int{http.StatusServiceUnavailable},
+			retryAfter:           "0",
+			expectedStatusCode:   http.StatusOK,
+			expectedRequestCount: 2,
+		},
+		{
+			name:                 "should not retry when Retry-After exceeds maximum backoff",
+			method:               http.MethodGet,
+			retryPolicy:          retryPolicy,
+			serverStatusCodes:    []int{http.StatusServiceUnavailable},
+			retryAfter:           "120",
+			expectedStatusCode:   http.StatusServiceUnavailable,
+			expectedRequestCount: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			var requestCount int
+			var requestBodies []string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				requestBodies = append(requestBodies, string(body))
+				if requestCount < len(tc.serverStatusCodes) {
+					if tc.retryAfter != "" {
+						w.Header().Set("Retry-After", tc.retryAfter)
+					}
+					w.WriteHeader(tc.serverStatusCodes[requestCount])
+				} else {
+					w.WriteHeader(http.StatusOK)
+				}
+				requestCount++
+			}))
+			defer ts.Close()
+
+			transport := NewRetryableRoundTripper(http.DefaultTransport, &authMock.Strategy{}, &csrfMock.TokenStrategy{}, clientcert.NewClientCertificate(nil), 10, false, tc.retryPolicy, model.RequestBodyBuffering{})
+			req, err := http.NewRequest(tc.method, ts.URL, strings.NewReader("body"))
+			require.NoError(t, err)
+
+			// when
+			res, err := (&http.Client{Transport: transport}).Do(req)
+
+			// then
+			require.NoError(t, err)
+			_ = res.Body.Close()
+			require.Equal(t, tc.expectedStatusCode, res.StatusCode)
+			require.Equal(t, tc.expectedRequestCount, requestCount)
+			for _, body := range requestBodies {
+				require.Equal(t, "body", body)
+			}
+		})
+	}
+
+	t.Run("should stop retrying when request is cancelled", func(t *testing.T) {
+		// given
+		var requestCount int
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestCount++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+
+		policy := &model.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Minute, MaxBackoff: time.Minute}
+		transport := NewRetryableRoundTripper(http.DefaultTransport, &authMock.Strategy{}, &csrfMock.TokenStrategy{}, clientcert.NewClientCertificate(nil), 10, false, policy, model.RequestBodyBuffering{})
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, nil)
+		require.NoError(t, err)
+
+		// when
+		_, err = (&http.Client{Transport: transport}).Do(req)
+
+		// then
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Equal(t, 1, requestCount)
+	})
+}
+
+func TestRetryableRoundTripper_RequestBodyBuffering(t *testing.T) {
+	requestBody := "some body exceeding the memory limit"
+
+	newServer := func(requestBodies *[]string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			*requestBodies = append(*requestBodies, string(body))
+			if len(*requestBodies) == 1 {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+	}
+
+	t.Run("should retry with body written to file in File mode", func(t *testing.T) {
+		// given
+		var requestBodies []string
+		ts := newServer(&requestBodies)
+		defer ts.Close()
+
+		authStrategyMock := &authMock.Strategy{}
+		authStrategyMock.On("AddAuthorization", mock.AnythingOfType("*http.Request"), mock.AnythingOfType("SetClientCertificateFunc"), false).Return(nil).Once()
+		authStrategyMock.On("Invalidate").Return().Once()
+		csrfTokenStrategyMock := &csrfMock.TokenStrategy{}
+		csrfTokenStrategyMock.On("AddCSRFToken", mock.AnythingOfType("*http.Request"), false).Return(nil).Once()
+		csrfTokenStrategyMock.On("Invalidate").Return().Once()
+
+		bodyBuffering := model.RequestBodyBuffering{Mode: model.BodyBufferingFile, MemoryLimit: 4}
+		transport := NewRetryableRoundTripper(http.DefaultTransport, authStrategyMock, csrfTokenStrategyMock, clientcert.NewClientCertificate(nil), 10, false, nil, bodyBuffering)
+		req, err := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader(requestBody))
+		require.NoError(t, err)
+
+		// when
+		res, err := (&http.Client{Transport: transport}).Do(req)
+
+		// then
+		require.NoError(t, err)
+		_ = res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, []string{requestBody, requestBody}, requestBodies)
+		authStrategyMock.AssertExpectations(t)
+		csrfTokenStrategyMock.AssertExpectations(t)
+	})
+
+	t.Run("should not retry call with streamed body in Stream mode", func(t *testing.T) {
+		// given
+		var requestBodies []string
+		ts := newServer(&requestBodies)
+		defer ts.Close()
+
+		bodyBuffering := model.RequestBodyBuffering{Mode: model.BodyBufferingStream, MemoryLimit: 4}
+		transport := NewRetryableRoundTripper(http.DefaultTransport, &authMock.Strategy{}, &csrfMock.TokenStrategy{}, clientcert.NewClientCertificate(nil), 10, false, nil, bodyBuffering)
+		req, err := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader(requestBody))
+		require.NoError(t, err)
+
+		// when
+		res, err := (&http.Client{Transport: transport}).Do(req)
+
+		// then
+		require.NoError(t, err)
+		_ = res.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+		require.Equal(t, []string{requestBody}, requestBodies)
+	})
+}
+
+func TestRetryableRoundTripper_Challenges(t *testing.T) {
+	bodyBuffering := model.RequestBodyBuffering{Mode: model.BodyBufferingMemory}
+	strategyFactory := authorization.NewStrategyFactory(authorization.FactoryConfiguration{})
+
+	newCSRFTokenStrategyMock := func() *csrfMock.TokenStrategy {
+		csrfTokenStrategyMock := &csrfMock.TokenStrategy{}
+		csrfTokenStrategyMock.On("AddCSRFToken", mock.AnythingOfType("*http.Request"), false).Return(nil)
+		csrfTokenStrategyMock.On("Invalidate").Return()
+		return csrfTokenStrategyMock
+	}
+
+	t.Run("should answer the digest challenge of the target which challenged the call and reuse the nonce", func(t *testing.T) {
+		// given
+		var authorizations [2][]string
+		newServer := func(authorizations *[]string) *httptest.Server {
+			return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				authorization := r.Header.Get(httpconsts.HeaderAuthorization)
+				*authorizations = append(*authorizations, authorization)
+				if !strings.HasPrefix(authorization, "Digest ") || !strings.Contains(authorization, `uri="/api/orders?id=1"`) {
+					w.Header().Set(httpconsts.HeaderWWWAuthenticate, `Digest realm="orders", qop="auth", nonce="abc"`)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				assert.Equal(t, "order", string(body))
+				w.WriteHeader(http.StatusOK)
+			}))
+		}
+		first := newServer(&authorizations[0])
+		defer first.Close()
+		second := newServer(&authorizations[1])
+		defer second.Close()
+
+		pool, appErr := newTargetPool([]model.Target{{URL: first.URL + "/api"}, {URL: second.URL + "/api"}}, nil)
+		require.NoError(t, appErr)
+		strategy := strategyFactory.Create(&authorization.Credentials{Digest: &authorization.Digest{Username: "user", Password: "password"}})
+		transport := NewRetryableRoundTripper(newBalancingRoundTripper(newSigningRoundTripper(http.DefaultTransport, strategy), pool, &bodyBuffering),
+			strategy, newCSRFTokenStrategyMock(), clientcert.NewClientCertificate(nil), 10, false, nil, bodyBuffering)
+
+		for i := 0; i < 2; i++ {
+			req, err := http.NewRequest(http.MethodPost, "/orders?id=1", strings.NewReader("order"))
+			require.NoError(t, err)
+			require.NoError(t, strategy.AddAuthorization(req, nil, false))
+
+			// when
+			res, err := transport.RoundTrip(req)
+
+			// then
+			require.NoError(t, err)
+			_ = res.Body.Close()
+			require.Equal(t, http.StatusOK, res.StatusCode)
+		}
+		challenged, other := authorizations[0], authorizations[1]
+		if len(challenged) < len(other) {
+			challenged, other = other, challenged
+		}
+		require.Len(t, challenged, 2)
+		assert.Empty(t, challenged[0])
+		assert.Contains(t, challenged[1], `username="user"`)
+		require.Len(t, other, 1, "the nonce is reused by the next call")
+		assert.Contains(t, other[0], `nonce="abc"`)
+	})
+
+	t.Run("should complete the NTLM handshake in the scheme offered by the target system", func(t *testing.T) {
+		// given
+		var requests, bodies []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+			authorization := r.Header.Get(httpconsts.HeaderAuthorization)
+			requests = append(requests, authorization)
+
+			token, found := strings.CutPrefix(authorization, "Negotiate ")
+			if !found {
+				w.Header().Set(httpconsts.HeaderWWWAuthenticate, "Negotiate")
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			message, err := base64.StdEncoding.DecodeString(token)
+			require.NoError(t, err)
+			if message[8] == 1 {
+				w.Header().Set(httpconsts.HeaderWWWAuthenticate, "Negotiate "+base64.StdEncoding.EncodeToString(ntlmChallengeMessage()))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			assert.Equal(t, byte(3), message[8])
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+
+		pool, appErr := newTargetPool([]model.Target{{URL: ts.URL}}, nil)
+		require.NoError(t, appErr)
+		strategy := strategyFactory.Create(&authorization.Credentials{NTLM: &authorization.NTLM{Username: "user", Password: "password", Domain: "DOMAIN"}})
+		transport := NewRetryableRoundTripper(newBalancingRoundTripper(newSigningRoundTripper(newTransport(strategy), strategy), pool, &bodyBuffering),
+			strategy, newCSRFTokenStrategyMock(), clientcert.NewClientCertificate(nil), 10, false, nil, bodyBuffering)
+
+		req, err := http.NewRequest(http.MethodPost, "/orders", strings.NewReader("order"))
+		require.NoError(t, err)
+		require.NoError(t, strategy.AddAuthorization(req, nil, false))
+
+		// when
+		res, err := transport.RoundTrip(req)
+
+		// then
+		require.NoError(t, err)
+		_ = res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Len(t, requests, 3)
+		assert.True(t, strings.HasPrefix(requests[0], "NTLM "))
+		assert.True(t, strings.HasPrefix(requests[1], "Negotiate "))
+		assert.Equal(t, []string{"", "", "order"}, bodies, "the body is sent only with the authenticate message")
+	})
+
+	t.Run("should not answer challenges endlessly", func(t *testing.T) {
+		// given
+		var requestCount int
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestCount++
+			w.Header().Set(httpconsts.HeaderWWWAuthenticate, `Digest realm="orders", nonce="abc"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer ts.Close()
+
+		pool, appErr := newTargetPool([]model.Target{{URL: ts.URL}}, nil)
+		require.NoError(t, appErr)
+		strategy := strategyFactory.Create(&authorization.Credentials{Digest: &authorization.Digest{Username: "user", Password: "wrong"}})
+		transport := NewRetryableRoundTripper(newBalancingRoundTripper(newSigningRoundTripper(http.DefaultTransport, strategy), pool, &bodyBuffering),
+			strategy, newCSRFTokenStrategyMock(), clientcert.NewClientCertificate(nil), 10, false, nil, bodyBuffering)
+
+		req, err := http.NewRequest(http.MethodGet, "/orders", nil)
+		require.NoError(t, err)
+
+		// when
+		res, err := transport.RoundTrip(req)
+
+		// then
+		require.NoError(t, err)
+		_ = res.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+		require.Equal(t, 1+maxChallengeRounds, requestCount)
+	})
+}
+
+// ntlmChallengeMessage returns the NTLM challenge message without target info
+func ntlmChallengeMessage() []byte {
+	message := append([]byte("NTLMSSP\x00"), 2, 0, 0, 0)
+	message = append(message, 0, 0, 0, 0, 0, 0, 0, 0)
+	message = append(message, 0x01, 0x82, 0x88, 0xa0)
+	return append(message, 0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef)
+}
@@ -0,0 +1,146 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+)
+
+const (
+	defaultInitialBackoff = 100 * time.Millisecond
+	defaultMaxBackoff     = 2 * time.Second
+)
+
+var defaultRetryableStatusCodes = []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+// retryPolicy retries idempotent calls which failed with a transport error or a retryable status code
+type retryPolicy struct {
+	maxAttempts          int
+	initialBackoff       time.Duration
+	maxBackoff           time.Duration
+	retryableStatusCodes map[int]bool
+}
+
+// newRetryPolicy applies defaults to the policy configured on the Application.
+// It returns nil if the policy allows only a single attempt.
+func newRetryPolicy(policy *model.RetryPolicy) *retryPolicy {
+	if policy == nil || policy.MaxAttempts <= 1 {
+		return nil
+	}
+
+	result := &retryPolicy{
+		maxAttempts:          policy.MaxAttempts,
+		initialBackoff:       policy.InitialBackoff,
+		maxBackoff:           policy.MaxBackoff,
+		retryableStatusCodes: map[int]bool{},
+	}
+	if result.initialBackoff <= 0 {
+		result.initialBackoff = defaultInitialBackoff
+	}
+	if result.maxBackoff <= 0 {
+		result.maxBackoff = defaultMaxBackoff
+	}
+	if result.maxBackoff < result.initialBackoff {
+		result.maxBackoff = result.initialBackoff
+	}
+
+	statusCodes := policy.RetryableStatusCodes
+	if len(statusCodes) == 0 {
+		statusCodes = defaultRetryableStatusCodes
+	}
+	for _, code := range statusCodes {
+		result.retryableStatusCodes[code] = true
+	}
+
+	return result
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func (p *retryPolicy) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		// the caller gave up or the proxy timeout elapsed, another attempt wouldn't be awaited
+		return req.Context().Err() == nil
+	}
+
+	return p.retryableStatusCodes[resp.StatusCode]
+}
+
+// delay returns the time to wait before the given retry, counted from 1.
+// The Retry-After header of the response takes precedence over the exponential backoff,
+// false is returned if the target system asks to wait longer than the maximum backoff.
+func (p *retryPolicy) delay(retry int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil {
+		if retryAfter, found := parseRetryAfter(resp.Header.Get(httpconsts.HeaderRetryAfter)); found {
+			return retryAfter, retryAfter <= p.maxBackoff
+		}
+	}
+
+	backoff := p.maxBackoff
+	if shift := retry - 1; shift < 32 {
+		if exponential := p.initialBackoff << shift; exponential > 0 && exponential < backoff {
+			backoff = exponential
+		}
+	}
+
+	// equal jitter keeps at least half of the backoff while spreading the retries of concurrent calls
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(backoff-half)+1)), true
+}
+
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	retryAfter := time.Until(date)
+	if retryAfter < 0 {
+		retryAfter = 0
+	}
+	return retryAfter, true
+}
+
+func wait(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// discardResponse releases the connection of the response which won't be returned to the caller
+func discardResponse(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+}
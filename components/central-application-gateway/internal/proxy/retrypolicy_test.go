@@ -0,0 +1,92 @@
+package proxy
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+)
+
+func TestNewRetryPolicy(t *testing.T) {
+	t.Run("should disable retries without policy or with single attempt", func(t *testing.T) {
+		assert.Nil(t, newRetryPolicy(nil))
+		assert.Nil(t, newRetryPolicy(&model.RetryPolicy{MaxAttempts: 1}))
+	})
+
+	t.Run("should apply defaults", func(t *testing.T) {
+		// when
+		policy := newRetryPolicy(&model.RetryPolicy{MaxAttempts: 2})
+
+		// then
+		require.NotNil(t, policy)
+		assert.Equal(t, defaultInitialBackoff, policy.initialBackoff)
+		assert.Equal(t, defaultMaxBackoff, policy.maxBackoff)
+		assert.Equal(t, map[int]bool{502: true, 503: true, 504: true}, policy.retryableStatusCodes)
+	})
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := newRetryPolicy(&model.RetryPolicy{
+		MaxAttempts:    10,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+	})
+
+	t.Run("should back off exponentially with jitter", func(t *testing.T) {
+		for retry, expectedBackoff := range map[int]time.Duration{
+			1: 100 * time.Millisecond,
+			2: 200 * time.Millisecond,
+			3: 400 * time.Millisecond,
+			5: time.Second,
+			9: time.Second,
+		} {
+			// when
+			delay, ok := policy.delay(retry, nil)
+
+			// then
+			assert.True(t, ok)
+			assert.GreaterOrEqual(t, delay, expectedBackoff/2)
+			assert.LessOrEqual(t, delay, expectedBackoff)
+		}
+	})
+
+	t.Run("should use Retry-After in seconds", func(t *testing.T) {
+		// given
+		resp := &http.Response{Header: http.Header{"Retry-After": []string{"1"}}}
+
+		// when
+		delay, ok := policy.delay(1, resp)
+
+		// then
+		assert.True(t, ok)
+		assert.Equal(t, time.Second, delay)
+	})
+
+	t.Run("should use Retry-After date", func(t *testing.T) {
+		// given
+		date := time.Now().Add(500 * time.Millisecond).UTC().Format(http.TimeFormat)
+		resp := &http.Response{Header: http.Header{"Retry-After": []string{date}}}
+
+		// when
+		delay, ok := policy.delay(1, resp)
+
+		// then
+		assert.True(t, ok)
+		assert.LessOrEqual(t, delay, time.Second)
+	})
+
+	t.Run("should give up when Retry-After exceeds maximum backoff", func(t *testing.T) {
+		// given
+		resp := &http.Response{Header: http.Header{"Retry-After": []string{"2"}}}
+
+		// when
+		_, ok := policy.delay(1, resp)
+
+		// then
+		assert.False(t, ok)
+	})
+}
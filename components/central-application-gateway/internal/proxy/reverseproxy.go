@@ -8,10 +8,15 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/accesslog"
 	"github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metrics"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/tracing"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/clientcert"
@@ -20,65 +25,95 @@ import (
 )
 
 func makeProxy(
-	targetURL string,
+	targets []model.Target,
+	loadBalancing *model.LoadBalancing,
 	requestParameters *authorization.RequestParameters,
 	serviceName string,
 	skipTLSVerify bool,
 	authorizationStrategy authorization.Strategy,
 	csrfTokenStrategy csrf.TokenStrategy,
 	clientCertificate clientcert.ClientCertificate,
+	connection *httptools.ConnectionSettings,
 	timeout int,
+	retryPolicy *model.RetryPolicy,
+	bodyBuffering model.RequestBodyBuffering,
+	requestHeaders *model.HeaderTransformations,
+	proxyMode string,
 ) (*httputil.ReverseProxy, apperrors.AppError) {
-	roundTripper := httptools.NewRoundTripper(httptools.WithTLSSkipVerify(skipTLSVerify), httptools.WithGetClientCertificate(clientCertificate.GetClientCertificate))
-	retryableRoundTripper := NewRetryableRoundTripper(roundTripper, authorizationStrategy, csrfTokenStrategy, clientCertificate, timeout, skipTLSVerify)
-	return newProxy(targetURL, requestParameters, serviceName, retryableRoundTripper)
+	if proxyMode == model.ProxyModePassThrough || proxyMode == model.ProxyModeHTTP2 {
+		return makePassThroughProxy(targets, loadBalancing, requestParameters, serviceName, skipTLSVerify, authorizationStrategy, clientCertificate, connection, timeout, requestHeaders, proxyMode)
+	}
+
+	pool, err := newTargetPool(targets, loadBalancing)
+	if err != nil {
+		return nil, err
+	}
+
+	roundTripper := newSigningRoundTripper(newTransport(authorizationStrategy,
+		httptools.WithTLSSkipVerify(skipTLSVerify),
+		httptools.WithGetClientCertificate(clientCertificate.GetClientCertificate),
+		httptools.WithConnectionSettings(connection)), authorizationStrategy)
+	balancingRoundTripper := newBalancingRoundTripper(roundTripper, pool, &bodyBuffering)
+	retryableRoundTripper := NewRetryableRoundTripper(balancingRoundTripper, authorizationStrategy, csrfTokenStrategy, clientCertificate, timeout, skipTLSVerify, retryPolicy, bodyBuffering)
+	return newProxy(requestParameters, serviceName, retryableRoundTripper, requestHeaders), nil
 }
 
-func newProxy(targetURL string, requestParameters *authorization.RequestParameters, serviceName string, transport http.RoundTripper) (*httputil.ReverseProxy, apperrors.AppError) {
-	target, err := url.Parse(targetURL)
+// makePassThroughProxy creates the proxy which streams calls and responses, including upgraded connections, e.g. WebSocket.
+// Request bodies aren't buffered, so calls aren't retried, and the timeout limits only the wait for the response headers.
+func makePassThroughProxy(
+	targets []model.Target,
+	loadBalancing *model.LoadBalancing,
+	requestParameters *authorization.RequestParameters,
+	serviceName string,
+	skipTLSVerify bool,
+	authorizationStrategy authorization.Strategy,
+	clientCertificate clientcert.ClientCertificate,
+	connection *httptools.ConnectionSettings,
+	timeout int,
+	requestHeaders *model.HeaderTransformations,
+	proxyMode string,
+) (*httputil.ReverseProxy, apperrors.AppError) {
+	pool, err := newTargetPool(targets, loadBalancing)
 	if err != nil {
-		zap.L().Error("failed to parse target URL",
-			zap.String("targetURL", targetURL),
-			zap.Error(err))
-		return nil, apperrors.Internalf("failed to parse target url '%s': '%s'", targetURL, err.Error())
+		return nil, err
 	}
 
-	targetQuery := target.RawQuery
+	options := []httptools.RoundTripperOption{
+		httptools.WithTLSSkipVerify(skipTLSVerify),
+		httptools.WithGetClientCertificate(clientCertificate.GetClientCertificate),
+		httptools.WithResponseHeaderTimeout(time.Duration(timeout) * time.Second),
+		httptools.WithConnectionSettings(connection),
+	}
+	if proxyMode == model.ProxyModeHTTP2 {
+		options = append(options, httptools.WithHTTP2())
+	}
+
+	// only calls without body are sent to the next target, as bodies are streamed
+	roundTripper := newBalancingRoundTripper(newSigningRoundTripper(newTransport(authorizationStrategy, options...), authorizationStrategy), pool, nil)
+	proxy := newProxy(requestParameters, serviceName, roundTripper, requestHeaders)
+	// flush every write, e.g. of gRPC messages and server-sent events, without waiting for the next one
+	proxy.FlushInterval = -1
+
+	return proxy, nil
+}
+
+// newProxy creates the proxy whose transport sends calls to the targets of the API, see balancingRoundTripper
+func newProxy(requestParameters *authorization.RequestParameters, serviceName string, transport http.RoundTripper, requestHeaders *model.HeaderTransformations) *httputil.ReverseProxy {
 	director := func(req *http.Request) {
 		zap.L().Info("Proxy call for service",
-			zap.String("serviceName", serviceName),
-			zap.String("targetURL", targetURL))
-
-		req.URL.Scheme = target.Scheme
-		req.URL.Host = target.Host
-		req.Host = target.Host
-
-		combinedPath := joinPaths(target.Path, req.URL.Path)
-		combinedPathEscaped := joinPaths(target.Path, req.URL.RawPath)
-		req.URL.Path = combinedPath
-		req.URL.RawPath = combinedPathEscaped
-
-		if targetQuery == "" || req.URL.RawQuery == "" {
-			req.URL.RawQuery = targetQuery + req.URL.RawQuery
-		} else {
-			req.URL.RawQuery = targetQuery + "&" + req.URL.RawQuery
-		}
+			zap.String("serviceName", serviceName))
 
 		if requestParameters != nil {
 			setCustomQueryParameters(req.URL, requestParameters.QueryParameters)
 			setCustomHeaders(req.Header, requestParameters.Headers)
 		}
-
-		zap.L().Info("modified request URL",
-			zap.String("url", req.URL.String()),
-			zap.String("schema", req.URL.Scheme),
-			zap.String("path", req.URL.Path))
+		transformHeaders(req.Header, requestHeaders)
 	}
 
 	errorHandler := func(rw http.ResponseWriter, req *http.Request, err error) {
 		zap.L().Warn("Request failed",
 			zap.Error(err),
-			zap.Any("requestID", req.Context().Value(httptools.ContextUUID)),
+			zap.String("traceID", tracing.TraceID(req.Context())),
 			zap.String("method", req.Method),
 			zap.String("host", req.Host),
 			zap.String("url", req.URL.RequestURI()),
@@ -91,7 +126,7 @@ func newProxy(targetURL string, requestParameters *authorization.RequestParamete
 		Director:     director,
 		Transport:    transport,
 		ErrorHandler: errorHandler,
-	}, nil
+	}
 }
 
 func joinPaths(a, b string) string {
@@ -124,12 +159,14 @@ func setCustomHeaders(reqHeaders http.Header, customHeaders *map[string][]string
 
 func responseModifier(
 	gatewayURL *url.URL,
-	targetURL string,
 	urlRewriter func(gatewayURL, target, loc *url.URL) *url.URL,
 ) func(*http.Response) error {
 	return func(resp *http.Response) error {
 		_ = httptools.LogResponse(zap.L().Sugar(), resp)
 
+		metrics.ObserveUpstreamResponse(apiIdentifierFromContext(resp.Request.Context()), resp.StatusCode)
+		accesslog.FromContext(resp.Request.Context()).SetUpstream(resp.Request.URL, resp.StatusCode)
+
 		if resp.StatusCode >= 500 && resp.StatusCode < 600 {
 			resp.Header.Set("Target-System-Status", strconv.Itoa(resp.StatusCode))
 			resp.StatusCode = http.StatusBadGateway
@@ -153,8 +190,9 @@ func responseModifier(
 			return nil
 		}
 
-		target, err := url.Parse(targetURL)
-		if err != nil {
+		// Location refers to the target which served the call
+		target := targetFromContext(resp.Request.Context())
+		if target == nil {
 			return nil
 		}
 
@@ -188,6 +226,12 @@ func urlRewriter(gatewayURL, target, loc *url.URL) *url.URL {
 }
 
 func codeRewriter(rw http.ResponseWriter, err error) {
+	// errors of the gateway, e.g. failures of signing the call, are returned with their own status codes
+	var appErr apperrors.AppError
+	if errors.As(err, &appErr) {
+		handleErrors(rw, appErr)
+		return
+	}
 	if errors.Is(err, context.DeadlineExceeded) {
 		zap.L().Warn("HTTP status code rewritten to 504",
 			zap.Error(err))
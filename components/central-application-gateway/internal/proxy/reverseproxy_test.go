@@ -1,6 +1,7 @@
 package proxy
 
 import (
+	"context"
 	"net/http"
 	"net/url"
 	"testing"
@@ -110,6 +111,7 @@ func TestResponseModifier(t *testing.T) {
 			urlRewriter: func(t *testing.T) func(gatewayURL, target, loc *url.URL) *url.URL {
 				return func(gatewayURL, trgt, loc *url.URL) *url.URL {
 					assert.Equal(t, target+"/some/endpoint", loc.String())
+					assert.Equal(t, target, trgt.String())
 
 					u, err := url.Parse("https://other.addr/with/path")
 					require.Nil(t, err)
@@ -187,7 +189,10 @@ func TestResponseModifier(t *testing.T) {
 			// given
 			called := false
 			res := tc.response
+			servedTarget, err := url.Parse(target)
+			require.Nil(t, err)
 			res.Request = tc.request()
+			res.Request = res.Request.WithContext(context.WithValue(res.Request.Context(), targetKey{}, servedTarget))
 
 			rewriter := func(gatewayURL, target, loc *url.URL) *url.URL {
 				called = true
@@ -202,7 +207,7 @@ func TestResponseModifier(t *testing.T) {
 			gw, err := url.Parse(gateway) // this could be out of the loop, but here it felt more readable
 			require.Nil(t, err)
 
-			rm := responseModifier(gw, target, rewriter)
+			rm := responseModifier(gw, rewriter)
 
 			// when
 			err = rm(res)
@@ -0,0 +1,45 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
+)
+
+// signingRoundTripper signs the calls of the strategies which authorize the final call, e.g. Digest or AWS SigV4, after the director
+// and the balancer set its URL and headers, see authorization.SigningStrategy
+type signingRoundTripper struct {
+	roundTripper    http.RoundTripper
+	signingStrategy authorization.SigningStrategy
+}
+
+// newSigningRoundTripper returns the round tripper signing the calls, or the given one if the strategy doesn't sign calls
+func newSigningRoundTripper(roundTripper http.RoundTripper, authorizationStrategy authorization.Strategy) http.RoundTripper {
+	signingStrategy, ok := authorization.AsSigningStrategy(authorizationStrategy)
+	if !ok {
+		return roundTripper
+	}
+
+	return &signingRoundTripper{
+		roundTripper:    roundTripper,
+		signingStrategy: signingStrategy,
+	}
+}
+
+func (s *signingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// the round tripper mustn't modify the call, which is sent again by the retries
+	outreq := req.Clone(req.Context())
+	if body, ok := req.Body.(replayableBody); ok {
+		// the strategy can read the copy of the buffered body, e.g. to sign its hash
+		outreq.GetBody = func() (io.ReadCloser, error) {
+			return body.replay(), nil
+		}
+	}
+	if err := s.signingStrategy.Sign(outreq); err != nil {
+		closeBody(req.Body)
+		return nil, err
+	}
+
+	return s.roundTripper.RoundTrip(outreq)
+}
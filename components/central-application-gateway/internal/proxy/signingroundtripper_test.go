@@ -0,0 +1,122 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	csrfMock "github.com/kyma-project/kyma/components/central-application-gateway/internal/csrf/mocks"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/clientcert"
+	authMock "github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/mocks"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+)
+
+// signingStrategy signs calls with their URL, the header set by the director and the body
+type signingStrategy struct {
+	authMock.Strategy
+	err apperrors.AppError
+}
+
+func (s *signingStrategy) Sign(r *http.Request) apperrors.AppError {
+	if s.err != nil {
+		return s.err
+	}
+
+	body, err := r.GetBody()
+	if err != nil {
+		return apperrors.Internalf("failed to read body: %s", err.Error())
+	}
+	defer body.Close()
+	content, _ := io.ReadAll(body)
+
+	r.Header.Set(httpconsts.HeaderAuthorization, "Signed "+r.URL.String()+" "+r.Header.Get("X-Tenant")+" "+string(content))
+	return nil
+}
+
+func TestSigningRoundTripper(t *testing.T) {
+	bodyBuffering := model.RequestBodyBuffering{Mode: model.BodyBufferingMemory}
+	requestParameters := &authorization.RequestParameters{
+		Headers:         &map[string][]string{"X-Tenant": {"tenant-1"}},
+		QueryParameters: &map[string][]string{"tenant": {"1"}},
+	}
+
+	newProxyWithStrategy := func(t *testing.T, targetURL string, strategy authorization.Strategy) http.Handler {
+		pool, appErr := newTargetPool([]model.Target{{URL: targetURL}}, nil)
+		require.NoError(t, appErr)
+
+		roundTripper := newBalancingRoundTripper(newSigningRoundTripper(http.DefaultTransport, strategy), pool, &bodyBuffering)
+		retryableRoundTripper := NewRetryableRoundTripper(roundTripper, strategy, &csrfMock.TokenStrategy{}, clientcert.NewClientCertificate(nil), 10, false, nil, bodyBuffering)
+		return newProxy(requestParameters, "orders", retryableRoundTripper, nil)
+	}
+
+	t.Run("should sign the call with the URL and headers set by the director", func(t *testing.T) {
+		// given
+		var authorizationHeader string
+		var targetURL string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorizationHeader = r.Header.Get(httpconsts.HeaderAuthorization)
+			body, _ := io.ReadAll(r.Body)
+			assert.Equal(t, "order", string(body))
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+		targetURL = ts.URL + "/api"
+
+		strategy := &signingStrategy{}
+		proxy := newProxyWithStrategy(t, targetURL, strategy)
+
+		req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader("order"))
+		rr := httptest.NewRecorder()
+
+		// when
+		proxy.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "Signed "+targetURL+"/orders?tenant=1 tenant-1 order", authorizationHeader)
+	})
+
+	t.Run("should return the error of the strategy when the call can't be signed", func(t *testing.T) {
+		// given
+		var requestCount int
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestCount++
+		}))
+		defer ts.Close()
+
+		strategy := &signingStrategy{err: apperrors.Internalf("failed to sign")}
+		proxy := newProxyWithStrategy(t, ts.URL, strategy)
+
+		req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		rr := httptest.NewRecorder()
+
+		// when
+		proxy.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Contains(t, rr.Body.String(), "failed to sign")
+		assert.Zero(t, requestCount)
+	})
+
+	t.Run("should not wrap the round tripper of strategies which don't sign calls", func(t *testing.T) {
+		// given
+		strategy := &authMock.Strategy{}
+		strategy.On("AddAuthorization", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		// when
+		roundTripper := newSigningRoundTripper(http.DefaultTransport, strategy)
+
+		// then
+		assert.Equal(t, http.DefaultTransport, roundTripper)
+	})
+}
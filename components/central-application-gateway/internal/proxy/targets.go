@@ -0,0 +1,275 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/accesslog"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/tracing"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+)
+
+const (
+	defaultUnhealthyThreshold = 3
+	defaultUnhealthyDuration  = 30 * time.Second
+)
+
+// target is an instance of the target system serving the API
+type target struct {
+	url      *url.URL
+	priority int
+	// failures is the number of consecutive failed calls
+	failures       int
+	unhealthyUntil time.Time
+}
+
+// targetPool selects the targets of calls to an API and tracks their health passively, from the results of the calls
+type targetPool struct {
+	mutex              sync.Mutex
+	targets            []*target
+	failover           bool
+	unhealthyThreshold int
+	unhealthyDuration  time.Duration
+	next               int
+}
+
+func newTargetPool(targets []model.Target, loadBalancing *model.LoadBalancing) (*targetPool, apperrors.AppError) {
+	pool := &targetPool{
+		unhealthyThreshold: defaultUnhealthyThreshold,
+		unhealthyDuration:  defaultUnhealthyDuration,
+	}
+	if loadBalancing != nil {
+		pool.failover = loadBalancing.Strategy == model.LoadBalancingFailover
+		if loadBalancing.UnhealthyThreshold > 0 {
+			pool.unhealthyThreshold = loadBalancing.UnhealthyThreshold
+		}
+		if loadBalancing.UnhealthyDuration > 0 {
+			pool.unhealthyDuration = loadBalancing.UnhealthyDuration
+		}
+	}
+
+	for _, t := range targets {
+		targetURL, err := url.Parse(t.URL)
+		if err != nil {
+			zap.L().Error("failed to parse target URL",
+				zap.String("targetURL", t.URL),
+				zap.Error(err))
+			return nil, apperrors.Internalf("failed to parse target url '%s': '%s'", t.URL, err.Error())
+		}
+		pool.targets = append(pool.targets, &target{url: targetURL, priority: t.Priority})
+	}
+
+	return pool, nil
+}
+
+// pick returns the target of the next call, skipping the excluded ones. Healthy targets are selected in turn,
+// in the failover strategy only the ones with the lowest priority. If all targets are unhealthy, the one which
+// recovers first is selected, so that calls aren't rejected without trying the target system.
+func (p *targetPool) pick(excluded map[*target]bool) *target {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := time.Now()
+	var healthy []*target
+	var recoversFirst *target
+	for _, t := range p.targets {
+		if excluded[t] {
+			continue
+		}
+		if now.Before(t.unhealthyUntil) {
+			if recoversFirst == nil || t.unhealthyUntil.Before(recoversFirst.unhealthyUntil) {
+				recoversFirst = t
+			}
+			continue
+		}
+		if p.failover && len(healthy) > 0 && t.priority != healthy[0].priority {
+			if t.priority > healthy[0].priority {
+				continue
+			}
+			healthy = healthy[:0]
+		}
+		healthy = append(healthy, t)
+	}
+
+	if len(healthy) == 0 {
+		return recoversFirst
+	}
+	p.next++
+
+	return healthy[p.next%len(healthy)]
+}
+
+// report records the result of the call to the target, which is skipped for unhealthyDuration after unhealthyThreshold consecutive failures
+func (p *targetPool) report(t *target, failed bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !failed {
+		t.failures = 0
+		t.unhealthyUntil = time.Time{}
+		return
+	}
+
+	t.failures++
+	if t.failures >= p.unhealthyThreshold {
+		t.unhealthyUntil = time.Now().Add(p.unhealthyDuration)
+		zap.L().Warn("Target is unhealthy, calls are sent to other targets",
+			zap.String("targetURL", t.url.Redacted()),
+			zap.Int("failures", t.failures),
+			zap.Duration("unhealthyDuration", p.unhealthyDuration))
+	}
+}
+
+// targetFailed reports whether the call failed because of the target, the calls canceled by the caller don't count
+func targetFailed(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+
+	return slices.Contains(defaultRetryableStatusCodes, resp.StatusCode)
+}
+
+type targetKey struct{}
+
+// withTarget returns the copy of the call sent to the target, the path and query of the call follow the ones of the target URL
+func withTarget(req *http.Request, t *target) *http.Request {
+	outreq := req.WithContext(context.WithValue(req.Context(), targetKey{}, t.url))
+	targetURL := *req.URL
+	outreq.URL = &targetURL
+
+	outreq.URL.Scheme = t.url.Scheme
+	outreq.URL.Host = t.url.Host
+	outreq.Host = t.url.Host
+	outreq.URL.Path = joinPaths(t.url.Path, req.URL.Path)
+	outreq.URL.RawPath = joinPaths(t.url.Path, req.URL.RawPath)
+
+	if t.url.RawQuery == "" || req.URL.RawQuery == "" {
+		outreq.URL.RawQuery = t.url.RawQuery + req.URL.RawQuery
+	} else {
+		outreq.URL.RawQuery = t.url.RawQuery + "&" + req.URL.RawQuery
+	}
+
+	return outreq
+}
+
+// targetFromContext returns the URL of the target which served the call, nil if the call wasn't sent
+func targetFromContext(ctx context.Context) *url.URL {
+	targetURL, _ := ctx.Value(targetKey{}).(*url.URL)
+	return targetURL
+}
+
+type pinnedTargetKey struct{}
+
+// withPinnedTarget returns the context of the call which must be sent to the target with the URL, e.g. the answer to the authentication challenge of the target
+func withPinnedTarget(ctx context.Context, targetURL *url.URL) context.Context {
+	return context.WithValue(ctx, pinnedTargetKey{}, targetURL)
+}
+
+// pinned returns the target to which the call must be sent, nil if the call can be sent to any target
+func (p *targetPool) pinned(ctx context.Context) *target {
+	targetURL, _ := ctx.Value(pinnedTargetKey{}).(*url.URL)
+	if targetURL == nil {
+		return nil
+	}
+	for _, t := range p.targets {
+		if t.url == targetURL {
+			return t
+		}
+	}
+
+	return nil
+}
+
+// balancingRoundTripper sends calls to the targets selected by the pool. Idempotent calls which failed
+// are sent to the next target right away, as long as their body can be sent again.
+type balancingRoundTripper struct {
+	roundTripper http.RoundTripper
+	pool         *targetPool
+	// bodyBuffering keeps the request body to send it to the next target, nil sends only calls without body to the next target
+	bodyBuffering *model.RequestBodyBuffering
+}
+
+func newBalancingRoundTripper(roundTripper http.RoundTripper, pool *targetPool, bodyBuffering *model.RequestBodyBuffering) *balancingRoundTripper {
+	return &balancingRoundTripper{
+		roundTripper:  roundTripper,
+		pool:          pool,
+		bodyBuffering: bodyBuffering,
+	}
+}
+
+func (b *balancingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	tried := make(map[*target]bool, len(b.pool.targets))
+	for {
+		t := b.pool.pinned(req.Context())
+		if t == nil || tried[t] {
+			t = b.pool.pick(tried)
+		}
+		tried[t] = true
+
+		nextRequestBody, failover, appErr := b.nextRequestBody(req, len(tried) < len(b.pool.targets))
+		if appErr != nil {
+			return nil, appErr
+		}
+
+		outreq := withTarget(req, t)
+		zap.L().Info("modified request URL",
+			zap.String("url", outreq.URL.Redacted()),
+			zap.String("schema", outreq.URL.Scheme),
+			zap.String("path", outreq.URL.Path))
+
+		resp, err := b.roundTripper.RoundTrip(outreq)
+		failed := targetFailed(resp, err)
+		b.pool.report(t, failed)
+		if err != nil {
+			accesslog.FromContext(req.Context()).SetUpstream(outreq.URL, 0)
+		}
+		if !failed || !failover || req.Context().Err() != nil {
+			closeBody(nextRequestBody)
+			return resp, err
+		}
+		discardResponse(resp)
+
+		zap.L().Warn("Sending call to the next target",
+			zap.String("failedTargetURL", t.url.Redacted()),
+			zap.Error(err))
+		accesslog.FromContext(req.Context()).AddRetry()
+		tracing.AddEvent(req.Context(), "failover", attribute.String("target", t.url.Redacted()))
+
+		req = req.Clone(req.Context())
+		req.Body = nextRequestBody
+	}
+}
+
+// nextRequestBody returns the body of the call to the next target, and false if the call can't be sent to the next target
+func (b *balancingRoundTripper) nextRequestBody(req *http.Request, nextTarget bool) (io.ReadCloser, bool, apperrors.AppError) {
+	if !nextTarget || !isIdempotent(req.Method) {
+		return nil, false, nil
+	}
+	if req.Body == nil || req.Body == http.NoBody {
+		return req.Body, true, nil
+	}
+	if b.bodyBuffering == nil {
+		return nil, false, nil
+	}
+
+	body, replayable, err := copyRequestBody(req, *b.bodyBuffering)
+	if err != nil {
+		return nil, false, err
+	}
+	if !replayable {
+		closeBody(body)
+		return nil, false, nil
+	}
+
+	return body, true, nil
+}
@@ -0,0 +1,202 @@
+package proxy
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+)
+
+func TestTargetPool(t *testing.T) {
+	t.Run("should select the targets in turn in the round robin strategy", func(t *testing.T) {
+		// given
+		pool, err := newTargetPool([]model.Target{{URL: "http://a"}, {URL: "http://b", Priority: 1}}, nil)
+		require.NoError(t, err)
+
+		// when
+		hosts := []string{pool.pick(nil).url.Host, pool.pick(nil).url.Host, pool.pick(nil).url.Host, pool.pick(nil).url.Host}
+
+		// then
+		assert.ElementsMatch(t, []string{"a", "a", "b", "b"}, hosts)
+		assert.NotEqual(t, hosts[0], hosts[1])
+	})
+
+	t.Run("should select the healthy targets with the lowest priority in the failover strategy", func(t *testing.T) {
+		// given
+		pool, err := newTargetPool([]model.Target{
+			{URL: "http://passive", Priority: 1},
+			{URL: "http://active-1"},
+			{URL: "http://active-2"},
+		}, &model.LoadBalancing{Strategy: model.LoadBalancingFailover, UnhealthyThreshold: 2})
+		require.NoError(t, err)
+
+		// when
+		first, second := pool.pick(nil), pool.pick(nil)
+
+		// then
+		assert.ElementsMatch(t, []string{"active-1", "active-2"}, []string{first.url.Host, second.url.Host})
+
+		// when
+		pool.report(first, true)
+		pool.report(second, true)
+
+		// then
+		assert.Contains(t, []string{"active-1", "active-2"}, pool.pick(nil).url.Host, "targets should stay healthy below the threshold")
+
+		// when
+		pool.report(first, true)
+		pool.report(second, true)
+
+		// then
+		assert.Equal(t, "passive", pool.pick(nil).url.Host)
+	})
+
+	t.Run("should select the target which recovers first when all targets are unhealthy", func(t *testing.T) {
+		// given
+		pool, err := newTargetPool([]model.Target{{URL: "http://a"}, {URL: "http://b"}}, &model.LoadBalancing{UnhealthyThreshold: 1, UnhealthyDuration: time.Minute})
+		require.NoError(t, err)
+		a, b := pool.targets[0], pool.targets[1]
+
+		// when
+		pool.report(b, true)
+		pool.report(a, true)
+
+		// then
+		assert.Equal(t, b, pool.pick(nil))
+		assert.Equal(t, a, pool.pick(map[*target]bool{b: true}))
+		assert.Nil(t, pool.pick(map[*target]bool{a: true, b: true}))
+	})
+
+	t.Run("should mark the target healthy after a successful call", func(t *testing.T) {
+		// given
+		pool, err := newTargetPool([]model.Target{{URL: "http://a"}}, &model.LoadBalancing{UnhealthyThreshold: 1})
+		require.NoError(t, err)
+		a := pool.targets[0]
+		pool.report(a, true)
+
+		// when
+		pool.report(a, false)
+
+		// then
+		assert.Zero(t, a.failures)
+		assert.True(t, a.unhealthyUntil.IsZero())
+	})
+
+	t.Run("should return error when a target URL is invalid", func(t *testing.T) {
+		// when
+		_, err := newTargetPool([]model.Target{{URL: "http://a"}, {URL: "http://b:port"}}, nil)
+
+		// then
+		require.Error(t, err)
+	})
+}
+
+func TestBalancingRoundTripper(t *testing.T) {
+	newPool := func(t *testing.T) *targetPool {
+		pool, err := newTargetPool([]model.Target{
+			{URL: "http://primary/api?tenant=1"},
+			{URL: "http://secondary", Priority: 1},
+		}, &model.LoadBalancing{Strategy: model.LoadBalancingFailover})
+		require.NoError(t, err)
+		return pool
+	}
+
+	t.Run("should send the call to the target with its path and query", func(t *testing.T) {
+		// given
+		var sent *http.Request
+		roundTripper := newBalancingRoundTripper(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			sent = r
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: r}, nil
+		}), newPool(t), nil)
+
+		req, err := http.NewRequest(http.MethodGet, "/orders?id=1", nil)
+		require.NoError(t, err)
+
+		// when
+		resp, err := roundTripper.RoundTrip(req)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "http://primary/api/orders?tenant=1&id=1", sent.URL.String())
+		assert.Equal(t, "primary", sent.Host)
+		assert.Equal(t, "http://primary/api?tenant=1", targetFromContext(resp.Request.Context()).String())
+	})
+
+	t.Run("should send the idempotent call with body to the next target after an error", func(t *testing.T) {
+		// given
+		var hosts []string
+		roundTripper := newBalancingRoundTripper(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			hosts = append(hosts, r.URL.Host)
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Equal(t, "order", string(body))
+			if r.URL.Host == "primary" {
+				return nil, errors.New("connection refused")
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: r}, nil
+		}), newPool(t), &model.RequestBodyBuffering{Mode: model.BodyBufferingMemory})
+
+		req, err := http.NewRequest(http.MethodPut, "/orders/1", bytes.NewBufferString("order"))
+		require.NoError(t, err)
+
+		// when
+		resp, err := roundTripper.RoundTrip(req)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []string{"primary", "secondary"}, hosts)
+	})
+
+	t.Run("should not send the non-idempotent call to the next target", func(t *testing.T) {
+		// given
+		var hosts []string
+		roundTripper := newBalancingRoundTripper(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			hosts = append(hosts, r.URL.Host)
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody, Request: r}, nil
+		}), newPool(t), &model.RequestBodyBuffering{Mode: model.BodyBufferingMemory})
+
+		req, err := http.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString("order"))
+		require.NoError(t, err)
+
+		// when
+		resp, err := roundTripper.RoundTrip(req)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, []string{"primary"}, hosts)
+	})
+
+	t.Run("should not send the streamed call with body to the next target", func(t *testing.T) {
+		// given
+		var hosts []string
+		roundTripper := newBalancingRoundTripper(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			hosts = append(hosts, r.URL.Host)
+			return nil, errors.New("connection refused")
+		}), newPool(t), nil)
+
+		req, err := http.NewRequest(http.MethodPut, "/orders/1", bytes.NewBufferString("order"))
+		require.NoError(t, err)
+
+		// when
+		_, err = roundTripper.RoundTrip(req)
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, []string{"primary"}, hosts)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
@@ -0,0 +1,90 @@
+package proxy
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+)
+
+// stripRequestHeaders removes the headers of the caller, for example its own Authorization, before the credentials of the API are added
+func stripRequestHeaders(header http.Header, transformations *model.Transformations) {
+	if transformations == nil {
+		return
+	}
+
+	for _, name := range transformations.StripRequestHeaders {
+		header.Del(name)
+	}
+}
+
+// rewritePath applies the path rewrites to the escaped path following the API name, which always starts with a slash when matched
+func rewritePath(path *url.URL, transformations *model.Transformations) apperrors.AppError {
+	if transformations == nil || len(transformations.PathRewrites) == 0 {
+		return nil
+	}
+
+	rewritten := "/" + strings.TrimPrefix(path.EscapedPath(), "/")
+	for _, pathRewrite := range transformations.PathRewrites {
+		rewritten = pathRewrite.Regex.ReplaceAllString(rewritten, pathRewrite.Replacement)
+	}
+
+	unescaped, err := url.PathUnescape(rewritten)
+	if err != nil {
+		return apperrors.Internalf("path rewritten to '%s' is invalid: %s", rewritten, err.Error())
+	}
+	path.Path = unescaped
+	path.RawPath = rewritten
+
+	return nil
+}
+
+// transformHeaders removes, renames and adds the headers in this order
+func transformHeaders(header http.Header, transformations *model.HeaderTransformations) {
+	if transformations == nil {
+		return
+	}
+
+	for _, name := range transformations.Remove {
+		header.Del(name)
+	}
+
+	for oldName, newName := range transformations.Rename {
+		values, found := header[oldName]
+		if !found {
+			continue
+		}
+		header.Del(oldName)
+		header[newName] = append(header[newName], values...)
+	}
+
+	for name, value := range transformations.Add {
+		header.Add(name, value)
+	}
+}
+
+func requestHeaderTransformations(transformations *model.Transformations) *model.HeaderTransformations {
+	if transformations == nil {
+		return nil
+	}
+
+	return transformations.RequestHeaders
+}
+
+// withResponseHeaderTransformations transforms the headers of the response after it's modified by the proxy, e.g. the Location header is rewritten
+func withResponseHeaderTransformations(modifyResponse func(*http.Response) error, transformations *model.Transformations) func(*http.Response) error {
+	if transformations == nil || transformations.ResponseHeaders == nil {
+		return modifyResponse
+	}
+
+	return func(resp *http.Response) error {
+		if err := modifyResponse(resp); err != nil {
+			return err
+		}
+		transformHeaders(resp.Header, transformations.ResponseHeaders)
+
+		return nil
+	}
+}
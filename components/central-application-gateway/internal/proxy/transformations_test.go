@@ -0,0 +1,113 @@
+package proxy
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+)
+
+func TestRewritePath(t *testing.T) {
+	transformations := &model.Transformations{
+		PathRewrites: []model.PathRewrite{
+			{Regex: regexp.MustCompile("^/v1/(.*)$"), Replacement: "/api/v2/$1"},
+			{Regex: regexp.MustCompile("/orders/"), Replacement: "/purchase-orders/"},
+		},
+	}
+
+	t.Run("should apply all path rewrites in order", func(t *testing.T) {
+		// given
+		path := &url.URL{Path: "/v1/orders/123"}
+
+		// when
+		err := rewritePath(path, transformations)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "/api/v2/purchase-orders/123", path.Path)
+	})
+
+	t.Run("should match the path with leading slash and keep it escaped", func(t *testing.T) {
+		// given
+		path, err := url.Parse("v1/orders/a%2Fb")
+		require.NoError(t, err)
+
+		// when
+		appErr := rewritePath(path, transformations)
+
+		// then
+		require.NoError(t, appErr)
+		assert.Equal(t, "/api/v2/purchase-orders/a/b", path.Path)
+		assert.Equal(t, "/api/v2/purchase-orders/a%2Fb", path.EscapedPath())
+	})
+
+	t.Run("should leave the path unchanged without path rewrites", func(t *testing.T) {
+		// given
+		path := &url.URL{Path: "/v1/orders"}
+
+		// when
+		err := rewritePath(path, nil)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "/v1/orders", path.Path)
+	})
+
+	t.Run("should return error when the rewritten path is invalid", func(t *testing.T) {
+		// given
+		path := &url.URL{Path: "/v1/orders"}
+		invalid := &model.Transformations{
+			PathRewrites: []model.PathRewrite{{Regex: regexp.MustCompile("orders"), Replacement: "%zz"}},
+		}
+
+		// when
+		err := rewritePath(path, invalid)
+
+		// then
+		require.Error(t, err)
+	})
+}
+
+func TestTransformHeaders(t *testing.T) {
+	t.Run("should remove, rename and add headers", func(t *testing.T) {
+		// given
+		header := http.Header{
+			"Server":     {"target"},
+			"X-Tenant":   {"tenant"},
+			"X-Customer": {"customer"},
+		}
+
+		// when
+		transformHeaders(header, &model.HeaderTransformations{
+			Remove: []string{"Server"},
+			Rename: map[string]string{"X-Tenant": "X-Customer", "X-Missing": "X-Other"},
+			Add:    map[string]string{"X-Source": "kyma"},
+		})
+
+		// then
+		assert.Equal(t, http.Header{
+			"X-Customer": {"customer", "tenant"},
+			"X-Source":   {"kyma"},
+		}, header)
+	})
+
+	t.Run("should strip request headers", func(t *testing.T) {
+		// given
+		header := http.Header{
+			"Authorization": {"Bearer caller"},
+			"Cookie":        {"session=caller"},
+			"Accept":        {"application/json"},
+		}
+
+		// when
+		stripRequestHeaders(header, &model.Transformations{StripRequestHeaders: []string{"Authorization", "Cookie"}})
+
+		// then
+		assert.Equal(t, http.Header{"Accept": {"application/json"}}, header)
+	})
+}
@@ -0,0 +1,122 @@
+// Package ratelimit contains token buckets which limit the rate of calls proxied to Applications and their services
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metrics"
+)
+
+const (
+	// ScopeApplication marks the limit of calls to all APIs of an Application
+	ScopeApplication = "application"
+	// ScopeService marks the limit of calls to all APIs of a service
+	ScopeService = "service"
+)
+
+// Limit identifies the token bucket and defines its rate
+type Limit struct {
+	Scope       string
+	Application string
+	Service     string
+	model.RateLimit
+}
+
+func (l Limit) key() string {
+	return l.Scope + "/" + l.Application + "/" + l.Service
+}
+
+func (l Limit) rate() (rate.Limit, int) {
+	period := l.Period
+	if period <= 0 {
+		period = time.Second
+	}
+	burst := l.Burst
+	if burst <= 0 {
+		burst = l.Requests
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+
+	return rate.Limit(float64(l.Requests) / period.Seconds()), burst
+}
+
+// Limiters keeps a token bucket for every limited Application and service
+type Limiters interface {
+	// Allow takes a token from the buckets of all limits. If any of them is empty, no token is taken,
+	// and the time after which the call can be retried and the scope of the exceeded limit are returned
+	Allow(limits ...Limit) (retryAfter time.Duration, scope string, allowed bool)
+}
+
+type bucket struct {
+	limiter *rate.Limiter
+	limit   model.RateLimit
+}
+
+type limiters struct {
+	mutex   sync.Mutex
+	buckets map[string]*bucket
+}
+
+// New creates empty token buckets
+func New() Limiters {
+	return &limiters{
+		buckets: map[string]*bucket{},
+	}
+}
+
+func (l *limiters) Allow(limits ...Limit) (time.Duration, string, bool) {
+	reservations := make([]*rate.Reservation, 0, len(limits))
+	cancel := func() {
+		for _, reservation := range reservations {
+			reservation.Cancel()
+		}
+	}
+
+	now := time.Now()
+	for _, limit := range limits {
+		if limit.Requests <= 0 {
+			continue
+		}
+
+		reservation := l.get(limit).ReserveN(now, 1)
+		if delay := reservation.DelayFrom(now); delay > 0 {
+			reservation.CancelAt(now)
+			cancel()
+			return delay, limit.Scope, false
+		}
+		reservations = append(reservations, reservation)
+	}
+
+	return 0, "", true
+}
+
+func (l *limiters) get(limit Limit) *rate.Limiter {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	limitPerSecond, burst := limit.rate()
+
+	b, found := l.buckets[limit.key()]
+	if !found {
+		b = &bucket{limiter: rate.NewLimiter(limitPerSecond, burst), limit: limit.RateLimit}
+		l.buckets[limit.key()] = b
+		metrics.SetRateLimit(limit.Application, limit.Service, limit.Scope, float64(limitPerSecond))
+		return b.limiter
+	}
+
+	// the Application was modified
+	if b.limit != limit.RateLimit {
+		b.limiter.SetLimit(limitPerSecond)
+		b.limiter.SetBurst(burst)
+		b.limit = limit.RateLimit
+		metrics.SetRateLimit(limit.Application, limit.Service, limit.Scope, float64(limitPerSecond))
+	}
+
+	return b.limiter
+}
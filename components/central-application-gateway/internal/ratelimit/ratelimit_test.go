@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+)
+
+func TestLimiters(t *testing.T) {
+	applicationLimit := Limit{
+		Scope:       ScopeApplication,
+		Application: "app",
+		RateLimit:   model.RateLimit{Requests: 3, Period: time.Hour},
+	}
+	serviceLimit := Limit{
+		Scope:       ScopeService,
+		Application: "app",
+		Service:     "service",
+		RateLimit:   model.RateLimit{Requests: 1, Period: time.Hour},
+	}
+
+	t.Run("should allow calls within the burst and reject the next one", func(t *testing.T) {
+		// given
+		limiters := New()
+
+		// when
+		for i := 0; i < 3; i++ {
+			_, _, allowed := limiters.Allow(applicationLimit)
+			assert.True(t, allowed)
+		}
+		retryAfter, scope, allowed := limiters.Allow(applicationLimit)
+
+		// then
+		assert.False(t, allowed)
+		assert.Equal(t, ScopeApplication, scope)
+		assert.InDelta(t, (20 * time.Minute).Seconds(), retryAfter.Seconds(), 1)
+	})
+
+	t.Run("should not take token from other buckets when one limit is exceeded", func(t *testing.T) {
+		// given
+		limiters := New()
+		_, _, allowed := limiters.Allow(serviceLimit, applicationLimit)
+		assert.True(t, allowed)
+
+		// when
+		_, scope, allowed := limiters.Allow(serviceLimit, applicationLimit)
+
+		// then
+		assert.False(t, allowed)
+		assert.Equal(t, ScopeService, scope)
+		for i := 0; i < 2; i++ {
+			_, _, allowed := limiters.Allow(applicationLimit)
+			assert.True(t, allowed)
+		}
+	})
+
+	t.Run("should apply modified limit", func(t *testing.T) {
+		// given
+		limiters := New()
+		_, _, allowed := limiters.Allow(serviceLimit)
+		assert.True(t, allowed)
+
+		// when
+		modifiedLimit := serviceLimit
+		modifiedLimit.Period = time.Millisecond
+		_, _, allowed = limiters.Allow(modifiedLimit)
+		assert.False(t, allowed)
+		time.Sleep(5 * time.Millisecond)
+
+		// then
+		_, _, allowed = limiters.Allow(modifiedLimit)
+		assert.True(t, allowed)
+	})
+
+	t.Run("should allow all calls without limits", func(t *testing.T) {
+		// given
+		limiters := New()
+
+		// when
+		_, _, allowed := limiters.Allow()
+
+		// then
+		assert.True(t, allowed)
+	})
+}
@@ -0,0 +1,233 @@
+package responsecache
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+)
+
+// cacheableStatusCodes are the status codes of responses which can be stored, see RFC 9110, section 15.1
+var cacheableStatusCodes = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusPermanentRedirect:    true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+}
+
+// Cacheable reports whether the response to the call can be taken from or stored in the cache.
+// Calls with their own credentials are never cached, because the cache is shared by all callers.
+func Cacheable(r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		return false
+	}
+	if r.Header.Get(httpconsts.HeaderAuthorization) != "" || r.Header.Get(httpconsts.HeaderAccessToken) != "" {
+		return false
+	}
+	_, noStore := cacheControl(r.Header)["no-store"]
+
+	return !noStore
+}
+
+// RequiresValidation reports whether the caller asks for a response validated by the target system
+func RequiresValidation(r *http.Request) bool {
+	directives := cacheControl(r.Header)
+	if _, noCache := directives["no-cache"]; noCache {
+		return true
+	}
+	if maxAge, found := directives["max-age"]; found && maxAge == "0" {
+		return true
+	}
+
+	return len(directives) == 0 && r.Header.Get("Pragma") == "no-cache"
+}
+
+// Conditional reports whether the caller sends its own conditional headers, which are passed to the target system unchanged
+func Conditional(header http.Header) bool {
+	for _, name := range []string{"If-None-Match", "If-Modified-Since", "If-Match", "If-Unmodified-Since", "If-Range"} {
+		if header.Get(name) != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Storable reports whether the response can be stored, judging by its status code and headers
+func Storable(statusCode int, header http.Header) bool {
+	if !cacheableStatusCodes[statusCode] || header.Get("Set-Cookie") != "" {
+		return false
+	}
+
+	directives := cacheControl(header)
+	if _, noStore := directives["no-store"]; noStore {
+		return false
+	}
+	if _, private := directives["private"]; private {
+		return false
+	}
+	for _, name := range varyNames(header) {
+		if name == "*" {
+			return false
+		}
+	}
+
+	// responses without explicit freshness are stored if they can be validated
+	_, explicit := lifetime(header)
+
+	return explicit || header.Get("ETag") != "" || header.Get("Last-Modified") != ""
+}
+
+// NewResponse creates the response to store, received from the target system at the given time
+func NewResponse(statusCode int, header http.Header, body []byte, now time.Time) *Response {
+	header = header.Clone()
+	date := now.Add(-age(header))
+	header.Del("Age")
+
+	ttl, _ := lifetime(header)
+
+	return &Response{
+		StatusCode: statusCode,
+		Header:     header,
+		Body:       body,
+		date:       date,
+		freshUntil: date.Add(ttl),
+	}
+}
+
+// Revalidated returns a copy of the response updated with the headers of the 304 Not Modified response of the target system,
+// and false if the updated response can't be stored anymore
+func (r *Response) Revalidated(header http.Header, now time.Time) (*Response, bool) {
+	updated := r.Header.Clone()
+	for name, values := range header {
+		if name == "Content-Length" {
+			continue
+		}
+		updated[name] = append([]string(nil), values...)
+	}
+
+	return NewResponse(r.StatusCode, updated, r.Body, now), Storable(r.StatusCode, updated)
+}
+
+// AddConditions sets the conditional headers which let the target system confirm that the response is still valid.
+// It returns false if the response has no validators.
+func (r *Response) AddConditions(header http.Header) bool {
+	etag := r.Header.Get("ETag")
+	lastModified := r.Header.Get("Last-Modified")
+	if etag != "" {
+		header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		header.Set("If-Modified-Since", lastModified)
+	}
+
+	return etag != "" || lastModified != ""
+}
+
+// NotModified reports whether the conditional headers of the caller match the response, so that 304 Not Modified can be returned
+func (r *Response) NotModified(header http.Header) bool {
+	if r.StatusCode != http.StatusOK {
+		return false
+	}
+
+	if ifNoneMatch := header.Get("If-None-Match"); ifNoneMatch != "" {
+		etag := weak(r.Header.Get("ETag"))
+		if etag == "" {
+			return false
+		}
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || weak(candidate) == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ifModifiedSince := header.Get("If-Modified-Since"); ifModifiedSince != "" {
+		since, err := http.ParseTime(ifModifiedSince)
+		if err != nil {
+			return false
+		}
+		lastModified, err := http.ParseTime(r.Header.Get("Last-Modified"))
+		if err != nil {
+			return false
+		}
+		return !lastModified.After(since)
+	}
+
+	return false
+}
+
+// weak strips the weak indicator, as If-None-Match uses the weak comparison
+func weak(etag string) string {
+	return strings.TrimPrefix(etag, "W/")
+}
+
+// lifetime returns the time for which the response is fresh, and false if the response doesn't define it
+func lifetime(header http.Header) (time.Duration, bool) {
+	directives := cacheControl(header)
+	if _, noCache := directives["no-cache"]; noCache {
+		return 0, true
+	}
+	for _, name := range []string{"s-maxage", "max-age"} {
+		if value, found := directives[name]; found {
+			seconds, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || seconds < 0 {
+				return 0, true
+			}
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+
+	if value := header.Get("Expires"); value != "" {
+		expires, err := http.ParseTime(value)
+		if err != nil {
+			// invalid dates, e.g. 0, mean that the response is already expired
+			return 0, true
+		}
+		date, err := http.ParseTime(header.Get("Date"))
+		if err != nil {
+			date = time.Now()
+		}
+		if ttl := expires.Sub(date); ttl > 0 {
+			return ttl, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
+
+func age(header http.Header) time.Duration {
+	seconds, err := strconv.ParseInt(header.Get("Age"), 10, 64)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// cacheControl returns the lowercase directives of the Cache-Control header with their unquoted values
+func cacheControl(header http.Header) map[string]string {
+	directives := map[string]string{}
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, argument, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name == "" {
+				continue
+			}
+			directives[strings.ToLower(name)] = strings.Trim(argument, `"`)
+		}
+	}
+
+	return directives
+}
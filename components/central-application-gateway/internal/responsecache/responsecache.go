@@ -0,0 +1,219 @@
+// Package responsecache keeps responses to GET calls proxied to APIs which enable caching, according to their Cache-Control, Expires, ETag, Last-Modified and Vary headers
+package responsecache
+
+import (
+	"container/list"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metrics"
+)
+
+// Key identifies the cached responses of an API by the URL called by the caller, so that responses never cross Applications
+type Key struct {
+	API model.APIIdentifier
+	URL string
+}
+
+// Response is a response stored in the cache, it isn't modified once stored
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+
+	// date is the time the response was generated by the target system, corrected by the Age header
+	date       time.Time
+	freshUntil time.Time
+}
+
+// Fresh reports whether the response can be returned without validating it with the target system
+func (r *Response) Fresh(now time.Time) bool {
+	return now.Before(r.freshUntil)
+}
+
+// Age returns the time since the response was generated or validated by the target system
+func (r *Response) Age(now time.Time) time.Duration {
+	if age := now.Sub(r.date); age > 0 {
+		return age
+	}
+	return 0
+}
+
+// TTL returns the time for which the response stays fresh
+func (r *Response) TTL(now time.Time) time.Duration {
+	if ttl := r.freshUntil.Sub(now); ttl > 0 {
+		return ttl
+	}
+	return 0
+}
+
+func (r *Response) size() int64 {
+	size := int64(len(r.Body))
+	for name, values := range r.Header {
+		size += int64(len(name))
+		for _, value := range values {
+			size += int64(len(value))
+		}
+	}
+
+	return size
+}
+
+// Cache keeps responses up to the maximum total size, evicting the least recently used ones
+type Cache interface {
+	// Get returns the response stored for the key whose Vary headers match the headers of the call
+	Get(key Key, header http.Header) (*Response, bool)
+	// Put stores the response to the call with the given headers, replacing the previous one with the same key and Vary headers
+	Put(key Key, header http.Header, response *Response)
+	// Invalidate removes the responses of all APIs of the Application
+	Invalidate(application string)
+}
+
+type entry struct {
+	key      Key
+	variant  string
+	response *Response
+	size     int64
+}
+
+type cache struct {
+	mutex   sync.Mutex
+	maxSize int64
+	size    int64
+	// lru has the most recently used entries at the front
+	lru *list.List
+	// vary keeps the names of the headers listed in the Vary header of the last response stored for the key
+	vary     map[Key][]string
+	elements map[Key]map[string]*list.Element
+}
+
+// New creates the cache keeping responses up to maxSize bytes
+func New(maxSize int64) Cache {
+	return &cache{
+		maxSize:  maxSize,
+		lru:      list.New(),
+		vary:     map[Key][]string{},
+		elements: map[Key]map[string]*list.Element{},
+	}
+}
+
+func (c *cache) Get(key Key, header http.Header) (*Response, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, found := c.elements[key][variant(header, c.vary[key])]
+	if !found {
+		return nil, false
+	}
+	c.lru.MoveToFront(element)
+
+	return element.Value.(*entry).response, true
+}
+
+func (c *cache) Put(key Key, header http.Header, response *Response) {
+	size := response.size()
+	if size > c.maxSize {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	names := varyNames(response.Header)
+	if !equal(c.vary[key], names) {
+		// responses selected by other headers can't be found anymore
+		c.removeKey(key)
+	}
+
+	v := variant(header, names)
+	if element, found := c.elements[key][v]; found {
+		c.remove(element)
+	}
+
+	c.vary[key] = names
+	if c.elements[key] == nil {
+		c.elements[key] = map[string]*list.Element{}
+	}
+	c.elements[key][v] = c.lru.PushFront(&entry{key: key, variant: v, response: response, size: size})
+	c.size += size
+
+	for c.size > c.maxSize {
+		c.remove(c.lru.Back())
+	}
+	metrics.SetResponseCacheSize(c.size)
+}
+
+func (c *cache) Invalidate(application string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for key := range c.elements {
+		if key.API.Application == application {
+			c.removeKey(key)
+		}
+	}
+	metrics.SetResponseCacheSize(c.size)
+}
+
+func (c *cache) removeKey(key Key) {
+	for _, element := range c.elements[key] {
+		c.remove(element)
+	}
+	delete(c.vary, key)
+}
+
+func (c *cache) remove(element *list.Element) {
+	e := c.lru.Remove(element).(*entry)
+	c.size -= e.size
+
+	delete(c.elements[e.key], e.variant)
+	if len(c.elements[e.key]) == 0 {
+		delete(c.elements, e.key)
+		delete(c.vary, e.key)
+	}
+}
+
+// varyNames returns the sorted canonical names of the headers which select the response
+func varyNames(header http.Header) []string {
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// variant joins the values of the Vary headers of the call
+func variant(header http.Header, names []string) string {
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(strings.Join(header.Values(name), ","))
+		b.WriteByte('\n')
+	}
+
+	return b.String()
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,187 @@
+package responsecache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/internal/metadata/model"
+)
+
+func TestCache(t *testing.T) {
+	now := time.Now()
+	key := Key{API: model.APIIdentifier{Application: "app", Service: "service", Entry: "entry"}, URL: "/orders?id=1"}
+
+	newResponse := func(body string, header http.Header) *Response {
+		if header == nil {
+			header = http.Header{}
+		}
+		header.Set("Cache-Control", "max-age=60")
+		return NewResponse(http.StatusOK, header, []byte(body), now)
+	}
+
+	t.Run("should return the stored response only for the same API and URL", func(t *testing.T) {
+		// given
+		cache := New(1024)
+		otherApplication := key
+		otherApplication.API.Application = "other-app"
+		otherURL := key
+		otherURL.URL = "/orders?id=2"
+
+		// when
+		cache.Put(key, http.Header{}, newResponse("orders", nil))
+
+		// then
+		response, found := cache.Get(key, http.Header{})
+		require.True(t, found)
+		assert.Equal(t, "orders", string(response.Body))
+
+		_, found = cache.Get(otherApplication, http.Header{})
+		assert.False(t, found)
+		_, found = cache.Get(otherURL, http.Header{})
+		assert.False(t, found)
+	})
+
+	t.Run("should select the response by the headers listed in Vary", func(t *testing.T) {
+		// given
+		cache := New(1024)
+
+		// when
+		cache.Put(key, http.Header{"Accept-Language": {"en"}}, newResponse("orders", http.Header{"Vary": {"accept-language"}}))
+		cache.Put(key, http.Header{"Accept-Language": {"de"}}, newResponse("Bestellungen", http.Header{"Vary": {"accept-language"}}))
+
+		// then
+		response, found := cache.Get(key, http.Header{"Accept-Language": {"en"}})
+		require.True(t, found)
+		assert.Equal(t, "orders", string(response.Body))
+
+		response, found = cache.Get(key, http.Header{"Accept-Language": {"de"}})
+		require.True(t, found)
+		assert.Equal(t, "Bestellungen", string(response.Body))
+
+		_, found = cache.Get(key, http.Header{"Accept-Language": {"fr"}})
+		assert.False(t, found)
+	})
+
+	t.Run("should evict the least recently used responses exceeding the size", func(t *testing.T) {
+		// given
+		cache := New(NewResponse(http.StatusOK, http.Header{"Cache-Control": {"max-age=60"}}, []byte("0123456789"), now).size() * 2)
+		first, second, third := key, key, key
+		first.URL, second.URL, third.URL = "/1", "/2", "/3"
+
+		// when
+		cache.Put(first, http.Header{}, newResponse("0123456789", nil))
+		cache.Put(second, http.Header{}, newResponse("0123456789", nil))
+		_, _ = cache.Get(first, http.Header{})
+		cache.Put(third, http.Header{}, newResponse("0123456789", nil))
+
+		// then
+		_, found := cache.Get(first, http.Header{})
+		assert.True(t, found)
+		_, found = cache.Get(second, http.Header{})
+		assert.False(t, found)
+		_, found = cache.Get(third, http.Header{})
+		assert.True(t, found)
+	})
+
+	t.Run("should remove the responses of the invalidated Application", func(t *testing.T) {
+		// given
+		cache := New(1024)
+		otherApplication := key
+		otherApplication.API.Application = "other-app"
+		cache.Put(key, http.Header{}, newResponse("orders", nil))
+		cache.Put(otherApplication, http.Header{}, newResponse("orders", nil))
+
+		// when
+		cache.Invalidate("app")
+
+		// then
+		_, found := cache.Get(key, http.Header{})
+		assert.False(t, found)
+		_, found = cache.Get(otherApplication, http.Header{})
+		assert.True(t, found)
+	})
+}
+
+func TestPolicy(t *testing.T) {
+	now := time.Now()
+
+	t.Run("should store responses with explicit freshness or validators", func(t *testing.T) {
+		assert.True(t, Storable(http.StatusOK, http.Header{"Cache-Control": {"public, max-age=60"}}))
+		assert.True(t, Storable(http.StatusOK, http.Header{"Expires": {now.Add(time.Minute).Format(http.TimeFormat)}}))
+		assert.True(t, Storable(http.StatusOK, http.Header{"Etag": {`"v1"`}}))
+		assert.True(t, Storable(http.StatusNotFound, http.Header{"Cache-Control": {"max-age=60"}}))
+
+		assert.False(t, Storable(http.StatusOK, http.Header{}))
+		assert.False(t, Storable(http.StatusOK, http.Header{"Cache-Control": {"no-store"}}))
+		assert.False(t, Storable(http.StatusOK, http.Header{"Cache-Control": {"private, max-age=60"}}))
+		assert.False(t, Storable(http.StatusOK, http.Header{"Cache-Control": {"max-age=60"}, "Set-Cookie": {"session=1"}}))
+		assert.False(t, Storable(http.StatusOK, http.Header{"Cache-Control": {"max-age=60"}, "Vary": {"*"}}))
+		assert.False(t, Storable(http.StatusBadGateway, http.Header{"Cache-Control": {"max-age=60"}}))
+	})
+
+	t.Run("should compute freshness from s-maxage, max-age and Age", func(t *testing.T) {
+		// when
+		sharedMaxAge := NewResponse(http.StatusOK, http.Header{"Cache-Control": {"max-age=10, s-maxage=120"}}, nil, now)
+		aged := NewResponse(http.StatusOK, http.Header{"Cache-Control": {"max-age=60"}, "Age": {"50"}}, nil, now)
+		noCache := NewResponse(http.StatusOK, http.Header{"Cache-Control": {"no-cache"}, "Etag": {`"v1"`}}, nil, now)
+
+		// then
+		assert.True(t, sharedMaxAge.Fresh(now.Add(100*time.Second)))
+		assert.True(t, aged.Fresh(now.Add(5*time.Second)))
+		assert.False(t, aged.Fresh(now.Add(15*time.Second)))
+		assert.Equal(t, 50*time.Second, aged.Age(now))
+		assert.Empty(t, aged.Header.Get("Age"))
+		assert.False(t, noCache.Fresh(now))
+	})
+
+	t.Run("should bypass the cache for calls with credentials of the caller", func(t *testing.T) {
+		get := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		post := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		withToken := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		withToken.Header.Set("Access-Token", "Bearer token")
+		noStore := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		noStore.Header.Set("Cache-Control", "no-store")
+
+		assert.True(t, Cacheable(get))
+		assert.False(t, Cacheable(post))
+		assert.False(t, Cacheable(withToken))
+		assert.False(t, Cacheable(noStore))
+	})
+
+	t.Run("should add conditions and update the response revalidated by the target system", func(t *testing.T) {
+		// given
+		stored := NewResponse(http.StatusOK, http.Header{"Cache-Control": {"max-age=0"}, "Etag": {`"v1"`}, "Content-Type": {"application/json"}}, []byte("orders"), now)
+		header := http.Header{}
+
+		// when
+		conditional := stored.AddConditions(header)
+		revalidated, storable := stored.Revalidated(http.Header{"Cache-Control": {"max-age=60"}, "Content-Length": {"0"}}, now)
+
+		// then
+		assert.True(t, conditional)
+		assert.Equal(t, `"v1"`, header.Get("If-None-Match"))
+		assert.True(t, storable)
+		assert.True(t, revalidated.Fresh(now.Add(time.Second)))
+		assert.Equal(t, "application/json", revalidated.Header.Get("Content-Type"))
+		assert.Empty(t, revalidated.Header.Get("Content-Length"))
+		assert.Equal(t, "orders", string(revalidated.Body))
+	})
+
+	t.Run("should match the conditional headers of the caller", func(t *testing.T) {
+		// given
+		lastModified := now.Add(-time.Hour).UTC()
+		stored := NewResponse(http.StatusOK, http.Header{"Etag": {`W/"v1"`}, "Last-Modified": {lastModified.Format(http.TimeFormat)}}, nil, now)
+
+		// then
+		assert.True(t, stored.NotModified(http.Header{"If-None-Match": {`"v0", "v1"`}}))
+		assert.False(t, stored.NotModified(http.Header{"If-None-Match": {`"v2"`}}))
+		assert.True(t, stored.NotModified(http.Header{"If-Modified-Since": {now.UTC().Format(http.TimeFormat)}}))
+		assert.False(t, stored.NotModified(http.Header{"If-Modified-Since": {lastModified.Add(-time.Hour).Format(http.TimeFormat)}}))
+		assert.False(t, stored.NotModified(http.Header{}))
+	})
+}
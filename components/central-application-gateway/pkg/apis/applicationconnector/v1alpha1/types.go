@@ -58,13 +58,118 @@ type ApplicationSpec struct {
 	ProviderDisplayName string   `json:"providerDisplayName"`
 	LongDescription     string   `json:"longDescription"`
 
-	SkipVerify bool `json:"skipVerify"`
-	EncodeURL  bool `json:"encodeUrl"`
+	SkipVerify           bool                  `json:"skipVerify"`
+	EncodeURL            bool                  `json:"encodeUrl"`
+	RetryPolicy          *RetryPolicy          `json:"retryPolicy,omitempty"`
+	RequestBodyBuffering *RequestBodyBuffering `json:"requestBodyBuffering,omitempty"`
+	RateLimit            *RateLimit            `json:"rateLimit,omitempty"`
+	UpstreamProxy        *UpstreamProxy        `json:"upstreamProxy,omitempty"`
+	TLS                  *TLS                  `json:"tls,omitempty"`
+	AccessControl        *AccessControl        `json:"accessControl,omitempty"`
 
 	// Deprecated
 	AccessLabel string `json:"accessLabel,omitempty"`
 }
 
+// RetryPolicy defines how idempotent calls to the Application's APIs are retried
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of calls, including the first one
+	MaxAttempts int `json:"maxAttempts"`
+	// InitialBackoff is the delay before the first retry, doubled with every next retry
+	InitialBackoff *metav1.Duration `json:"initialBackoff,omitempty"`
+	// MaxBackoff limits the delay between retries
+	MaxBackoff *metav1.Duration `json:"maxBackoff,omitempty"`
+	// RetryableStatusCodes lists response status codes which cause a retry
+	RetryableStatusCodes []int `json:"retryableStatusCodes,omitempty"`
+}
+
+// RequestBodyBuffering defines how request bodies are kept to be sent again when calls to the Application's APIs are retried
+type RequestBodyBuffering struct {
+	// Mode is Memory, File or Stream
+	Mode string `json:"mode,omitempty"`
+	// MemoryLimit is the number of bytes kept in memory in the File and Stream modes
+	MemoryLimit int64 `json:"memoryLimit,omitempty"`
+}
+
+// RateLimit defines the token bucket which limits the rate of calls proxied to an Application or its service
+type RateLimit struct {
+	// Requests is the number of calls allowed per Period
+	Requests int `json:"requests"`
+	// Period defaults to one second
+	Period *metav1.Duration `json:"period,omitempty"`
+	// Burst is the number of calls allowed at once, defaults to Requests
+	Burst int `json:"burst,omitempty"`
+}
+
+// UpstreamProxy defines the HTTP or SOCKS5 proxy through which the Application's APIs and token endpoints are called
+type UpstreamProxy struct {
+	// URL of the proxy with the http, https, socks5 or socks5h scheme
+	URL string `json:"url"`
+	// CredentialsSecretName is the name of the Secret with the username and password keys authenticating to the proxy
+	CredentialsSecretName string `json:"credentialsSecretName,omitempty"`
+}
+
+// AccessControl restricts the in-cluster callers of the Application's APIs, a call is allowed if its caller matches any of the lists
+type AccessControl struct {
+	// ServiceAccounts lists the ServiceAccounts whose tokens the callers send in the X-Caller-Token header
+	ServiceAccounts []ServiceAccountReference `json:"serviceAccounts,omitempty"`
+	// SPIFFEIDs lists the SPIFFE IDs of the client certificates of the callers' mTLS connections
+	SPIFFEIDs []string `json:"spiffeIds,omitempty"`
+	// Namespaces lists the namespaces of the callers' ServiceAccounts or SPIFFE IDs
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// ServiceAccountReference identifies a ServiceAccount
+type ServiceAccountReference struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// TLS defines how TLS connections to the target system and its token endpoints are opened and verified
+type TLS struct {
+	// CABundle is the source of the PEM certificates of the certificate authorities trusted instead of the system ones
+	CABundle *CABundle `json:"caBundle,omitempty"`
+	// MinVersion is 1.0, 1.1, 1.2 or 1.3, defaults to 1.2
+	MinVersion string `json:"minVersion,omitempty"`
+	// CipherSuites lists the names of the cipher suites allowed up to TLS 1.2, empty allows the default ones
+	CipherSuites []string `json:"cipherSuites,omitempty"`
+	// ServerName replaces the host of the target URL in SNI and in the verification of the certificate
+	ServerName string `json:"serverName,omitempty"`
+	// PinnedPublicKeys lists the base64-encoded SHA-256 hashes of public keys, one of which must be in the certificate chain of the target system
+	PinnedPublicKeys []string `json:"pinnedPublicKeys,omitempty"`
+}
+
+// CABundle is the Secret or ConfigMap with the PEM certificates of trusted certificate authorities
+type CABundle struct {
+	SecretName    string `json:"secretName,omitempty"`
+	ConfigMapName string `json:"configMapName,omitempty"`
+	// Key of the certificates in the Secret or ConfigMap, defaults to ca.crt
+	Key string `json:"key,omitempty"`
+}
+
+// ResponseCache defines how responses to GET calls to an API are cached according to their Cache-Control, Expires, ETag, Last-Modified and Vary headers
+type ResponseCache struct {
+	// MaxEntrySize is the maximum size, in bytes, of a cached response body, defaults to the limit of Application Gateway
+	MaxEntrySize int64 `json:"maxEntrySize,omitempty"`
+}
+
+// Target is an instance of the target system serving the API of an entry
+type Target struct {
+	URL string `json:"url"`
+	// Priority orders the targets in the Failover strategy, lower values first
+	Priority int `json:"priority,omitempty"`
+}
+
+// LoadBalancing defines how calls are distributed among the targets of an entry and when a target is skipped as unhealthy
+type LoadBalancing struct {
+	// Strategy is RoundRobin or Failover, empty means RoundRobin
+	Strategy string `json:"strategy,omitempty"`
+	// UnhealthyThreshold is the number of consecutive failed calls after which a target is unhealthy, defaults to 3
+	UnhealthyThreshold int `json:"unhealthyThreshold,omitempty"`
+	// UnhealthyDuration is the time for which an unhealthy target is skipped, defaults to 30 seconds
+	UnhealthyDuration *metav1.Duration `json:"unhealthyDuration,omitempty"`
+}
+
 type CompassMetadata struct {
 	ApplicationID  string         `json:"applicationId"`
 	Authentication Authentication `json:"authentication"`
@@ -74,14 +179,55 @@ type Authentication struct {
 	ClientIds []string `json:"clientIds"`
 }
 
+// Transformations modify the calls proxied to the API of an entry and the responses returned to the caller
+type Transformations struct {
+	Request  *RequestTransformations `json:"request,omitempty"`
+	Response *HeaderTransformations  `json:"response,omitempty"`
+}
+
+// RequestTransformations modify the calls proxied to the API
+type RequestTransformations struct {
+	// StripHeaders lists headers of the caller which are removed before the credentials of the API are added
+	StripHeaders []string `json:"stripHeaders,omitempty"`
+	// PathRewrites are applied in order to the path following the API name
+	PathRewrites []PathRewrite          `json:"pathRewrites,omitempty"`
+	Headers      *HeaderTransformations `json:"headers,omitempty"`
+}
+
+// HeaderTransformations are applied in the order: remove, rename, add
+type HeaderTransformations struct {
+	Remove []string `json:"remove,omitempty"`
+	// Rename maps the old header names to the new ones
+	Rename map[string]string `json:"rename,omitempty"`
+	// Add appends the values to the headers
+	Add map[string]string `json:"add,omitempty"`
+}
+
+// PathRewrite replaces the matches of the regular expression, the replacement can refer to capture groups, for example $1
+type PathRewrite struct {
+	Regex       string `json:"regex"`
+	Replacement string `json:"replacement"`
+}
+
 // Entry defines, what is enabled by activating the service.
 type Entry struct {
-	Type                        string      `json:"type"`
-	TargetUrl                   string      `json:"targetUrl"`
-	SpecificationUrl            string      `json:"specificationUrl,omitempty"`
-	ApiType                     string      `json:"apiType,omitempty"`
-	Credentials                 Credentials `json:"credentials,omitempty"`
-	RequestParametersSecretName string      `json:"requestParametersSecretName,omitempty"`
+	Type                        string           `json:"type"`
+	TargetUrl                   string           `json:"targetUrl"`
+	SpecificationUrl            string           `json:"specificationUrl,omitempty"`
+	ApiType                     string           `json:"apiType,omitempty"`
+	Credentials                 Credentials      `json:"credentials,omitempty"`
+	RequestParametersSecretName string           `json:"requestParametersSecretName,omitempty"`
+	Transformations             *Transformations `json:"transformations,omitempty"`
+	// ProxyMode is one of Buffered, PassThrough, HTTP2, empty means Buffered
+	ProxyMode string `json:"proxyMode,omitempty"`
+	// ResponseCache enables caching of responses to GET calls, nil disables it
+	ResponseCache *ResponseCache `json:"responseCache,omitempty"`
+	// Targets lists the instances of the target system serving the API, TargetUrl is used if it's empty
+	Targets []Target `json:"targets,omitempty"`
+	// LoadBalancing defines how calls are distributed among Targets
+	LoadBalancing *LoadBalancing `json:"loadBalancing,omitempty"`
+	// TLS replaces the TLS settings of the Application for calls to this API
+	TLS *TLS `json:"tls,omitempty"`
 
 	// New fields used by V2 version
 	Name string `json:"name"`
@@ -111,12 +257,13 @@ type Credentials struct {
 // - service class in V1
 // - service plans in V2 (since api-packages support)
 type Service struct {
-	ID          string  `json:"id"`
-	Identifier  string  `json:"identifier"`
-	Name        string  `json:"name"`
-	DisplayName string  `json:"displayName"`
-	Description string  `json:"description"`
-	Entries     []Entry `json:"entries"`
+	ID          string     `json:"id"`
+	Identifier  string     `json:"identifier"`
+	Name        string     `json:"name"`
+	DisplayName string     `json:"displayName"`
+	Description string     `json:"description"`
+	Entries     []Entry    `json:"entries"`
+	RateLimit   *RateLimit `json:"rateLimit,omitempty"`
 
 	// New fields used by V2 version
 	AuthCreateParameterSchema *string `json:"authCreateParameterSchema,omitempty"`
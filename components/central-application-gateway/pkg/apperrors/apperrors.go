@@ -8,6 +8,7 @@ const (
 	CodeAlreadyExists            = 3
 	CodeWrongInput               = 4
 	CodeUpstreamServerCallFailed = 5
+	CodeForbidden                = 6
 )
 
 type AppError interface {
@@ -56,6 +57,10 @@ func UpstreamServerCallFailed(format string, a ...interface{}) AppError {
 	return errorf(CodeUpstreamServerCallFailed, format, a...)
 }
 
+func Forbiddenf(format string, a ...interface{}) AppError {
+	return errorf(CodeForbidden, format, a...)
+}
+
 func (ae appError) Code() int {
 	return ae.code
 }
@@ -14,6 +14,7 @@ func TestAppError(t *testing.T) {
 		assert.Equal(t, CodeAlreadyExists, AlreadyExists("error").Code())
 		assert.Equal(t, CodeWrongInput, WrongInputf("error").Code())
 		assert.Equal(t, CodeUpstreamServerCallFailed, UpstreamServerCallFailed("error").Code())
+		assert.Equal(t, CodeForbidden, Forbiddenf("error").Code())
 	})
 
 	t.Run("should create error with simple message", func(t *testing.T) {
@@ -22,6 +23,7 @@ func TestAppError(t *testing.T) {
 		assert.Equal(t, "error", AlreadyExists("error").Error())
 		assert.Equal(t, "error", WrongInputf("error").Error())
 		assert.Equal(t, "error", UpstreamServerCallFailed("error").Error())
+		assert.Equal(t, "error", Forbiddenf("error").Error())
 	})
 
 	t.Run("should create error with formatted message", func(t *testing.T) {
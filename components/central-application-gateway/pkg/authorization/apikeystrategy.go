@@ -0,0 +1,61 @@
+package authorization
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/clientcert"
+)
+
+type apiKeyStrategy struct {
+	key       string
+	name      string
+	placement string
+	prefix    string
+}
+
+func newAPIKeyStrategy(key, name, placement, prefix string) apiKeyStrategy {
+	return apiKeyStrategy{
+		key:       key,
+		name:      name,
+		placement: placement,
+		prefix:    prefix,
+	}
+}
+
+func (a apiKeyStrategy) AddAuthorization(r *http.Request, _ clientcert.SetClientCertificateFunc, _ bool) apperrors.AppError {
+	switch a.placement {
+	case APIKeyPlacementHeader:
+		r.Header.Set(a.name, a.prefix+a.key)
+	case APIKeyPlacementQuery:
+		r.URL.RawQuery = a.setQueryParameter(r.URL.RawQuery)
+	default:
+		return apperrors.Internalf("unsupported placement '%s' of the API key", a.placement)
+	}
+
+	return nil
+}
+
+func (a apiKeyStrategy) Invalidate() {
+}
+
+// setQueryParameter replaces the query parameter with the same name sent by the caller with the API key,
+// other query parameters are kept in their order and encoding, as the target system may depend on them
+func (a apiKeyStrategy) setQueryParameter(rawQuery string) string {
+	var parameters []string
+	for _, parameter := range strings.Split(rawQuery, "&") {
+		if parameter == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(parameter, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil && unescaped == a.name {
+			continue
+		}
+		parameters = append(parameters, parameter)
+	}
+	parameters = append(parameters, url.QueryEscape(a.name)+"="+url.QueryEscape(a.prefix+a.key))
+
+	return strings.Join(parameters, "&")
+}
@@ -0,0 +1,75 @@
+package authorization
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+)
+
+func TestAPIKeyStrategy(t *testing.T) {
+
+	t.Run("should add API key header with prefix", func(t *testing.T) {
+		// given
+		apiKeyStrategy := newAPIKeyStrategy("secret-key", "X-API-Key", APIKeyPlacementHeader, "ApiKey ")
+
+		request, err := http.NewRequest("GET", "http://www.example.com/orders", nil)
+		require.NoError(t, err)
+		request.Header.Set("X-Api-Key", "caller-key")
+
+		// when
+		err = apiKeyStrategy.AddAuthorization(request, nil, false)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ApiKey secret-key"}, request.Header.Values("X-API-Key"))
+	})
+
+	t.Run("should add API key query parameter and keep other query parameters", func(t *testing.T) {
+		// given
+		apiKeyStrategy := newAPIKeyStrategy("secret key", "api_key", APIKeyPlacementQuery, "")
+
+		request, err := http.NewRequest("GET", "http://www.example.com/orders?top=10&api_key=caller-key", nil)
+		require.NoError(t, err)
+
+		// when
+		err = apiKeyStrategy.AddAuthorization(request, nil, false)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "top=10&api_key=secret+key", request.URL.RawQuery)
+	})
+
+	t.Run("should keep the order and encoding of other query parameters", func(t *testing.T) {
+		// given
+		apiKeyStrategy := newAPIKeyStrategy("secret-key", "api key", APIKeyPlacementQuery, "")
+
+		request, err := http.NewRequest("GET", "http://www.example.com/orders?$top=10&filter=name%20eq%20'a;b'&api%20key=caller-key&$skip=5", nil)
+		require.NoError(t, err)
+
+		// when
+		err = apiKeyStrategy.AddAuthorization(request, nil, false)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "$top=10&filter=name%20eq%20'a;b'&$skip=5&api+key=secret-key", request.URL.RawQuery)
+	})
+
+	t.Run("should return error when placement is not supported", func(t *testing.T) {
+		// given
+		apiKeyStrategy := newAPIKeyStrategy("secret-key", "api_key", "cookie", "")
+
+		request, err := http.NewRequest("GET", "http://www.example.com/orders", nil)
+		require.NoError(t, err)
+
+		// when
+		appErr := apiKeyStrategy.AddAuthorization(request, nil, false)
+
+		// then
+		require.Error(t, appErr)
+		assert.Equal(t, apperrors.CodeInternal, appErr.Code())
+	})
+}
@@ -0,0 +1,252 @@
+package authorization
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/clientcert"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+)
+
+const (
+	awsSigV4Algorithm     = "AWS4-HMAC-SHA256"
+	awsSigV4Request       = "aws4_request"
+	awsSigV4TimeFormat    = "20060102T150405Z"
+	awsSigV4DateFormat    = "20060102"
+	awsUnsignedPayload    = "UNSIGNED-PAYLOAD"
+	awsS3Service          = "s3"
+	headerAmzDate         = "X-Amz-Date"
+	headerAmzContentHash  = "X-Amz-Content-Sha256"
+	headerAmzSessionToken = "X-Amz-Security-Token"
+)
+
+// awsSigV4Strategy signs calls with the AWS Signature Version 4. The signature covers the final URL, headers and body of the call,
+// so the call is signed again right before it's sent, see SigningStrategy.
+type awsSigV4Strategy struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+	region          string
+	service         string
+	now             func() time.Time
+}
+
+func newAWSSigV4Strategy(accessKeyID, secretAccessKey, sessionToken, region, service string) awsSigV4Strategy {
+	return awsSigV4Strategy{
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		sessionToken:    sessionToken,
+		region:          region,
+		service:         service,
+		now:             time.Now,
+	}
+}
+
+// AddAuthorization signs the call as it is, the calls of the proxy are signed again once their URL is final and their body is buffered
+func (a awsSigV4Strategy) AddAuthorization(r *http.Request, _ clientcert.SetClientCertificateFunc, _ bool) apperrors.AppError {
+	r.Header.Del(httpconsts.HeaderAuthorization)
+	return a.sign(r, true)
+}
+
+// Sign replaces the signature added by AddAuthorization, the calls authorized with the token of the caller aren't signed.
+// Only S3 accepts unsigned payloads, so the streamed bodies sent to other services fail the call.
+func (a awsSigV4Strategy) Sign(r *http.Request) apperrors.AppError {
+	if !strings.HasPrefix(r.Header.Get(httpconsts.HeaderAuthorization), awsSigV4Algorithm+" ") {
+		return nil
+	}
+
+	return a.sign(r, a.service == awsS3Service)
+}
+
+func (a awsSigV4Strategy) Invalidate() {
+}
+
+// sign signs the call, allowUnsignedPayload lets the streamed body be signed as UNSIGNED-PAYLOAD
+func (a awsSigV4Strategy) sign(r *http.Request, allowUnsignedPayload bool) apperrors.AppError {
+	payloadHash, err := awsPayloadHash(r)
+	if err != nil {
+		zap.L().Error("failed to hash the body of the call", zap.Error(err))
+		return apperrors.Internalf("failed to hash the body of the call: %s", err.Error())
+	}
+	if payloadHash == awsUnsignedPayload && !allowUnsignedPayload {
+		zap.L().Error("streamed body of the call can't be signed, the service doesn't accept unsigned payloads",
+			zap.String("service", a.service))
+		return apperrors.Internalf("the streamed body of the call can't be signed for the '%s' service, which accepts only signed payloads, buffer the request body in the Memory or File mode", a.service)
+	}
+
+	now := a.now().UTC()
+	amzDate := now.Format(awsSigV4TimeFormat)
+	scope := strings.Join([]string{now.Format(awsSigV4DateFormat), a.region, a.service, awsSigV4Request}, "/")
+
+	r.Header.Set(headerAmzDate, amzDate)
+	if a.sessionToken != "" {
+		r.Header.Set(headerAmzSessionToken, a.sessionToken)
+	} else {
+		r.Header.Del(headerAmzSessionToken)
+	}
+	if a.service == awsS3Service {
+		r.Header.Set(headerAmzContentHash, payloadHash)
+	}
+
+	signedHeaders, canonicalHeaders := awsCanonicalHeaders(r)
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		awsCanonicalURI(r.URL, a.service != awsS3Service),
+		awsCanonicalQuery(r.URL),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	stringToSign := strings.Join([]string{awsSigV4Algorithm, amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := []byte("AWS4" + a.secretAccessKey)
+	for _, part := range []string{now.Format(awsSigV4DateFormat), a.region, a.service, awsSigV4Request} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	r.Header.Set(httpconsts.HeaderAuthorization, awsSigV4Algorithm+" Credential="+a.accessKeyID+"/"+scope+", SignedHeaders="+signedHeaders+", Signature="+signature)
+
+	return nil
+}
+
+// awsPayloadHash returns the hash of the body read from its copy. The streamed bodies, which can't be read twice, aren't signed.
+func awsPayloadHash(r *http.Request) (string, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return sha256Hex(nil), nil
+	}
+	if r.GetBody == nil {
+		return awsUnsignedPayload, nil
+	}
+
+	body, err := r.GetBody()
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, body); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// awsCanonicalHeaders returns the signed headers: the host, the content type and the headers with the x-amz- prefix,
+// as the other ones can be changed on the way to the target system
+func awsCanonicalHeaders(r *http.Request) (string, string) {
+	host := r.Host
+	if host == "" {
+		host = r.URL.Host
+	}
+	values := map[string]string{"host": host}
+	for name, headerValues := range r.Header {
+		name = strings.ToLower(name)
+		if name != "content-type" && !strings.HasPrefix(name, "x-amz-") {
+			continue
+		}
+		trimmed := make([]string, 0, len(headerValues))
+		for _, value := range headerValues {
+			trimmed = append(trimmed, strings.Join(strings.Fields(value), " "))
+		}
+		values[name] = strings.Join(trimmed, ",")
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	for _, name := range names {
+		canonical.WriteString(name + ":" + values[name] + "\n")
+	}
+
+	return strings.Join(names, ";"), canonical.String()
+}
+
+// awsCanonicalURI returns the encoded path, the services other than S3 expect the path to be encoded twice
+func awsCanonicalURI(u *url.URL, encodeTwice bool) string {
+	segments := strings.Split(u.EscapedPath(), "/")
+	for i, segment := range segments {
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			segment = unescaped
+		}
+		segment = awsEscape(segment)
+		if encodeTwice {
+			segment = awsEscape(segment)
+		}
+		segments[i] = segment
+	}
+
+	path := strings.Join(segments, "/")
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	return path
+}
+
+func awsCanonicalQuery(u *url.URL) string {
+	type pair struct{ name, value string }
+	var pairs []pair
+	for name, values := range u.Query() {
+		for _, value := range values {
+			pairs = append(pairs, pair{awsEscape(name), awsEscape(value)})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].name != pairs[j].name {
+			return pairs[i].name < pairs[j].name
+		}
+		return pairs[i].value < pairs[j].value
+	})
+
+	encoded := make([]string, 0, len(pairs))
+	for _, p := range pairs {
+		encoded = append(encoded, p.name+"="+p.value)
+	}
+
+	return strings.Join(encoded, "&")
+}
+
+// awsEscape encodes all characters except the unreserved ones of RFC 3986
+func awsEscape(value string) string {
+	const hexDigits = "0123456789ABCDEF"
+	var escaped strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			escaped.WriteByte(c)
+			continue
+		}
+		escaped.WriteByte('%')
+		escaped.WriteByte(hexDigits[c>>4])
+		escaped.WriteByte(hexDigits[c&15])
+	}
+
+	return escaped.String()
+}
+
+func sha256Hex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,167 @@
+package authorization
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+)
+
+func TestAWSSigV4Strategy(t *testing.T) {
+	// examples of the AWS Signature Version 4 documentation and test suite
+	const (
+		accessKeyID     = "AKIDEXAMPLE"
+		secretAccessKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	)
+	signedAt := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	newStrategy := func(sessionToken, service string) awsSigV4Strategy {
+		strategy := newAWSSigV4Strategy(accessKeyID, secretAccessKey, sessionToken, "us-east-1", service)
+		strategy.now = func() time.Time { return signedAt }
+		return strategy
+	}
+
+	t.Run("should sign the call", func(t *testing.T) {
+		// given
+		strategy := newStrategy("", "iam")
+		request, err := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+		require.NoError(t, err)
+		request.Header.Set(httpconsts.HeaderContentType, "application/x-www-form-urlencoded; charset=utf-8")
+		request.Header.Set(httpconsts.HeaderAuthorization, "Bearer caller")
+
+		// when
+		err = strategy.AddAuthorization(request, nil, false)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "20150830T123600Z", request.Header.Get("X-Amz-Date"))
+		assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, "+
+			"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7", request.Header.Get(httpconsts.HeaderAuthorization))
+	})
+
+	t.Run("should sign the final URL of the call", func(t *testing.T) {
+		// given
+		strategy := newStrategy("", "service")
+		request, err := http.NewRequest(http.MethodGet, "/orders", nil)
+		require.NoError(t, err)
+		require.NoError(t, strategy.AddAuthorization(request, nil, false))
+
+		request.URL, err = request.URL.Parse("https://example.amazonaws.com/")
+		require.NoError(t, err)
+		request.Host = "example.amazonaws.com"
+
+		// when
+		err = strategy.Sign(request)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, "+
+			"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31", request.Header.Get(httpconsts.HeaderAuthorization))
+	})
+
+	t.Run("should sign the payload hash and the session token for S3", func(t *testing.T) {
+		// given
+		strategy := newStrategy("session-token", "s3")
+		request, err := http.NewRequest(http.MethodPut, "https://bucket.s3.amazonaws.com/orders/order 1.json", strings.NewReader("order"))
+		require.NoError(t, err)
+
+		// when
+		err = strategy.AddAuthorization(request, nil, false)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, sha256Hex([]byte("order")), request.Header.Get("X-Amz-Content-Sha256"))
+		assert.Equal(t, "session-token", request.Header.Get("X-Amz-Security-Token"))
+		assert.Contains(t, request.Header.Get(httpconsts.HeaderAuthorization), "SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-security-token,")
+	})
+
+	t.Run("should not sign the streamed body", func(t *testing.T) {
+		// given
+		strategy := newStrategy("", "s3")
+		request, err := http.NewRequest(http.MethodPut, "https://bucket.s3.amazonaws.com/orders", strings.NewReader("order"))
+		require.NoError(t, err)
+		request.GetBody = nil
+
+		// when
+		err = strategy.AddAuthorization(request, nil, false)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "UNSIGNED-PAYLOAD", request.Header.Get("X-Amz-Content-Sha256"))
+	})
+
+	t.Run("should fail to sign the streamed body for services other than S3", func(t *testing.T) {
+		// given
+		strategy := newStrategy("", "execute-api")
+		request, err := http.NewRequest(http.MethodPost, "https://api.execute-api.eu-central-1.amazonaws.com/orders", strings.NewReader("order"))
+		require.NoError(t, err)
+		request.GetBody = nil
+		require.NoError(t, strategy.AddAuthorization(request, nil, false))
+
+		// when
+		appErr := strategy.Sign(request)
+
+		// then
+		require.Error(t, appErr)
+		assert.Equal(t, apperrors.CodeInternal, appErr.Code())
+		assert.Contains(t, appErr.Error(), "execute-api")
+	})
+
+	t.Run("should sign the buffered body for services other than S3", func(t *testing.T) {
+		// given
+		strategy := newStrategy("", "execute-api")
+		request, err := http.NewRequest(http.MethodPost, "https://api.execute-api.eu-central-1.amazonaws.com/orders", strings.NewReader("order"))
+		require.NoError(t, err)
+		require.NoError(t, strategy.AddAuthorization(request, nil, false))
+
+		// when
+		appErr := strategy.Sign(request)
+
+		// then
+		require.NoError(t, appErr)
+		assert.Contains(t, request.Header.Get(httpconsts.HeaderAuthorization), "/execute-api/aws4_request")
+	})
+
+	t.Run("should not sign calls authorized with the token of the caller", func(t *testing.T) {
+		// given
+		strategy := newStrategy("", "service")
+		request, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+		require.NoError(t, err)
+		request.Header.Set(httpconsts.HeaderAuthorization, "Bearer external")
+
+		// when
+		err = strategy.Sign(request)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer external", request.Header.Get(httpconsts.HeaderAuthorization))
+		assert.Empty(t, request.Header.Get("X-Amz-Date"))
+	})
+}
+
+func TestAWSCanonicalRequest(t *testing.T) {
+	t.Run("should encode the path once for S3 and twice for other services", func(t *testing.T) {
+		// given
+		request, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/documents%20and%20settings/a%2Fb", nil)
+		require.NoError(t, err)
+
+		// then
+		assert.Equal(t, "/documents%20and%20settings/a%2Fb", awsCanonicalURI(request.URL, false))
+		assert.Equal(t, "/documents%2520and%2520settings/a%252Fb", awsCanonicalURI(request.URL, true))
+	})
+
+	t.Run("should sort the query parameters by name and value", func(t *testing.T) {
+		// given
+		request, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/?b=2&a-b=1&a=2&a=1&c=x+y", nil)
+		require.NoError(t, err)
+
+		// then
+		assert.Equal(t, "a=1&a=2&a-b=1&b=2&c=x%20y", awsCanonicalQuery(request.URL))
+	})
+}
@@ -0,0 +1,154 @@
+package authorization
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httptools"
+)
+
+// ChallengeStrategy is the strategy which authenticates in a handshake started by the authentication challenge of the target system,
+// sent in the WWW-Authenticate headers of the 401 response. The challenges are passed to AddAuthorization in the context of the call, see WithChallenges.
+type ChallengeStrategy interface {
+	Strategy
+	// Answers reports whether the strategy answers one of the challenges
+	Answers(challenges []string) bool
+}
+
+// ConnectionStrategy is the challenge strategy whose handshake authenticates the connection, e.g. NTLM, so the messages of the handshake
+// are sent over the connection dedicated to the call, and the body is sent only with the last of them
+type ConnectionStrategy interface {
+	ChallengeStrategy
+	// Negotiate sets the message starting the handshake
+	Negotiate(r *http.Request)
+	// Authenticate sets the message answering the challenges of the response to the negotiate message, false if they don't continue the handshake
+	Authenticate(r *http.Request, challenges []string) (bool, apperrors.AppError)
+}
+
+// SigningStrategy is the strategy which signs the call right before it's sent, when its URL and headers are final
+type SigningStrategy interface {
+	Strategy
+	// Sign replaces the authorization added by AddAuthorization with the one for the final call, the calls authorized otherwise are left unchanged
+	Sign(r *http.Request) apperrors.AppError
+}
+
+// CallInvalidatingStrategy is the strategy whose credentials depend on the call, e.g. on the user calling the API,
+// so that the rejected call invalidates only its own credentials instead of the credentials of all calls
+type CallInvalidatingStrategy interface {
+	Strategy
+	// InvalidateCall invalidates the credentials of the call
+	InvalidateCall(r *http.Request)
+}
+
+// AsChallengeStrategy returns the strategy created by the factory as the ChallengeStrategy, false if it doesn't answer challenges
+func AsChallengeStrategy(strategy Strategy) (ChallengeStrategy, bool) {
+	challengeStrategy, ok := unwrap(strategy).(ChallengeStrategy)
+	return challengeStrategy, ok
+}
+
+// AsConnectionStrategy returns the strategy created by the factory as the ConnectionStrategy, false if its handshake doesn't authenticate the connection
+func AsConnectionStrategy(strategy Strategy) (ConnectionStrategy, bool) {
+	connectionStrategy, ok := unwrap(strategy).(ConnectionStrategy)
+	return connectionStrategy, ok
+}
+
+// AsSigningStrategy returns the strategy created by the factory as the SigningStrategy, false if it doesn't sign calls
+func AsSigningStrategy(strategy Strategy) (SigningStrategy, bool) {
+	signingStrategy, ok := unwrap(strategy).(SigningStrategy)
+	return signingStrategy, ok
+}
+
+// AsCallInvalidatingStrategy returns the strategy created by the factory as the CallInvalidatingStrategy, false if its credentials don't depend on the call
+func AsCallInvalidatingStrategy(strategy Strategy) (CallInvalidatingStrategy, bool) {
+	callInvalidatingStrategy, ok := unwrap(strategy).(CallInvalidatingStrategy)
+	return callInvalidatingStrategy, ok
+}
+
+func unwrap(strategy Strategy) Strategy {
+	if e, ok := strategy.(externalTokenStrategy); ok {
+		return e.strategy
+	}
+
+	return strategy
+}
+
+type challengesKey struct{}
+
+// WithChallenges returns the context of the call answering the challenges, the values of the WWW-Authenticate headers of the 401 response
+func WithChallenges(ctx context.Context, challenges []string) context.Context {
+	return context.WithValue(ctx, challengesKey{}, challenges)
+}
+
+func challengesFromContext(ctx context.Context) []string {
+	challenges, _ := ctx.Value(challengesKey{}).([]string)
+	return challenges
+}
+
+// challenge is the authentication challenge described in RFC 9110, with either the token68 or the auth parameters
+type challenge struct {
+	scheme  string
+	token68 string
+	// params are keyed by the lowercase names
+	params map[string]string
+}
+
+// parseChallenges parses the values of the WWW-Authenticate headers, each of which can hold many comma separated challenges
+func parseChallenges(values []string) []challenge {
+	var challenges []challenge
+	for _, value := range values {
+		for _, element := range httptools.SplitQuoted(value, ',') {
+			element = strings.TrimSpace(element)
+			if element == "" {
+				continue
+			}
+
+			scheme, rest, _ := strings.Cut(element, " ")
+			if !strings.Contains(scheme, "=") {
+				challenges = append(challenges, challenge{scheme: scheme, params: map[string]string{}})
+				element = strings.TrimSpace(rest)
+				if element == "" {
+					continue
+				}
+			}
+			if len(challenges) == 0 {
+				continue
+			}
+
+			current := &challenges[len(challenges)-1]
+			if isToken68(element) {
+				current.token68 = element
+				continue
+			}
+			name, paramValue, _ := strings.Cut(element, "=")
+			current.params[strings.ToLower(strings.TrimSpace(name))] = unquote(strings.TrimSpace(paramValue))
+		}
+	}
+
+	return challenges
+}
+
+// isToken68 reports whether the value is the token68 of the challenge, not the auth parameter, which has the equal sign followed by the value
+func isToken68(value string) bool {
+	return !strings.ContainsAny(strings.TrimRight(value, "="), `="`)
+}
+
+func unquote(value string) string {
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return value
+	}
+
+	var unquoted strings.Builder
+	escaped := false
+	for _, c := range value[1 : len(value)-1] {
+		if c == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		unquoted.WriteRune(c)
+	}
+
+	return unquoted.String()
+}
@@ -0,0 +1,33 @@
+package authorization
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseChallenges(t *testing.T) {
+	t.Run("should parse challenges with auth parameters and token68", func(t *testing.T) {
+		// given
+		values := []string{
+			`Digest realm="orders, invoices", qop="auth,auth-int", nonce="abc\"d", Basic realm=orders`,
+			"Negotiate",
+			"NTLM TlRMTVNTUAACAAAA==",
+		}
+
+		// when
+		challenges := parseChallenges(values)
+
+		// then
+		assert.Equal(t, []challenge{
+			{scheme: "Digest", params: map[string]string{"realm": "orders, invoices", "qop": "auth,auth-int", "nonce": `abc"d`}},
+			{scheme: "Basic", params: map[string]string{"realm": "orders"}},
+			{scheme: "Negotiate", params: map[string]string{}},
+			{scheme: "NTLM", token68: "TlRMTVNTUAACAAAA==", params: map[string]string{}},
+		}, challenges)
+	})
+
+	t.Run("should skip parameters without challenge", func(t *testing.T) {
+		assert.Empty(t, parseChallenges([]string{`realm="orders"`, ""}))
+	})
+}
@@ -0,0 +1,215 @@
+package authorization
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/clientcert"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+)
+
+const (
+	digestScheme = "Digest"
+	digestQOP    = "auth"
+)
+
+// digestAlgorithms are the supported algorithms of RFC 7616, the ones with the -sess suffix hash the credentials with the nonces
+var digestAlgorithms = map[string]func() hash.Hash{
+	"MD5":          md5.New,
+	"MD5-SESS":     md5.New,
+	"SHA-256":      sha256.New,
+	"SHA-256-SESS": sha256.New,
+}
+
+// digestChallenge is the state of the handshake, the nonce is reused by the following calls until the target system issues a new one
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	// qop is auth or empty if the target system doesn't support the quality of protection
+	qop        string
+	nonceCount uint32
+	cnonce     string
+}
+
+// digestStrategy answers the Digest challenge described in RFC 7616. The calls sent before the first challenge have no authorization.
+type digestStrategy struct {
+	username string
+	password string
+
+	mutex     sync.Mutex
+	challenge *digestChallenge
+}
+
+func newDigestStrategy(username, password string) *digestStrategy {
+	return &digestStrategy{
+		username: username,
+		password: password,
+	}
+}
+
+func (d *digestStrategy) AddAuthorization(r *http.Request, _ clientcert.SetClientCertificateFunc, _ bool) apperrors.AppError {
+	if c, found := supportedDigestChallenge(challengesFromContext(r.Context())); found {
+		d.update(c)
+	}
+
+	return d.authorize(r)
+}
+
+// Sign authorizes the call to its final URL, as the digest covers the method and the URI of the call
+func (d *digestStrategy) Sign(r *http.Request) apperrors.AppError {
+	if !strings.HasPrefix(r.Header.Get(httpconsts.HeaderAuthorization), digestScheme+" ") {
+		return nil
+	}
+
+	return d.authorize(r)
+}
+
+func (d *digestStrategy) Answers(challenges []string) bool {
+	_, found := supportedDigestChallenge(challenges)
+	return found
+}
+
+func (d *digestStrategy) Invalidate() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.challenge = nil
+}
+
+// update starts answering the challenge, the nonce count continues if the nonce didn't change
+func (d *digestStrategy) update(c challenge) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.challenge != nil && d.challenge.nonce == c.params["nonce"] && d.challenge.realm == c.params["realm"] {
+		return
+	}
+
+	d.challenge = &digestChallenge{
+		realm:     c.params["realm"],
+		nonce:     c.params["nonce"],
+		opaque:    c.params["opaque"],
+		algorithm: c.params["algorithm"],
+		qop:       digestQOPOf(c),
+		cnonce:    newCnonce(),
+	}
+}
+
+func (d *digestStrategy) authorize(r *http.Request) apperrors.AppError {
+	d.mutex.Lock()
+	c := d.challenge
+	if c == nil {
+		d.mutex.Unlock()
+		return nil
+	}
+	c.nonceCount++
+	nonceCount := fmt.Sprintf("%08x", c.nonceCount)
+	d.mutex.Unlock()
+
+	newHash, found := digestAlgorithms[strings.ToUpper(c.algorithmOrDefault())]
+	if !found {
+		return apperrors.Internalf("unsupported digest algorithm '%s'", c.algorithm)
+	}
+	h := func(values ...string) string {
+		digest := newHash()
+		digest.Write([]byte(strings.Join(values, ":")))
+		return hex.EncodeToString(digest.Sum(nil))
+	}
+
+	uri := r.URL.RequestURI()
+	ha1 := h(d.username, c.realm, d.password)
+	if strings.HasSuffix(strings.ToUpper(c.algorithmOrDefault()), "-SESS") {
+		ha1 = h(ha1, c.nonce, c.cnonce)
+	}
+	ha2 := h(r.Method, uri)
+
+	var response string
+	if c.qop == "" {
+		response = h(ha1, c.nonce, ha2)
+	} else {
+		response = h(ha1, c.nonce, nonceCount, c.cnonce, c.qop, ha2)
+	}
+
+	params := []string{
+		digestParam("username", d.username),
+		digestParam("realm", c.realm),
+		digestParam("nonce", c.nonce),
+		digestParam("uri", uri),
+		digestParam("response", response),
+	}
+	if c.algorithm != "" {
+		params = append(params, "algorithm="+c.algorithm)
+	}
+	if c.opaque != "" {
+		params = append(params, digestParam("opaque", c.opaque))
+	}
+	if c.qop != "" {
+		params = append(params, "qop="+c.qop, "nc="+nonceCount, digestParam("cnonce", c.cnonce))
+	}
+
+	r.Header.Set(httpconsts.HeaderAuthorization, digestScheme+" "+strings.Join(params, ", "))
+
+	return nil
+}
+
+func (c *digestChallenge) algorithmOrDefault() string {
+	if c.algorithm == "" {
+		return "MD5"
+	}
+
+	return c.algorithm
+}
+
+// supportedDigestChallenge returns the first Digest challenge with the supported algorithm and quality of protection, the target system lists the preferred ones first
+func supportedDigestChallenge(challenges []string) (challenge, bool) {
+	for _, c := range parseChallenges(challenges) {
+		if !strings.EqualFold(c.scheme, digestScheme) || c.params["nonce"] == "" {
+			continue
+		}
+		algorithm := c.params["algorithm"]
+		if algorithm == "" {
+			algorithm = "MD5"
+		}
+		if _, found := digestAlgorithms[strings.ToUpper(algorithm)]; !found {
+			continue
+		}
+		if _, qopFound := c.params["qop"]; qopFound && digestQOPOf(c) == "" {
+			continue
+		}
+
+		return c, true
+	}
+
+	return challenge{}, false
+}
+
+// digestQOPOf returns auth if the challenge offers it, auth-int isn't supported, as it requires hashing the body
+func digestQOPOf(c challenge) string {
+	for _, qop := range strings.Split(c.params["qop"], ",") {
+		if strings.TrimSpace(qop) == digestQOP {
+			return digestQOP
+		}
+	}
+
+	return ""
+}
+
+func digestParam(name, value string) string {
+	return name + `="` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+func newCnonce() string {
+	cnonce := make([]byte, 16)
+	_, _ = rand.Read(cnonce)
+	return hex.EncodeToString(cnonce)
+}
@@ -0,0 +1,120 @@
+package authorization
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+)
+
+func TestDigestStrategy(t *testing.T) {
+	newRequest := func(challenges ...string) *http.Request {
+		request, err := http.NewRequest("GET", "http://www.example.com/dir/index.html", nil)
+		require.NoError(t, err)
+		if len(challenges) > 0 {
+			request = request.WithContext(WithChallenges(context.Background(), challenges))
+		}
+		return request
+	}
+
+	t.Run("should send call without authorization before the first challenge", func(t *testing.T) {
+		// given
+		digestStrategy := newDigestStrategy("Mufasa", "Circle of Life")
+		request := newRequest()
+
+		// when
+		err := digestStrategy.AddAuthorization(request, nil, false)
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, request.Header.Get(httpconsts.HeaderAuthorization))
+	})
+
+	t.Run("should answer the challenge with the preferred supported algorithm", func(t *testing.T) {
+		// given
+		digestStrategy := newDigestStrategy("Mufasa", "Circle of Life")
+		request := newRequest(
+			`Digest realm="http-auth@example.org", qop="auth-int", algorithm=SHA-512-256, nonce="ignored"`,
+			`Digest realm="http-auth@example.org", qop="auth, auth-int", algorithm=SHA-256, nonce="7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v", opaque="FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS"`,
+			`Digest realm="http-auth@example.org", qop="auth, auth-int", algorithm=MD5, nonce="7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v", opaque="FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS"`)
+		require.NoError(t, digestStrategy.AddAuthorization(request, nil, false))
+		digestStrategy.challenge.cnonce = "f2/wE4q74E6zIJEtWaHKaf5wv/H5QzzpXusqGemxURZJ"
+		digestStrategy.challenge.nonceCount = 0
+
+		// when
+		err := digestStrategy.Sign(request)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, `Digest username="Mufasa", realm="http-auth@example.org", nonce="7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v", uri="/dir/index.html", `+
+			`response="753927fa0e85d155564e2e272a28d1802ca10daf4496794697cf8db5856cb6c1", algorithm=SHA-256, opaque="FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS", `+
+			`qop=auth, nc=00000001, cnonce="f2/wE4q74E6zIJEtWaHKaf5wv/H5QzzpXusqGemxURZJ"`, request.Header.Get(httpconsts.HeaderAuthorization))
+	})
+
+	t.Run("should reuse the nonce in the following calls", func(t *testing.T) {
+		// given
+		digestStrategy := newDigestStrategy("Mufasa", "Circle Of Life")
+		challenge := `Digest realm="testrealm@host.com", qop="auth,auth-int", nonce="dcd98b7102dd2f0e8b11d0f600bfb0c093", opaque="5ccc069c403ebaf9f0171e9517f40e41"`
+		require.NoError(t, digestStrategy.AddAuthorization(newRequest(challenge), nil, false))
+		digestStrategy.challenge.cnonce = "0a4f113b"
+		digestStrategy.challenge.nonceCount = 0
+		request := newRequest()
+
+		// when
+		err := digestStrategy.AddAuthorization(request, nil, false)
+		require.NoError(t, err)
+		repeated := newRequest(challenge)
+		err = digestStrategy.AddAuthorization(repeated, nil, false)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, `Digest username="Mufasa", realm="testrealm@host.com", nonce="dcd98b7102dd2f0e8b11d0f600bfb0c093", uri="/dir/index.html", `+
+			`response="6629fae49393a05397450978507c4ef1", opaque="5ccc069c403ebaf9f0171e9517f40e41", qop=auth, nc=00000001, cnonce="0a4f113b"`,
+			request.Header.Get(httpconsts.HeaderAuthorization))
+		assert.Contains(t, repeated.Header.Get(httpconsts.HeaderAuthorization), "nc=00000002")
+	})
+
+	t.Run("should sign only calls authorized with digest", func(t *testing.T) {
+		// given
+		digestStrategy := newDigestStrategy("Mufasa", "Circle Of Life")
+		require.NoError(t, digestStrategy.AddAuthorization(newRequest(`Digest realm="testrealm@host.com", nonce="abc"`), nil, false))
+		request := newRequest()
+		request.Header.Set(httpconsts.HeaderAuthorization, "Bearer external")
+
+		// when
+		err := digestStrategy.Sign(request)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer external", request.Header.Get(httpconsts.HeaderAuthorization))
+	})
+
+	t.Run("should answer only supported digest challenges", func(t *testing.T) {
+		// given
+		digestStrategy := newDigestStrategy("Mufasa", "Circle Of Life")
+
+		// then
+		assert.True(t, digestStrategy.Answers([]string{`Basic realm="orders"`, `Digest realm="orders", nonce="abc", algorithm=MD5-sess`}))
+		assert.False(t, digestStrategy.Answers([]string{`Digest realm="orders", nonce="abc", qop="auth-int"`}))
+		assert.False(t, digestStrategy.Answers([]string{`Basic realm="orders"`}))
+	})
+
+	t.Run("should clear the nonce on invalidate", func(t *testing.T) {
+		// given
+		digestStrategy := newDigestStrategy("Mufasa", "Circle Of Life")
+		require.NoError(t, digestStrategy.AddAuthorization(newRequest(`Digest realm="testrealm@host.com", nonce="abc"`), nil, false))
+		request := newRequest()
+
+		// when
+		digestStrategy.Invalidate()
+		err := digestStrategy.AddAuthorization(request, nil, false)
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, request.Header.Get(httpconsts.HeaderAuthorization))
+	})
+}
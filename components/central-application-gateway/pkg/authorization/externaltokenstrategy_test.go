@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth/mocks"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
 	"github.com/stretchr/testify/assert"
@@ -16,7 +17,7 @@ func TestExternalAuthStrategy(t *testing.T) {
 		// given
 		oauthClientMock := &mocks.Client{}
 
-		oauthStrategy := newOAuthStrategy(oauthClientMock, "clientId", "clientSecret", "www.example.com/token", nil)
+		oauthStrategy := newOAuthStrategy(oauthClientMock, "clientId", "clientSecret", "www.example.com/token", oauth.TokenParameters{}, nil)
 
 		externalTokenStrategy := newExternalTokenStrategy(&oauthStrategy)
 
@@ -41,9 +42,9 @@ func TestExternalAuthStrategy(t *testing.T) {
 	t.Run("should use provided strategy when external token header is missing", func(t *testing.T) {
 		// given
 		oauthClientMock := &mocks.Client{}
-		oauthClientMock.On("GetToken", "clientId", "clientSecret", "www.example.com/token", (*map[string][]string)(nil), (*map[string][]string)(nil), false).Return("token", nil).Once()
+		oauthClientMock.On("GetToken", "clientId", "clientSecret", "www.example.com/token", oauth.TokenParameters{}, (*map[string][]string)(nil), (*map[string][]string)(nil), false).Return("token", nil).Once()
 
-		oauthStrategy := newOAuthStrategy(oauthClientMock, "clientId", "clientSecret", "www.example.com/token", nil)
+		oauthStrategy := newOAuthStrategy(oauthClientMock, "clientId", "clientSecret", "www.example.com/token", oauth.TokenParameters{}, nil)
 
 		externalTokenStrategy := newExternalTokenStrategy(&oauthStrategy)
 
@@ -64,9 +65,9 @@ func TestExternalAuthStrategy(t *testing.T) {
 	t.Run("should call Invalidate method on the provided strategy", func(t *testing.T) {
 		// given
 		oauthClientMock := &mocks.Client{}
-		oauthClientMock.On("InvalidateTokenCache", "clientId", "clientSecret", "www.example.com/token").Return("token", nil).Once()
+		oauthClientMock.On("InvalidateTokenCache", "clientId", "clientSecret", "www.example.com/token", oauth.TokenParameters{}).Return("token", nil).Once()
 
-		oauthStrategy := newOAuthStrategy(oauthClientMock, "clientId", "clientSecret", "www.example.com/token", nil)
+		oauthStrategy := newOAuthStrategy(oauthClientMock, "clientId", "clientSecret", "www.example.com/token", oauth.TokenParameters{}, nil)
 
 		externalTokenStrategy := newExternalTokenStrategy(&oauthStrategy)
 
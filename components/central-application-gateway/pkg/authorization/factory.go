@@ -8,6 +8,7 @@ import (
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/clientcert"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth/tokencache"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httptools"
 )
 
 //go:generate mockery --name=Strategy
@@ -27,15 +28,25 @@ type StrategyFactory interface {
 //go:generate mockery --name=OAuthClient
 type OAuthClient interface {
 	// GetToken obtains OAuth token
-	GetToken(clientID string, clientSecret string, authURL string, headers, queryParameters *map[string][]string, skipTLSVerification bool) (string, apperrors.AppError)
-	GetTokenMTLS(clientID, authURL string, certificate, privateKey []byte, headers, queryParameters *map[string][]string, skipVerify bool) (string, apperrors.AppError)
+	GetToken(clientID string, clientSecret string, authURL string, tokenParameters oauth.TokenParameters, headers, queryParameters *map[string][]string, skipTLSVerification bool) (string, apperrors.AppError)
+	GetTokenMTLS(clientID, authURL string, certificate, privateKey []byte, tokenParameters oauth.TokenParameters, headers, queryParameters *map[string][]string, skipVerify bool) (string, apperrors.AppError)
+	// GetTokenJWTAssertion obtains OAuth token authenticating with a JWT signed with privateKey
+	GetTokenJWTAssertion(clientID, authURL string, privateKey []byte, keyID string, tokenParameters oauth.TokenParameters, headers, queryParameters *map[string][]string, skipVerify bool) (string, apperrors.AppError)
 	// InvalidateTokenCache resets internal token cache
-	InvalidateTokenCache(clientID string, clientSecret string, authURL string)
-	InvalidateTokenCacheMTLS(clientID, authURL string, certificate, privateKey []byte)
+	InvalidateTokenCache(clientID string, clientSecret string, authURL string, tokenParameters oauth.TokenParameters)
+	InvalidateTokenCacheMTLS(clientID, authURL string, certificate, privateKey []byte, tokenParameters oauth.TokenParameters)
+	InvalidateTokenCacheJWTAssertion(clientID, authURL string, privateKey []byte, keyID string, tokenParameters oauth.TokenParameters)
+	// GetTokenExchange obtains OAuth token on behalf of the user in exchange for the token of the user
+	GetTokenExchange(clientID, clientSecret, authURL, subjectToken string, exchange oauth.TokenExchange, tokenParameters oauth.TokenParameters, headers, queryParameters *map[string][]string, skipVerify bool) (string, apperrors.AppError)
+	InvalidateTokenCacheExchange(clientID, clientSecret, authURL string, exchange oauth.TokenExchange, tokenParameters oauth.TokenParameters)
+	// InvalidateTokenCacheExchangeSubject resets the token cached for the user with the subject token
+	InvalidateTokenCacheExchangeSubject(clientID, clientSecret, authURL, subjectToken string, exchange oauth.TokenExchange, tokenParameters oauth.TokenParameters)
 }
 
 type authorizationStrategyFactory struct {
 	oauthClient OAuthClient
+	// newOAuthClient returns the client for credentials with own connection settings, sharing the token cache with oauthClient
+	newOAuthClient func(connection *httptools.ConnectionSettings) OAuthClient
 }
 
 // Create creates strategy for credentials provided
@@ -44,15 +55,32 @@ func (asf authorizationStrategyFactory) Create(c *Credentials) Strategy {
 }
 
 func (asf authorizationStrategyFactory) create(c *Credentials) Strategy {
+	oauthClient := asf.oauthClient
+	if c != nil && c.Connection != nil && asf.newOAuthClient != nil {
+		oauthClient = asf.newOAuthClient(c.Connection.ForAuthorizationServer())
+	}
+
 	if c != nil && c.OAuth != nil {
-		return newOAuthStrategy(asf.oauthClient, c.OAuth.ClientID, c.OAuth.ClientSecret, c.OAuth.URL, c.OAuth.RequestParameters)
+		return newOAuthStrategy(oauthClient, c.OAuth.ClientID, c.OAuth.ClientSecret, c.OAuth.URL, c.OAuth.TokenParameters, c.OAuth.RequestParameters)
 	} else if c != nil && c.OAuthWithCert != nil {
-		oAuthStrategy := newOAuthWithCertStrategy(asf.oauthClient, c.OAuthWithCert.ClientID, c.OAuthWithCert.ClientSecret, c.OAuthWithCert.Certificate, c.OAuthWithCert.PrivateKey, c.OAuthWithCert.URL, c.OAuthWithCert.RequestParameters)
+		oAuthStrategy := newOAuthWithCertStrategy(oauthClient, c.OAuthWithCert.ClientID, c.OAuthWithCert.ClientSecret, c.OAuthWithCert.Certificate, c.OAuthWithCert.PrivateKey, c.OAuthWithCert.URL, c.OAuthWithCert.TokenParameters, c.OAuthWithCert.RequestParameters)
 		return &oAuthStrategy
+	} else if c != nil && c.OAuthJWTAssertion != nil {
+		return newOAuthJWTAssertionStrategy(oauthClient, c.OAuthJWTAssertion.ClientID, c.OAuthJWTAssertion.PrivateKey, c.OAuthJWTAssertion.KeyID, c.OAuthJWTAssertion.URL, c.OAuthJWTAssertion.TokenParameters, c.OAuthJWTAssertion.RequestParameters)
+	} else if c != nil && c.OAuthTokenExchange != nil {
+		return newOAuthTokenExchangeStrategy(oauthClient, c.OAuthTokenExchange.ClientID, c.OAuthTokenExchange.ClientSecret, c.OAuthTokenExchange.URL, c.OAuthTokenExchange.Exchange, c.OAuthTokenExchange.TokenParameters, c.OAuthTokenExchange.RequestParameters)
 	} else if c != nil && c.BasicAuth != nil {
 		return newBasicAuthStrategy(c.BasicAuth.Username, c.BasicAuth.Password)
 	} else if c != nil && c.CertificateGen != nil {
 		return newCertificateGenStrategy(c.CertificateGen.Certificate, c.CertificateGen.PrivateKey)
+	} else if c != nil && c.APIKey != nil {
+		return newAPIKeyStrategy(c.APIKey.Key, c.APIKey.Name, c.APIKey.Placement, c.APIKey.Prefix)
+	} else if c != nil && c.Digest != nil {
+		return newDigestStrategy(c.Digest.Username, c.Digest.Password)
+	} else if c != nil && c.NTLM != nil {
+		return newNTLMStrategy(c.NTLM.Username, c.NTLM.Password, c.NTLM.Domain)
+	} else if c != nil && c.AWSSigV4 != nil {
+		return newAWSSigV4Strategy(c.AWSSigV4.AccessKeyID, c.AWSSigV4.SecretAccessKey, c.AWSSigV4.SessionToken, c.AWSSigV4.Region, c.AWSSigV4.Service)
 	} else {
 		return newNoAuthStrategy()
 	}
@@ -61,12 +89,20 @@ func (asf authorizationStrategyFactory) create(c *Credentials) Strategy {
 // FactoryConfiguration holds factory configuration options
 type FactoryConfiguration struct {
 	OAuthClientTimeout int
+	// TokenRefreshFraction is the fraction of the token lifetime after which the token is refreshed in the background
+	TokenRefreshFraction float64
 }
 
 // NewStrategyFactory creates factory for instantiating Strategy implementations
 func NewStrategyFactory(config FactoryConfiguration) StrategyFactory {
-	cache := tokencache.NewTokenCache()
-	oauthClient := oauth.NewOauthClient(config.OAuthClientTimeout, cache)
+	cache := tokencache.NewTokenCacheWithRefresh(config.TokenRefreshFraction)
+	subjectTokenCache := tokencache.NewSubjectTokenCache()
+	oauthClient := oauth.NewOauthClientWithConnection(config.OAuthClientTimeout, cache, subjectTokenCache, nil)
 
-	return authorizationStrategyFactory{oauthClient: oauthClient}
+	return authorizationStrategyFactory{
+		oauthClient: oauthClient,
+		newOAuthClient: func(connection *httptools.ConnectionSettings) OAuthClient {
+			return oauth.NewOauthClientWithConnection(config.OAuthClientTimeout, cache, subjectTokenCache, connection)
+		},
+	}
 }
@@ -3,14 +3,17 @@ package authorization
 import (
 	"crypto/tls"
 	"net/http"
+	"net/url"
 	"testing"
 
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/testconsts"
 
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/clientcert"
 
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth"
 	oauthMocks "github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth/mocks"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httptools"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -103,7 +106,7 @@ func TestStrategyFactory(t *testing.T) {
 	t.Run("should create oauth strategy", func(t *testing.T) {
 		// given
 		oauthClientMock := &oauthMocks.Client{}
-		oauthClientMock.On("GetToken", "clientId", "clientSecret", "www.example.com/token", (*map[string][]string)(nil), (*map[string][]string)(nil), false).Return("token", nil)
+		oauthClientMock.On("GetToken", "clientId", "clientSecret", "www.example.com/token", oauth.TokenParameters{}, (*map[string][]string)(nil), (*map[string][]string)(nil), false).Return("token", nil)
 
 		factory := authorizationStrategyFactory{oauthClient: oauthClientMock}
 		credentials := &Credentials{
@@ -150,7 +153,7 @@ func TestStrategyFactory(t *testing.T) {
 	t.Run("should create oauth with cert strategy", func(t *testing.T) {
 		// given
 		oauthClientMock := &oauthMocks.Client{}
-		oauthClientMock.On("GetTokenMTLS", "clientId", "www.example.com/token", []byte(testconsts.Certificate), []byte(testconsts.PrivateKey), (*map[string][]string)(nil), (*map[string][]string)(nil), false).Return("token", nil)
+		oauthClientMock.On("GetTokenMTLS", "clientId", "www.example.com/token", []byte(testconsts.Certificate), []byte(testconsts.PrivateKey), oauth.TokenParameters{}, (*map[string][]string)(nil), (*map[string][]string)(nil), false).Return("token", nil)
 
 		factory := authorizationStrategyFactory{oauthClient: oauthClientMock}
 		credentials := &Credentials{
@@ -196,6 +199,192 @@ func TestStrategyFactory(t *testing.T) {
 		assert.Equal(t, "Bearer external", authHeader)
 	})
 
+	t.Run("should create oauth jwt assertion strategy", func(t *testing.T) {
+		// given
+		oauthClientMock := &oauthMocks.Client{}
+		oauthClientMock.On("GetTokenJWTAssertion", "clientId", "www.example.com/token", privateKey, "keyId", oauth.TokenParameters{Scope: "read"}, (*map[string][]string)(nil), (*map[string][]string)(nil), false).Return("token", nil)
+
+		factory := authorizationStrategyFactory{oauthClient: oauthClientMock}
+		credentials := &Credentials{
+			OAuthJWTAssertion: &OAuthJWTAssertion{
+				ClientID:        "clientId",
+				PrivateKey:      privateKey,
+				KeyID:           "keyId",
+				URL:             "www.example.com/token",
+				TokenParameters: oauth.TokenParameters{Scope: "read"},
+			},
+		}
+
+		// when
+		strategy := factory.Create(credentials)
+
+		// then
+		require.NotNil(t, strategy)
+
+		// given
+		request, err := http.NewRequest("GET", "www.example.com", nil)
+		require.NoError(t, err)
+
+		// when
+		err = strategy.AddAuthorization(request, nil, false)
+
+		// then
+		authHeader := request.Header.Get(httpconsts.HeaderAuthorization)
+		assert.Nil(t, err)
+		assert.Equal(t, "Bearer token", authHeader)
+	})
+
+	t.Run("should create oauth token exchange strategy", func(t *testing.T) {
+		// given
+		exchange := oauth.TokenExchange{GrantType: oauth.GrantTypeSAMLBearer}
+		oauthClientMock := &oauthMocks.Client{}
+		oauthClientMock.On("GetTokenExchange", "clientId", "clientSecret", "www.example.com/token", "assertion", exchange, oauth.TokenParameters{}, (*map[string][]string)(nil), (*map[string][]string)(nil), false).Return("token", nil)
+
+		factory := authorizationStrategyFactory{oauthClient: oauthClientMock}
+		credentials := &Credentials{
+			OAuthTokenExchange: &OAuthTokenExchange{
+				ClientID:     "clientId",
+				ClientSecret: "clientSecret",
+				URL:          "www.example.com/token",
+				Exchange:     exchange,
+			},
+		}
+
+		// when
+		strategy := factory.Create(credentials)
+
+		// then
+		require.NotNil(t, strategy)
+
+		// given
+		request, err := http.NewRequest("GET", "www.example.com", nil)
+		require.NoError(t, err)
+		request = request.WithContext(WithSubjectToken(request.Context(), "assertion"))
+
+		// when
+		err = strategy.AddAuthorization(request, nil, false)
+
+		// then
+		authHeader := request.Header.Get(httpconsts.HeaderAuthorization)
+		assert.Nil(t, err)
+		assert.Equal(t, "Bearer token", authHeader)
+	})
+
+	t.Run("should create API key strategy", func(t *testing.T) {
+		// given
+		oauthClientMock := &oauthMocks.Client{}
+
+		factory := authorizationStrategyFactory{oauthClient: oauthClientMock}
+		credentials := &Credentials{
+			APIKey: &APIKey{
+				Key:       "secret-key",
+				Name:      "X-API-Key",
+				Placement: APIKeyPlacementHeader,
+			},
+		}
+
+		// when
+		strategy := factory.Create(credentials)
+
+		// then
+		require.NotNil(t, strategy)
+
+		// given
+		request, err := http.NewRequest("GET", "www.example.com", nil)
+		require.NoError(t, err)
+
+		// when
+		err = strategy.AddAuthorization(request, nil, false)
+
+		// then
+		assert.Nil(t, err)
+		assert.Equal(t, "secret-key", request.Header.Get("X-API-Key"))
+	})
+
+	t.Run("should create digest strategy answering challenges and signing calls", func(t *testing.T) {
+		// given
+		oauthClientMock := &oauthMocks.Client{}
+
+		factory := authorizationStrategyFactory{oauthClient: oauthClientMock}
+		credentials := &Credentials{
+			Digest: &Digest{
+				Username: "username",
+				Password: "password",
+			},
+		}
+
+		// when
+		strategy := factory.Create(credentials)
+
+		// then
+		require.NotNil(t, strategy)
+
+		challengeStrategy, ok := AsChallengeStrategy(strategy)
+		require.True(t, ok)
+		assert.True(t, challengeStrategy.Answers([]string{`Digest realm="orders", nonce="abc"`}))
+		_, ok = AsSigningStrategy(strategy)
+		assert.True(t, ok)
+	})
+
+	t.Run("should create NTLM strategy answering challenges", func(t *testing.T) {
+		// given
+		oauthClientMock := &oauthMocks.Client{}
+
+		factory := authorizationStrategyFactory{oauthClient: oauthClientMock}
+		credentials := &Credentials{
+			NTLM: &NTLM{
+				Username: `DOMAIN\username`,
+				Password: "password",
+			},
+		}
+
+		// when
+		strategy := factory.Create(credentials)
+
+		// then
+		require.NotNil(t, strategy)
+
+		challengeStrategy, ok := AsChallengeStrategy(strategy)
+		require.True(t, ok)
+		assert.True(t, challengeStrategy.Answers([]string{"Negotiate", "NTLM"}))
+		_, ok = AsSigningStrategy(strategy)
+		assert.False(t, ok)
+	})
+
+	t.Run("should create AWS SigV4 strategy signing calls", func(t *testing.T) {
+		// given
+		oauthClientMock := &oauthMocks.Client{}
+
+		factory := authorizationStrategyFactory{oauthClient: oauthClientMock}
+		credentials := &Credentials{
+			AWSSigV4: &AWSSigV4{
+				AccessKeyID:     "AKIDEXAMPLE",
+				SecretAccessKey: "secret",
+				Region:          "eu-central-1",
+				Service:         "execute-api",
+			},
+		}
+
+		// when
+		strategy := factory.Create(credentials)
+
+		// then
+		require.NotNil(t, strategy)
+		_, ok := AsSigningStrategy(strategy)
+		assert.True(t, ok)
+
+		// given
+		request, err := http.NewRequest("GET", "https://api.example.com/orders", nil)
+		require.NoError(t, err)
+
+		// when
+		err = strategy.AddAuthorization(request, nil, false)
+
+		// then
+		assert.Nil(t, err)
+		assert.Contains(t, request.Header.Get(httpconsts.HeaderAuthorization), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/")
+	})
+
 	t.Run("should create certificate gen strategy", func(t *testing.T) {
 		// given
 		oauthClientMock := &oauthMocks.Client{}
@@ -244,4 +433,51 @@ func TestStrategyFactory(t *testing.T) {
 		assert.Nil(t, err)
 		assert.Equal(t, "Bearer external", authHeader)
 	})
+	t.Run("should create oauth strategy requesting tokens over the connection of the credentials without the identity of the target system", func(t *testing.T) {
+		// given
+		oauthClientMock := &oauthMocks.Client{}
+		connectionClientMock := &oauthMocks.Client{}
+		connectionClientMock.On("GetToken", "clientId", "clientSecret", "www.example.com/token", oauth.TokenParameters{}, (*map[string][]string)(nil), (*map[string][]string)(nil), false).Return("token", nil)
+
+		connection := &httptools.ConnectionSettings{Proxy: &url.URL{Scheme: "http", Host: "proxy:3128"}, ServerName: "target.internal", MinVersion: tls.VersionTLS13}
+		var usedConnection *httptools.ConnectionSettings
+		factory := authorizationStrategyFactory{
+			oauthClient: oauthClientMock,
+			newOAuthClient: func(connection *httptools.ConnectionSettings) OAuthClient {
+				usedConnection = connection
+				return connectionClientMock
+			},
+		}
+		credentials := &Credentials{
+			OAuth: &OAuth{
+				URL:          "www.example.com/token",
+				ClientID:     "clientId",
+				ClientSecret: "clientSecret",
+			},
+			Connection: connection,
+		}
+
+		// when
+		strategy := factory.Create(credentials)
+
+		// then
+		require.NotNil(t, strategy)
+		require.NotNil(t, usedConnection)
+		assert.Equal(t, connection.Proxy, usedConnection.Proxy)
+		assert.Equal(t, connection.MinVersion, usedConnection.MinVersion)
+		assert.Empty(t, usedConnection.ServerName)
+
+		// given
+		request, err := http.NewRequest("GET", "www.example.com", nil)
+		require.NoError(t, err)
+
+		// when
+		err = strategy.AddAuthorization(request, nil, false)
+
+		// then
+		assert.Nil(t, err)
+		assert.Equal(t, "Bearer token", request.Header.Get(httpconsts.HeaderAuthorization))
+		oauthClientMock.AssertNotCalled(t, "GetToken")
+		connectionClientMock.AssertExpectations(t)
+	})
 }
@@ -6,6 +6,8 @@ import (
 	apperrors "github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 
 	mock "github.com/stretchr/testify/mock"
+
+	oauth "github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth"
 )
 
 // OAuthClient is an autogenerated mock type for the OAuthClient type
@@ -13,20 +15,43 @@ type OAuthClient struct {
 	mock.Mock
 }
 
-// GetToken provides a mock function with given fields: clientID, clientSecret, authURL, headers, queryParameters, skipTLSVerification
-func (_m *OAuthClient) GetToken(clientID string, clientSecret string, authURL string, headers *map[string][]string, queryParameters *map[string][]string, skipTLSVerification bool) (string, apperrors.AppError) {
-	ret := _m.Called(clientID, clientSecret, authURL, headers, queryParameters, skipTLSVerification)
+// GetToken provides a mock function with given fields: clientID, clientSecret, authURL, tokenParameters, headers, queryParameters, skipTLSVerification
+func (_m *OAuthClient) GetToken(clientID string, clientSecret string, authURL string, tokenParameters oauth.TokenParameters, headers *map[string][]string, queryParameters *map[string][]string, skipTLSVerification bool) (string, apperrors.AppError) {
+	ret := _m.Called(clientID, clientSecret, authURL, tokenParameters, headers, queryParameters, skipTLSVerification)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string, string, string, oauth.TokenParameters, *map[string][]string, *map[string][]string, bool) string); ok {
+		r0 = rf(clientID, clientSecret, authURL, tokenParameters, headers, queryParameters, skipTLSVerification)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 apperrors.AppError
+	if rf, ok := ret.Get(1).(func(string, string, string, oauth.TokenParameters, *map[string][]string, *map[string][]string, bool) apperrors.AppError); ok {
+		r1 = rf(clientID, clientSecret, authURL, tokenParameters, headers, queryParameters, skipTLSVerification)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(apperrors.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetTokenExchange provides a mock function with given fields: clientID, clientSecret, authURL, subjectToken, exchange, tokenParameters, headers, queryParameters, skipVerify
+func (_m *OAuthClient) GetTokenExchange(clientID string, clientSecret string, authURL string, subjectToken string, exchange oauth.TokenExchange, tokenParameters oauth.TokenParameters, headers *map[string][]string, queryParameters *map[string][]string, skipVerify bool) (string, apperrors.AppError) {
+	ret := _m.Called(clientID, clientSecret, authURL, subjectToken, exchange, tokenParameters, headers, queryParameters, skipVerify)
 
 	var r0 string
-	if rf, ok := ret.Get(0).(func(string, string, string, *map[string][]string, *map[string][]string, bool) string); ok {
-		r0 = rf(clientID, clientSecret, authURL, headers, queryParameters, skipTLSVerification)
+	if rf, ok := ret.Get(0).(func(string, string, string, string, oauth.TokenExchange, oauth.TokenParameters, *map[string][]string, *map[string][]string, bool) string); ok {
+		r0 = rf(clientID, clientSecret, authURL, subjectToken, exchange, tokenParameters, headers, queryParameters, skipVerify)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
 	var r1 apperrors.AppError
-	if rf, ok := ret.Get(1).(func(string, string, string, *map[string][]string, *map[string][]string, bool) apperrors.AppError); ok {
-		r1 = rf(clientID, clientSecret, authURL, headers, queryParameters, skipTLSVerification)
+	if rf, ok := ret.Get(1).(func(string, string, string, string, oauth.TokenExchange, oauth.TokenParameters, *map[string][]string, *map[string][]string, bool) apperrors.AppError); ok {
+		r1 = rf(clientID, clientSecret, authURL, subjectToken, exchange, tokenParameters, headers, queryParameters, skipVerify)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(apperrors.AppError)
@@ -36,20 +61,20 @@ func (_m *OAuthClient) GetToken(clientID string, clientSecret string, authURL st
 	return r0, r1
 }
 
-// GetTokenMTLS provides a mock function with given fields: clientID, authURL, certificate, privateKey, headers, queryParameters, skipVerify
-func (_m *OAuthClient) GetTokenMTLS(clientID string, authURL string, certificate []byte, privateKey []byte, headers *map[string][]string, queryParameters *map[string][]string, skipVerify bool) (string, apperrors.AppError) {
-	ret := _m.Called(clientID, authURL, certificate, privateKey, headers, queryParameters, skipVerify)
+// GetTokenJWTAssertion provides a mock function with given fields: clientID, authURL, privateKey, keyID, tokenParameters, headers, queryParameters, skipVerify
+func (_m *OAuthClient) GetTokenJWTAssertion(clientID string, authURL string, privateKey []byte, keyID string, tokenParameters oauth.TokenParameters, headers *map[string][]string, queryParameters *map[string][]string, skipVerify bool) (string, apperrors.AppError) {
+	ret := _m.Called(clientID, authURL, privateKey, keyID, tokenParameters, headers, queryParameters, skipVerify)
 
 	var r0 string
-	if rf, ok := ret.Get(0).(func(string, string, []byte, []byte, *map[string][]string, *map[string][]string, bool) string); ok {
-		r0 = rf(clientID, authURL, certificate, privateKey, headers, queryParameters, skipVerify)
+	if rf, ok := ret.Get(0).(func(string, string, []byte, string, oauth.TokenParameters, *map[string][]string, *map[string][]string, bool) string); ok {
+		r0 = rf(clientID, authURL, privateKey, keyID, tokenParameters, headers, queryParameters, skipVerify)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
 	var r1 apperrors.AppError
-	if rf, ok := ret.Get(1).(func(string, string, []byte, []byte, *map[string][]string, *map[string][]string, bool) apperrors.AppError); ok {
-		r1 = rf(clientID, authURL, certificate, privateKey, headers, queryParameters, skipVerify)
+	if rf, ok := ret.Get(1).(func(string, string, []byte, string, oauth.TokenParameters, *map[string][]string, *map[string][]string, bool) apperrors.AppError); ok {
+		r1 = rf(clientID, authURL, privateKey, keyID, tokenParameters, headers, queryParameters, skipVerify)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(apperrors.AppError)
@@ -59,14 +84,52 @@ func (_m *OAuthClient) GetTokenMTLS(clientID string, authURL string, certificate
 	return r0, r1
 }
 
-// InvalidateTokenCache provides a mock function with given fields: clientID, clientSecret, authURL
-func (_m *OAuthClient) InvalidateTokenCache(clientID string, clientSecret string, authURL string) {
-	_m.Called(clientID, clientSecret, authURL)
+// GetTokenMTLS provides a mock function with given fields: clientID, authURL, certificate, privateKey, tokenParameters, headers, queryParameters, skipVerify
+func (_m *OAuthClient) GetTokenMTLS(clientID string, authURL string, certificate []byte, privateKey []byte, tokenParameters oauth.TokenParameters, headers *map[string][]string, queryParameters *map[string][]string, skipVerify bool) (string, apperrors.AppError) {
+	ret := _m.Called(clientID, authURL, certificate, privateKey, tokenParameters, headers, queryParameters, skipVerify)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string, string, []byte, []byte, oauth.TokenParameters, *map[string][]string, *map[string][]string, bool) string); ok {
+		r0 = rf(clientID, authURL, certificate, privateKey, tokenParameters, headers, queryParameters, skipVerify)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 apperrors.AppError
+	if rf, ok := ret.Get(1).(func(string, string, []byte, []byte, oauth.TokenParameters, *map[string][]string, *map[string][]string, bool) apperrors.AppError); ok {
+		r1 = rf(clientID, authURL, certificate, privateKey, tokenParameters, headers, queryParameters, skipVerify)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(apperrors.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// InvalidateTokenCache provides a mock function with given fields: clientID, clientSecret, authURL, tokenParameters
+func (_m *OAuthClient) InvalidateTokenCache(clientID string, clientSecret string, authURL string, tokenParameters oauth.TokenParameters) {
+	_m.Called(clientID, clientSecret, authURL, tokenParameters)
+}
+
+// InvalidateTokenCacheExchange provides a mock function with given fields: clientID, clientSecret, authURL, exchange, tokenParameters
+func (_m *OAuthClient) InvalidateTokenCacheExchange(clientID string, clientSecret string, authURL string, exchange oauth.TokenExchange, tokenParameters oauth.TokenParameters) {
+	_m.Called(clientID, clientSecret, authURL, exchange, tokenParameters)
+}
+
+// InvalidateTokenCacheExchangeSubject provides a mock function with given fields: clientID, clientSecret, authURL, subjectToken, exchange, tokenParameters
+func (_m *OAuthClient) InvalidateTokenCacheExchangeSubject(clientID string, clientSecret string, authURL string, subjectToken string, exchange oauth.TokenExchange, tokenParameters oauth.TokenParameters) {
+	_m.Called(clientID, clientSecret, authURL, subjectToken, exchange, tokenParameters)
+}
+
+// InvalidateTokenCacheJWTAssertion provides a mock function with given fields: clientID, authURL, privateKey, keyID, tokenParameters
+func (_m *OAuthClient) InvalidateTokenCacheJWTAssertion(clientID string, authURL string, privateKey []byte, keyID string, tokenParameters oauth.TokenParameters) {
+	_m.Called(clientID, authURL, privateKey, keyID, tokenParameters)
 }
 
-// InvalidateTokenCacheMTLS provides a mock function with given fields: clientID, authURL, certificate, privateKey
-func (_m *OAuthClient) InvalidateTokenCacheMTLS(clientID string, authURL string, certificate []byte, privateKey []byte) {
-	_m.Called(clientID, authURL, certificate, privateKey)
+// InvalidateTokenCacheMTLS provides a mock function with given fields: clientID, authURL, certificate, privateKey, tokenParameters
+func (_m *OAuthClient) InvalidateTokenCacheMTLS(clientID string, authURL string, certificate []byte, privateKey []byte, tokenParameters oauth.TokenParameters) {
+	_m.Called(clientID, authURL, certificate, privateKey, tokenParameters)
 }
 
 type mockConstructorTestingTNewOAuthClient interface {
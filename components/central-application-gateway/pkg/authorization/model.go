@@ -1,19 +1,38 @@
 package authorization
 
+import (
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httptools"
+)
+
 // Credentials contains OAuth or BasicAuth configuration.
 type Credentials struct {
 	// OAuth is OAuth configuration.
 	OAuth *OAuth
 	// OAuthWithCert is OAuthWithCert configuration
 	OAuthWithCert *OAuthWithCert
+	// OAuthJWTAssertion is OAuth configuration using a JWT client assertion (private_key_jwt)
+	OAuthJWTAssertion *OAuthJWTAssertion
+	// OAuthTokenExchange is OAuth configuration exchanging the token of the user for the token of the target system
+	OAuthTokenExchange *OAuthTokenExchange
 	// BasicAuth is BasicAuth configuration.
 	BasicAuth *BasicAuth
 	// CertificateGen is CertificateGen configuration.
 	CertificateGen *CertificateGen
+	// APIKey is API key configuration.
+	APIKey *APIKey
+	// Digest is HTTP Digest configuration.
+	Digest *Digest
+	// NTLM is NTLM configuration.
+	NTLM *NTLM
+	// AWSSigV4 is AWS Signature Version 4 configuration.
+	AWSSigV4 *AWSSigV4
 	// CSRFTokenEndpointURL (optional) to fetch CSRF token
 	// Deprecated: This field is only used for old implementation of fetching credentials from Application and Secrets. It is not used by authorization package.
 	// It should be removed when it is no longer supported
 	CSRFTokenEndpointURL string
+	// Connection (optional) defines how connections to the token endpoints are opened, nil uses the defaults
+	Connection *httptools.ConnectionSettings
 }
 
 // BasicAuth contains details of BasicAuth Auth configuration
@@ -24,6 +43,62 @@ type BasicAuth struct {
 	Password string
 }
 
+// Digest contains details of HTTP Digest configuration
+type Digest struct {
+	// Username to use for authentication
+	Username string
+	// Password to use for authentication
+	Password string
+}
+
+// NTLM contains details of NTLM configuration
+type NTLM struct {
+	// Username to use for authentication, it can have the DOMAIN\username form
+	Username string
+	// Password to use for authentication
+	Password string
+	// Domain (optional) of the user
+	Domain string
+}
+
+// AWSSigV4 contains details of AWS Signature Version 4 configuration
+type AWSSigV4 struct {
+	// AccessKeyID of the AWS credentials
+	AccessKeyID string
+	// SecretAccessKey of the AWS credentials
+	SecretAccessKey string
+	// SessionToken (optional) of temporary AWS credentials
+	SessionToken string
+	// Region of the called service, for example eu-central-1
+	Region string
+	// Service is the signing name of the called service, for example execute-api or s3
+	Service string
+}
+
+// AcceptsUnsignedPayload reports whether the service accepts calls whose body isn't signed, so that streamed bodies can be sent, which only S3 does
+func (a *AWSSigV4) AcceptsUnsignedPayload() bool {
+	return a.Service == awsS3Service
+}
+
+const (
+	// APIKeyPlacementHeader sends the API key in a header
+	APIKeyPlacementHeader = "header"
+	// APIKeyPlacementQuery sends the API key in a query parameter
+	APIKeyPlacementQuery = "query"
+)
+
+// APIKey contains details of API key configuration
+type APIKey struct {
+	// Key to send to the target system
+	Key string
+	// Name of the header or query parameter with the key
+	Name string
+	// Placement of the key, header or query
+	Placement string
+	// Prefix (optional) prepended to the key, for example "ApiKey "
+	Prefix string
+}
+
 // OAuth contains details of OAuth configuration
 type OAuth struct {
 	// URL to OAuth token provider.
@@ -32,6 +107,8 @@ type OAuth struct {
 	ClientID string
 	// ClientSecret to use for authorization.
 	ClientSecret string
+	// TokenParameters (optional) narrow down the requested token.
+	TokenParameters oauth.TokenParameters
 	// RequestParameters will be used with request send by the Application Gateway.
 	RequestParameters *RequestParameters
 }
@@ -50,6 +127,40 @@ type OAuthWithCert struct {
 	ClientSecret      string
 	Certificate       []byte
 	PrivateKey        []byte
+	TokenParameters   oauth.TokenParameters
+	RequestParameters *RequestParameters
+}
+
+// OAuthJWTAssertion contains details of OAuth configuration in which the client authenticates with a signed JWT (RFC 7523)
+type OAuthJWTAssertion struct {
+	// URL to OAuth token provider.
+	URL string
+	// ClientID to use for authorization.
+	ClientID string
+	// PrivateKey used to sign the client assertion, PEM encoded RSA or ECDSA key.
+	PrivateKey []byte
+	// KeyID (optional) identifies the signing key at the OAuth token provider.
+	KeyID string
+	// TokenParameters (optional) narrow down the requested token.
+	TokenParameters oauth.TokenParameters
+	// RequestParameters will be used with request send by the Application Gateway.
+	RequestParameters *RequestParameters
+}
+
+// OAuthTokenExchange contains details of OAuth configuration in which the token of the calling user is exchanged
+// for the token of the target system, as described in RFC 8693, or sent as the SAML bearer assertion described in RFC 7522
+type OAuthTokenExchange struct {
+	// URL to OAuth token provider.
+	URL string
+	// ClientID to use for authorization.
+	ClientID string
+	// ClientSecret to use for authorization.
+	ClientSecret string
+	// Exchange defines the grant used to exchange the token of the user.
+	Exchange oauth.TokenExchange
+	// TokenParameters (optional) narrow down the requested token.
+	TokenParameters oauth.TokenParameters
+	// RequestParameters will be used with request send by the Application Gateway.
 	RequestParameters *RequestParameters
 }
 
@@ -0,0 +1,180 @@
+package authorization
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/md4"
+)
+
+// NTLM messages described in MS-NLMP. Only NTLMv2 is supported, the calls aren't signed or sealed, so no session key is exchanged.
+
+const (
+	ntlmNegotiateUnicode                 = 0x00000001
+	ntlmNegotiateOEM                     = 0x00000002
+	ntlmRequestTarget                    = 0x00000004
+	ntlmNegotiateNTLM                    = 0x00000200
+	ntlmNegotiateAlwaysSign              = 0x00008000
+	ntlmNegotiateExtendedSessionSecurity = 0x00080000
+	ntlmNegotiateTargetInfo              = 0x00800000
+	ntlmNegotiate128                     = 0x20000000
+	ntlmNegotiate56                      = 0x80000000
+
+	ntlmNegotiateFlags = ntlmNegotiateUnicode | ntlmNegotiateOEM | ntlmRequestTarget | ntlmNegotiateNTLM | ntlmNegotiateAlwaysSign |
+		ntlmNegotiateExtendedSessionSecurity | ntlmNegotiateTargetInfo | ntlmNegotiate128 | ntlmNegotiate56
+
+	ntlmNegotiateMessage    = 1
+	ntlmChallengeMessage    = 2
+	ntlmAuthenticateMessage = 3
+
+	ntlmAuthenticateHeaderLength = 64
+	// ntlmAvTimestamp is the ID of the attribute of the target info with the time of the target system
+	ntlmAvTimestamp = 7
+	ntlmAvEOL       = 0
+)
+
+var ntlmSignature = []byte("NTLMSSP\x00")
+
+// ntlmChallengeMessageContent is the content of the challenge message of the target system used to authenticate
+type ntlmChallengeMessageContent struct {
+	flags           uint32
+	serverChallenge []byte
+	targetInfo      []byte
+}
+
+func ntlmNegotiate() []byte {
+	message := append([]byte{}, ntlmSignature...)
+	message = binary.LittleEndian.AppendUint32(message, ntlmNegotiateMessage)
+	message = binary.LittleEndian.AppendUint32(message, ntlmNegotiateFlags)
+	// no domain and workstation are sent
+	return append(message, make([]byte, 16)...)
+}
+
+func parseNTLMChallenge(message []byte) (ntlmChallengeMessageContent, error) {
+	if len(message) < 32 || !bytes.Equal(message[:8], ntlmSignature) || binary.LittleEndian.Uint32(message[8:]) != ntlmChallengeMessage {
+		return ntlmChallengeMessageContent{}, errors.New("not an NTLM challenge message")
+	}
+
+	content := ntlmChallengeMessageContent{
+		flags:           binary.LittleEndian.Uint32(message[20:]),
+		serverChallenge: message[24:32],
+	}
+	if len(message) >= 48 {
+		length := int(binary.LittleEndian.Uint16(message[40:]))
+		offset := int(binary.LittleEndian.Uint32(message[44:]))
+		if offset+length > len(message) {
+			return ntlmChallengeMessageContent{}, errors.New("target info exceeds the NTLM challenge message")
+		}
+		content.targetInfo = message[offset : offset+length]
+	}
+
+	return content, nil
+}
+
+// ntlmAuthenticate returns the authenticate message answering the challenge, clientChallenge is the random nonce of the client
+func ntlmAuthenticate(challenge ntlmChallengeMessageContent, username, password, domain string, clientChallenge []byte, now time.Time) []byte {
+	v2Hash := ntowfV2(username, password, domain)
+
+	timestamp, found := ntlmTimestamp(challenge.targetInfo)
+	var lmResponse []byte
+	if found {
+		// the LM response is omitted when the target system sends its time, see MS-NLMP 3.1.5.1.2
+		lmResponse = make([]byte, 24)
+	} else {
+		timestamp = fileTime(now)
+		lmResponse = lmV2Response(v2Hash, challenge.serverChallenge, clientChallenge)
+	}
+	ntResponse := ntV2Response(v2Hash, challenge.serverChallenge, clientChallenge, timestamp, challenge.targetInfo)
+
+	encode := func(value string) []byte {
+		if challenge.flags&ntlmNegotiateUnicode != 0 {
+			return utf16LE(value)
+		}
+		return []byte(value)
+	}
+
+	payloads := [][]byte{lmResponse, ntResponse, encode(domain), encode(username), nil, nil}
+	message := append([]byte{}, ntlmSignature...)
+	message = binary.LittleEndian.AppendUint32(message, ntlmAuthenticateMessage)
+	offset := ntlmAuthenticateHeaderLength
+	for _, payload := range payloads {
+		message = binary.LittleEndian.AppendUint16(message, uint16(len(payload)))
+		message = binary.LittleEndian.AppendUint16(message, uint16(len(payload)))
+		message = binary.LittleEndian.AppendUint32(message, uint32(offset))
+		offset += len(payload)
+	}
+	message = binary.LittleEndian.AppendUint32(message, challenge.flags&ntlmNegotiateFlags|ntlmNegotiateNTLM)
+	for _, payload := range payloads {
+		message = append(message, payload...)
+	}
+
+	return message
+}
+
+// ntowfV2 is the NTLMv2 hash of the credentials
+func ntowfV2(username, password, domain string) []byte {
+	hash := md4.New()
+	hash.Write(utf16LE(password))
+	return hmacMD5(hash.Sum(nil), utf16LE(strings.ToUpper(username)+domain))
+}
+
+func ntV2Response(v2Hash, serverChallenge, clientChallenge, timestamp, targetInfo []byte) []byte {
+	temp := []byte{1, 1, 0, 0, 0, 0, 0, 0}
+	temp = append(temp, timestamp...)
+	temp = append(temp, clientChallenge...)
+	temp = append(temp, 0, 0, 0, 0)
+	temp = append(temp, targetInfo...)
+	temp = append(temp, 0, 0, 0, 0)
+
+	ntProof := hmacMD5(v2Hash, append(append([]byte{}, serverChallenge...), temp...))
+	return append(ntProof, temp...)
+}
+
+func lmV2Response(v2Hash, serverChallenge, clientChallenge []byte) []byte {
+	response := hmacMD5(v2Hash, append(append([]byte{}, serverChallenge...), clientChallenge...))
+	return append(response, clientChallenge...)
+}
+
+// ntlmTimestamp returns the time of the target system from its target info
+func ntlmTimestamp(targetInfo []byte) ([]byte, bool) {
+	for len(targetInfo) >= 4 {
+		id := binary.LittleEndian.Uint16(targetInfo)
+		length := int(binary.LittleEndian.Uint16(targetInfo[2:]))
+		if id == ntlmAvEOL || 4+length > len(targetInfo) {
+			break
+		}
+		if id == ntlmAvTimestamp && length == 8 {
+			return targetInfo[4:12], true
+		}
+		targetInfo = targetInfo[4+length:]
+	}
+
+	return nil, false
+}
+
+// fileTime returns the time as the number of 100 nanosecond intervals since January 1, 1601
+func fileTime(t time.Time) []byte {
+	const unixEpochIntervals = 116444736000000000
+	return binary.LittleEndian.AppendUint64(nil, uint64(t.UnixNano()/100+unixEpochIntervals))
+}
+
+func hmacMD5(key, data []byte) []byte {
+	mac := hmac.New(md5.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func utf16LE(value string) []byte {
+	var encoded []byte
+	for _, c := range utf16.Encode([]rune(value)) {
+		encoded = binary.LittleEndian.AppendUint16(encoded, c)
+	}
+
+	return encoded
+}
@@ -0,0 +1,164 @@
+package authorization
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/clientcert"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+)
+
+const (
+	ntlmScheme      = "NTLM"
+	negotiateScheme = "Negotiate"
+)
+
+// ntlmStrategy authenticates in the NTLM handshake, sent in the NTLM or Negotiate scheme, whichever the target system offers.
+// Every call starts the handshake with the negotiate message, which is answered with the challenge message of the target system.
+// The handshake authenticates the connection, so it's sent over the connection dedicated to the call, see ConnectionStrategy.
+type ntlmStrategy struct {
+	username string
+	password string
+	domain   string
+
+	mutex  sync.Mutex
+	scheme string
+}
+
+// newNTLMStrategy creates the strategy, the domain can be passed in the DOMAIN\username form of the username as well
+func newNTLMStrategy(username, password, domain string) *ntlmStrategy {
+	if domain == "" {
+		if userDomain, user, found := strings.Cut(username, `\`); found {
+			domain, username = userDomain, user
+		}
+	}
+
+	return &ntlmStrategy{
+		username: username,
+		password: password,
+		domain:   domain,
+		scheme:   ntlmScheme,
+	}
+}
+
+func (n *ntlmStrategy) AddAuthorization(r *http.Request, _ clientcert.SetClientCertificateFunc, _ bool) apperrors.AppError {
+	authenticated, err := n.Authenticate(r, challengesFromContext(r.Context()))
+	if err != nil {
+		return err
+	}
+	if !authenticated {
+		n.Negotiate(r)
+	}
+
+	return nil
+}
+
+// Negotiate sets the negotiate message in the scheme the target system offered the last time
+func (n *ntlmStrategy) Negotiate(r *http.Request) {
+	setNTLMAuthorization(r, n.offeredScheme(), ntlmNegotiate())
+}
+
+// Authenticate answers the challenge message, the scheme of the challenges without the message is used by the next negotiate messages
+func (n *ntlmStrategy) Authenticate(r *http.Request, challenges []string) (bool, apperrors.AppError) {
+	c, found := ntlmChallenge(challenges)
+	if !found {
+		return false, nil
+	}
+	n.setOfferedScheme(c.scheme)
+	if c.token68 == "" {
+		return false, nil
+	}
+
+	message, err := n.authenticate(c.token68)
+	if err != nil {
+		zap.L().Error("invalid NTLM challenge of the target system, only NTLM is supported in the Negotiate scheme",
+			zap.String("scheme", c.scheme),
+			zap.Error(err))
+		return false, apperrors.UpstreamServerCallFailed("invalid %s challenge of the target system: %s", c.scheme, err.Error())
+	}
+	setNTLMAuthorization(r, c.scheme, message)
+
+	return true, nil
+}
+
+// authenticate returns the authenticate message answering the base64 encoded challenge message
+func (n *ntlmStrategy) authenticate(token string) ([]byte, error) {
+	message, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+	challengeMessage, err := parseNTLMChallenge(message)
+	if err != nil {
+		return nil, err
+	}
+
+	clientChallenge := make([]byte, 8)
+	_, _ = rand.Read(clientChallenge)
+
+	return ntlmAuthenticate(challengeMessage, n.username, n.password, n.domain, clientChallenge, time.Now()), nil
+}
+
+func (n *ntlmStrategy) Answers(challenges []string) bool {
+	_, found := ntlmChallenge(challenges)
+	return found
+}
+
+func (n *ntlmStrategy) Invalidate() {
+}
+
+// offeredScheme returns the scheme the target system offered the last time, the next calls start the handshake in the same scheme
+func (n *ntlmStrategy) offeredScheme() string {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	return n.scheme
+}
+
+func (n *ntlmStrategy) setOfferedScheme(scheme string) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.scheme = scheme
+}
+
+func setNTLMAuthorization(r *http.Request, scheme string, message []byte) {
+	r.Header.Set(httpconsts.HeaderAuthorization, scheme+" "+base64.StdEncoding.EncodeToString(message))
+}
+
+// ntlmChallenge returns the challenge continuing the handshake if there is one, otherwise the NTLM challenge is preferred over the Negotiate one,
+// which the target system may expect to start Kerberos
+func ntlmChallenge(challenges []string) (challenge, bool) {
+	var offered []challenge
+	for _, c := range parseChallenges(challenges) {
+		switch {
+		case strings.EqualFold(c.scheme, ntlmScheme):
+			c.scheme = ntlmScheme
+		case strings.EqualFold(c.scheme, negotiateScheme):
+			c.scheme = negotiateScheme
+		default:
+			continue
+		}
+		if c.token68 != "" {
+			return c, true
+		}
+		offered = append(offered, c)
+	}
+
+	for _, c := range offered {
+		if c.scheme == ntlmScheme {
+			return c, true
+		}
+	}
+	if len(offered) > 0 {
+		return offered[0], true
+	}
+
+	return challenge{}, false
+}
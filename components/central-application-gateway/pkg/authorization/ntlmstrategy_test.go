@@ -0,0 +1,162 @@
+package authorization
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+)
+
+// targetInfo of the examples of MS-NLMP 4.2.1, with the Domain NetBIOS domain name and the Server NetBIOS computer name
+var testTargetInfo = []byte{
+	0x02, 0x00, 0x0c, 0x00, 0x44, 0x00, 0x6f, 0x00, 0x6d, 0x00, 0x61, 0x00, 0x69, 0x00, 0x6e, 0x00,
+	0x01, 0x00, 0x0c, 0x00, 0x53, 0x00, 0x65, 0x00, 0x72, 0x00, 0x76, 0x00, 0x65, 0x00, 0x72, 0x00,
+	0x00, 0x00, 0x00, 0x00,
+}
+
+func TestNTLMStrategy(t *testing.T) {
+	newRequest := func(challenges ...string) *http.Request {
+		request, err := http.NewRequest("GET", "http://www.example.com/sites/orders", nil)
+		require.NoError(t, err)
+		if len(challenges) > 0 {
+			request = request.WithContext(WithChallenges(context.Background(), challenges))
+		}
+		return request
+	}
+
+	decode := func(t *testing.T, header, scheme string) []byte {
+		token, found := strings.CutPrefix(header, scheme+" ")
+		require.True(t, found, header)
+		message, err := base64.StdEncoding.DecodeString(token)
+		require.NoError(t, err)
+		return message
+	}
+
+	t.Run("should start the handshake with the negotiate message in the scheme offered by the target system", func(t *testing.T) {
+		// given
+		ntlmStrategy := newNTLMStrategy("User", "Password", "Domain")
+		first := newRequest()
+		offered := newRequest("Negotiate")
+		next := newRequest()
+
+		// when
+		require.NoError(t, ntlmStrategy.AddAuthorization(first, nil, false))
+		require.NoError(t, ntlmStrategy.AddAuthorization(offered, nil, false))
+		require.NoError(t, ntlmStrategy.AddAuthorization(next, nil, false))
+
+		// then
+		message := decode(t, first.Header.Get(httpconsts.HeaderAuthorization), "NTLM")
+		assert.Equal(t, ntlmNegotiate(), message)
+		assert.Equal(t, "NTLMSSP\x00", string(message[:8]))
+		assert.Equal(t, uint32(ntlmNegotiateMessage), binary.LittleEndian.Uint32(message[8:]))
+		decode(t, offered.Header.Get(httpconsts.HeaderAuthorization), "Negotiate")
+		decode(t, next.Header.Get(httpconsts.HeaderAuthorization), "Negotiate")
+	})
+
+	t.Run("should answer the challenge message with the authenticate message", func(t *testing.T) {
+		// given
+		ntlmStrategy := newNTLMStrategy(`Domain\User`, "Password", "")
+		request := newRequest("NTLM " + base64.StdEncoding.EncodeToString(testChallengeMessage()))
+
+		// when
+		err := ntlmStrategy.AddAuthorization(request, nil, false)
+
+		// then
+		require.NoError(t, err)
+		message := decode(t, request.Header.Get(httpconsts.HeaderAuthorization), "NTLM")
+		assert.Equal(t, uint32(ntlmAuthenticateMessage), binary.LittleEndian.Uint32(message[8:]))
+		assert.Equal(t, utf16LE("Domain"), securityBuffer(message, 28))
+		assert.Equal(t, utf16LE("User"), securityBuffer(message, 36))
+		ntResponse := securityBuffer(message, 20)
+		lmResponse := securityBuffer(message, 12)
+		assert.Len(t, lmResponse, 24)
+		assert.Equal(t, ntResponse[32:40], lmResponse[16:], "client challenge")
+		assert.Equal(t, testTargetInfo, ntResponse[16+28:len(ntResponse)-4])
+	})
+
+	t.Run("should return error when the target system doesn't send the NTLM challenge message", func(t *testing.T) {
+		// given
+		ntlmStrategy := newNTLMStrategy("User", "Password", "Domain")
+		request := newRequest("Negotiate YIIBhgYGKwYBBQUCoIIBejCCAXagMDAuBgkqhkiC9xIBAgIGCSqGSIb3EgECAgYKKwYBBAGCNwICHgYKKwYBBAGCNwICCg==")
+
+		// when
+		err := ntlmStrategy.AddAuthorization(request, nil, false)
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, apperrors.CodeUpstreamServerCallFailed, err.Code())
+	})
+
+	t.Run("should answer NTLM and Negotiate challenges only", func(t *testing.T) {
+		// given
+		ntlmStrategy := newNTLMStrategy("User", "Password", "Domain")
+
+		// then
+		assert.True(t, ntlmStrategy.Answers([]string{"Negotiate, NTLM"}))
+		assert.False(t, ntlmStrategy.Answers([]string{`Basic realm="orders"`}))
+	})
+}
+
+func TestNTLMv2(t *testing.T) {
+	// examples of MS-NLMP 4.2.4
+	serverChallenge, _ := hex.DecodeString("0123456789abcdef")
+	clientChallenge, _ := hex.DecodeString("aaaaaaaaaaaaaaaa")
+
+	t.Run("should compute the NTLMv2 hash and responses", func(t *testing.T) {
+		// given
+		v2Hash := ntowfV2("User", "Password", "Domain")
+
+		// when
+		ntResponse := ntV2Response(v2Hash, serverChallenge, clientChallenge, make([]byte, 8), testTargetInfo)
+		lmResponse := lmV2Response(v2Hash, serverChallenge, clientChallenge)
+
+		// then
+		assert.Equal(t, "0c868a403bfd7a93a3001ef22ef02e3f", hex.EncodeToString(v2Hash))
+		assert.Equal(t, "68cd0ab851e51c96aabc927bebef6a1c", hex.EncodeToString(ntResponse[:16]))
+		assert.Equal(t, "86c35097ac9cec102554764a57cccc19aaaaaaaaaaaaaaaa", hex.EncodeToString(lmResponse))
+	})
+
+	t.Run("should use the time of the target system", func(t *testing.T) {
+		// given
+		timestamp := []byte{7, 0, 8, 0, 1, 2, 3, 4, 5, 6, 7, 8}
+		targetInfo := append(append([]byte{}, timestamp...), 0, 0, 0, 0)
+		challenge := ntlmChallengeMessageContent{flags: ntlmNegotiateUnicode, serverChallenge: serverChallenge, targetInfo: targetInfo}
+
+		// when
+		message := ntlmAuthenticate(challenge, "User", "Password", "Domain", clientChallenge, time.Now())
+
+		// then
+		ntResponse := securityBuffer(message, 20)
+		assert.Equal(t, timestamp[4:], ntResponse[24:32])
+		assert.Equal(t, make([]byte, 24), securityBuffer(message, 12))
+	})
+}
+
+func testChallengeMessage() []byte {
+	message := append([]byte{}, ntlmSignature...)
+	message = binary.LittleEndian.AppendUint32(message, ntlmChallengeMessage)
+	message = append(message, 0, 0, 0, 0, 0, 0, 0, 0)
+	message = binary.LittleEndian.AppendUint32(message, ntlmNegotiateFlags)
+	message = append(message, 0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef)
+	message = append(message, 0, 0, 0, 0, 0, 0, 0, 0)
+	message = binary.LittleEndian.AppendUint16(message, uint16(len(testTargetInfo)))
+	message = binary.LittleEndian.AppendUint16(message, uint16(len(testTargetInfo)))
+	message = binary.LittleEndian.AppendUint32(message, 48)
+	return append(message, testTargetInfo...)
+}
+
+func securityBuffer(message []byte, at int) []byte {
+	length := int(binary.LittleEndian.Uint16(message[at:]))
+	offset := int(binary.LittleEndian.Uint32(message[at+4:]))
+	return message[offset : offset+length]
+}
@@ -0,0 +1,57 @@
+package oauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClientAssertion(t *testing.T) {
+	t.Run("should sign assertion with ECDSA key", func(t *testing.T) {
+		// given
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		require.NoError(t, err)
+		keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+		// when
+		assertion, err := newClientAssertion("testID", "https://auth.example.com/token", keyPEM, "")
+
+		// then
+		require.NoError(t, err)
+		parts := strings.Split(assertion, ".")
+		require.Len(t, parts, 3)
+
+		var header jwtHeader
+		decodeSegment(t, parts[0], &header)
+		assert.Equal(t, "ES256", header.Algorithm)
+		assert.Empty(t, header.KeyID)
+
+		signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+		require.NoError(t, err)
+		require.Len(t, signature, 64)
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		assert.True(t, ecdsa.Verify(&key.PublicKey, digest[:], r, s))
+	})
+
+	t.Run("should fail if key is not PEM encoded", func(t *testing.T) {
+		// when
+		_, err := newClientAssertion("testID", "https://auth.example.com/token", []byte("test"), "")
+
+		// then
+		require.Error(t, err)
+	})
+}
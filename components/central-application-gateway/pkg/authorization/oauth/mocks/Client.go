@@ -4,7 +4,10 @@ package mocks
 
 import (
 	apperrors "github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+
 	mock "github.com/stretchr/testify/mock"
+
+	oauth "github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth"
 )
 
 // Client is an autogenerated mock type for the Client type
@@ -12,20 +15,66 @@ type Client struct {
 	mock.Mock
 }
 
-// GetToken provides a mock function with given fields: clientID, clientSecret, authURL, headers, queryParameters, skipVerify
-func (_m *Client) GetToken(clientID string, clientSecret string, authURL string, headers *map[string][]string, queryParameters *map[string][]string, skipVerify bool) (string, apperrors.AppError) {
-	ret := _m.Called(clientID, clientSecret, authURL, headers, queryParameters, skipVerify)
+// GetToken provides a mock function with given fields: clientID, clientSecret, authURL, tokenParameters, headers, queryParameters, skipVerify
+func (_m *Client) GetToken(clientID string, clientSecret string, authURL string, tokenParameters oauth.TokenParameters, headers *map[string][]string, queryParameters *map[string][]string, skipVerify bool) (string, apperrors.AppError) {
+	ret := _m.Called(clientID, clientSecret, authURL, tokenParameters, headers, queryParameters, skipVerify)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string, string, string, oauth.TokenParameters, *map[string][]string, *map[string][]string, bool) string); ok {
+		r0 = rf(clientID, clientSecret, authURL, tokenParameters, headers, queryParameters, skipVerify)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 apperrors.AppError
+	if rf, ok := ret.Get(1).(func(string, string, string, oauth.TokenParameters, *map[string][]string, *map[string][]string, bool) apperrors.AppError); ok {
+		r1 = rf(clientID, clientSecret, authURL, tokenParameters, headers, queryParameters, skipVerify)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(apperrors.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetTokenExchange provides a mock function with given fields: clientID, clientSecret, authURL, subjectToken, exchange, tokenParameters, headers, queryParameters, skipVerify
+func (_m *Client) GetTokenExchange(clientID string, clientSecret string, authURL string, subjectToken string, exchange oauth.TokenExchange, tokenParameters oauth.TokenParameters, headers *map[string][]string, queryParameters *map[string][]string, skipVerify bool) (string, apperrors.AppError) {
+	ret := _m.Called(clientID, clientSecret, authURL, subjectToken, exchange, tokenParameters, headers, queryParameters, skipVerify)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string, string, string, string, oauth.TokenExchange, oauth.TokenParameters, *map[string][]string, *map[string][]string, bool) string); ok {
+		r0 = rf(clientID, clientSecret, authURL, subjectToken, exchange, tokenParameters, headers, queryParameters, skipVerify)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 apperrors.AppError
+	if rf, ok := ret.Get(1).(func(string, string, string, string, oauth.TokenExchange, oauth.TokenParameters, *map[string][]string, *map[string][]string, bool) apperrors.AppError); ok {
+		r1 = rf(clientID, clientSecret, authURL, subjectToken, exchange, tokenParameters, headers, queryParameters, skipVerify)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(apperrors.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetTokenJWTAssertion provides a mock function with given fields: clientID, authURL, privateKey, keyID, tokenParameters, headers, queryParameters, skipVerify
+func (_m *Client) GetTokenJWTAssertion(clientID string, authURL string, privateKey []byte, keyID string, tokenParameters oauth.TokenParameters, headers *map[string][]string, queryParameters *map[string][]string, skipVerify bool) (string, apperrors.AppError) {
+	ret := _m.Called(clientID, authURL, privateKey, keyID, tokenParameters, headers, queryParameters, skipVerify)
 
 	var r0 string
-	if rf, ok := ret.Get(0).(func(string, string, string, *map[string][]string, *map[string][]string, bool) string); ok {
-		r0 = rf(clientID, clientSecret, authURL, headers, queryParameters, skipVerify)
+	if rf, ok := ret.Get(0).(func(string, string, []byte, string, oauth.TokenParameters, *map[string][]string, *map[string][]string, bool) string); ok {
+		r0 = rf(clientID, authURL, privateKey, keyID, tokenParameters, headers, queryParameters, skipVerify)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
 	var r1 apperrors.AppError
-	if rf, ok := ret.Get(1).(func(string, string, string, *map[string][]string, *map[string][]string, bool) apperrors.AppError); ok {
-		r1 = rf(clientID, clientSecret, authURL, headers, queryParameters, skipVerify)
+	if rf, ok := ret.Get(1).(func(string, string, []byte, string, oauth.TokenParameters, *map[string][]string, *map[string][]string, bool) apperrors.AppError); ok {
+		r1 = rf(clientID, authURL, privateKey, keyID, tokenParameters, headers, queryParameters, skipVerify)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(apperrors.AppError)
@@ -35,20 +84,20 @@ func (_m *Client) GetToken(clientID string, clientSecret string, authURL string,
 	return r0, r1
 }
 
-// GetTokenMTLS provides a mock function with given fields: clientID, authURL, certificate, privateKey, headers, queryParameters, skipVerify
-func (_m *Client) GetTokenMTLS(clientID string, authURL string, certificate []byte, privateKey []byte, headers *map[string][]string, queryParameters *map[string][]string, skipVerify bool) (string, apperrors.AppError) {
-	ret := _m.Called(clientID, authURL, certificate, privateKey, headers, queryParameters, skipVerify)
+// GetTokenMTLS provides a mock function with given fields: clientID, authURL, certificate, privateKey, tokenParameters, headers, queryParameters, skipVerify
+func (_m *Client) GetTokenMTLS(clientID string, authURL string, certificate []byte, privateKey []byte, tokenParameters oauth.TokenParameters, headers *map[string][]string, queryParameters *map[string][]string, skipVerify bool) (string, apperrors.AppError) {
+	ret := _m.Called(clientID, authURL, certificate, privateKey, tokenParameters, headers, queryParameters, skipVerify)
 
 	var r0 string
-	if rf, ok := ret.Get(0).(func(string, string, []byte, []byte, *map[string][]string, *map[string][]string, bool) string); ok {
-		r0 = rf(clientID, authURL, certificate, privateKey, headers, queryParameters, skipVerify)
+	if rf, ok := ret.Get(0).(func(string, string, []byte, []byte, oauth.TokenParameters, *map[string][]string, *map[string][]string, bool) string); ok {
+		r0 = rf(clientID, authURL, certificate, privateKey, tokenParameters, headers, queryParameters, skipVerify)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
 	var r1 apperrors.AppError
-	if rf, ok := ret.Get(1).(func(string, string, []byte, []byte, *map[string][]string, *map[string][]string, bool) apperrors.AppError); ok {
-		r1 = rf(clientID, authURL, certificate, privateKey, headers, queryParameters, skipVerify)
+	if rf, ok := ret.Get(1).(func(string, string, []byte, []byte, oauth.TokenParameters, *map[string][]string, *map[string][]string, bool) apperrors.AppError); ok {
+		r1 = rf(clientID, authURL, certificate, privateKey, tokenParameters, headers, queryParameters, skipVerify)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(apperrors.AppError)
@@ -58,14 +107,29 @@ func (_m *Client) GetTokenMTLS(clientID string, authURL string, certificate []by
 	return r0, r1
 }
 
-// InvalidateTokenCache provides a mock function with given fields: clientID, clientSecret, authURL
-func (_m *Client) InvalidateTokenCache(clientID string, clientSecret string, authURL string) {
-	_m.Called(clientID, clientSecret, authURL)
+// InvalidateTokenCache provides a mock function with given fields: clientID, clientSecret, authURL, tokenParameters
+func (_m *Client) InvalidateTokenCache(clientID string, clientSecret string, authURL string, tokenParameters oauth.TokenParameters) {
+	_m.Called(clientID, clientSecret, authURL, tokenParameters)
+}
+
+// InvalidateTokenCacheExchange provides a mock function with given fields: clientID, clientSecret, authURL, exchange, tokenParameters
+func (_m *Client) InvalidateTokenCacheExchange(clientID string, clientSecret string, authURL string, exchange oauth.TokenExchange, tokenParameters oauth.TokenParameters) {
+	_m.Called(clientID, clientSecret, authURL, exchange, tokenParameters)
+}
+
+// InvalidateTokenCacheExchangeSubject provides a mock function with given fields: clientID, clientSecret, authURL, subjectToken, exchange, tokenParameters
+func (_m *Client) InvalidateTokenCacheExchangeSubject(clientID string, clientSecret string, authURL string, subjectToken string, exchange oauth.TokenExchange, tokenParameters oauth.TokenParameters) {
+	_m.Called(clientID, clientSecret, authURL, subjectToken, exchange, tokenParameters)
+}
+
+// InvalidateTokenCacheJWTAssertion provides a mock function with given fields: clientID, authURL, privateKey, keyID, tokenParameters
+func (_m *Client) InvalidateTokenCacheJWTAssertion(clientID string, authURL string, privateKey []byte, keyID string, tokenParameters oauth.TokenParameters) {
+	_m.Called(clientID, authURL, privateKey, keyID, tokenParameters)
 }
 
-// InvalidateTokenCacheMTLS provides a mock function with given fields: clientID, authURL, certificate, privateKey
-func (_m *Client) InvalidateTokenCacheMTLS(clientID string, authURL string, certificate []byte, privateKey []byte) {
-	_m.Called(clientID, authURL, certificate, privateKey)
+// InvalidateTokenCacheMTLS provides a mock function with given fields: clientID, authURL, certificate, privateKey, tokenParameters
+func (_m *Client) InvalidateTokenCacheMTLS(clientID string, authURL string, certificate []byte, privateKey []byte, tokenParameters oauth.TokenParameters) {
+	_m.Called(clientID, authURL, certificate, privateKey, tokenParameters)
 }
 
 type mockConstructorTestingTNewClient interface {
@@ -11,8 +11,12 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth/tokencache"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/util"
@@ -21,185 +25,366 @@ import (
 )
 
 type oauthResponse struct {
-	AccessToken string `json:"access_token"`
-	TokenType   string `json:"token_type"`
-	ExpiresIn   int    `json:"expires_in"`
-	Scope       string `json:"scope"`
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int    `json:"expires_in"`
+	Scope            string `json:"scope"`
+	RefreshToken     string `json:"refresh_token"`
+	RefreshExpiresIn int    `json:"refresh_expires_in"`
+}
+
+// grant holds the grant type specific parameters of a token request
+type grant url.Values
+
+func clientCredentialsGrant() grant {
+	return grant{"grant_type": {"client_credentials"}}
+}
+
+func refreshTokenGrant(refreshToken string) grant {
+	return grant{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	}
+}
+
+func (g grant) addTo(form url.Values) {
+	for key, values := range g {
+		form[key] = values
+	}
+}
+
+type tokenRequest func(g grant) (*oauthResponse, apperrors.AppError)
+
+// TokenParameters contains optional parameters narrowing down the requested token
+type TokenParameters struct {
+	// Scope is a space-delimited list of requested scopes
+	Scope string
+	// Audience is the intended audience of the token
+	Audience string
+	// Resource is the target service or resource the token is requested for (RFC 8707)
+	Resource string
+}
+
+func (tp TokenParameters) addTo(form url.Values) {
+	if tp.Scope != "" {
+		form.Add("scope", tp.Scope)
+	}
+	if tp.Audience != "" {
+		form.Add("audience", tp.Audience)
+	}
+	if tp.Resource != "" {
+		form.Add("resource", tp.Resource)
+	}
+}
+
+// cacheKey returns a suffix distinguishing tokens requested with different parameters, empty if no parameters are set
+func (tp TokenParameters) cacheKey() string {
+	form := url.Values{}
+	tp.addTo(form)
+	if len(form) == 0 {
+		return ""
+	}
+	return "?" + form.Encode()
 }
 
 //go:generate mockery --name=Client
 type Client interface {
-	GetToken(clientID, clientSecret, authURL string, headers, queryParameters *map[string][]string, skipVerify bool) (string, apperrors.AppError)
-	GetTokenMTLS(clientID, authURL string, certificate, privateKey []byte, headers, queryParameters *map[string][]string, skipVerify bool) (string, apperrors.AppError)
-	InvalidateTokenCache(clientID string, clientSecret string, authURL string)
-	InvalidateTokenCacheMTLS(clientID, authURL string, certificate, privateKey []byte)
+	GetToken(clientID, clientSecret, authURL string, tokenParameters TokenParameters, headers, queryParameters *map[string][]string, skipVerify bool) (string, apperrors.AppError)
+	GetTokenMTLS(clientID, authURL string, certificate, privateKey []byte, tokenParameters TokenParameters, headers, queryParameters *map[string][]string, skipVerify bool) (string, apperrors.AppError)
+	GetTokenJWTAssertion(clientID, authURL string, privateKey []byte, keyID string, tokenParameters TokenParameters, headers, queryParameters *map[string][]string, skipVerify bool) (string, apperrors.AppError)
+	InvalidateTokenCache(clientID, clientSecret, authURL string, tokenParameters TokenParameters)
+	InvalidateTokenCacheMTLS(clientID, authURL string, certificate, privateKey []byte, tokenParameters TokenParameters)
+	InvalidateTokenCacheJWTAssertion(clientID, authURL string, privateKey []byte, keyID string, tokenParameters TokenParameters)
+	GetTokenExchange(clientID, clientSecret, authURL, subjectToken string, exchange TokenExchange, tokenParameters TokenParameters, headers, queryParameters *map[string][]string, skipVerify bool) (string, apperrors.AppError)
+	InvalidateTokenCacheExchange(clientID, clientSecret, authURL string, exchange TokenExchange, tokenParameters TokenParameters)
+	InvalidateTokenCacheExchangeSubject(clientID, clientSecret, authURL, subjectToken string, exchange TokenExchange, tokenParameters TokenParameters)
 }
 
 type client struct {
-	timeoutDuration int
-	tokenCache      tokencache.TokenCache
+	timeoutDuration   int
+	tokenCache        tokencache.TokenCache
+	subjectTokenCache tokencache.SubjectTokenCache
+	connection        *httptools.ConnectionSettings
+	requests          singleflight.Group
+	refreshing        sync.Map
 }
 
 func NewOauthClient(timeoutDuration int, tokenCache tokencache.TokenCache) Client {
 	return &client{
-		timeoutDuration: timeoutDuration,
-		tokenCache:      tokenCache,
+		timeoutDuration:   timeoutDuration,
+		tokenCache:        tokenCache,
+		subjectTokenCache: tokencache.NewSubjectTokenCache(),
 	}
 }
 
-func (c *client) GetToken(clientID, clientSecret, authURL string, headers, queryParameters *map[string][]string, skipVerify bool) (string, apperrors.AppError) {
-	token, found := c.tokenCache.Get(c.makeOAuthTokenCacheKey(clientID, clientSecret, authURL))
-	if found {
-		return token, nil
+// NewOauthClientWithConnection returns the client requesting tokens over the connections with given settings, e.g. through the proxy of the Application.
+// Nil connection uses the defaults.
+func NewOauthClientWithConnection(timeoutDuration int, tokenCache tokencache.TokenCache, subjectTokenCache tokencache.SubjectTokenCache, connection *httptools.ConnectionSettings) Client {
+	return &client{
+		timeoutDuration:   timeoutDuration,
+		tokenCache:        tokenCache,
+		subjectTokenCache: subjectTokenCache,
+		connection:        connection,
 	}
+}
 
-	tokenResponse, err := c.requestToken(clientID, clientSecret, authURL, headers, queryParameters, skipVerify)
-	if err != nil {
-		return "", err
-	}
+func (c *client) GetToken(clientID, clientSecret, authURL string, tokenParameters TokenParameters, headers, queryParameters *map[string][]string, skipVerify bool) (string, apperrors.AppError) {
+	cacheKey := c.makeOAuthTokenCacheKey(clientID, clientSecret, authURL, tokenParameters)
+
+	return c.getToken(cacheKey, func(g grant) (*oauthResponse, apperrors.AppError) {
+		return c.requestToken(clientID, clientSecret, authURL, g, tokenParameters, headers, queryParameters, skipVerify)
+	})
+}
 
-	c.tokenCache.Add(c.makeOAuthTokenCacheKey(clientID, clientSecret, authURL), tokenResponse.AccessToken, tokenResponse.ExpiresIn)
+func (c *client) GetTokenMTLS(clientID, authURL string, certificate, privateKey []byte, tokenParameters TokenParameters, headers, queryParameters *map[string][]string, skipVerify bool) (string, apperrors.AppError) {
+	cacheKey := c.makeMTLSOAuthTokenCacheKey(clientID, authURL, certificate, privateKey, tokenParameters)
 
-	return tokenResponse.AccessToken, nil
+	return c.getToken(cacheKey, func(g grant) (*oauthResponse, apperrors.AppError) {
+		cert, err := tls.X509KeyPair(certificate, privateKey)
+		if err != nil {
+			return nil, apperrors.Internalf("Failed to prepare certificate, %s", err.Error())
+		}
+
+		tokenResponse, requestError := c.requestTokenMTLS(clientID, authURL, cert, g, tokenParameters, headers, queryParameters, skipVerify)
+		if requestError != nil {
+			return nil, requestError
+		}
+
+		if tokenResponse == nil {
+			return nil, apperrors.Internalf("Failed to fetch token, possible certificate problem")
+		}
+
+		return tokenResponse, nil
+	})
+}
+
+func (c *client) GetTokenJWTAssertion(clientID, authURL string, privateKey []byte, keyID string, tokenParameters TokenParameters, headers, queryParameters *map[string][]string, skipVerify bool) (string, apperrors.AppError) {
+	cacheKey := c.makeJWTAssertionOAuthTokenCacheKey(clientID, authURL, privateKey, keyID, tokenParameters)
+
+	return c.getToken(cacheKey, func(g grant) (*oauthResponse, apperrors.AppError) {
+		// every request needs a fresh assertion as the token endpoint may reject reused token IDs
+		assertion, err := newClientAssertion(clientID, authURL, privateKey, keyID)
+		if err != nil {
+			return nil, apperrors.Internalf("Failed to prepare client assertion, %s", err.Error())
+		}
+
+		return c.requestTokenJWTAssertion(clientID, authURL, assertion, g, tokenParameters, headers, queryParameters, skipVerify)
+	})
 }
 
-func (c *client) GetTokenMTLS(clientID, authURL string, certificate, privateKey []byte, headers, queryParameters *map[string][]string, skipVerify bool) (string, apperrors.AppError) {
-	token, found := c.tokenCache.Get(c.makeMTLSOAuthTokenCacheKey(clientID, authURL, certificate, privateKey))
+// GetTokenExchange returns the token issued on behalf of the user in exchange for the subject token, the tokens are cached per subject
+func (c *client) GetTokenExchange(clientID, clientSecret, authURL, subjectToken string, exchange TokenExchange, tokenParameters TokenParameters, headers, queryParameters *map[string][]string, skipVerify bool) (string, apperrors.AppError) {
+	cacheKey := c.makeTokenExchangeCacheKey(clientID, clientSecret, authURL, exchange, tokenParameters)
+	subject, subjectTokenExpiresAt := subject(subjectToken)
+
+	token, found := c.subjectTokenCache.Get(cacheKey, subject, subjectToken)
 	if found {
 		return token, nil
 	}
 
-	cert, err := tls.X509KeyPair(certificate, privateKey)
+	subjectTokenHash := sha256.Sum256([]byte(subjectToken))
+	result, err, _ := c.requests.Do(cacheKey+"\n"+hex.EncodeToString(subjectTokenHash[:]), func() (interface{}, error) {
+		tokenResponse, err := c.requestToken(clientID, clientSecret, authURL, exchange.grant(subjectToken), tokenParameters, headers, queryParameters, skipVerify)
+		if err != nil {
+			return "", err
+		}
+
+		c.subjectTokenCache.Add(cacheKey, subject, subjectToken, tokenResponse.AccessToken, expirationSeconds(tokenResponse.ExpiresIn, subjectTokenExpiresAt))
+
+		return tokenResponse.AccessToken, nil
+	})
 	if err != nil {
-		return "", apperrors.Internalf("Failed to prepare certificate, %s", err.Error())
+		return "", err.(apperrors.AppError)
 	}
 
-	tokenResponse, requestError := c.requestTokenMTLS(clientID, authURL, cert, headers, queryParameters, skipVerify)
-	if err != nil {
-		return "", requestError
+	return result.(string), nil
+}
+
+func (c *client) InvalidateTokenCacheExchange(clientID, clientSecret, authURL string, exchange TokenExchange, tokenParameters TokenParameters) {
+	c.subjectTokenCache.Remove(c.makeTokenExchangeCacheKey(clientID, clientSecret, authURL, exchange, tokenParameters))
+}
+
+// InvalidateTokenCacheExchangeSubject removes the token issued on behalf of the user with the subject token, the tokens of other users are kept
+func (c *client) InvalidateTokenCacheExchangeSubject(clientID, clientSecret, authURL, subjectToken string, exchange TokenExchange, tokenParameters TokenParameters) {
+	subject, _ := subject(subjectToken)
+	c.subjectTokenCache.RemoveSubject(c.makeTokenExchangeCacheKey(clientID, clientSecret, authURL, exchange, tokenParameters), subject)
+}
+
+func (c *client) InvalidateTokenCache(clientID, clientSecret, authURL string, tokenParameters TokenParameters) {
+	c.tokenCache.Remove(c.makeOAuthTokenCacheKey(clientID, clientSecret, authURL, tokenParameters))
+}
+
+func (c *client) InvalidateTokenCacheMTLS(clientID, authURL string, certificate, privateKey []byte, tokenParameters TokenParameters) {
+	c.tokenCache.Remove(c.makeMTLSOAuthTokenCacheKey(clientID, authURL, certificate, privateKey, tokenParameters))
+}
+
+func (c *client) InvalidateTokenCacheJWTAssertion(clientID, authURL string, privateKey []byte, keyID string, tokenParameters TokenParameters) {
+	c.tokenCache.Remove(c.makeJWTAssertionOAuthTokenCacheKey(clientID, authURL, privateKey, keyID, tokenParameters))
+}
+
+// getToken returns the cached token or requests a new one if it is not present in the cache.
+// Tokens which passed the refresh threshold are still returned while a new token is requested in the background.
+func (c *client) getToken(cacheKey string, request tokenRequest) (string, apperrors.AppError) {
+	token, found := c.tokenCache.Get(cacheKey)
+	if found {
+		if c.tokenCache.NeedsRefresh(cacheKey) {
+			c.refreshInBackground(cacheKey, request)
+		}
+		return token, nil
 	}
 
-	if tokenResponse == nil {
-		return "", apperrors.Internalf("Failed to fetch token, possible certificate problem")
+	return c.fetchToken(cacheKey, request)
+}
+
+func (c *client) refreshInBackground(cacheKey string, request tokenRequest) {
+	if _, inProgress := c.refreshing.LoadOrStore(cacheKey, struct{}{}); inProgress {
+		return
 	}
 
-	c.tokenCache.Add(c.makeMTLSOAuthTokenCacheKey(clientID, authURL, certificate, privateKey), tokenResponse.AccessToken, tokenResponse.ExpiresIn)
+	go func() {
+		defer c.refreshing.Delete(cacheKey)
 
-	return tokenResponse.AccessToken, nil
+		_, err := c.fetchToken(cacheKey, request)
+		if err != nil {
+			zap.L().Warn("failed to refresh token, cached token will be used until it expires",
+				zap.Error(err))
+		}
+	}()
 }
 
-func (c *client) InvalidateTokenCache(clientID, clientSecret, authURL string) {
-	c.tokenCache.Remove(c.makeOAuthTokenCacheKey(clientID, clientSecret, authURL))
+// fetchToken requests a token and stores it in the cache. Concurrent calls for the same cache key share a single request.
+func (c *client) fetchToken(cacheKey string, request tokenRequest) (string, apperrors.AppError) {
+	token, err, _ := c.requests.Do(cacheKey, func() (interface{}, error) {
+		tokenResponse, err := c.requestWithRefreshToken(cacheKey, request)
+		if err != nil {
+			return "", err
+		}
+
+		c.tokenCache.Add(cacheKey, tokenResponse.AccessToken, tokenResponse.ExpiresIn)
+		if tokenResponse.RefreshToken != "" {
+			c.tokenCache.AddRefreshToken(cacheKey, tokenResponse.RefreshToken, tokenResponse.RefreshExpiresIn)
+		}
+
+		return tokenResponse.AccessToken, nil
+	})
+	if err != nil {
+		return "", err.(apperrors.AppError)
+	}
+
+	return token.(string), nil
 }
 
-func (c *client) InvalidateTokenCacheMTLS(clientID, authURL string, certificate, privateKey []byte) {
-	c.tokenCache.Remove(c.makeMTLSOAuthTokenCacheKey(clientID, authURL, certificate, privateKey))
+func (c *client) requestWithRefreshToken(cacheKey string, request tokenRequest) (*oauthResponse, apperrors.AppError) {
+	refreshToken, found := c.tokenCache.GetRefreshToken(cacheKey)
+	if found {
+		tokenResponse, err := request(refreshTokenGrant(refreshToken))
+		if err == nil {
+			return tokenResponse, nil
+		}
+
+		zap.L().Warn("failed to get token using refresh token, falling back to client credentials",
+			zap.Error(err))
+	}
+
+	return request(clientCredentialsGrant())
 }
 
 // to avoid case of single clientID and different endpoints for MTLS and standard oauth
-func (c *client) makeOAuthTokenCacheKey(clientID, clientSecret, authURL string) string {
-	return clientID + clientSecret + authURL
+func (c *client) makeOAuthTokenCacheKey(clientID, clientSecret, authURL string, tokenParameters TokenParameters) string {
+	return clientID + clientSecret + authURL + tokenParameters.cacheKey()
 }
 
-func (c *client) makeMTLSOAuthTokenCacheKey(clientID, authURL string, certificate, privateKey []byte) string {
+func (c *client) makeMTLSOAuthTokenCacheKey(clientID, authURL string, certificate, privateKey []byte, tokenParameters TokenParameters) string {
 	certificateSha := sha256.Sum256(certificate)
 	keySha := sha256.Sum256(privateKey)
 
 	hashedCertificate := hex.EncodeToString(certificateSha[:])
 	hashedKey := hex.EncodeToString(keySha[:])
-	return fmt.Sprintf("%v-%v-%v-%v", clientID, hashedCertificate, hashedKey, authURL)
+	return fmt.Sprintf("%v-%v-%v-%v%v", clientID, hashedCertificate, hashedKey, authURL, tokenParameters.cacheKey())
 }
 
-func (c *client) requestToken(clientID, clientSecret, authURL string, headers, queryParameters *map[string][]string, skipVerify bool) (*oauthResponse, apperrors.AppError) {
-	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: skipVerify},
-	}
-	client := &http.Client{Transport: transport}
+func (c *client) makeJWTAssertionOAuthTokenCacheKey(clientID, authURL string, privateKey []byte, keyID string, tokenParameters TokenParameters) string {
+	keySha := sha256.Sum256(privateKey)
 
+	hashedKey := hex.EncodeToString(keySha[:])
+	return fmt.Sprintf("jwt-%v-%v-%v-%v%v", clientID, hashedKey, keyID, authURL, tokenParameters.cacheKey())
+}
+
+func (c *client) makeTokenExchangeCacheKey(clientID, clientSecret, authURL string, exchange TokenExchange, tokenParameters TokenParameters) string {
+	return fmt.Sprintf("exchange-%v-%v-%v-%v%v", clientID, clientSecret, authURL, exchange.cacheKey(), tokenParameters.cacheKey())
+}
+
+func (c *client) requestToken(clientID, clientSecret, authURL string, g grant, tokenParameters TokenParameters, headers, queryParameters *map[string][]string, skipVerify bool) (*oauthResponse, apperrors.AppError) {
 	form := url.Values{}
 	form.Add("client_id", clientID)
 	form.Add("client_secret", clientSecret)
-	form.Add("grant_type", "client_credentials")
-
-	req, err := http.NewRequest(http.MethodPost, authURL, strings.NewReader(form.Encode()))
-	if err != nil {
-		return nil, apperrors.Internalf("failed to create token request: %s", err.Error())
-	}
-
-	util.AddBasicAuthHeader(req, clientID, clientSecret)
-	req.Header.Add(httpconsts.HeaderContentType, httpconsts.ContentTypeApplicationURLEncoded)
+	g.addTo(form)
+	tokenParameters.addTo(form)
 
-	setCustomQueryParameters(req.URL, queryParameters)
-	setCustomHeaders(req.Header, headers)
-
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.timeoutDuration)*time.Second)
-	defer cancel()
-	requestWithContext := req.WithContext(ctx)
-
-	response, err := client.Do(requestWithContext)
-	if err != nil {
-		return nil, apperrors.UpstreamServerCallFailed("failed to make a request to '%s': %s", authURL, err.Error())
-	}
+	return c.postTokenRequest(authURL, &tls.Config{InsecureSkipVerify: skipVerify}, form, headers, queryParameters, func(req *http.Request) {
+		util.AddBasicAuthHeader(req, clientID, clientSecret)
+	})
+}
 
-	if response.StatusCode != http.StatusOK {
-		return nil, apperrors.UpstreamServerCallFailed("incorrect response code '%d' while getting token from %s", response.StatusCode, authURL)
-	}
+func (c *client) requestTokenMTLS(clientID, authURL string, cert tls.Certificate, g grant, tokenParameters TokenParameters, headers, queryParameters *map[string][]string, skipVerify bool) (*oauthResponse, apperrors.AppError) {
+	form := url.Values{}
+	form.Add("client_id", clientID)
+	g.addTo(form)
+	tokenParameters.addTo(form)
 
-	body, err := ioutil.ReadAll(response.Body)
-	defer response.Body.Close()
-	if err != nil {
-		return nil, apperrors.UpstreamServerCallFailed("failed to read token response body from '%s': %s", authURL, err.Error())
+	tlsConfig := &tls.Config{
+		Certificates:       []tls.Certificate{cert},
+		InsecureSkipVerify: skipVerify,
 	}
 
-	tokenResponse := &oauthResponse{}
+	return c.postTokenRequest(authURL, tlsConfig, form, headers, queryParameters, nil)
+}
 
-	err = json.Unmarshal(body, tokenResponse)
-	if err != nil {
-		return nil, apperrors.UpstreamServerCallFailed("failed to unmarshal token response body: %s", err.Error())
-	}
+func (c *client) requestTokenJWTAssertion(clientID, authURL, assertion string, g grant, tokenParameters TokenParameters, headers, queryParameters *map[string][]string, skipVerify bool) (*oauthResponse, apperrors.AppError) {
+	form := url.Values{}
+	form.Add("client_id", clientID)
+	g.addTo(form)
+	form.Add("client_assertion_type", clientAssertionType)
+	form.Add("client_assertion", assertion)
+	tokenParameters.addTo(form)
 
-	return tokenResponse, nil
+	return c.postTokenRequest(authURL, &tls.Config{InsecureSkipVerify: skipVerify}, form, headers, queryParameters, nil)
 }
 
-func (c *client) requestTokenMTLS(clientID, authURL string, cert tls.Certificate, headers, queryParameters *map[string][]string, skipVerify bool) (*oauthResponse, apperrors.AppError) {
-	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			Certificates:       []tls.Certificate{cert},
-			InsecureSkipVerify: skipVerify,
-		},
-	}
+// postTokenRequest posts the form to the token endpoint and decodes the issued token, authorize adds the client credentials to the request if they are not part of the form
+func (c *client) postTokenRequest(authURL string, tlsConfig *tls.Config, form url.Values, headers, queryParameters *map[string][]string, authorize func(req *http.Request)) (*oauthResponse, apperrors.AppError) {
+	transport := httptools.NewRoundTripper(
+		httptools.WithTLSConfig(tlsConfig),
+		httptools.WithConnectionSettings(c.connection))
 	client := &http.Client{Transport: transport}
 
-	form := url.Values{}
-	form.Add("client_id", clientID)
-	form.Add("grant_type", "client_credentials")
-
-	req, err := http.NewRequest(http.MethodPost, authURL, strings.NewReader(form.Encode()))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.timeoutDuration)*time.Second)
+	defer cancel()
 
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, authURL, strings.NewReader(form.Encode()))
 	if err != nil {
 		return nil, apperrors.Internalf("failed to create token request: %s", err.Error())
 	}
 
+	if authorize != nil {
+		authorize(req)
+	}
 	req.Header.Add(httpconsts.HeaderContentType, httpconsts.ContentTypeApplicationURLEncoded)
 
 	setCustomQueryParameters(req.URL, queryParameters)
 	setCustomHeaders(req.Header, headers)
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.timeoutDuration)*time.Second)
-	defer cancel()
-	requestWithContext := req.WithContext(ctx)
-
-	response, err := client.Do(requestWithContext)
+	response, err := client.Do(req)
 	if err != nil {
 		return nil, apperrors.UpstreamServerCallFailed("failed to make a request to '%s': %s", authURL, err.Error())
 	}
+	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
 		return nil, apperrors.UpstreamServerCallFailed("incorrect response code '%d' while getting token from %s", response.StatusCode, authURL)
 	}
 
 	body, err := ioutil.ReadAll(response.Body)
-	defer response.Body.Close()
 	if err != nil {
 		return nil, apperrors.UpstreamServerCallFailed("failed to read token response body from '%s': %s", authURL, err.Error())
 	}
@@ -1,19 +1,31 @@
 package oauth
 
 import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
 
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth/tokencache"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth/tokencache/mocks"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/testconsts"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httptools"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -22,11 +34,12 @@ func TestOauthClient_GetToken(t *testing.T) {
 		// given
 		tokenCache := mocks.TokenCache{}
 		tokenCache.On("Get", "testIDtestSecret").Return("123456789", true)
+		tokenCache.On("NeedsRefresh", "testIDtestSecret").Return(false)
 
 		oauthClient := NewOauthClient(10, &tokenCache)
 
 		// when
-		token, err := oauthClient.GetToken("testID", "testSecret", "", nil, nil, false)
+		token, err := oauthClient.GetToken("testID", "testSecret", "", TokenParameters{}, nil, nil, false)
 
 		// then
 		require.NoError(t, err)
@@ -51,12 +64,13 @@ func TestOauthClient_GetToken(t *testing.T) {
 
 		tokenCache := mocks.TokenCache{}
 		tokenCache.On("Get", tokenKey).Return("", false)
+		tokenCache.On("GetRefreshToken", tokenKey).Return("", false)
 		tokenCache.On("Add", tokenKey, "123456789", 3600).Return()
 
 		oauthClient := NewOauthClient(10, &tokenCache)
 
 		// when
-		token, err := oauthClient.GetToken("testID", "testSecret", ts.URL, nil, nil, false)
+		token, err := oauthClient.GetToken("testID", "testSecret", ts.URL, TokenParameters{}, nil, nil, false)
 
 		// then
 		require.NoError(t, err)
@@ -83,12 +97,13 @@ func TestOauthClient_GetToken(t *testing.T) {
 
 		tokenCache := mocks.TokenCache{}
 		tokenCache.On("Get", tokenKey).Return("", false)
+		tokenCache.On("GetRefreshToken", tokenKey).Return("", false)
 		tokenCache.On("Add", tokenKey, "123456789", 3600).Return()
 
 		oauthClient := NewOauthClient(10, &tokenCache)
 
 		// when
-		token, err := oauthClient.GetToken("testID", "testSecret", ts.URL, nil, nil, true)
+		token, err := oauthClient.GetToken("testID", "testSecret", ts.URL, TokenParameters{}, nil, nil, true)
 
 		// then
 		require.NoError(t, err)
@@ -121,12 +136,52 @@ func TestOauthClient_GetToken(t *testing.T) {
 
 		tokenCache := mocks.TokenCache{}
 		tokenCache.On("Get", tokenKey).Return("", false)
+		tokenCache.On("GetRefreshToken", tokenKey).Return("", false)
 		tokenCache.On("Add", tokenKey, "123456789", 3600).Return()
 
 		oauthClient := NewOauthClient(10, &tokenCache)
 
 		// when
-		token, err := oauthClient.GetToken("testID", "testSecret", ts.URL, &headers, &queryParameters, false)
+		token, err := oauthClient.GetToken("testID", "testSecret", ts.URL, TokenParameters{}, &headers, &queryParameters, false)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "123456789", token)
+		tokenCache.AssertExpectations(t)
+	})
+
+	t.Run("should fetch token with token parameters and cache it separately", func(t *testing.T) {
+		// given
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			checkAccessTokenRequest(t, r)
+			assert.Equal(t, "read write", r.PostForm.Get("scope"))
+			assert.Equal(t, "https://api.example.com", r.PostForm.Get("audience"))
+			assert.Equal(t, "urn:example:resource", r.PostForm.Get("resource"))
+
+			response := oauthResponse{AccessToken: "123456789", TokenType: "bearer", ExpiresIn: 3600, Scope: "read write"}
+
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(response)
+		}))
+		defer ts.Close()
+
+		tokenParameters := TokenParameters{
+			Scope:    "read write",
+			Audience: "https://api.example.com",
+			Resource: "urn:example:resource",
+		}
+		tokenKey := "testID" + "testSecret" + ts.URL + "?audience=https%3A%2F%2Fapi.example.com&resource=urn%3Aexample%3Aresource&scope=read+write"
+
+		tokenCache := mocks.TokenCache{}
+		tokenCache.On("Get", tokenKey).Return("", false)
+		tokenCache.On("GetRefreshToken", tokenKey).Return("", false)
+		tokenCache.On("Add", tokenKey, "123456789", 3600).Return()
+
+		oauthClient := NewOauthClient(10, &tokenCache)
+
+		// when
+		token, err := oauthClient.GetToken("testID", "testSecret", ts.URL, tokenParameters, nil, nil, false)
 
 		// then
 		require.NoError(t, err)
@@ -146,11 +201,12 @@ func TestOauthClient_GetToken(t *testing.T) {
 
 		tokenCache := mocks.TokenCache{}
 		tokenCache.On("Get", tokenKey).Return("", false)
+		tokenCache.On("GetRefreshToken", tokenKey).Return("", false)
 
 		oauthClient := NewOauthClient(10, &tokenCache)
 
 		// when
-		token, err := oauthClient.GetToken("testID", "testSecret", ts.URL, nil, nil, false)
+		token, err := oauthClient.GetToken("testID", "testSecret", ts.URL, TokenParameters{}, nil, nil, false)
 
 		// then
 		require.Error(t, err)
@@ -172,11 +228,12 @@ func TestOauthClient_GetToken(t *testing.T) {
 
 		tokenCache := mocks.TokenCache{}
 		tokenCache.On("Get", tokenKey).Return("", false)
+		tokenCache.On("GetRefreshToken", tokenKey).Return("", false)
 
 		oauthClient := NewOauthClient(10, &tokenCache)
 
 		// when
-		token, err := oauthClient.GetToken("testID", "testSecret", ts.URL, nil, nil, false)
+		token, err := oauthClient.GetToken("testID", "testSecret", ts.URL, TokenParameters{}, nil, nil, false)
 
 		// then
 		require.Error(t, err)
@@ -190,11 +247,12 @@ func TestOauthClient_GetToken(t *testing.T) {
 
 		tokenCache := mocks.TokenCache{}
 		tokenCache.On("Get", tokenKey).Return("", false)
+		tokenCache.On("GetRefreshToken", tokenKey).Return("", false)
 
 		oauthClient := NewOauthClient(10, &tokenCache)
 
 		// when
-		token, err := oauthClient.GetToken("testID", "testSecret", "http://some_no_existent_address.com/token", nil, nil, false)
+		token, err := oauthClient.GetToken("testID", "testSecret", "http://some_no_existent_address.com/token", TokenParameters{}, nil, nil, false)
 
 		// then
 		require.Error(t, err)
@@ -215,17 +273,52 @@ func TestOauthClient_GetToken(t *testing.T) {
 
 		tokenCache := mocks.TokenCache{}
 		tokenCache.On("Get", tokenKey).Return("", false)
+		tokenCache.On("GetRefreshToken", tokenKey).Return("", false)
 		//tokenCache.On("Add", mock.Anything, mock.Anything, mock.Anything).Times(0)
 
 		oauthClient := NewOauthClient(10, &tokenCache)
 
 		// when
-		_, err := oauthClient.GetToken("testID", "testSecret", ts.URL, nil, nil, false)
+		_, err := oauthClient.GetToken("testID", "testSecret", ts.URL, TokenParameters{}, nil, nil, false)
 
 		// then
 		require.Error(t, err)
 		tokenCache.AssertExpectations(t)
 	})
+	t.Run("should fetch token from server with certificate issued by the certificate authority of the connection", func(t *testing.T) {
+		// given
+		ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			checkAccessTokenRequest(t, r)
+
+			response := oauthResponse{AccessToken: "123456789", TokenType: "bearer", ExpiresIn: 3600, Scope: "basic"}
+
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(response)
+		}))
+
+		ts.StartTLS()
+		defer ts.Close()
+
+		rootCAs := x509.NewCertPool()
+		rootCAs.AddCert(ts.Certificate())
+
+		tokenKey := "testID" + "testSecret" + ts.URL
+
+		tokenCache := mocks.TokenCache{}
+		tokenCache.On("Get", tokenKey).Return("", false)
+		tokenCache.On("GetRefreshToken", tokenKey).Return("", false)
+		tokenCache.On("Add", tokenKey, "123456789", 3600).Return()
+
+		oauthClient := NewOauthClientWithConnection(10, &tokenCache, tokencache.NewSubjectTokenCache(), &httptools.ConnectionSettings{RootCAs: rootCAs})
+
+		// when
+		token, err := oauthClient.GetToken("testID", "testSecret", ts.URL, TokenParameters{}, nil, nil, false)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "123456789", token)
+		tokenCache.AssertExpectations(t)
+	})
 }
 
 func TestOauthClient_GetTokenMTLS(t *testing.T) {
@@ -236,11 +329,12 @@ func TestOauthClient_GetTokenMTLS(t *testing.T) {
 		// given
 		tokenCache := mocks.TokenCache{}
 		tokenCache.On("Get", "testID-"+certSHA+"-"+keySHA+"-testURL").Return("123456789", true)
+		tokenCache.On("NeedsRefresh", "testID-"+certSHA+"-"+keySHA+"-testURL").Return(false)
 
 		oauthClient := NewOauthClient(10, &tokenCache)
 
 		// when
-		token, err := oauthClient.GetTokenMTLS("testID", "testURL", []byte("test"), []byte("test"), nil, nil, false)
+		token, err := oauthClient.GetTokenMTLS("testID", "testURL", []byte("test"), []byte("test"), TokenParameters{}, nil, nil, false)
 
 		// then
 		require.NoError(t, err)
@@ -252,11 +346,12 @@ func TestOauthClient_GetTokenMTLS(t *testing.T) {
 		// given
 		tokenCache := mocks.TokenCache{}
 		tokenCache.On("Get", "testID-"+certSHA+"-"+keySHA+"-testURL").Return("", false)
+		tokenCache.On("GetRefreshToken", "testID-"+certSHA+"-"+keySHA+"-testURL").Return("", false)
 
 		oauthClient := NewOauthClient(10, &tokenCache)
 
 		// when
-		token, err := oauthClient.GetTokenMTLS("testID", "testURL", []byte("test"), []byte("test"), nil, nil, false)
+		token, err := oauthClient.GetTokenMTLS("testID", "testURL", []byte("test"), []byte("test"), TokenParameters{}, nil, nil, false)
 
 		// then
 		assert.Error(t, err, apperrors.Internalf("Failed to prepare certificate, %s", err.Error()))
@@ -265,6 +360,402 @@ func TestOauthClient_GetTokenMTLS(t *testing.T) {
 	})
 }
 
+func TestOauthClient_TokenRefresh(t *testing.T) {
+	t.Run("should send single token request for concurrent calls", func(t *testing.T) {
+		// given
+		var requestCount int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requestCount, 1)
+			time.Sleep(100 * time.Millisecond)
+
+			response := oauthResponse{AccessToken: "123456789", TokenType: "bearer", ExpiresIn: 3600}
+
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(response)
+		}))
+		defer ts.Close()
+
+		oauthClient := NewOauthClient(10, tokencache.NewTokenCache())
+
+		// when
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				token, err := oauthClient.GetToken("testID", "testSecret", ts.URL, TokenParameters{}, nil, nil, false)
+				assert.NoError(t, err)
+				assert.Equal(t, "123456789", token)
+			}()
+		}
+		wg.Wait()
+
+		// then
+		assert.Equal(t, int32(1), atomic.LoadInt32(&requestCount))
+	})
+
+	t.Run("should return cached token and refresh it in background using refresh token", func(t *testing.T) {
+		// given
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := r.ParseForm()
+			require.NoError(t, err)
+
+			assert.Equal(t, "refresh_token", r.PostForm.Get("grant_type"))
+			assert.Equal(t, "refreshToken", r.PostForm.Get("refresh_token"))
+
+			response := oauthResponse{AccessToken: "987654321", TokenType: "bearer", ExpiresIn: 3600, RefreshToken: "newRefreshToken", RefreshExpiresIn: 7200}
+
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(response)
+		}))
+		defer ts.Close()
+
+		tokenKey := "testID" + "testSecret" + ts.URL
+		refreshed := make(chan struct{})
+
+		tokenCache := mocks.TokenCache{}
+		tokenCache.On("Get", tokenKey).Return("123456789", true)
+		tokenCache.On("NeedsRefresh", tokenKey).Return(true)
+		tokenCache.On("GetRefreshToken", tokenKey).Return("refreshToken", true)
+		tokenCache.On("Add", tokenKey, "987654321", 3600).Return()
+		tokenCache.On("AddRefreshToken", tokenKey, "newRefreshToken", 7200).Return().Run(func(args mock.Arguments) {
+			close(refreshed)
+		})
+
+		oauthClient := NewOauthClient(10, &tokenCache)
+
+		// when
+		token, err := oauthClient.GetToken("testID", "testSecret", ts.URL, TokenParameters{}, nil, nil, false)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "123456789", token)
+
+		select {
+		case <-refreshed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("token was not refreshed")
+		}
+		tokenCache.AssertExpectations(t)
+	})
+
+	t.Run("should fall back to client credentials when refresh token is rejected", func(t *testing.T) {
+		// given
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := r.ParseForm()
+			require.NoError(t, err)
+
+			if r.PostForm.Get("grant_type") == "refresh_token" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			checkAccessTokenRequest(t, r)
+
+			response := oauthResponse{AccessToken: "123456789", TokenType: "bearer", ExpiresIn: 3600}
+
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(response)
+		}))
+		defer ts.Close()
+
+		tokenKey := "testID" + "testSecret" + ts.URL
+
+		tokenCache := mocks.TokenCache{}
+		tokenCache.On("Get", tokenKey).Return("", false)
+		tokenCache.On("GetRefreshToken", tokenKey).Return("expiredRefreshToken", true)
+		tokenCache.On("Add", tokenKey, "123456789", 3600).Return()
+
+		oauthClient := NewOauthClient(10, &tokenCache)
+
+		// when
+		token, err := oauthClient.GetToken("testID", "testSecret", ts.URL, TokenParameters{}, nil, nil, false)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "123456789", token)
+		tokenCache.AssertExpectations(t)
+	})
+}
+
+func TestOauthClient_GetTokenJWTAssertion(t *testing.T) {
+	t.Run("should fetch token using signed client assertion", func(t *testing.T) {
+		// given
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := r.ParseForm()
+			require.NoError(t, err)
+
+			assert.Equal(t, "testID", r.PostForm.Get("client_id"))
+			assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+			assert.Equal(t, "api://default", r.PostForm.Get("scope"))
+			assert.Equal(t, clientAssertionType, r.PostForm.Get("client_assertion_type"))
+			assert.Empty(t, r.PostForm.Get("client_secret"))
+			assert.Empty(t, r.Header.Get(httpconsts.HeaderAuthorization))
+			checkClientAssertion(t, r.PostForm.Get("client_assertion"), "testID", "http://"+r.Host+r.URL.Path, "key-1")
+
+			response := oauthResponse{AccessToken: "123456789", TokenType: "bearer", ExpiresIn: 3600}
+
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(response)
+		}))
+		defer ts.Close()
+
+		tokenCache := mocks.TokenCache{}
+		tokenCache.On("Get", mock.AnythingOfType("string")).Return("", false)
+		tokenCache.On("GetRefreshToken", mock.AnythingOfType("string")).Return("", false)
+		tokenCache.On("Add", mock.AnythingOfType("string"), "123456789", 3600).Return()
+
+		oauthClient := NewOauthClient(10, &tokenCache)
+
+		// when
+		token, err := oauthClient.GetTokenJWTAssertion("testID", ts.URL+"/oauth/token", []byte(testconsts.PrivateKey), "key-1", TokenParameters{Scope: "api://default"}, nil, nil, false)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "123456789", token)
+		tokenCache.AssertExpectations(t)
+	})
+
+	t.Run("should get token from cache if present", func(t *testing.T) {
+		// given
+		tokenCache := mocks.TokenCache{}
+		tokenCache.On("Get", mock.AnythingOfType("string")).Return("123456789", true)
+		tokenCache.On("NeedsRefresh", mock.AnythingOfType("string")).Return(false)
+
+		oauthClient := NewOauthClient(10, &tokenCache)
+
+		// when
+		token, err := oauthClient.GetTokenJWTAssertion("testID", "testURL", []byte(testconsts.PrivateKey), "key-1", TokenParameters{}, nil, nil, false)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "123456789", token)
+		tokenCache.AssertExpectations(t)
+	})
+
+	t.Run("should fail if Private Key is not valid", func(t *testing.T) {
+		// given
+		tokenCache := mocks.TokenCache{}
+		tokenCache.On("Get", mock.AnythingOfType("string")).Return("", false)
+		tokenCache.On("GetRefreshToken", mock.AnythingOfType("string")).Return("", false)
+
+		oauthClient := NewOauthClient(10, &tokenCache)
+
+		// when
+		token, err := oauthClient.GetTokenJWTAssertion("testID", "testURL", []byte("test"), "", TokenParameters{}, nil, nil, false)
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, apperrors.CodeInternal, err.Code())
+		assert.Equal(t, "", token)
+		tokenCache.AssertExpectations(t)
+	})
+}
+
+func TestOauthClient_GetTokenExchange(t *testing.T) {
+	newSubjectToken := func(subject string, expiresAt time.Time) string {
+		payload, err := json.Marshal(map[string]interface{}{"iss": "https://idp.example.com", "sub": subject, "aud": []string{"app"}, "exp": expiresAt.Unix()})
+		require.NoError(t, err)
+		return "eyJhbGciOiJSUzI1NiJ9." + base64.RawURLEncoding.EncodeToString(payload) + ".signature"
+	}
+
+	newTokenServer := func(requests *int, expiresIn int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*requests++
+			err := r.ParseForm()
+			require.NoError(t, err)
+
+			var token string
+			switch r.PostForm.Get("grant_type") {
+			case tokenExchangeGrantType:
+				assert.Equal(t, defaultSubjectTokenType, r.PostForm.Get("subject_token_type"))
+				assert.Equal(t, "urn:ietf:params:oauth:token-type:access_token", r.PostForm.Get("requested_token_type"))
+				token = "exchanged-" + r.PostForm.Get("subject_token")
+			case samlBearerGrantType:
+				token = "exchanged-" + r.PostForm.Get("assertion")
+			}
+			assert.Equal(t, "orders", r.PostForm.Get("audience"))
+
+			clientID, clientSecret, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "testID", clientID)
+			assert.Equal(t, "testSecret", clientSecret)
+
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(oauthResponse{AccessToken: token, TokenType: "bearer", ExpiresIn: expiresIn})
+		}))
+	}
+
+	exchange := TokenExchange{RequestedTokenType: "urn:ietf:params:oauth:token-type:access_token"}
+	tokenParameters := TokenParameters{Audience: "orders"}
+
+	t.Run("should exchange the token of the user and cache the result per subject", func(t *testing.T) {
+		// given
+		var requests int
+		ts := newTokenServer(&requests, 3600)
+		defer ts.Close()
+
+		oauthClient := NewOauthClient(10, tokencache.NewTokenCache())
+		alice := newSubjectToken("alice", time.Now().Add(time.Hour))
+		bob := newSubjectToken("bob", time.Now().Add(time.Hour))
+
+		// when
+		aliceToken, aliceErr := oauthClient.GetTokenExchange("testID", "testSecret", ts.URL, alice, exchange, tokenParameters, nil, nil, false)
+		bobToken, bobErr := oauthClient.GetTokenExchange("testID", "testSecret", ts.URL, bob, exchange, tokenParameters, nil, nil, false)
+		cachedAliceToken, cachedErr := oauthClient.GetTokenExchange("testID", "testSecret", ts.URL, alice, exchange, tokenParameters, nil, nil, false)
+
+		// then
+		require.NoError(t, aliceErr)
+		require.NoError(t, bobErr)
+		require.NoError(t, cachedErr)
+		assert.Equal(t, "exchanged-"+alice, aliceToken)
+		assert.Equal(t, "exchanged-"+bob, bobToken)
+		assert.Equal(t, aliceToken, cachedAliceToken)
+		assert.Equal(t, 2, requests)
+
+		// when
+		oauthClient.InvalidateTokenCacheExchange("testID", "testSecret", ts.URL, exchange, tokenParameters)
+		_, err := oauthClient.GetTokenExchange("testID", "testSecret", ts.URL, alice, exchange, tokenParameters, nil, nil, false)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 3, requests)
+	})
+
+	t.Run("should invalidate the cached token of the subject only", func(t *testing.T) {
+		// given
+		var requests int
+		ts := newTokenServer(&requests, 3600)
+		defer ts.Close()
+
+		oauthClient := NewOauthClient(10, tokencache.NewTokenCache())
+		alice := newSubjectToken("alice", time.Now().Add(time.Hour))
+		bob := newSubjectToken("bob", time.Now().Add(time.Hour))
+		_, err := oauthClient.GetTokenExchange("testID", "testSecret", ts.URL, alice, exchange, tokenParameters, nil, nil, false)
+		require.NoError(t, err)
+		_, err = oauthClient.GetTokenExchange("testID", "testSecret", ts.URL, bob, exchange, tokenParameters, nil, nil, false)
+		require.NoError(t, err)
+
+		// when
+		oauthClient.InvalidateTokenCacheExchangeSubject("testID", "testSecret", ts.URL, alice, exchange, tokenParameters)
+		_, aliceErr := oauthClient.GetTokenExchange("testID", "testSecret", ts.URL, alice, exchange, tokenParameters, nil, nil, false)
+		_, bobErr := oauthClient.GetTokenExchange("testID", "testSecret", ts.URL, bob, exchange, tokenParameters, nil, nil, false)
+
+		// then
+		require.NoError(t, aliceErr)
+		require.NoError(t, bobErr)
+		assert.Equal(t, 3, requests)
+	})
+
+	t.Run("should not return the cached token of the subject for another token of the subject", func(t *testing.T) {
+		// given
+		var requests int
+		ts := newTokenServer(&requests, 3600)
+		defer ts.Close()
+
+		oauthClient := NewOauthClient(10, tokencache.NewTokenCache())
+		alice := newSubjectToken("alice", time.Now().Add(time.Hour))
+		forged := newSubjectToken("alice", time.Now().Add(2*time.Hour))
+
+		// when
+		_, err := oauthClient.GetTokenExchange("testID", "testSecret", ts.URL, alice, exchange, tokenParameters, nil, nil, false)
+		require.NoError(t, err)
+		token, err := oauthClient.GetTokenExchange("testID", "testSecret", ts.URL, forged, exchange, tokenParameters, nil, nil, false)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "exchanged-"+forged, token)
+		assert.Equal(t, 2, requests)
+	})
+
+	t.Run("should not cache the token longer than the token of the user is valid", func(t *testing.T) {
+		// given
+		var requests int
+		ts := newTokenServer(&requests, 3600)
+		defer ts.Close()
+
+		oauthClient := NewOauthClient(10, tokencache.NewTokenCache())
+		expiring := newSubjectToken("alice", time.Now().Add(time.Second))
+
+		// when
+		_, err := oauthClient.GetTokenExchange("testID", "testSecret", ts.URL, expiring, exchange, tokenParameters, nil, nil, false)
+		require.NoError(t, err)
+		_, err = oauthClient.GetTokenExchange("testID", "testSecret", ts.URL, expiring, exchange, tokenParameters, nil, nil, false)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 2, requests)
+	})
+
+	t.Run("should send the SAML assertion of the user as the authorization grant", func(t *testing.T) {
+		// given
+		var requests int
+		ts := newTokenServer(&requests, 3600)
+		defer ts.Close()
+
+		oauthClient := NewOauthClient(10, tokencache.NewTokenCache())
+
+		// when
+		token, err := oauthClient.GetTokenExchange("testID", "testSecret", ts.URL, "PHNhbWw6QXNzZXJ0aW9uPg", TokenExchange{GrantType: GrantTypeSAMLBearer}, tokenParameters, nil, nil, false)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "exchanged-PHNhbWw6QXNzZXJ0aW9uPg", token)
+	})
+
+	t.Run("should fail when the token endpoint rejects the token of the user", func(t *testing.T) {
+		// given
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer ts.Close()
+
+		oauthClient := NewOauthClient(10, tokencache.NewTokenCache())
+
+		// when
+		token, err := oauthClient.GetTokenExchange("testID", "testSecret", ts.URL, "invalid", exchange, tokenParameters, nil, nil, false)
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, apperrors.CodeUpstreamServerCallFailed, err.Code())
+		assert.Equal(t, "", token)
+	})
+}
+
+func checkClientAssertion(t *testing.T, assertion, clientID, audience, keyID string) {
+	parts := strings.Split(assertion, ".")
+	require.Len(t, parts, 3)
+
+	var header jwtHeader
+	decodeSegment(t, parts[0], &header)
+	assert.Equal(t, "RS256", header.Algorithm)
+	assert.Equal(t, keyID, header.KeyID)
+
+	var claims jwtClaims
+	decodeSegment(t, parts[1], &claims)
+	assert.Equal(t, clientID, claims.Issuer)
+	assert.Equal(t, clientID, claims.Subject)
+	assert.Equal(t, audience, claims.Audience)
+	assert.NotEmpty(t, claims.ID)
+	assert.Greater(t, claims.ExpiresAt, claims.IssuedAt)
+
+	block, _ := pem.Decode([]byte(testconsts.PrivateKey))
+	require.NotNil(t, block)
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	require.NoError(t, err)
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	assert.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature))
+}
+
+func decodeSegment(t *testing.T, segment string, v interface{}) {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, v))
+}
+
 func checkAccessTokenRequest(t *testing.T, r *http.Request) {
 	err := r.ParseForm()
 	require.NoError(t, err)
@@ -14,6 +14,11 @@ func (_m *TokenCache) Add(clientID string, token string, expirationSeconds int)
 	_m.Called(clientID, token, expirationSeconds)
 }
 
+// AddRefreshToken provides a mock function with given fields: clientID, refreshToken, expirationSeconds
+func (_m *TokenCache) AddRefreshToken(clientID string, refreshToken string, expirationSeconds int) {
+	_m.Called(clientID, refreshToken, expirationSeconds)
+}
+
 // Get provides a mock function with given fields: clientID
 func (_m *TokenCache) Get(clientID string) (string, bool) {
 	ret := _m.Called(clientID)
@@ -35,6 +40,41 @@ func (_m *TokenCache) Get(clientID string) (string, bool) {
 	return r0, r1
 }
 
+// GetRefreshToken provides a mock function with given fields: clientID
+func (_m *TokenCache) GetRefreshToken(clientID string) (string, bool) {
+	ret := _m.Called(clientID)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(clientID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 bool
+	if rf, ok := ret.Get(1).(func(string) bool); ok {
+		r1 = rf(clientID)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
+// NeedsRefresh provides a mock function with given fields: clientID
+func (_m *TokenCache) NeedsRefresh(clientID string) bool {
+	ret := _m.Called(clientID)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(clientID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
 // Remove provides a mock function with given fields: clientID
 func (_m *TokenCache) Remove(clientID string) {
 	_m.Called(clientID)
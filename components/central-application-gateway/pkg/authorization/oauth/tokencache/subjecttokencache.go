@@ -0,0 +1,83 @@
+package tokencache
+
+import (
+	"crypto/sha256"
+	"strings"
+	"time"
+
+	cache "github.com/patrickmn/go-cache"
+)
+
+const (
+	subjectSeparator       = "\n"
+	subjectCleanupInterval = time.Minute
+)
+
+// SubjectTokenCache keeps the tokens issued on behalf of users, one token per subject of the credentials.
+// A token is returned only for the subject token it was issued for, so that a forged subject token can't get the token of another user.
+type SubjectTokenCache interface {
+	Get(key, subject, subjectToken string) (token string, found bool)
+	// Add replaces the token of the subject
+	Add(key, subject, subjectToken, token string, expirationSeconds int)
+	// Remove removes the tokens of all subjects of the key
+	Remove(key string)
+	// RemoveSubject removes the token of the subject, the tokens of other subjects of the key are kept
+	RemoveSubject(key, subject string)
+}
+
+type subjectTokenCache struct {
+	cache *cache.Cache
+}
+
+type subjectEntry struct {
+	token            string
+	subjectTokenHash [sha256.Size]byte
+}
+
+// NewSubjectTokenCache creates cache which keeps tokens until they expire, expired tokens of users who stopped calling are removed periodically
+func NewSubjectTokenCache() SubjectTokenCache {
+	return &subjectTokenCache{
+		cache: cache.New(cache.NoExpiration, subjectCleanupInterval),
+	}
+}
+
+func (tc *subjectTokenCache) Get(key, subject, subjectToken string) (string, bool) {
+	res, found := tc.cache.Get(subjectKey(key, subject))
+	if !found {
+		return "", false
+	}
+
+	e := res.(subjectEntry)
+	if e.subjectTokenHash != sha256.Sum256([]byte(subjectToken)) {
+		return "", false
+	}
+
+	return e.token, true
+}
+
+func (tc *subjectTokenCache) Add(key, subject, subjectToken, token string, expirationSeconds int) {
+	// tokens without expiration aren't kept, as they would never be removed
+	if expirationSeconds <= 2 {
+		return
+	}
+
+	e := subjectEntry{token: token, subjectTokenHash: sha256.Sum256([]byte(subjectToken))}
+	tc.cache.Set(subjectKey(key, subject), e, time.Duration(expirationSeconds-2)*time.Second)
+}
+
+func (tc *subjectTokenCache) Remove(key string) {
+	prefix := key + subjectSeparator
+	for cacheKey := range tc.cache.Items() {
+		if strings.HasPrefix(cacheKey, prefix) {
+			tc.cache.Delete(cacheKey)
+		}
+	}
+}
+
+func (tc *subjectTokenCache) RemoveSubject(key, subject string) {
+	tc.cache.Delete(subjectKey(key, subject))
+}
+
+func subjectKey(key, subject string) string {
+	return key + subjectSeparator + subject
+}
@@ -0,0 +1,91 @@
+package tokencache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubjectTokenCache(t *testing.T) {
+	t.Run("should return the token of the subject only for the subject token it was issued for", func(t *testing.T) {
+		// given
+		tokenCache := NewSubjectTokenCache()
+		tokenCache.Add(cachedClientID, "alice", "alice-jwt", cachedToken, 3600)
+
+		// when
+		token, found := tokenCache.Get(cachedClientID, "alice", "alice-jwt")
+		_, foundForOtherSubjectToken := tokenCache.Get(cachedClientID, "alice", "forged-jwt")
+		_, foundForOtherSubject := tokenCache.Get(cachedClientID, "bob", "alice-jwt")
+
+		// then
+		assert.True(t, found)
+		assert.Equal(t, cachedToken, token)
+		assert.False(t, foundForOtherSubjectToken)
+		assert.False(t, foundForOtherSubject)
+	})
+
+	t.Run("should keep one token per subject", func(t *testing.T) {
+		// given
+		tokenCache := NewSubjectTokenCache()
+		tokenCache.Add(cachedClientID, "alice", "old-jwt", "old-token", 3600)
+		tokenCache.Add(cachedClientID, "alice", "new-jwt", "new-token", 3600)
+
+		// when
+		_, foundOld := tokenCache.Get(cachedClientID, "alice", "old-jwt")
+		token, found := tokenCache.Get(cachedClientID, "alice", "new-jwt")
+
+		// then
+		assert.False(t, foundOld)
+		assert.True(t, found)
+		assert.Equal(t, "new-token", token)
+	})
+
+	t.Run("should remove the tokens of all subjects of the key", func(t *testing.T) {
+		// given
+		tokenCache := NewSubjectTokenCache()
+		tokenCache.Add(cachedClientID, "alice", "alice-jwt", "alice-token", 3600)
+		tokenCache.Add(cachedClientID, "bob", "bob-jwt", "bob-token", 3600)
+		tokenCache.Add("otherClientID", "alice", "alice-jwt", "other-token", 3600)
+
+		// when
+		tokenCache.Remove(cachedClientID)
+
+		// then
+		_, foundAlice := tokenCache.Get(cachedClientID, "alice", "alice-jwt")
+		_, foundBob := tokenCache.Get(cachedClientID, "bob", "bob-jwt")
+		token, foundOther := tokenCache.Get("otherClientID", "alice", "alice-jwt")
+		assert.False(t, foundAlice)
+		assert.False(t, foundBob)
+		assert.True(t, foundOther)
+		assert.Equal(t, "other-token", token)
+	})
+
+	t.Run("should remove token of the subject only", func(t *testing.T) {
+		// given
+		tokenCache := NewSubjectTokenCache()
+		tokenCache.Add(cachedClientID, "alice", "alice-jwt", "alice-token", 3600)
+		tokenCache.Add(cachedClientID, "bob", "bob-jwt", "bob-token", 3600)
+
+		// when
+		tokenCache.RemoveSubject(cachedClientID, "alice")
+
+		// then
+		_, foundAlice := tokenCache.Get(cachedClientID, "alice", "alice-jwt")
+		token, foundBob := tokenCache.Get(cachedClientID, "bob", "bob-jwt")
+		assert.False(t, foundAlice)
+		assert.True(t, foundBob)
+		assert.Equal(t, "bob-token", token)
+	})
+
+	t.Run("should not keep tokens without expiration", func(t *testing.T) {
+		// given
+		tokenCache := NewSubjectTokenCache()
+		tokenCache.Add(cachedClientID, "alice", "alice-jwt", cachedToken, 0)
+
+		// when
+		_, found := tokenCache.Get(cachedClientID, "alice", "alice-jwt")
+
+		// then
+		assert.False(t, found)
+	})
+}
@@ -6,19 +6,51 @@ import (
 	cache "github.com/patrickmn/go-cache"
 )
 
+const (
+	cleanupInterval = time.Minute
+	// defaultRefreshTokenExpiration bounds the lifetime of refresh tokens issued without refresh_expires_in
+	defaultRefreshTokenExpiration = 24 * time.Hour
+)
+
 type TokenCache interface {
 	Get(clientID string) (token string, found bool)
 	Add(clientID, token string, expirationSeconds int)
 	Remove(clientID string)
+	// NeedsRefresh returns true if the cached token passed the configured fraction of its lifetime and should be refreshed
+	NeedsRefresh(clientID string) bool
+	// AddRefreshToken stores refresh token issued together with the cached token until it expires.
+	// Non-positive expirationSeconds fall back to the default lifetime of 24 hours
+	AddRefreshToken(clientID, refreshToken string, expirationSeconds int)
+	GetRefreshToken(clientID string) (refreshToken string, found bool)
 }
 
 type tokenCache struct {
-	cache *cache.Cache
+	cache           *cache.Cache
+	refreshTokens   *cache.Cache
+	refreshFraction float64
+}
+
+type entry struct {
+	token     string
+	refreshAt time.Time
 }
 
+// NewTokenCache creates cache which keeps tokens until they expire
 func NewTokenCache() TokenCache {
+	return NewTokenCacheWithRefresh(1)
+}
+
+// NewTokenCacheWithRefresh creates cache which marks tokens for refresh once refreshFraction of their lifetime has elapsed.
+// Values outside of (0, 1) disable early refresh.
+func NewTokenCacheWithRefresh(refreshFraction float64) TokenCache {
+	if refreshFraction <= 0 || refreshFraction > 1 {
+		refreshFraction = 1
+	}
+
 	return &tokenCache{
-		cache: cache.New(cache.NoExpiration, cache.NoExpiration),
+		cache:           cache.New(cache.NoExpiration, cleanupInterval),
+		refreshTokens:   cache.New(defaultRefreshTokenExpiration, cleanupInterval),
+		refreshFraction: refreshFraction,
 	}
 }
 
@@ -28,13 +60,48 @@ func (tc *tokenCache) Get(clientId string) (token string, found bool) {
 		return "", false
 	}
 
-	return res.(string), found
+	return res.(entry).token, found
 }
 
 func (tc *tokenCache) Add(clientID, token string, expirationSeconds int) {
-	tc.cache.Set(clientID, token, time.Duration(expirationSeconds-2)*time.Second)
+	e := entry{token: token}
+	if tc.refreshFraction < 1 && expirationSeconds > 0 {
+		refreshAfter := time.Duration(float64(expirationSeconds) * tc.refreshFraction * float64(time.Second))
+		e.refreshAt = time.Now().Add(refreshAfter)
+	}
+
+	tc.cache.Set(clientID, e, time.Duration(expirationSeconds-2)*time.Second)
 }
 
 func (tc *tokenCache) Remove(clientID string) {
 	tc.cache.Delete(clientID)
+	tc.refreshTokens.Delete(clientID)
+}
+
+func (tc *tokenCache) NeedsRefresh(clientID string) bool {
+	res, found := tc.cache.Get(clientID)
+	if !found {
+		return false
+	}
+
+	refreshAt := res.(entry).refreshAt
+	return !refreshAt.IsZero() && time.Now().After(refreshAt)
+}
+
+func (tc *tokenCache) AddRefreshToken(clientID, refreshToken string, expirationSeconds int) {
+	expiration := cache.DefaultExpiration
+	if expirationSeconds > 0 {
+		expiration = time.Duration(expirationSeconds) * time.Second
+	}
+
+	tc.refreshTokens.Set(clientID, refreshToken, expiration)
+}
+
+func (tc *tokenCache) GetRefreshToken(clientID string) (refreshToken string, found bool) {
+	res, found := tc.refreshTokens.Get(clientID)
+	if !found {
+		return "", false
+	}
+
+	return res.(string), found
 }
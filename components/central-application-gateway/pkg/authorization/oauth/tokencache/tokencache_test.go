@@ -67,4 +67,83 @@ func TestTokenCache(t *testing.T) {
 		assert.Equal(t, false, found)
 		assert.Equal(t, "", token)
 	})
+
+	t.Run("should not mark token for refresh if early refresh is disabled", func(t *testing.T) {
+		// given
+		tokenCache := NewTokenCache()
+		tokenCache.Add(cachedClientID, cachedToken, 1)
+
+		time.Sleep(100 * time.Millisecond)
+
+		// when
+		needsRefresh := tokenCache.NeedsRefresh(cachedClientID)
+
+		// then
+		assert.Equal(t, false, needsRefresh)
+	})
+
+	t.Run("should mark token for refresh once refresh fraction of lifetime elapsed", func(t *testing.T) {
+		// given
+		tokenCache := NewTokenCacheWithRefresh(0.00001)
+		tokenCache.Add(cachedClientID, cachedToken, 3600)
+
+		time.Sleep(100 * time.Millisecond)
+
+		// when
+		token, found := tokenCache.Get(cachedClientID)
+		needsRefresh := tokenCache.NeedsRefresh(cachedClientID)
+
+		// then
+		assert.Equal(t, true, found)
+		assert.Equal(t, cachedToken, token)
+		assert.Equal(t, true, needsRefresh)
+	})
+
+	t.Run("should not mark token for refresh before refresh fraction of lifetime elapsed", func(t *testing.T) {
+		// given
+		tokenCache := NewTokenCacheWithRefresh(0.5)
+		tokenCache.Add(cachedClientID, cachedToken, 3600)
+
+		// when
+		needsRefresh := tokenCache.NeedsRefresh(cachedClientID)
+
+		// then
+		assert.Equal(t, false, needsRefresh)
+	})
+
+	t.Run("should store refresh token until it is removed", func(t *testing.T) {
+		// given
+		tokenCache := NewTokenCache()
+		tokenCache.Add(cachedClientID, cachedToken, 3600)
+		tokenCache.AddRefreshToken(cachedClientID, "refreshToken", 0)
+
+		// when
+		refreshToken, found := tokenCache.GetRefreshToken(cachedClientID)
+
+		// then
+		assert.Equal(t, true, found)
+		assert.Equal(t, "refreshToken", refreshToken)
+
+		// when
+		tokenCache.Remove(cachedClientID)
+		refreshToken, found = tokenCache.GetRefreshToken(cachedClientID)
+
+		// then
+		assert.Equal(t, false, found)
+		assert.Equal(t, "", refreshToken)
+	})
+
+	t.Run("should expire refresh token after its lifetime", func(t *testing.T) {
+		// given
+		tokenCache := NewTokenCache()
+		tokenCache.AddRefreshToken(cachedClientID, "refreshToken", 1)
+
+		// when
+		time.Sleep(1100 * time.Millisecond)
+		refreshToken, found := tokenCache.GetRefreshToken(cachedClientID)
+
+		// then
+		assert.Equal(t, false, found)
+		assert.Equal(t, "", refreshToken)
+	})
 }
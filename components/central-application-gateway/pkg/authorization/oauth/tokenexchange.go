@@ -0,0 +1,115 @@
+package oauth
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+const (
+	// GrantTypeTokenExchange exchanges the token of the user as described in RFC 8693
+	GrantTypeTokenExchange = "TokenExchange"
+	// GrantTypeSAMLBearer sends the SAML assertion of the user as the authorization grant described in RFC 7522
+	GrantTypeSAMLBearer = "SAMLBearer"
+
+	tokenExchangeGrantType  = "urn:ietf:params:oauth:grant-type:token-exchange"
+	samlBearerGrantType     = "urn:ietf:params:oauth:grant-type:saml2-bearer"
+	defaultSubjectTokenType = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+// TokenExchange defines how the token of the user is exchanged for the token of the target system
+type TokenExchange struct {
+	// GrantType is TokenExchange, the default, or SAMLBearer
+	GrantType string
+	// SubjectTokenType (optional) is the type of the token of the user in the token exchange, urn:ietf:params:oauth:token-type:jwt by default
+	SubjectTokenType string
+	// RequestedTokenType (optional) is the type of the requested token in the token exchange
+	RequestedTokenType string
+}
+
+func (te TokenExchange) grant(subjectToken string) grant {
+	if te.GrantType == GrantTypeSAMLBearer {
+		return grant{
+			"grant_type": {samlBearerGrantType},
+			"assertion":  {subjectToken},
+		}
+	}
+
+	subjectTokenType := te.SubjectTokenType
+	if subjectTokenType == "" {
+		subjectTokenType = defaultSubjectTokenType
+	}
+	g := grant{
+		"grant_type":         {tokenExchangeGrantType},
+		"subject_token":      {subjectToken},
+		"subject_token_type": {subjectTokenType},
+	}
+	if te.RequestedTokenType != "" {
+		g["requested_token_type"] = []string{te.RequestedTokenType}
+	}
+
+	return g
+}
+
+func (te TokenExchange) cacheKey() string {
+	return te.GrantType + "|" + te.SubjectTokenType + "|" + te.RequestedTokenType
+}
+
+// subject identifies the user of the subject token. The claims of a JWT aren't verified, as the token endpoint verifies the token,
+// and the cached tokens are returned only for the same subject token. Other tokens, e.g. SAML assertions, are identified by their hash.
+// The expiration of a JWT is returned as well, zero if unknown.
+func subject(subjectToken string) (string, time.Time) {
+	if claims, ok := parseJWTClaims(subjectToken); ok && claims.Subject != "" {
+		var expiresAt time.Time
+		if claims.ExpiresAt > 0 {
+			expiresAt = time.Unix(claims.ExpiresAt, 0)
+		}
+		return claims.Issuer + "|" + claims.Subject, expiresAt
+	}
+
+	hash := sha256.Sum256([]byte(subjectToken))
+	return "sha256:" + hex.EncodeToString(hash[:]), time.Time{}
+}
+
+// subjectClaims are the claims of the subject token identifying the user, the audience isn't parsed as it can be a string or an array
+type subjectClaims struct {
+	Issuer    string `json:"iss"`
+	Subject   string `json:"sub"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+func parseJWTClaims(token string) (subjectClaims, bool) {
+	segments := strings.Split(token, ".")
+	if len(segments) != 3 {
+		return subjectClaims{}, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(segments[1])
+	if err != nil {
+		return subjectClaims{}, false
+	}
+
+	var claims subjectClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return subjectClaims{}, false
+	}
+
+	return claims, true
+}
+
+// expirationSeconds limits the lifetime of the issued token to the lifetime of the subject token, so that the user isn't represented after its token expired
+func expirationSeconds(expiresIn int, subjectTokenExpiresAt time.Time) int {
+	if subjectTokenExpiresAt.IsZero() {
+		return expiresIn
+	}
+
+	remaining := int(time.Until(subjectTokenExpiresAt).Seconds())
+	if expiresIn <= 0 || remaining < expiresIn {
+		return remaining
+	}
+
+	return expiresIn
+}
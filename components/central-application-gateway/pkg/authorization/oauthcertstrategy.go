@@ -10,6 +10,7 @@ import (
 
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/clientcert"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth"
 )
 
 type oauthWithCertStrategy struct {
@@ -19,11 +20,12 @@ type oauthWithCertStrategy struct {
 	certificate            []byte
 	privateKey             []byte
 	url                    string
+	tokenParameters        oauth.TokenParameters
 	requestParameters      *RequestParameters
 	tokenRequestSkipVerify bool
 }
 
-func newOAuthWithCertStrategy(oauthClient OAuthClient, clientId string, clientSecret string, certificate, privateKey []byte, url string, requestParameters *RequestParameters) oauthWithCertStrategy {
+func newOAuthWithCertStrategy(oauthClient OAuthClient, clientId string, clientSecret string, certificate, privateKey []byte, url string, tokenParameters oauth.TokenParameters, requestParameters *RequestParameters) oauthWithCertStrategy {
 	return oauthWithCertStrategy{
 		oauthClient:       oauthClient,
 		clientId:          clientId,
@@ -31,6 +33,7 @@ func newOAuthWithCertStrategy(oauthClient OAuthClient, clientId string, clientSe
 		certificate:       certificate,
 		privateKey:        privateKey,
 		url:               url,
+		tokenParameters:   tokenParameters,
 		requestParameters: requestParameters,
 	}
 }
@@ -39,7 +42,7 @@ func (o oauthWithCertStrategy) AddAuthorization(r *http.Request, _ clientcert.Se
 	zap.L().Info("passing skipTLSVerification to GetTokenMTLS",
 		zap.Bool("skipTLSVerification", skipTLSVerification))
 	headers, queryParameters := o.requestParameters.unpack()
-	token, err := o.oauthClient.GetTokenMTLS(o.clientId, o.url, o.certificate, o.privateKey, headers, queryParameters, skipTLSVerification)
+	token, err := o.oauthClient.GetTokenMTLS(o.clientId, o.url, o.certificate, o.privateKey, o.tokenParameters, headers, queryParameters, skipTLSVerification)
 	if err != nil {
 		zap.L().Error("failed to get token",
 			zap.Error(err))
@@ -52,5 +55,5 @@ func (o oauthWithCertStrategy) AddAuthorization(r *http.Request, _ clientcert.Se
 }
 
 func (o oauthWithCertStrategy) Invalidate() {
-	o.oauthClient.InvalidateTokenCacheMTLS(o.clientId, o.url, o.certificate, o.privateKey)
+	o.oauthClient.InvalidateTokenCacheMTLS(o.clientId, o.url, o.certificate, o.privateKey, o.tokenParameters)
 }
@@ -0,0 +1,53 @@
+package authorization
+
+import (
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/clientcert"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+)
+
+type oauthJWTAssertionStrategy struct {
+	oauthClient       OAuthClient
+	clientId          string
+	privateKey        []byte
+	keyId             string
+	url               string
+	tokenParameters   oauth.TokenParameters
+	requestParameters *RequestParameters
+}
+
+func newOAuthJWTAssertionStrategy(oauthClient OAuthClient, clientId string, privateKey []byte, keyId, url string, tokenParameters oauth.TokenParameters, requestParameters *RequestParameters) oauthJWTAssertionStrategy {
+	return oauthJWTAssertionStrategy{
+		oauthClient:       oauthClient,
+		clientId:          clientId,
+		privateKey:        privateKey,
+		keyId:             keyId,
+		url:               url,
+		tokenParameters:   tokenParameters,
+		requestParameters: requestParameters,
+	}
+}
+
+func (o oauthJWTAssertionStrategy) AddAuthorization(r *http.Request, _ clientcert.SetClientCertificateFunc, skipTLSVerification bool) apperrors.AppError {
+	headers, queryParameters := o.requestParameters.unpack()
+	token, err := o.oauthClient.GetTokenJWTAssertion(o.clientId, o.url, o.privateKey, o.keyId, o.tokenParameters, headers, queryParameters, skipTLSVerification)
+	if err != nil {
+		zap.L().Error("failed to get token",
+			zap.Error(err))
+		return err
+	}
+
+	r.Header.Set(httpconsts.HeaderAuthorization, fmt.Sprintf("Bearer %s", token))
+
+	return nil
+}
+
+func (o oauthJWTAssertionStrategy) Invalidate() {
+	o.oauthClient.InvalidateTokenCacheJWTAssertion(o.clientId, o.url, o.privateKey, o.keyId, o.tokenParameters)
+}
@@ -0,0 +1,72 @@
+package authorization
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth"
+	oauthMocks "github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth/mocks"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/testconsts"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuthJWTAssertionStrategy(t *testing.T) {
+	privateKey := []byte(testconsts.PrivateKey)
+	tokenParameters := oauth.TokenParameters{Scope: "read"}
+
+	t.Run("should add Authorization header", func(t *testing.T) {
+		// given
+		oauthClientMock := &oauthMocks.Client{}
+		oauthClientMock.On("GetTokenJWTAssertion", "clientId", "www.example.com/token", privateKey, "keyId", tokenParameters, (*map[string][]string)(nil), (*map[string][]string)(nil), true).Return("token", nil)
+
+		strategy := newOAuthJWTAssertionStrategy(oauthClientMock, "clientId", privateKey, "keyId", "www.example.com/token", tokenParameters, nil)
+
+		request, err := http.NewRequest("GET", "www.example.com", nil)
+		require.NoError(t, err)
+
+		// when
+		err = strategy.AddAuthorization(request, nil, true)
+
+		// then
+		require.NoError(t, err)
+		authHeader := request.Header.Get(httpconsts.HeaderAuthorization)
+		assert.Equal(t, "Bearer token", authHeader)
+	})
+
+	t.Run("should invalidate cache", func(t *testing.T) {
+		// given
+		oauthClientMock := &oauthMocks.Client{}
+		oauthClientMock.On("InvalidateTokenCacheJWTAssertion", "clientId", "www.example.com/token", privateKey, "keyId", tokenParameters).Return().Once()
+
+		strategy := newOAuthJWTAssertionStrategy(oauthClientMock, "clientId", privateKey, "keyId", "www.example.com/token", tokenParameters, nil)
+
+		// when
+		strategy.Invalidate()
+
+		// then
+		oauthClientMock.AssertExpectations(t)
+	})
+
+	t.Run("should not add Authorization header when getting token failed", func(t *testing.T) {
+		// given
+		oauthClientMock := &oauthMocks.Client{}
+		oauthClientMock.On("GetTokenJWTAssertion", "clientId", "www.example.com/token", privateKey, "keyId", tokenParameters, (*map[string][]string)(nil), (*map[string][]string)(nil), false).Return("", apperrors.Internalf("failed")).Once()
+
+		strategy := newOAuthJWTAssertionStrategy(oauthClientMock, "clientId", privateKey, "keyId", "www.example.com/token", tokenParameters, nil)
+
+		request, err := http.NewRequest("GET", "www.example.com", nil)
+		require.NoError(t, err)
+
+		// when
+		err = strategy.AddAuthorization(request, nil, false)
+
+		// then
+		require.Error(t, err)
+		authHeader := request.Header.Get(httpconsts.HeaderAuthorization)
+		assert.Equal(t, "", authHeader)
+		oauthClientMock.AssertExpectations(t)
+	})
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/clientcert"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
 )
 
@@ -16,23 +17,25 @@ type oauthStrategy struct {
 	clientId               string
 	clientSecret           string
 	url                    string
+	tokenParameters        oauth.TokenParameters
 	requestParameters      *RequestParameters
 	tokenRequestSkipVerify bool
 }
 
-func newOAuthStrategy(oauthClient OAuthClient, clientId, clientSecret, url string, requestParameters *RequestParameters) oauthStrategy {
+func newOAuthStrategy(oauthClient OAuthClient, clientId, clientSecret, url string, tokenParameters oauth.TokenParameters, requestParameters *RequestParameters) oauthStrategy {
 	return oauthStrategy{
 		oauthClient:       oauthClient,
 		clientId:          clientId,
 		clientSecret:      clientSecret,
 		url:               url,
+		tokenParameters:   tokenParameters,
 		requestParameters: requestParameters,
 	}
 }
 
 func (o oauthStrategy) AddAuthorization(r *http.Request, _ clientcert.SetClientCertificateFunc, skipTLSVerification bool) apperrors.AppError {
 	headers, queryParameters := o.requestParameters.unpack()
-	token, err := o.oauthClient.GetToken(o.clientId, o.clientSecret, o.url, headers, queryParameters, skipTLSVerification)
+	token, err := o.oauthClient.GetToken(o.clientId, o.clientSecret, o.url, o.tokenParameters, headers, queryParameters, skipTLSVerification)
 	if err != nil {
 		zap.L().Error("failed to get token",
 			zap.Error(err))
@@ -45,5 +48,5 @@ func (o oauthStrategy) AddAuthorization(r *http.Request, _ clientcert.SetClientC
 }
 
 func (o oauthStrategy) Invalidate() {
-	o.oauthClient.InvalidateTokenCache(o.clientId, o.clientSecret, o.url)
+	o.oauthClient.InvalidateTokenCache(o.clientId, o.clientSecret, o.url, o.tokenParameters)
 }
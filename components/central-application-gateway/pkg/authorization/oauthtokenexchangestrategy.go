@@ -0,0 +1,83 @@
+package authorization
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/clientcert"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+)
+
+type subjectTokenKey struct{}
+
+// WithSubjectToken returns the context of the call made on behalf of the user with the given token.
+// The token is kept in the context, so that it can be exchanged again when the call is retried with the Authorization header already replaced.
+func WithSubjectToken(ctx context.Context, subjectToken string) context.Context {
+	return context.WithValue(ctx, subjectTokenKey{}, subjectToken)
+}
+
+func subjectTokenFromContext(ctx context.Context) string {
+	subjectToken, _ := ctx.Value(subjectTokenKey{}).(string)
+	return subjectToken
+}
+
+type oauthTokenExchangeStrategy struct {
+	oauthClient       OAuthClient
+	clientId          string
+	clientSecret      string
+	url               string
+	exchange          oauth.TokenExchange
+	tokenParameters   oauth.TokenParameters
+	requestParameters *RequestParameters
+}
+
+func newOAuthTokenExchangeStrategy(oauthClient OAuthClient, clientId, clientSecret, url string, exchange oauth.TokenExchange, tokenParameters oauth.TokenParameters, requestParameters *RequestParameters) oauthTokenExchangeStrategy {
+	return oauthTokenExchangeStrategy{
+		oauthClient:       oauthClient,
+		clientId:          clientId,
+		clientSecret:      clientSecret,
+		url:               url,
+		exchange:          exchange,
+		tokenParameters:   tokenParameters,
+		requestParameters: requestParameters,
+	}
+}
+
+func (o oauthTokenExchangeStrategy) AddAuthorization(r *http.Request, _ clientcert.SetClientCertificateFunc, skipTLSVerification bool) apperrors.AppError {
+	subjectToken := subjectTokenFromContext(r.Context())
+	if subjectToken == "" {
+		return apperrors.WrongInputf("the call has no bearer token of the user to exchange for the token of the target system")
+	}
+
+	headers, queryParameters := o.requestParameters.unpack()
+	token, err := o.oauthClient.GetTokenExchange(o.clientId, o.clientSecret, o.url, subjectToken, o.exchange, o.tokenParameters, headers, queryParameters, skipTLSVerification)
+	if err != nil {
+		zap.L().Error("failed to exchange token",
+			zap.Error(err))
+		return err
+	}
+
+	r.Header.Set(httpconsts.HeaderAuthorization, fmt.Sprintf("Bearer %s", token))
+
+	return nil
+}
+
+// Invalidate removes the tokens of all users, e.g. when the credentials change
+func (o oauthTokenExchangeStrategy) Invalidate() {
+	o.oauthClient.InvalidateTokenCacheExchange(o.clientId, o.clientSecret, o.url, o.exchange, o.tokenParameters)
+}
+
+// InvalidateCall removes the token of the user calling the API only, so that the tokens of other users are still used
+func (o oauthTokenExchangeStrategy) InvalidateCall(r *http.Request) {
+	subjectToken := subjectTokenFromContext(r.Context())
+	if subjectToken == "" {
+		return
+	}
+
+	o.oauthClient.InvalidateTokenCacheExchangeSubject(o.clientId, o.clientSecret, o.url, subjectToken, o.exchange, o.tokenParameters)
+}
@@ -0,0 +1,109 @@
+package authorization
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth"
+	oauthMocks "github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization/oauth/mocks"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/httpconsts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuthTokenExchangeStrategy(t *testing.T) {
+	exchange := oauth.TokenExchange{GrantType: oauth.GrantTypeTokenExchange}
+	tokenParameters := oauth.TokenParameters{Audience: "orders"}
+
+	t.Run("should add Authorization header with the token exchanged for the token of the user", func(t *testing.T) {
+		// given
+		oauthClientMock := &oauthMocks.Client{}
+		oauthClientMock.On("GetTokenExchange", "clientId", "clientSecret", "www.example.com/token", "userToken", exchange, tokenParameters, (*map[string][]string)(nil), (*map[string][]string)(nil), true).Return("token", nil)
+
+		strategy := newOAuthTokenExchangeStrategy(oauthClientMock, "clientId", "clientSecret", "www.example.com/token", exchange, tokenParameters, nil)
+
+		request, err := http.NewRequest("GET", "www.example.com", nil)
+		require.NoError(t, err)
+		request = request.WithContext(WithSubjectToken(request.Context(), "userToken"))
+		request.Header.Set(httpconsts.HeaderAuthorization, "Bearer userToken")
+
+		// when
+		err = strategy.AddAuthorization(request, nil, true)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer token", request.Header.Get(httpconsts.HeaderAuthorization))
+	})
+
+	t.Run("should return error when the call has no token of the user", func(t *testing.T) {
+		// given
+		oauthClientMock := &oauthMocks.Client{}
+
+		strategy := newOAuthTokenExchangeStrategy(oauthClientMock, "clientId", "clientSecret", "www.example.com/token", exchange, tokenParameters, nil)
+
+		request, err := http.NewRequest("GET", "www.example.com", nil)
+		require.NoError(t, err)
+
+		// when
+		appErr := strategy.AddAuthorization(request, nil, false)
+
+		// then
+		require.Error(t, appErr)
+		assert.Equal(t, apperrors.CodeWrongInput, appErr.Code())
+		oauthClientMock.AssertNotCalled(t, "GetTokenExchange")
+	})
+
+	t.Run("should invalidate cache", func(t *testing.T) {
+		// given
+		oauthClientMock := &oauthMocks.Client{}
+		oauthClientMock.On("InvalidateTokenCacheExchange", "clientId", "clientSecret", "www.example.com/token", exchange, tokenParameters).Return().Once()
+
+		strategy := newOAuthTokenExchangeStrategy(oauthClientMock, "clientId", "clientSecret", "www.example.com/token", exchange, tokenParameters, nil)
+
+		// when
+		strategy.Invalidate()
+
+		// then
+		oauthClientMock.AssertExpectations(t)
+	})
+
+	t.Run("should invalidate cached token of the user of the call", func(t *testing.T) {
+		// given
+		oauthClientMock := &oauthMocks.Client{}
+		oauthClientMock.On("InvalidateTokenCacheExchangeSubject", "clientId", "clientSecret", "www.example.com/token", "userToken", exchange, tokenParameters).Return().Once()
+
+		strategy := newOAuthTokenExchangeStrategy(oauthClientMock, "clientId", "clientSecret", "www.example.com/token", exchange, tokenParameters, nil)
+
+		request, err := http.NewRequest("GET", "www.example.com", nil)
+		require.NoError(t, err)
+		request = request.WithContext(WithSubjectToken(request.Context(), "userToken"))
+
+		// when
+		strategy.InvalidateCall(request)
+
+		// then
+		oauthClientMock.AssertExpectations(t)
+		oauthClientMock.AssertNotCalled(t, "InvalidateTokenCacheExchange")
+	})
+
+	t.Run("should not add Authorization header when exchanging token failed", func(t *testing.T) {
+		// given
+		oauthClientMock := &oauthMocks.Client{}
+		oauthClientMock.On("GetTokenExchange", "clientId", "clientSecret", "www.example.com/token", "userToken", exchange, tokenParameters, (*map[string][]string)(nil), (*map[string][]string)(nil), false).Return("", apperrors.UpstreamServerCallFailed("failed")).Once()
+
+		strategy := newOAuthTokenExchangeStrategy(oauthClientMock, "clientId", "clientSecret", "www.example.com/token", exchange, tokenParameters, nil)
+
+		request, err := http.NewRequest("GET", "www.example.com", nil)
+		require.NoError(t, err)
+		request = request.WithContext(WithSubjectToken(request.Context(), "userToken"))
+
+		// when
+		err = strategy.AddAuthorization(request, nil, false)
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, "", request.Header.Get(httpconsts.HeaderAuthorization))
+		oauthClientMock.AssertExpectations(t)
+	})
+}
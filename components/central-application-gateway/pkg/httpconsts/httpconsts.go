@@ -16,6 +16,11 @@ const (
 	HeaderCacheControl         = "cache-control"
 	HeaderCacheControlVal      = "no-cache"
 	HeaderCookie               = "Cookie"
+	HeaderRetryAfter           = "Retry-After"
+	HeaderRequestID            = "X-Request-Id"
+	HeaderCacheStatus          = "Cache-Status"
+	HeaderCallerToken          = "X-Caller-Token"
+	HeaderWWWAuthenticate      = "WWW-Authenticate"
 )
 
 const (
@@ -0,0 +1,169 @@
+package httptools
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ConnectionSettings define how connections to a target system and its token endpoints are opened
+type ConnectionSettings struct {
+	// Proxy is the URL of the HTTP CONNECT or SOCKS5 proxy with its credentials as user info, nil uses the proxy from the environment, e.g. HTTPS_PROXY
+	Proxy *url.URL
+	// RootCAs are the certificate authorities trusted instead of the system ones, nil trusts the system ones
+	RootCAs *x509.CertPool
+	// MinVersion is the minimum TLS version, zero uses the default of crypto/tls
+	MinVersion uint16
+	// CipherSuites are the cipher suites allowed up to TLS 1.2, empty allows the default ones
+	CipherSuites []uint16
+	// ServerName replaces the host of the URL in SNI and in the verification of the certificate
+	ServerName string
+	// PinnedPublicKeys are SHA-256 hashes of public keys, one of which must be in the certificate chain of the server
+	PinnedPublicKeys [][]byte
+}
+
+// ForAuthorizationServer returns the settings of connections to the OAuth authorization server. As it's usually
+// served by another host than the target system, the server name and the pinned keys of the target system don't apply.
+func (s *ConnectionSettings) ForAuthorizationServer() *ConnectionSettings {
+	if s == nil {
+		return nil
+	}
+
+	result := *s
+	result.ServerName = ""
+	result.PinnedPublicKeys = nil
+
+	return &result
+}
+
+// WithConnectionSettings applies the settings to the connections, nil keeps the defaults
+func WithConnectionSettings(settings *ConnectionSettings) RoundTripperOption {
+	return func(rt *RoundTripper) {
+		if settings == nil {
+			return
+		}
+		if settings.Proxy != nil {
+			rt.transport.Proxy = http.ProxyURL(settings.Proxy)
+		}
+
+		if rt.transport.TLSClientConfig == nil {
+			rt.transport.TLSClientConfig = &tls.Config{}
+		}
+		config := rt.transport.TLSClientConfig
+		if settings.RootCAs != nil {
+			config.RootCAs = settings.RootCAs
+		}
+		if settings.MinVersion != 0 {
+			config.MinVersion = settings.MinVersion
+		}
+		if len(settings.CipherSuites) > 0 {
+			config.CipherSuites = settings.CipherSuites
+		}
+		if settings.ServerName != "" {
+			config.ServerName = settings.ServerName
+		}
+		if len(settings.PinnedPublicKeys) > 0 {
+			config.VerifyConnection = verifyPinnedPublicKeys(settings.PinnedPublicKeys)
+		}
+	}
+}
+
+// verifyPinnedPublicKeys checks the pins also when the verification of certificates is skipped
+func verifyPinnedPublicKeys(pins [][]byte) func(tls.ConnectionState) error {
+	return func(state tls.ConnectionState) error {
+		for _, certificate := range state.PeerCertificates {
+			hash := sha256.Sum256(certificate.RawSubjectPublicKeyInfo)
+			for _, pin := range pins {
+				if bytes.Equal(hash[:], pin) {
+					return nil
+				}
+			}
+		}
+
+		return errors.New("certificate chain of the server doesn't contain any of the pinned public keys")
+	}
+}
+
+// ParseProxyURL parses the URL of the proxy, which can be an HTTP proxy supporting CONNECT for HTTPS targets, or a SOCKS5 proxy
+func ParseProxyURL(rawURL string) (*url.URL, error) {
+	proxyURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme '%s', expected http, https, socks5 or socks5h", proxyURL.Scheme)
+	}
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("proxy URL '%s' has no host", proxyURL.Redacted())
+	}
+
+	return proxyURL, nil
+}
+
+// ParseCABundle parses the PEM certificates of trusted certificate authorities
+func ParseCABundle(pemCerts []byte) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemCerts) {
+		return nil, errors.New("no PEM certificates found in the CA bundle")
+	}
+
+	return pool, nil
+}
+
+// ParseTLSVersion parses the TLS version in the 1.x form
+func ParseTLSVersion(version string) (uint16, error) {
+	switch version {
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version '%s', expected 1.0, 1.1, 1.2 or 1.3", version)
+	}
+}
+
+// ParseCipherSuites parses the names of cipher suites, e.g. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256. Insecure cipher suites aren't accepted.
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	ids := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		ids[suite.Name] = suite.ID
+	}
+
+	var result []uint16
+	for _, name := range names {
+		id, found := ids[name]
+		if !found {
+			return nil, fmt.Errorf("unsupported cipher suite '%s'", name)
+		}
+		result = append(result, id)
+	}
+
+	return result, nil
+}
+
+// ParsePublicKeyPins parses the base64-encoded SHA-256 hashes of public keys
+func ParsePublicKeyPins(pins []string) ([][]byte, error) {
+	var result [][]byte
+	for _, pin := range pins {
+		hash, err := base64.StdEncoding.DecodeString(pin)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("pinned public key '%s' isn't a base64-encoded SHA-256 hash", pin)
+		}
+		result = append(result, hash)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,141 @@
+package httptools
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithConnectionSettings(t *testing.T) {
+	t.Run("should send calls through the proxy with its credentials", func(t *testing.T) {
+		// given
+		var proxiedURL, proxyAuthorization string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxiedURL = r.URL.String()
+			proxyAuthorization = r.Header.Get("Proxy-Authorization")
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer proxy.Close()
+
+		proxyURL, err := ParseProxyURL(proxy.URL)
+		require.NoError(t, err)
+		proxyURL.User = url.UserPassword("user", "secret")
+
+		transport := NewRoundTripper(WithConnectionSettings(&ConnectionSettings{Proxy: proxyURL}))
+
+		req, err := http.NewRequest(http.MethodGet, "http://target.example.com/orders", nil)
+		require.NoError(t, err)
+
+		// when
+		resp, err := transport.RoundTrip(req)
+
+		// then
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "http://target.example.com/orders", proxiedURL)
+		assert.Equal(t, "Basic "+base64.StdEncoding.EncodeToString([]byte("user:secret")), proxyAuthorization)
+	})
+}
+
+func TestWithConnectionSettingsTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+	serverKeyHash := sha256.Sum256(server.Certificate().RawSubjectPublicKeyInfo)
+
+	call := func(settings *ConnectionSettings, options ...RoundTripperOption) error {
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+
+		resp, err := NewRoundTripper(append(options, WithConnectionSettings(settings))...).RoundTrip(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+
+	t.Run("should trust the certificate authorities of the settings", func(t *testing.T) {
+		assert.Error(t, call(&ConnectionSettings{}))
+		assert.NoError(t, call(&ConnectionSettings{RootCAs: rootCAs, MinVersion: tls.VersionTLS12}))
+	})
+
+	t.Run("should verify the certificate for the server name of the settings", func(t *testing.T) {
+		// the certificate of the test server is valid for example.com
+		assert.NoError(t, call(&ConnectionSettings{RootCAs: rootCAs, ServerName: "example.com"}))
+		assert.Error(t, call(&ConnectionSettings{RootCAs: rootCAs, ServerName: "other.example.org"}))
+	})
+
+	t.Run("should accept only the certificate chain with a pinned public key", func(t *testing.T) {
+		assert.NoError(t, call(&ConnectionSettings{RootCAs: rootCAs, PinnedPublicKeys: [][]byte{serverKeyHash[:]}}))
+		assert.Error(t, call(&ConnectionSettings{RootCAs: rootCAs, PinnedPublicKeys: [][]byte{make([]byte, sha256.Size)}}))
+		assert.Error(t, call(&ConnectionSettings{PinnedPublicKeys: [][]byte{make([]byte, sha256.Size)}}, WithTLSSkipVerify(true)), "pins should be checked when verification is skipped")
+	})
+}
+
+func TestParseTLSSettings(t *testing.T) {
+	t.Run("should parse TLS versions, cipher suites and public key pins", func(t *testing.T) {
+		// when
+		version, versionErr := ParseTLSVersion("1.3")
+		cipherSuites, cipherSuitesErr := ParseCipherSuites([]string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384"})
+		pins, pinsErr := ParsePublicKeyPins([]string{base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))})
+
+		// then
+		require.NoError(t, versionErr)
+		require.NoError(t, cipherSuitesErr)
+		require.NoError(t, pinsErr)
+		assert.Equal(t, uint16(tls.VersionTLS13), version)
+		assert.Equal(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384}, cipherSuites)
+		assert.Equal(t, [][]byte{make([]byte, sha256.Size)}, pins)
+	})
+
+	t.Run("should return error when TLS settings are invalid", func(t *testing.T) {
+		_, err := ParseTLSVersion("TLSv1.2")
+		assert.Error(t, err)
+
+		_, err = ParseCipherSuites([]string{"TLS_RSA_WITH_RC4_128_SHA"})
+		assert.Error(t, err, "insecure cipher suites should be rejected")
+
+		_, err = ParsePublicKeyPins([]string{base64.StdEncoding.EncodeToString([]byte("short"))})
+		assert.Error(t, err)
+
+		_, err = ParseCABundle([]byte("no certificates"))
+		assert.Error(t, err)
+	})
+}
+
+func TestParseProxyURL(t *testing.T) {
+	t.Run("should parse proxy URLs with supported schemes", func(t *testing.T) {
+		for _, rawURL := range []string{"http://proxy:3128", "https://proxy:3129", "socks5://proxy:1080", "socks5h://proxy:1080"} {
+			// when
+			proxyURL, err := ParseProxyURL(rawURL)
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, rawURL, proxyURL.String())
+		}
+	})
+
+	t.Run("should return error when proxy URL is invalid", func(t *testing.T) {
+		for _, rawURL := range []string{"ftp://proxy:21", "proxy:3128", "http://", "http://proxy:port"} {
+			// when
+			_, err := ParseProxyURL(rawURL)
+
+			// then
+			assert.Error(t, err, rawURL)
+		}
+	})
+}
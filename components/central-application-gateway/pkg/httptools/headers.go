@@ -30,3 +30,29 @@ func RemoveHeader(reqHeaders http.Header, headerToRemove string) {
 		reqHeaders.Del(headerToRemove)
 	}
 }
+
+// SplitQuoted splits the header value by the separator outside of double-quoted strings, the escaped quotes don't end the strings
+func SplitQuoted(value string, separator rune) []string {
+	var parts []string
+	quoted := false
+	escaped := false
+	start := 0
+	for i, c := range value {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+		case c == separator && !quoted:
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+	if start < len(value) {
+		parts = append(parts, value[start:])
+	}
+
+	return parts
+}
@@ -0,0 +1,33 @@
+package httptools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitQuoted(t *testing.T) {
+	t.Run("should split the value by the separator outside of double-quoted strings", func(t *testing.T) {
+		// when
+		parts := SplitQuoted(`Digest realm="a, b", nonce="abc",NTLM`, ',')
+
+		// then
+		assert.Equal(t, []string{`Digest realm="a, b"`, ` nonce="abc"`, `NTLM`}, parts)
+	})
+
+	t.Run("should not end the quoted string at the escaped quote", func(t *testing.T) {
+		// when
+		parts := SplitQuoted(`By=spiffe://a;Subject="CN=\"x;y\"";URI=spiffe://b`, ';')
+
+		// then
+		assert.Equal(t, []string{`By=spiffe://a`, `Subject="CN=\"x;y\""`, `URI=spiffe://b`}, parts)
+	})
+
+	t.Run("should return no parts for the empty value", func(t *testing.T) {
+		// when
+		parts := SplitQuoted("", ',')
+
+		// then
+		assert.Empty(t, parts)
+	})
+}
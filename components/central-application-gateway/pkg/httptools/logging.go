@@ -7,10 +7,6 @@ import (
 	"go.uber.org/zap"
 )
 
-type ContextKey string
-
-const ContextUUID ContextKey = "context-uuid"
-
 func LogResponse(log *zap.SugaredLogger, res *http.Response) error {
 	req := res.Request
 	log.WithOptions(
@@ -48,7 +44,7 @@ func RequestLogger(label string, h http.Handler) http.Handler {
 
 		h.ServeHTTP(lw, r)
 
-		responseCode := lw.status
+		responseCode := lw.statusCode()
 		duration := time.Since(lw.start).Nanoseconds() / int64(time.Millisecond)
 
 		log := zap.L().Sugar().
@@ -72,7 +68,29 @@ func newLoggingResponseWriter(w http.ResponseWriter) *loggingResponseWriter {
 	return &loggingResponseWriter{ResponseWriter: w, start: time.Now()}
 }
 
+// WriteHeader remembers the status code before writing it, so that it's known even if writing fails
 func (w *loggingResponseWriter) WriteHeader(statusCode int) {
+	if w.status == 0 && statusCode >= http.StatusOK {
+		w.status = statusCode
+	}
 	w.ResponseWriter.WriteHeader(statusCode)
-	w.status = statusCode
+}
+
+func (w *loggingResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap allows http.ResponseController to reach the original writer, e.g. for flushing streamed responses
+func (w *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *loggingResponseWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
 }
@@ -37,6 +37,23 @@ func WithGetClientCertificate(f func(*tls.CertificateRequestInfo) (*tls.Certific
 	}
 }
 
+// WithHTTP2 sends calls only over HTTP/2, negotiated with TLS or without TLS (h2c) for plain HTTP targets
+func WithHTTP2() RoundTripperOption {
+	return func(rt *RoundTripper) {
+		protocols := new(http.Protocols)
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
+		rt.transport.Protocols = protocols
+	}
+}
+
+// WithResponseHeaderTimeout limits the time of waiting for the response headers, without limiting the time of reading the body
+func WithResponseHeaderTimeout(timeout time.Duration) RoundTripperOption {
+	return func(rt *RoundTripper) {
+		rt.transport.ResponseHeaderTimeout = timeout
+	}
+}
+
 func NewRoundTripper(options ...RoundTripperOption) *RoundTripper {
 	rt := &RoundTripper{
 		transport: newDefaultTransport(),
@@ -51,6 +68,11 @@ func (p *RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 	return p.transport.RoundTrip(req)
 }
 
+// CloseIdleConnections closes the connections which aren't used by any call
+func (p *RoundTripper) CloseIdleConnections() {
+	p.transport.CloseIdleConnections()
+}
+
 func newDefaultTransport() *http.Transport {
 	// http.DefaultTransport
 	return &http.Transport{
@@ -142,6 +142,34 @@ func TestRoundTripperMTLS(t *testing.T) {
 	require.Equal(t, res.StatusCode, http.StatusOK)
 }
 
+func TestRoundTripperHTTP2(t *testing.T) {
+	// given
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, 2, r.ProtoMajor)
+		w.WriteHeader(http.StatusOK)
+	}))
+	ts.Config.Protocols = new(http.Protocols)
+	ts.Config.Protocols.SetUnencryptedHTTP2(true)
+	ts.Start()
+	defer ts.Close()
+
+	httpClient := &http.Client{
+		Transport: NewRoundTripper(WithHTTP2()),
+	}
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+	require.NoError(t, err)
+
+	// when
+	res, err := httpClient.Do(req)
+
+	// then
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, 2, res.ProtoMajor)
+}
+
 func TestRoundTripperMTLSMissingCert_RequireAndVerifyClientCert(t *testing.T) {
 	_, err := roundTripperMTLSMissingCert(t, tls.RequireAndVerifyClientCert)
 	require.NotNil(t, err, "Should have tls: client didn't provide a certificate error")
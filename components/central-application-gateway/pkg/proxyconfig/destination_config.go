@@ -1,6 +1,9 @@
 package proxyconfig
 
 import (
+	"encoding/json"
+	"fmt"
+
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
 	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
 )
@@ -35,6 +38,58 @@ type Configuration struct {
 	Credentials       Credentials                      `json:"credentials,omitempty"`
 }
 
+// UnmarshalJSON decodes credentials according to the credentialsType field
+func (c *Configuration) UnmarshalJSON(data []byte) error {
+	var configuration struct {
+		RequestParameters *authorization.RequestParameters `json:"requestParameters,omitempty"`
+		CSRFConfig        *CSRFConfig                      `json:"csrfConfig,omitempty"`
+		CredentialsType   AuthType                         `json:"credentialsType,omitempty"`
+		Credentials       json.RawMessage                  `json:"credentials,omitempty"`
+	}
+	if err := json.Unmarshal(data, &configuration); err != nil {
+		return err
+	}
+
+	credentials, err := decodeCredentials(configuration.CredentialsType, configuration.Credentials)
+	if err != nil {
+		return err
+	}
+
+	c.RequestParameters = configuration.RequestParameters
+	c.CSRFConfig = configuration.CSRFConfig
+	c.Credentials = credentials
+
+	return nil
+}
+
+func decodeCredentials(authType AuthType, data json.RawMessage) (Credentials, error) {
+	switch authType {
+	case Undefined, NoAuth:
+		return NoAuthConfig{}, nil
+	case Oauth:
+		credentials := OauthConfig{}
+		err := unmarshalCredentials(data, &credentials)
+		return credentials, err
+	case Basic:
+		credentials := BasicAuthConfig{}
+		err := unmarshalCredentials(data, &credentials)
+		return credentials, err
+	case Certificate:
+		credentials := CertificateConfig{}
+		err := unmarshalCredentials(data, &credentials)
+		return credentials, err
+	default:
+		return nil, fmt.Errorf("unsupported credentials type '%s'", authType)
+	}
+}
+
+func unmarshalCredentials(data json.RawMessage, credentials interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, credentials)
+}
+
 type CSRFConfig struct {
 	TokenURL string `json:"tokenUrl"`
 }
@@ -0,0 +1,47 @@
+package proxyconfig
+
+import (
+	"encoding/json"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+)
+
+// SecretsRepository allows to read data of the destination secret
+type SecretsRepository interface {
+	Get(name string) (map[string][]byte, apperrors.AppError)
+}
+
+type targetConfigProvider struct {
+	secretsRepository SecretsRepository
+}
+
+// NewTargetConfigProvider creates TargetConfigProvider reading destinations from secrets.
+// Every key of the secret holds the ProxyDestinationConfig of the API with the same name encoded as JSON.
+func NewTargetConfigProvider(secretsRepository SecretsRepository) TargetConfigProvider {
+	return &targetConfigProvider{
+		secretsRepository: secretsRepository,
+	}
+}
+
+func (p *targetConfigProvider) GetDestinationConfig(secretName, apiName string) (ProxyDestinationConfig, apperrors.AppError) {
+	secret, err := p.secretsRepository.Get(secretName)
+	if err != nil {
+		return ProxyDestinationConfig{}, err
+	}
+
+	data, found := secret[apiName]
+	if !found {
+		return ProxyDestinationConfig{}, apperrors.NotFoundf("destination '%s' not found in secret '%s'", apiName, secretName)
+	}
+
+	var config ProxyDestinationConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return ProxyDestinationConfig{}, apperrors.Internalf("failed to unmarshal destination '%s' from secret '%s': %s", apiName, secretName, err.Error())
+	}
+
+	if config.TargetURL == "" {
+		return ProxyDestinationConfig{}, apperrors.Internalf("destination '%s' in secret '%s' has no target URL", apiName, secretName)
+	}
+
+	return config, nil
+}
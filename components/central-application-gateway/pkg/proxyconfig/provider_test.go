@@ -0,0 +1,121 @@
+package proxyconfig
+
+import (
+	"testing"
+
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apperrors"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/authorization"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type secretsRepositoryStub map[string]map[string][]byte
+
+func (s secretsRepositoryStub) Get(name string) (map[string][]byte, apperrors.AppError) {
+	secret, found := s[name]
+	if !found {
+		return nil, apperrors.NotFoundf("secret '%s' not found", name)
+	}
+	return secret, nil
+}
+
+func TestTargetConfigProvider_GetDestinationConfig(t *testing.T) {
+	secrets := secretsRepositoryStub{
+		"destinations": {
+			"oauth-api": []byte(`{
+				"targetUrl": "https://oauth.example.com/api",
+				"configuration": {
+					"credentialsType": "oauth",
+					"credentials": {"clientId": "client", "clientSecret": "secret", "tokenUrl": "https://oauth.example.com/token"},
+					"csrfConfig": {"tokenUrl": "https://oauth.example.com/csrf"},
+					"requestParameters": {"headers": {"X-Tenant": ["tenant"]}}
+				}
+			}`),
+			"basic-api": []byte(`{
+				"targetUrl": "https://basic.example.com/api",
+				"configuration": {
+					"credentialsType": "basicauth",
+					"credentials": {"username": "user", "password": "password"}
+				}
+			}`),
+			"public-api":  []byte(`{"targetUrl": "https://public.example.com/api"}`),
+			"unknown-api": []byte(`{"targetUrl": "https://public.example.com/api", "configuration": {"credentialsType": "unknown"}}`),
+			"invalid-api": []byte(`{"configuration": {}}`),
+		},
+	}
+	provider := NewTargetConfigProvider(secrets)
+
+	t.Run("should read destination with OAuth credentials", func(t *testing.T) {
+		// when
+		config, err := provider.GetDestinationConfig("destinations", "oauth-api")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "https://oauth.example.com/api", config.TargetURL)
+		assert.Equal(t, "https://oauth.example.com/csrf", config.Configuration.CSRFConfig.TokenURL)
+		assert.Equal(t, &map[string][]string{"X-Tenant": {"tenant"}}, config.Configuration.RequestParameters.Headers)
+
+		credentials := config.Configuration.Credentials.ToCredentials()
+		require.NotNil(t, credentials.OAuth)
+		assert.Equal(t, "client", credentials.OAuth.ClientID)
+		assert.Equal(t, "secret", credentials.OAuth.ClientSecret)
+		assert.Equal(t, "https://oauth.example.com/token", credentials.OAuth.URL)
+	})
+
+	t.Run("should read destination with Basic credentials", func(t *testing.T) {
+		// when
+		config, err := provider.GetDestinationConfig("destinations", "basic-api")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, &authorization.Credentials{
+			BasicAuth: &authorization.BasicAuth{Username: "user", Password: "password"},
+		}, config.Configuration.Credentials.ToCredentials())
+	})
+
+	t.Run("should read destination without credentials", func(t *testing.T) {
+		// when
+		config, err := provider.GetDestinationConfig("destinations", "public-api")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "https://public.example.com/api", config.TargetURL)
+		assert.Nil(t, config.Configuration.Credentials)
+	})
+
+	t.Run("should return an error for unsupported credentials type", func(t *testing.T) {
+		// when
+		_, err := provider.GetDestinationConfig("destinations", "unknown-api")
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, apperrors.CodeInternal, err.Code())
+	})
+
+	t.Run("should return an error for destination without target URL", func(t *testing.T) {
+		// when
+		_, err := provider.GetDestinationConfig("destinations", "invalid-api")
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, apperrors.CodeInternal, err.Code())
+	})
+
+	t.Run("should return not found if destination does not exist", func(t *testing.T) {
+		// when
+		_, err := provider.GetDestinationConfig("destinations", "missing-api")
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, apperrors.CodeNotFound, err.Code())
+	})
+
+	t.Run("should return not found if secret does not exist", func(t *testing.T) {
+		// when
+		_, err := provider.GetDestinationConfig("missing", "oauth-api")
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, apperrors.CodeNotFound, err.Code())
+	})
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"github.com/google/uuid"
 	"github.com/kyma-incubator/compass/components/director/pkg/correlation"
 	"github.com/kyma-incubator/compass/components/director/pkg/str"
 
@@ -15,6 +14,7 @@ import (
 	"github.com/kyma-project/kyma/components/compass-runtime-agent/internal/healthz"
 	"github.com/kyma-project/kyma/components/compass-runtime-agent/internal/kyma"
 	"github.com/kyma-project/kyma/components/compass-runtime-agent/internal/secrets"
+	"github.com/kyma-project/kyma/components/compass-runtime-agent/internal/tracing"
 	apis "github.com/kyma-project/kyma/components/compass-runtime-agent/pkg/apis/compass/v1alpha1"
 
 	"github.com/pkg/errors"
@@ -35,6 +35,14 @@ func main() {
 
 	log.Infof("Env config: %s", options.String())
 
+	shutdownTracing, err := tracing.Init(context.Background(), "compass-runtime-agent", options.TracingCollectorURL)
+	exitOnError(err, "Failed to initialize tracing")
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Warnf("Failed to flush spans: %s", err.Error())
+		}
+	}()
+
 	// Get a config to talk to the apiserver
 	log.Info("Setting up client for manager")
 	cfg, err := config.GetConfig()
@@ -93,11 +101,13 @@ func main() {
 	compassConnectionSupervisor, err := controllerDependencies.InitializeController()
 	exitOnError(err, "Failed to initialize Controller")
 
-	correlationID := uuid.New().String()
-	ctx := correlation.SaveCorrelationIDHeaderToContext(context.Background(), str.Ptr(correlation.RequestIDHeaderKey), str.Ptr(correlationID))
+	ctx, span := tracing.StartSpan(context.Background(), "initialize compass connection")
+	correlationID := tracing.CorrelationID(ctx)
+	ctx = correlation.SaveCorrelationIDHeaderToContext(ctx, str.Ptr(correlation.RequestIDHeaderKey), str.Ptr(correlationID))
 
 	log.Infoln("Initializing Compass Connection CR")
 	_, err = compassConnectionSupervisor.InitializeCompassConnection(ctx)
+	tracing.End(span, err)
 	exitOnError(err, "Failed to initialize Compass Connection CR")
 
 	log.Infoln("Initializing metrics logger")
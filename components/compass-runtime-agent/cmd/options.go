@@ -29,6 +29,7 @@ type Config struct {
 	MetricsLoggingTimeInterval   time.Duration `envconfig:"default=30m"`
 	HealthPort                   string        `envconfig:"default=8090"`
 	IntegrationNamespace         string        `envconfig:"default=kyma-system"`
+	TracingCollectorURL          string        `envconfig:"optional"`
 	Runtime                      director.RuntimeURLsConfig
 }
 
@@ -40,7 +41,8 @@ func (o *Config) String() string {
 		"SkipAppTLSVerify=%v, "+
 		"QueryLogging=%v, MetricsLoggingTimeInterval=%s, "+
 		"RuntimeEventsURL=%s, RuntimeConsoleURL=%s, "+
-		"HealthPort=%s, IntegrationNamespace=%s, CentralGatewayServiceUrl=%v",
+		"HealthPort=%s, IntegrationNamespace=%s, CentralGatewayServiceUrl=%v, "+
+		"TracingCollectorURL=%s",
 		o.AgentConfigurationSecret,
 		o.ControllerSyncPeriod.String(), o.MinimalCompassSyncTime.String(),
 		o.CertValidityRenewalThreshold, o.ClusterCertificatesSecret, o.CaCertificatesSecret,
@@ -49,6 +51,7 @@ func (o *Config) String() string {
 		o.QueryLogging, o.MetricsLoggingTimeInterval,
 		o.Runtime.EventsURL, o.Runtime.ConsoleURL,
 		o.HealthPort, o.IntegrationNamespace, o.CentralGatewayServiceUrl,
+		o.TracingCollectorURL,
 	)
 }
 
@@ -13,6 +13,7 @@ import (
 	"github.com/kyma-project/kyma/components/compass-runtime-agent/internal/compass/director"
 	"github.com/kyma-project/kyma/components/compass-runtime-agent/internal/config"
 	"github.com/kyma-project/kyma/components/compass-runtime-agent/internal/graphql"
+	"github.com/kyma-project/kyma/components/compass-runtime-agent/internal/tracing"
 	"github.com/pkg/errors"
 )
 
@@ -26,7 +27,7 @@ type ClientsProvider interface {
 func NewClientsProvider(gqlClientConstr graphql.ClientConstructor, skipCompassTLSVerification, enableLogging bool) *clientsProvider {
 	transport := http.DefaultTransport.(*http.Transport).Clone()
 	transport.TLSClientConfig.InsecureSkipVerify = skipCompassTLSVerification
-	corrIdTransport := cmp_http.NewCorrelationIDTransport(transport)
+	corrIdTransport := tracing.NewTransport(cmp_http.NewCorrelationIDTransport(transport))
 
 	return &clientsProvider{
 		gqlClientConstructor:       gqlClientConstr,
@@ -62,7 +63,7 @@ func (cp *clientsProvider) UpdateConnectionData(data cache.ConnectionData) error
 	transport.TLSClientConfig.InsecureSkipVerify = cp.skipCompassTLSVerification
 	transport.TLSClientConfig.Certificates = []tls.Certificate{data.Certificate}
 
-	cp.mtlsHTTPClient.Transport = cmp_http.NewCorrelationIDTransport(transport)
+	cp.mtlsHTTPClient.Transport = tracing.NewTransport(cmp_http.NewCorrelationIDTransport(transport))
 	cp.directorURL = data.DirectorURL
 	cp.connectorSecuredURL = data.ConnectorURL
 
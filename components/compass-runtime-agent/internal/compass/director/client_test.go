@@ -82,6 +82,7 @@ const (
 		url
 	}
 	}
+		accessStrategy
 		additionalHeaders
 		additionalQueryParams
 		requestAuth {
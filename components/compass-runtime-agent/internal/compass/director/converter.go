@@ -6,6 +6,9 @@ import (
 	kymamodel "github.com/kyma-project/kyma/components/compass-runtime-agent/internal/kyma/model"
 )
 
+// APIKeyAccessStrategy marks the auth whose single additional header or query parameter is the API key of the API
+const APIKeyAccessStrategy = "api-key"
+
 func (app Application) ToApplication() kymamodel.Application {
 	var bundles []kymamodel.APIBundle
 	if app.Bundles != nil {
@@ -129,6 +132,14 @@ func convertAuth(compassAuth *graphql.Auth) *kymamodel.Auth {
 	if compassAuth == nil {
 		return nil
 	}
+	if apiKey := convertAPIKey(compassAuth); apiKey != nil {
+		return &kymamodel.Auth{
+			Credentials: &kymamodel.Credentials{
+				APIKey:   apiKey,
+				CSRFInfo: convertCSRFInfo(compassAuth),
+			},
+		}
+	}
 	return &kymamodel.Auth{
 		Credentials:       convertCredentials(compassAuth),
 		RequestParameters: convertRequestParameters(compassAuth),
@@ -167,6 +178,37 @@ func convertCredentials(compassAuth *graphql.Auth) *kymamodel.Credentials {
 	return nil
 }
 
+// convertAPIKey returns the API key of the auth with the api-key access strategy, which has no credentials and a single additional header or query parameter with a single value.
+// Compass has no API key credentials, so such keys are passed as additional headers or query parameters. Other auths keep them as request parameters.
+func convertAPIKey(compassAuth *graphql.Auth) *kymamodel.APIKey {
+	if compassAuth.AccessStrategy == nil || *compassAuth.AccessStrategy != APIKeyAccessStrategy {
+		return nil
+	}
+	if compassAuth.Credential != nil || len(compassAuth.AdditionalHeaders)+len(compassAuth.AdditionalQueryParams) != 1 {
+		return nil
+	}
+
+	placement := kymamodel.APIKeyPlacementHeader
+	parameters := map[string][]string(compassAuth.AdditionalHeaders)
+	if len(compassAuth.AdditionalQueryParams) == 1 {
+		placement = kymamodel.APIKeyPlacementQuery
+		parameters = compassAuth.AdditionalQueryParams
+	}
+
+	for name, values := range parameters {
+		if len(values) != 1 || values[0] == "" {
+			return nil
+		}
+		return &kymamodel.APIKey{
+			Key:       values[0],
+			Name:      name,
+			Placement: placement,
+		}
+	}
+
+	return nil
+}
+
 func convertRequestParameters(compassAuth *graphql.Auth) *kymamodel.RequestParameters {
 	if compassAuth.AdditionalHeaders != nil || compassAuth.AdditionalQueryParams != nil {
 		result := &kymamodel.RequestParameters{}
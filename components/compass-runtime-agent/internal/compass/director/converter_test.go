@@ -147,6 +147,26 @@ func TestApplication_ToApplication(t *testing.T) {
 								},
 							},
 						}),
+						fixCompassBundleWithDefaultInstanceAuth("6", &graphql.Auth{
+							AccessStrategy:    stringPtr(APIKeyAccessStrategy),
+							AdditionalHeaders: graphql.HTTPHeaders{"X-API-Key": {"my-api-key"}},
+						}),
+						fixCompassBundleWithDefaultInstanceAuth("7", &graphql.Auth{
+							AccessStrategy:        stringPtr(APIKeyAccessStrategy),
+							AdditionalQueryParams: graphql.QueryParams{"api_key": {"my-api-key2"}},
+							RequestAuth: &graphql.CredentialRequestAuth{
+								Csrf: &graphql.CSRFTokenCredentialRequestAuth{
+									TokenEndpointURL: "https://csrf.apikey.example.com",
+								},
+							},
+						}),
+						fixCompassBundleWithDefaultInstanceAuth("8", &graphql.Auth{
+							AccessStrategy:    stringPtr(APIKeyAccessStrategy),
+							AdditionalHeaders: graphql.HTTPHeaders{"h1": {"v1", "v2"}},
+						}),
+						fixCompassBundleWithDefaultInstanceAuth("9", &graphql.Auth{
+							AdditionalQueryParams: graphql.QueryParams{"sap-client": {"100"}},
+						}),
 					},
 				},
 			},
@@ -215,6 +235,41 @@ func TestApplication_ToApplication(t *testing.T) {
 							},
 						},
 					}),
+					fixInternalAPIBundleWithInstanceAuth("6", &kymaModel.Auth{
+						Credentials: &kymaModel.Credentials{
+							APIKey: &kymaModel.APIKey{
+								Key:       "my-api-key",
+								Name:      "X-API-Key",
+								Placement: kymaModel.APIKeyPlacementHeader,
+							},
+						},
+					}),
+					fixInternalAPIBundleWithInstanceAuth("7", &kymaModel.Auth{
+						Credentials: &kymaModel.Credentials{
+							APIKey: &kymaModel.APIKey{
+								Key:       "my-api-key2",
+								Name:      "api_key",
+								Placement: kymaModel.APIKeyPlacementQuery,
+							},
+							CSRFInfo: &kymaModel.CSRFInfo{
+								TokenEndpointURL: "https://csrf.apikey.example.com",
+							},
+						},
+					}),
+					fixInternalAPIBundleWithInstanceAuth("8", &kymaModel.Auth{
+						RequestParameters: &kymaModel.RequestParameters{
+							Headers: &map[string][]string{
+								"h1": {"v1", "v2"},
+							},
+						},
+					}),
+					fixInternalAPIBundleWithInstanceAuth("9", &kymaModel.Auth{
+						RequestParameters: &kymaModel.RequestParameters{
+							QueryParameters: &map[string][]string{
+								"sap-client": {"100"},
+							},
+						},
+					}),
 				},
 			},
 		},
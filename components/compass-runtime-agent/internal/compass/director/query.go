@@ -106,6 +106,7 @@ func eventAPIData() string {
 func authData() string {
 	return fmt.Sprintf(`
 		credential {%s}
+		accessStrategy
 		additionalHeaders
 		additionalQueryParams
 		requestAuth {%s}
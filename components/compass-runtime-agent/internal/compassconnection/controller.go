@@ -4,7 +4,6 @@ import (
 	"context"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/kyma-incubator/compass/components/director/pkg/correlation"
 	"github.com/kyma-incubator/compass/components/director/pkg/str"
 	"github.com/sirupsen/logrus"
@@ -17,6 +16,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/kyma-project/kyma/components/compass-runtime-agent/internal/config"
+	"github.com/kyma-project/kyma/components/compass-runtime-agent/internal/tracing"
 	"github.com/kyma-project/kyma/components/compass-runtime-agent/pkg/apis/compass/v1alpha1"
 )
 
@@ -72,7 +72,11 @@ func newReconciler(client Client, supervisior Supervisor, minimalConfigSyncTime
 	}
 }
 
-func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (result reconcile.Result, err error) {
+	ctx, span := tracing.StartSpan(ctx, "reconcile compass connection")
+	defer func() {
+		tracing.End(span, err)
+	}()
 
 	if r.runtimeID == "" {
 		runtimeConfig, err := r.configProvider.GetRuntimeConfig()
@@ -81,7 +85,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 		}
 	}
 
-	correlationID := r.runtimeID + "_" + uuid.New().String()
+	correlationID := r.runtimeID + "_" + tracing.CorrelationID(ctx)
 	logFields := logrus.Fields{
 		"CompassConnection":            request.Name,
 		correlation.RequestIDHeaderKey: correlationID,
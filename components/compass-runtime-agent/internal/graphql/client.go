@@ -6,8 +6,12 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/machinebox/graphql"
+
+	"github.com/kyma-project/kyma/components/compass-runtime-agent/internal/tracing"
 )
 
 const (
@@ -23,6 +27,7 @@ type Client interface {
 
 type client struct {
 	gqlClient *graphql.Client
+	endpoint  string
 	logs      []string
 	logging   bool
 }
@@ -32,6 +37,7 @@ func New(httpClient *http.Client, graphqlEndpoint string, enableLogging bool) (C
 
 	client := &client{
 		gqlClient: gqlClient,
+		endpoint:  graphqlEndpoint,
 		logging:   enableLogging,
 		logs:      []string{},
 	}
@@ -41,12 +47,20 @@ func New(httpClient *http.Client, graphqlEndpoint string, enableLogging bool) (C
 	return client, nil
 }
 
-func (c *client) Do(ctx context.Context, req *graphql.Request, res interface{}) error {
+func (c *client) Do(ctx context.Context, req *graphql.Request, res interface{}) (err error) {
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	// the query isn't recorded as it may contain credentials of the Applications
+	ctx, span := tracing.StartSpan(ctx, "graphql call",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("graphql.endpoint", c.endpoint)))
+	defer func() {
+		tracing.End(span, err)
+	}()
+
 	c.clearLogs()
-	err := c.gqlClient.Run(ctx, req, res)
+	err = c.gqlClient.Run(ctx, req, res)
 	if err != nil {
 		for _, l := range c.logs {
 			if l != "" {
@@ -165,6 +165,12 @@ func (c converter) toCredential(applicationName string, apiBundle model.APIBundl
 				SecretName: c.nameResolver.GetCredentialsSecretName(applicationName, apiBundle.ID),
 				CSRFInfo:   csrfInfo(apiBundle.DefaultInstanceAuth.Credentials.CSRFInfo),
 			}
+		} else if apiBundle.DefaultInstanceAuth.Credentials.APIKey != nil {
+			return v1alpha1.Credentials{
+				Type:       CredentialsAPIKeyType,
+				SecretName: c.nameResolver.GetCredentialsSecretName(applicationName, apiBundle.ID),
+				CSRFInfo:   csrfInfo(apiBundle.DefaultInstanceAuth.Credentials.CSRFInfo),
+			}
 		}
 	}
 	return result
@@ -134,6 +134,28 @@ func TestConverter(t *testing.T) {
 					Description:    &emptyDescription,
 					APIDefinitions: []model.APIDefinition{},
 				},
+				{
+					ID:          "bundle4",
+					Name:        "bundleName4",
+					Description: &description,
+					APIDefinitions: []model.APIDefinition{
+						{
+							ID:          "serviceId4",
+							Name:        "serviceName4",
+							Description: "",
+							TargetUrl:   "www.example.com/4",
+						},
+					},
+					DefaultInstanceAuth: &model.Auth{
+						Credentials: &model.Credentials{
+							APIKey: &model.APIKey{
+								Key:       "my-api-key",
+								Name:      "X-API-Key",
+								Placement: model.APIKeyPlacementHeader,
+							},
+						},
+					},
+				},
 			},
 			SystemAuthsIDs: []string{"auth1", "auth2"},
 		}
@@ -224,6 +246,26 @@ func TestConverter(t *testing.T) {
 						Description: "Description not provided",
 						Entries:     []v1alpha1.Entry{},
 					},
+					{
+						ID:          "bundle4",
+						Identifier:  "",
+						Name:        "bundlename4-6015f",
+						DisplayName: "bundleName4",
+						Description: "description",
+						Entries: []v1alpha1.Entry{
+							{
+								ID:                "serviceId4",
+								Name:              "serviceName4",
+								Type:              SpecAPIType,
+								TargetUrl:         "www.example.com/4",
+								CentralGatewayUrl: "http://central-application-gateway.kyma-system.svc.cluster.local:8082/Appname1/bundlename4/servicename4",
+								Credentials: v1alpha1.Credentials{
+									Type:       "APIKey",
+									SecretName: "Appname1-bundle4",
+								},
+							},
+						},
+					},
 				},
 			},
 		}
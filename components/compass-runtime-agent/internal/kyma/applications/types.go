@@ -1,8 +1,9 @@
 package applications
 
 const (
-	CredentialsOAuthType = "OAuth"
-	CredentialsBasicType = "Basic"
+	CredentialsOAuthType  = "OAuth"
+	CredentialsBasicType  = "Basic"
+	CredentialsAPIKeyType = "APIKey"
 )
 
 type Credentials struct {
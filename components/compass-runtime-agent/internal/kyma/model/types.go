@@ -39,12 +39,14 @@ type EventAPIDefinition struct {
 	Description string
 }
 
-// Credentials contains OAuth or BasicAuth configuration along with optional CSRF data.
+// Credentials contains OAuth, BasicAuth or API key configuration along with optional CSRF data.
 type Credentials struct {
 	// OAuth configuration
 	Oauth *Oauth
 	// BasicAuth configuration
 	Basic *Basic
+	// API key configuration
+	APIKey *APIKey
 	// Optional CSRF Data
 	CSRFInfo *CSRFInfo
 }
@@ -67,6 +69,25 @@ type Basic struct {
 	Password string
 }
 
+const (
+	// APIKeyPlacementHeader sends the API key in a header
+	APIKeyPlacementHeader = "header"
+	// APIKeyPlacementQuery sends the API key in a query parameter
+	APIKeyPlacementQuery = "query"
+)
+
+// APIKey contains the API key and where it's sent
+type APIKey struct {
+	// Key to use for authentication.
+	Key string
+	// Name of the header or query parameter with the key.
+	Name string
+	// Placement of the key, header or query.
+	Placement string
+	// Prefix (optional) prepended to the key.
+	Prefix string
+}
+
 // CSRFInfo contains data for performing CSRF token request
 type CSRFInfo struct {
 	TokenEndpointURL string
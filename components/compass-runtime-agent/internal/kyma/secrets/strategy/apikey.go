@@ -0,0 +1,88 @@
+package strategy
+
+import (
+	"github.com/kyma-project/kyma/components/compass-runtime-agent/internal/apperrors"
+	"github.com/kyma-project/kyma/components/compass-runtime-agent/internal/kyma/applications"
+	"github.com/kyma-project/kyma/components/compass-runtime-agent/internal/kyma/model"
+)
+
+// The keys are read by Central Application Gateway from the Secrets of the APIKey credentials
+const (
+	APIKeyKey          = "apiKey"
+	APIKeyNameKey      = "name"
+	APIKeyPlacementKey = "placement"
+	APIKeyPrefixKey    = "prefix"
+)
+
+type apiKey struct{}
+
+func (svc *apiKey) ToCredentials(secretData SecretData, appCredentials *applications.Credentials) (model.Credentials, apperrors.AppError) {
+	return model.Credentials{
+		APIKey:   svc.readAPIKeyMap(secretData),
+		CSRFInfo: convertToModelCSRInfo(appCredentials),
+	}, nil
+}
+
+func (svc *apiKey) CredentialsProvided(credentials *model.Credentials) bool {
+	return credentials != nil && credentials.APIKey != nil && credentials.APIKey.Key != ""
+}
+
+func (svc *apiKey) CreateSecretData(credentials *model.Credentials) (SecretData, apperrors.AppError) {
+	key := credentials.APIKey
+
+	switch key.Placement {
+	case "", model.APIKeyPlacementHeader:
+	case model.APIKeyPlacementQuery:
+		if key.Name == "" {
+			return nil, apperrors.WrongInput("API key sent in a query parameter requires the name of the parameter")
+		}
+	default:
+		return nil, apperrors.WrongInput("Unsupported placement '%s' of the API key", key.Placement)
+	}
+
+	return svc.makeAPIKeyMap(key), nil
+}
+
+func (svc *apiKey) ToCredentialsInfo(credentials *model.Credentials, secretName string) applications.Credentials {
+	return applications.Credentials{
+		Type:       applications.CredentialsAPIKeyType,
+		SecretName: secretName,
+		CSRFInfo:   toAppCSRFInfo(credentials),
+	}
+}
+
+func (svc *apiKey) ShouldUpdate(currentData SecretData, newData SecretData) bool {
+	for _, key := range []string{APIKeyKey, APIKeyNameKey, APIKeyPlacementKey, APIKeyPrefixKey} {
+		if string(currentData[key]) != string(newData[key]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (svc *apiKey) makeAPIKeyMap(key *model.APIKey) map[string][]byte {
+	m := map[string][]byte{
+		APIKeyKey: []byte(key.Key),
+	}
+	if key.Name != "" {
+		m[APIKeyNameKey] = []byte(key.Name)
+	}
+	if key.Placement != "" {
+		m[APIKeyPlacementKey] = []byte(key.Placement)
+	}
+	if key.Prefix != "" {
+		m[APIKeyPrefixKey] = []byte(key.Prefix)
+	}
+
+	return m
+}
+
+func (svc *apiKey) readAPIKeyMap(data map[string][]byte) *model.APIKey {
+	return &model.APIKey{
+		Key:       string(data[APIKeyKey]),
+		Name:      string(data[APIKeyNameKey]),
+		Placement: string(data[APIKeyPlacementKey]),
+		Prefix:    string(data[APIKeyPrefixKey]),
+	}
+}
@@ -0,0 +1,188 @@
+package strategy
+
+import (
+	"testing"
+
+	"github.com/kyma-project/kyma/components/compass-runtime-agent/internal/apperrors"
+	"github.com/kyma-project/kyma/components/compass-runtime-agent/internal/kyma/applications"
+	"github.com/kyma-project/kyma/components/compass-runtime-agent/internal/kyma/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	key       = "api-key"
+	keyName   = "X-API-Key"
+	keyPrefix = "ApiKey "
+)
+
+var (
+	apiKeyCredentials = &model.Credentials{
+		APIKey: &model.APIKey{
+			Key: key, Name: keyName, Placement: model.APIKeyPlacementHeader, Prefix: keyPrefix,
+		},
+	}
+)
+
+func TestAPIKey_ToCredentials(t *testing.T) {
+
+	secretData := map[string][]byte{
+		APIKeyKey:          []byte(key),
+		APIKeyNameKey:      []byte(keyName),
+		APIKeyPlacementKey: []byte(model.APIKeyPlacementHeader),
+		APIKeyPrefixKey:    []byte(keyPrefix),
+	}
+
+	t.Run("should convert to credentials with CSRFInfo", func(t *testing.T) {
+		// given
+		apiKeyStrategy := apiKey{}
+
+		// when
+		credentials, err := apiKeyStrategy.ToCredentials(secretData, &applications.Credentials{CSRFInfo: &applications.CSRFInfo{TokenEndpointURL: "https://test.it"}})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, apiKeyCredentials.APIKey, credentials.APIKey)
+		require.NotNil(t, credentials.CSRFInfo)
+		assert.Equal(t, "https://test.it", credentials.CSRFInfo.TokenEndpointURL)
+	})
+}
+
+func TestAPIKey_CredentialsProvided(t *testing.T) {
+
+	testCases := []struct {
+		credentials *model.Credentials
+		result      bool
+	}{
+		{
+			credentials: apiKeyCredentials,
+			result:      true,
+		},
+		{
+			credentials: &model.Credentials{
+				APIKey: &model.APIKey{Name: keyName},
+			},
+			result: false,
+		},
+		{
+			credentials: nil,
+			result:      false,
+		},
+	}
+
+	t.Run("should check if credentials provided", func(t *testing.T) {
+		// given
+		apiKeyStrategy := apiKey{}
+
+		for _, test := range testCases {
+			// when
+			result := apiKeyStrategy.CredentialsProvided(test.credentials)
+
+			// then
+			assert.Equal(t, test.result, result)
+		}
+	})
+}
+
+func TestAPIKey_CreateSecretData(t *testing.T) {
+	t.Run("should create secret data", func(t *testing.T) {
+		// given
+		apiKeyStrategy := apiKey{}
+
+		// when
+		secretData, err := apiKeyStrategy.CreateSecretData(apiKeyCredentials)
+
+		//then
+		require.NoError(t, err)
+		assert.Equal(t, []byte(key), secretData[APIKeyKey])
+		assert.Equal(t, []byte(keyName), secretData[APIKeyNameKey])
+		assert.Equal(t, []byte(model.APIKeyPlacementHeader), secretData[APIKeyPlacementKey])
+		assert.Equal(t, []byte(keyPrefix), secretData[APIKeyPrefixKey])
+	})
+
+	t.Run("should create secret data without optional keys", func(t *testing.T) {
+		// given
+		apiKeyStrategy := apiKey{}
+
+		// when
+		secretData, err := apiKeyStrategy.CreateSecretData(&model.Credentials{APIKey: &model.APIKey{Key: key}})
+
+		//then
+		require.NoError(t, err)
+		assert.Equal(t, SecretData{APIKeyKey: []byte(key)}, secretData)
+	})
+
+	t.Run("should return error when credentials are invalid", func(t *testing.T) {
+		for _, credentials := range []*model.APIKey{
+			{Key: key, Placement: model.APIKeyPlacementQuery},
+			{Key: key, Name: keyName, Placement: "cookie"},
+		} {
+			// given
+			apiKeyStrategy := apiKey{}
+
+			// when
+			_, err := apiKeyStrategy.CreateSecretData(&model.Credentials{APIKey: credentials})
+
+			//then
+			require.Error(t, err)
+			assert.Equal(t, apperrors.CodeWrongInput, err.Code())
+		}
+	})
+}
+
+func TestAPIKey_ToCredentialsInfo(t *testing.T) {
+	t.Run("should convert to app credentials", func(t *testing.T) {
+		// given
+		apiKeyStrategy := apiKey{}
+
+		// when
+		appCredentials := apiKeyStrategy.ToCredentialsInfo(apiKeyCredentials, secretName)
+
+		// then
+		assert.Equal(t, applications.CredentialsAPIKeyType, appCredentials.Type)
+		assert.Equal(t, secretName, appCredentials.SecretName)
+		assert.Equal(t, "", appCredentials.AuthenticationUrl)
+	})
+}
+
+func TestAPIKey_ShouldUpdate(t *testing.T) {
+	currentData := SecretData{
+		APIKeyKey:     []byte(key),
+		APIKeyNameKey: []byte(keyName),
+	}
+
+	testCases := []struct {
+		newData SecretData
+		result  bool
+	}{
+		{
+			newData: SecretData{APIKeyKey: []byte("rotated key"), APIKeyNameKey: []byte(keyName)},
+			result:  true,
+		},
+		{
+			newData: SecretData{APIKeyKey: []byte(key), APIKeyNameKey: []byte(keyName), APIKeyPrefixKey: []byte(keyPrefix)},
+			result:  true,
+		},
+		{
+			newData: SecretData{},
+			result:  true,
+		},
+		{
+			newData: SecretData{APIKeyKey: []byte(key), APIKeyNameKey: []byte(keyName)},
+			result:  false,
+		},
+	}
+
+	t.Run("should return true when update needed", func(t *testing.T) {
+		// given
+		apiKeyStrategy := apiKey{}
+
+		for _, test := range testCases {
+			// when
+			result := apiKeyStrategy.ShouldUpdate(currentData, test.newData)
+
+			// then
+			assert.Equal(t, test.result, result)
+		}
+	})
+}
@@ -47,6 +47,10 @@ func (s *factory) NewSecretModificationStrategy(credentials *model.Credentials)
 		return &oauth{}, nil
 	}
 
+	if credentials.APIKey != nil {
+		return &apiKey{}, nil
+	}
+
 	return nil, apperrors.WrongInput("Invalid credential type provided")
 }
 
@@ -60,6 +64,10 @@ func credentialsValid(credentials *model.Credentials) bool {
 	if credentials.Oauth != nil {
 		credentialsCount++
 	}
+
+	if credentials.APIKey != nil {
+		credentialsCount++
+	}
 	return credentialsCount == 1
 }
 
@@ -69,6 +77,8 @@ func (s *factory) NewSecretAccessStrategy(credentials *applications.Credentials)
 		return &basicAuth{}, nil
 	case applications.CredentialsOAuthType:
 		return &oauth{}, nil
+	case applications.CredentialsAPIKeyType:
+		return &apiKey{}, nil
 	default:
 		return nil, apperrors.Internal("Failed to initialize secret access strategy")
 	}
@@ -24,6 +24,10 @@ func TestFactory_NewSecretModificationStrategy(t *testing.T) {
 			credentials: oauthCredentials,
 			strategy:    &oauth{},
 		},
+		{
+			credentials: apiKeyCredentials,
+			strategy:    &apiKey{},
+		},
 	}
 
 	t.Run("should create new modification strategy", func(t *testing.T) {
@@ -67,6 +71,10 @@ func TestFactory_NewSecretAccessStrategy(t *testing.T) {
 			credentials: &applications.Credentials{Type: applications.CredentialsOAuthType},
 			strategy:    &oauth{},
 		},
+		{
+			credentials: &applications.Credentials{Type: applications.CredentialsAPIKeyType},
+			strategy:    &apiKey{},
+		},
 	}
 
 	t.Run("should create new access strategy", func(t *testing.T) {
@@ -191,7 +191,7 @@ func (s *service) upsertCredentialsSecrets(directorApplication model.Application
 	for _, apiBundle := range directorApplication.ApiBundles {
 		if apiBundle.DefaultInstanceAuth != nil && apiBundle.DefaultInstanceAuth.Credentials != nil {
 			credentials := apiBundle.DefaultInstanceAuth.Credentials
-			if credentials.Basic == nil && credentials.Oauth == nil {
+			if credentials.Basic == nil && credentials.Oauth == nil && credentials.APIKey == nil {
 				continue
 			}
 			r, _ := getApplicationUIDFunc(directorApplication.Name)
@@ -0,0 +1,38 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestCorrelationID(t *testing.T) {
+	t.Run("should use the trace ID as the correlation ID", func(t *testing.T) {
+		// given
+		traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+		assert.NoError(t, err)
+		spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+		assert.NoError(t, err)
+		ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID,
+			SpanID:  spanID,
+		}))
+
+		// when
+		correlationID := CorrelationID(ctx)
+
+		// then
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", correlationID)
+	})
+
+	t.Run("should generate the correlation ID if there is no trace", func(t *testing.T) {
+		// when
+		correlationID := CorrelationID(context.Background())
+
+		// then
+		assert.NotEmpty(t, correlationID)
+		assert.NotEqual(t, correlationID, CorrelationID(context.Background()))
+	})
+}